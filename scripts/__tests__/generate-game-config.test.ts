@@ -0,0 +1,66 @@
+/**
+ * Tests for GameConfig generation from decomp symbol files
+ */
+
+import { describe, expect, it } from 'vitest'
+import {
+  parseDefineHeader,
+  parseMapFile,
+  parseSymFile,
+  renderGameConfig,
+  resolveMemoryAddresses,
+} from '../generate-game-config'
+
+describe('generate-game-config', () => {
+  it('should resolve party addresses from a pokeemerald .sym file', () => {
+    const table = parseSymFile(
+      [
+        '020244e9 g 00000001 gPlayerPartyCount',
+        '020244ec g 00000258 gPlayerParty',
+        '02024744 g 00000258 gEnemyParty',
+        '03005d8c g 00000004 gSaveBlock1Ptr',
+      ].join('\n')
+    )
+
+    expect(resolveMemoryAddresses(table)).toEqual({
+      partyCount: 0x20244e9,
+      partyData: 0x20244ec,
+      enemyParty: 0x2024744,
+      saveBlock1Ptr: 0x3005d8c,
+    })
+  })
+
+  it('should read .map symbol lines and #define headers', () => {
+    const table = parseMapFile('                0x020244ec                gPlayerParty\n')
+    parseDefineHeader(
+      '#define ENEMY_PARTY (0x02024744)\n#define PLAYER_PARTY_COUNT 0x020244e9',
+      table
+    )
+
+    expect(resolveMemoryAddresses(table)).toMatchObject({
+      partyData: 0x20244ec,
+      partyCount: 0x20244e9,
+      enemyParty: 0x2024744,
+    })
+  })
+
+  it('should render a config class with the resolved addresses', () => {
+    const source = renderGameConfig({
+      className: 'MyhackConfig',
+      name: 'My Hack',
+      title: 'POKEMON MYHK',
+      pokemonSize: 100,
+      addresses: { partyData: 0x20244ec, partyCount: 0x20244e9, enemyParty: 0x2024744 },
+    })
+
+    expect(source).toContain('export class MyhackConfig extends GameConfigBase')
+    expect(source).toContain('partyData: 0x20244ec')
+    expect(source).toContain("includes('POKEMON MYHK')")
+  })
+
+  it('should refuse to render without party symbols', () => {
+    expect(() =>
+      renderGameConfig({ className: 'X', name: 'X', title: 'X', pokemonSize: 100, addresses: {} })
+    ).toThrow('gPlayerParty')
+  })
+})
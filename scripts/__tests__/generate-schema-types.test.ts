@@ -0,0 +1,68 @@
+/**
+ * Tests for TypeScript declaration generation from the --json JSON Schema
+ */
+
+import { readFileSync } from 'fs'
+import { describe, expect, it } from 'vitest'
+import {
+  generateSaveDataTypes,
+  renderSchemaTypes,
+  SAVE_DATA_TYPES_PATH,
+  schemaToType,
+  typeName,
+} from '../generate-schema-types'
+
+describe('generate-schema-types', () => {
+  it('should name $defs entries in PascalCase', () => {
+    expect(typeName('bag_item')).toBe('BagItem')
+    expect(typeName('pokemon')).toBe('Pokemon')
+  })
+
+  it('should convert nullable, enum and array schemas', () => {
+    expect(schemaToType({ type: ['integer', 'null'] })).toBe('number | null')
+    expect(schemaToType({ enum: ['male', 'female', null] })).toBe("'male' | 'female' | null")
+    expect(schemaToType({ const: 1 })).toBe('1')
+    expect(schemaToType({ $ref: '#/$defs/play_time' })).toBe('PlayTime')
+    expect(schemaToType({ type: 'array', items: { type: ['string', 'null'] } })).toBe(
+      'readonly (string | null)[]'
+    )
+    expect(
+      schemaToType({ type: 'array', items: { type: 'array', items: { type: 'integer' } } })
+    ).toBe('readonly (readonly number[])[]')
+    expect(schemaToType({ type: 'object' })).toBe('Record<string, unknown>')
+  })
+
+  it('should declare objects as interfaces with optional keys and docs', () => {
+    const types = renderSchemaTypes(
+      {
+        description: 'Root document',
+        type: 'object',
+        properties: {
+          name: { type: 'string', description: 'Player name' },
+          box: { type: 'integer' },
+        },
+        required: ['name'],
+        $defs: { play_time: { type: 'object', properties: {}, required: [] } },
+      },
+      'Root',
+      'test.schema.json'
+    )
+
+    expect(types).toContain('// Generated from test.schema.json')
+    expect(types).toContain('export interface PlayTime {')
+    expect(types).toContain(
+      [
+        '/** Root document */',
+        'export interface Root {',
+        '  /** Player name */',
+        '  readonly name: string',
+        '  readonly box?: number',
+        '}',
+      ].join('\n')
+    )
+  })
+
+  it('should keep save-data.d.ts in step with the schema', () => {
+    expect(readFileSync(SAVE_DATA_TYPES_PATH, 'utf8')).toBe(generateSaveDataTypes())
+  })
+})
@@ -0,0 +1,224 @@
+#!/usr/bin/env -S npx tsx
+
+/**
+ * Generate a GameConfig skeleton from decomp build artifacts
+ * Reads pokeemerald-style .sym / .map files (or #define headers such as CFRU configs)
+ * and emits a ready-to-use config with the memory addresses the parser needs
+ *
+ * Usage:
+ *   tsx scripts/generate-game-config.ts <symbols.sym|.map|.h>... --name="My Hack" [--id=myhack]
+ *     [--title="POKEMON MYHK"] [--pokemon-size=100] [--out=path/to/config.ts]
+ */
+
+import fs from 'fs'
+import path from 'path'
+import { fileURLToPath } from 'url'
+
+const __dirname = path.dirname(fileURLToPath(import.meta.url))
+const GAMES_DIR = path.join(__dirname, '..', 'src', 'lib', 'parser', 'games')
+
+export type SymbolTable = Map<string, number>
+
+/**
+ * Candidate symbol names for each memory address the parser uses, in priority order
+ */
+const MEMORY_SYMBOLS = {
+  partyData: ['gPlayerParty', 'PLAYER_PARTY'],
+  partyCount: ['gPlayerPartyCount', 'PLAYER_PARTY_COUNT'],
+  enemyParty: ['gEnemyParty', 'ENEMY_PARTY'],
+  enemyPartyCount: ['gEnemyPartyCount', 'ENEMY_PARTY_COUNT'],
+  saveBlock1Ptr: ['gSaveBlock1Ptr', 'SAVE_BLOCK1_PTR'],
+  saveBlock2Ptr: ['gSaveBlock2Ptr', 'SAVE_BLOCK2_PTR'],
+} as const
+
+type MemoryAddressKey = keyof typeof MEMORY_SYMBOLS
+
+/**
+ * Parse a .sym file: "02024284 g 00000258 gPlayerParty"
+ */
+export function parseSymFile(content: string, table: SymbolTable = new Map()): SymbolTable {
+  for (const line of content.split(/\r?\n/)) {
+    const match = /^([0-9a-fA-F]{8})\s+\S+\s+[0-9a-fA-F]+\s+(\S+)$/.exec(line.trim())
+    if (match) table.set(match[2]!, parseInt(match[1]!, 16))
+  }
+  return table
+}
+
+/**
+ * Parse a GNU ld .map file: "                0x02024284                gPlayerParty"
+ */
+export function parseMapFile(content: string, table: SymbolTable = new Map()): SymbolTable {
+  for (const line of content.split(/\r?\n/)) {
+    const match = /^\s+0x([0-9a-fA-F]+)\s+([A-Za-z_][A-Za-z0-9_]*)\s*$/.exec(line)
+    if (match) table.set(match[2]!, parseInt(match[1]!, 16))
+  }
+  return table
+}
+
+/**
+ * Parse "#define NAME 0x..." lines from C headers (CFRU style configs)
+ */
+export function parseDefineHeader(content: string, table: SymbolTable = new Map()): SymbolTable {
+  for (const line of content.split(/\r?\n/)) {
+    const match = /^\s*#define\s+([A-Za-z_][A-Za-z0-9_]*)\s+\(?\s*(0x[0-9a-fA-F]+|\d+)\s*\)?/.exec(
+      line
+    )
+    if (match) table.set(match[1]!, Number(match[2]))
+  }
+  return table
+}
+
+/**
+ * Load any supported symbol source into a single table
+ */
+export function loadSymbols(files: readonly string[]): SymbolTable {
+  const table: SymbolTable = new Map()
+  for (const file of files) {
+    const content = fs.readFileSync(file, 'utf8')
+    const ext = path.extname(file).toLowerCase()
+    if (ext === '.map') parseMapFile(content, table)
+    else if (ext === '.h') parseDefineHeader(content, table)
+    else parseSymFile(content, table)
+  }
+  return table
+}
+
+/**
+ * Resolve the parser's memory addresses from a symbol table
+ */
+export function resolveMemoryAddresses(
+  table: SymbolTable
+): Partial<Record<MemoryAddressKey, number>> {
+  const resolved: Partial<Record<MemoryAddressKey, number>> = {}
+  for (const [key, candidates] of Object.entries(MEMORY_SYMBOLS) as [
+    MemoryAddressKey,
+    readonly string[],
+  ][]) {
+    const symbol = candidates.find(name => table.has(name))
+    if (symbol) resolved[key] = table.get(symbol)
+  }
+  return resolved
+}
+
+const hex = (value: number) => `0x${value.toString(16)}`
+
+/**
+ * Render a GameConfig source file following the layout of the bundled configs
+ */
+export function renderGameConfig(options: {
+  className: string
+  name: string
+  title: string
+  pokemonSize: number
+  addresses: Partial<Record<MemoryAddressKey, number>>
+}): string {
+  const { className, name, title, pokemonSize, addresses } = options
+  const { partyData, partyCount, enemyParty, enemyPartyCount } = addresses
+  if (partyData === undefined || partyCount === undefined || enemyParty === undefined) {
+    throw new Error('Symbols must define at least gPlayerParty, gPlayerPartyCount and gEnemyParty')
+  }
+
+  const enemyCount =
+    enemyPartyCount === undefined
+      ? `get enemyPartyCount() {
+      return this.partyCount + 0x8
+    },`
+      : `enemyPartyCount: ${hex(enemyPartyCount)},`
+  const pointers = [
+    addresses.saveBlock1Ptr === undefined
+      ? ''
+      : `\n  // gSaveBlock1Ptr: ${hex(addresses.saveBlock1Ptr)}`,
+    addresses.saveBlock2Ptr === undefined
+      ? ''
+      : `\n  // gSaveBlock2Ptr: ${hex(addresses.saveBlock2Ptr)}`,
+  ].join('')
+
+  return `/**
+ * ${name} configuration
+ * Generated by scripts/generate-game-config.ts - review offsets before shipping
+ */
+
+import { VANILLA_SAVE_LAYOUT, type GameConfig } from '../../core/types'
+import { GameConfigBase } from '../../core/GameConfigBase'
+
+export class ${className} extends GameConfigBase implements GameConfig {
+  readonly name = '${name.replace(/'/g, "\\'")}'
+
+  readonly pokemonSize = ${pokemonSize}
+  readonly maxPartySize = 6
+
+  // Save layout matches vanilla unless the hack moved SaveBlock fields
+  readonly saveLayout = VANILLA_SAVE_LAYOUT
+
+  // Memory addresses resolved from decomp symbols${pointers}
+  readonly memoryAddresses = {
+    partyData: ${hex(partyData)},
+    partyCount: ${hex(partyCount)},
+    enemyParty: ${hex(enemyParty)},
+    ${enemyCount}
+  } as const
+
+  get preloadRegions() {
+    return [
+      {
+        address: this.memoryAddresses.partyData,
+        size: this.pokemonSize * this.maxPartySize,
+      },
+      {
+        address: this.memoryAddresses.partyCount,
+        size: 7, // Party count + context
+      },
+    ]
+  }
+
+  canHandle(saveData: Uint8Array): boolean {
+    return this.hasValidEmeraldSignature(saveData)
+  }
+
+  canHandleMemory(gameTitle: string): boolean {
+    return gameTitle.toUpperCase().includes('${title.toUpperCase().replace(/'/g, "\\'")}')
+  }
+}
+`
+}
+
+function getArg(argv: readonly string[], name: string): string | undefined {
+  return argv.find(arg => arg.startsWith(`--${name}=`))?.split('=')[1]
+}
+
+function main() {
+  const argv = process.argv.slice(2)
+  const files = argv.filter(arg => !arg.startsWith('--'))
+  const name = getArg(argv, 'name')
+  if (!files.length || !name) {
+    console.error(
+      'Usage: tsx scripts/generate-game-config.ts <symbols.sym|.map|.h>... --name="My Hack" ' +
+        '[--id=myhack] [--title="POKEMON MYHK"] [--pokemon-size=100] [--out=config.ts]'
+    )
+    process.exit(1)
+  }
+
+  const id = getArg(argv, 'id') ?? name.toLowerCase().replace(/[^a-z0-9]+/g, '')
+  const className = `${id.charAt(0).toUpperCase()}${id.slice(1)}Config`
+  const table = loadSymbols(files)
+  const addresses = resolveMemoryAddresses(table)
+  console.log(`Loaded ${table.size} symbols from ${files.length} file(s)`)
+
+  const source = renderGameConfig({
+    className,
+    name,
+    title: getArg(argv, 'title') ?? name,
+    pokemonSize: Number(getArg(argv, 'pokemon-size') ?? 100),
+    addresses,
+  })
+
+  const out = getArg(argv, 'out') ?? path.join(GAMES_DIR, id, 'config.ts')
+  fs.mkdirSync(path.dirname(out), { recursive: true })
+  fs.writeFileSync(out, source)
+  console.log(`Wrote ${className} to ${out}`)
+  console.log('Register it in src/lib/parser/games/index.ts before the vanilla fallback.')
+}
+
+if (process.argv[1] && path.resolve(process.argv[1]) === fileURLToPath(import.meta.url)) {
+  main()
+}
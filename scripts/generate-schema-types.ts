@@ -0,0 +1,161 @@
+#!/usr/bin/env -S npx tsx
+
+/**
+ * Generate TypeScript declarations for the parser's --json output from its JSON Schema
+ * Consumers of `pokemon-save-parser <save> --json` (the web frontend's workers, third-party tools)
+ * can import save-data.d.ts instead of redeclaring the shape, and it can't drift from the schema
+ *
+ * Usage:
+ *   tsx scripts/generate-schema-types.ts [--check]
+ */
+
+import fs from 'fs'
+import path from 'path'
+import { fileURLToPath } from 'url'
+
+const __dirname = path.dirname(fileURLToPath(import.meta.url))
+const SCHEMA_DIR = path.join(__dirname, '..', 'src', 'lib', 'parser', 'schema')
+const SCHEMA_PATH = path.join(SCHEMA_DIR, 'save-data.schema.json')
+export const SAVE_DATA_TYPES_PATH = path.join(SCHEMA_DIR, 'save-data.d.ts')
+
+/**
+ * The JSON Schema keywords save-data.schema.json uses
+ */
+export interface JsonSchema {
+  readonly title?: string
+  readonly description?: string
+  readonly type?: string | readonly string[]
+  readonly properties?: Readonly<Record<string, JsonSchema>>
+  readonly required?: readonly string[]
+  readonly additionalProperties?: boolean | JsonSchema
+  readonly items?: JsonSchema
+  readonly enum?: readonly unknown[]
+  readonly const?: unknown
+  readonly oneOf?: readonly JsonSchema[]
+  readonly $ref?: string
+  readonly $defs?: Readonly<Record<string, JsonSchema>>
+}
+
+const PRIMITIVES: Readonly<Record<string, string>> = {
+  string: 'string',
+  integer: 'number',
+  number: 'number',
+  boolean: 'boolean',
+  null: 'null',
+}
+
+/** "bag_item" -> "BagItem" */
+export const typeName = (defName: string) =>
+  defName.replace(/(?:^|_)([a-z])/g, (_, letter: string) => letter.toUpperCase())
+
+// String literals in the repo's single-quote style
+const literal = (value: unknown) =>
+  typeof value === 'string'
+    ? `'${value.replace(/\\/g, '\\\\').replace(/'/g, "\\'")}'`
+    : JSON.stringify(value)
+
+const docComment = (description: string | undefined, indent: string) =>
+  description ? `${indent}/** ${description.replace(/\*\//g, '*\\/')} */\n` : ''
+
+function objectBody(schema: JsonSchema, indent: string): string {
+  const required = new Set(schema.required)
+  const lines = Object.entries(schema.properties ?? {}).map(([key, property]) => {
+    const name = /^[A-Za-z_$][\w$]*$/.test(key) ? key : JSON.stringify(key)
+    const optional = required.has(key) ? '' : '?'
+    const type = schemaToType(property, `${indent}  `)
+    const doc = docComment(property.description, `${indent}  `)
+    return `${doc}${indent}  readonly ${name}${optional}: ${type}`
+  })
+  return `{\n${lines.join('\n')}\n${indent}}`
+}
+
+// Whether a type expression has a top-level `|`, so it needs parentheses inside an array type
+function isUnion(type: string): boolean {
+  let depth = 0
+  for (let i = 0; i < type.length; i++) {
+    if ('{<('.includes(type[i]!)) depth++
+    else if ('}>)'.includes(type[i]!)) depth--
+    else if (depth === 0 && type.startsWith(' | ', i)) return true
+  }
+  return false
+}
+
+function typeFor(type: string, schema: JsonSchema, indent: string): string {
+  if (type === 'array') {
+    const item = schema.items ? schemaToType(schema.items, indent) : 'unknown'
+    const wrap = isUnion(item) || item.startsWith('readonly ')
+    return wrap ? `readonly (${item})[]` : `readonly ${item}[]`
+  }
+  if (type !== 'object') return PRIMITIVES[type] ?? 'unknown'
+  if (schema.properties) return objectBody(schema, indent)
+  // Objects documented by their TypeScript interface rather than in the schema
+  const values =
+    typeof schema.additionalProperties === 'object'
+      ? schemaToType(schema.additionalProperties, indent)
+      : 'unknown'
+  return `Record<string, ${values}>`
+}
+
+/**
+ * TypeScript type expression for a schema; nested objects are inlined and `$defs` references use
+ * the declared type names
+ */
+export function schemaToType(schema: JsonSchema, indent = ''): string {
+  if (schema.$ref) return typeName(schema.$ref.replace('#/$defs/', ''))
+  if (schema.const !== undefined) return literal(schema.const)
+  if (schema.enum) return schema.enum.map(literal).join(' | ')
+  if (schema.oneOf) return schema.oneOf.map(option => schemaToType(option, indent)).join(' | ')
+
+  const types = typeof schema.type === 'string' ? [schema.type] : (schema.type ?? [])
+  if (types.length === 0) return schema.properties ? objectBody(schema, indent) : 'unknown'
+  return types.map(type => typeFor(type, schema, indent)).join(' | ')
+}
+
+function declaration(name: string, schema: JsonSchema): string {
+  const doc = docComment(schema.description ?? schema.title, '')
+  const type = schemaToType(schema)
+  return type.startsWith('{')
+    ? `${doc}export interface ${name} ${type}`
+    : `${doc}export type ${name} = ${type}`
+}
+
+/**
+ * Declarations for a schema: every `$defs` entry, then the root document as `rootName`
+ */
+export function renderSchemaTypes(schema: JsonSchema, rootName: string, source: string): string {
+  const declarations = [
+    ...Object.entries(schema.$defs ?? {}).map(([name, def]) => declaration(typeName(name), def)),
+    declaration(rootName, schema),
+  ]
+  const header = `// Generated from ${source} by scripts/generate-schema-types.ts - do not edit`
+  return `${header}\n\n${declarations.join('\n\n')}\n`
+}
+
+/**
+ * save-data.d.ts contents for the schema currently on disk
+ */
+export function generateSaveDataTypes(): string {
+  const schema = JSON.parse(fs.readFileSync(SCHEMA_PATH, 'utf8')) as JsonSchema
+  return renderSchemaTypes(schema, 'SaveDataJson', path.basename(SCHEMA_PATH))
+}
+
+function main() {
+  const types = generateSaveDataTypes()
+  if (process.argv.includes('--check')) {
+    const current = fs.existsSync(SAVE_DATA_TYPES_PATH)
+      ? fs.readFileSync(SAVE_DATA_TYPES_PATH, 'utf8')
+      : ''
+    if (current !== types) {
+      console.error(`${SAVE_DATA_TYPES_PATH} is out of date; run npm run generate-schema-types`)
+      process.exit(1)
+    }
+    console.log(`${SAVE_DATA_TYPES_PATH} is up to date`)
+    return
+  }
+  fs.writeFileSync(SAVE_DATA_TYPES_PATH, types)
+  console.log(`Wrote ${SAVE_DATA_TYPES_PATH}`)
+}
+
+if (process.argv[1] && path.resolve(process.argv[1]) === fileURLToPath(import.meta.url)) {
+  main()
+}
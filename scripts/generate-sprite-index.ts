@@ -0,0 +1,32 @@
+#!/usr/bin/env -S npx tsx
+
+/**
+ * Generate the sprite index embedded in the parser for the team card renderer
+ * Lists the sprite names in public/sprites and public/sprites/shiny, so a Pokemon's sprite can be
+ * resolved without touching the filesystem
+ *
+ * Usage:
+ *   tsx scripts/generate-sprite-index.ts
+ */
+
+import fs from 'fs'
+import path from 'path'
+import { fileURLToPath } from 'url'
+
+const __dirname = path.dirname(fileURLToPath(import.meta.url))
+const SPRITES_DIR = path.join(__dirname, '..', 'public', 'sprites')
+const OUT_PATH = path.join(__dirname, '..', 'src', 'lib', 'parser', 'data', 'sprite_index.json')
+
+const spriteNames = (dir: string) =>
+  fs
+    .readdirSync(dir)
+    .filter(file => file.endsWith('.gif'))
+    .map(file => file.slice(0, -'.gif'.length))
+    .sort()
+
+const index = {
+  sprites: spriteNames(SPRITES_DIR),
+  shiny: spriteNames(path.join(SPRITES_DIR, 'shiny')),
+}
+fs.writeFileSync(OUT_PATH, `${JSON.stringify(index, null, 2)}\n`)
+console.log(`Indexed ${index.sprites.length} sprites (${index.shiny.length} shiny) in ${OUT_PATH}`)
@@ -2,6 +2,7 @@ import { FaHashtag } from 'react-icons/fa6'
 import { useRef } from 'react'
 import { MousePointerClick } from 'lucide-react'
 import { Skeleton } from '@/components/common'
+import { PokemonLegalityBadge } from '@/components/pokemon/PokemonLegalityBadge'
 import { PokemonTypeBadge } from '@/components/pokemon/PokemonTypeBadge'
 import { useActivePokemonLoading, useMegaPreview } from '@/hooks'
 import { usePokemonStore } from '@/stores'
@@ -114,7 +115,9 @@ export const PokemonHeader: React.FC = () => {
               <PokemonTypeBadge key={type} type={type} isLarge />
             ))}
           </Skeleton.Container>
-          <div className="flex items-center gap-2 min-w-8" />
+          <div className="flex items-center gap-2 min-w-8">
+            {pokemon && !isLoading && <PokemonLegalityBadge pokemon={pokemon.data} />}
+          </div>
         </div>
       </div>
     </Skeleton.LoadingProvider>
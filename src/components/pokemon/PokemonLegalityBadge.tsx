@@ -0,0 +1,28 @@
+import { ShieldAlert, ShieldCheck } from 'lucide-react'
+import { checkLegality } from '@/lib/parser/core/legality'
+import type { PokemonBase } from '@/lib/parser/core/PokemonBase'
+import { useSaveFileStore } from '@/stores'
+
+// Legality summary for the active Pokemon; the issue list is shown as the badge's tooltip
+export const PokemonLegalityBadge: React.FC<{ pokemon: PokemonBase }> = ({ pokemon }) => {
+  const config = useSaveFileStore(s => s.parser?.gameConfig)
+  if (!config) return null
+
+  const { legal, issues } = checkLegality(pokemon, config)
+  const problems = issues.filter(({ severity }) => severity !== 'info')
+  if (problems.length === 0 && issues.length > 0) return null
+
+  const Icon = legal ? ShieldCheck : ShieldAlert
+  const colors = legal
+    ? 'dark:bg-green-900/50 bg-green-100 dark:text-green-300 text-green-800 dark:border-green-800 border-green-300'
+    : 'dark:bg-amber-900/50 bg-amber-100 dark:text-amber-300 text-amber-800 dark:border-amber-800 border-amber-300'
+  return (
+    <div
+      className={`${colors} text-xs px-2 py-1 rounded-md flex items-center gap-1.5 border`}
+      title={problems.map(({ message }) => message).join('\n') || 'No legality issues found'}
+    >
+      <Icon className="w-3.5 h-3.5" strokeWidth={2} />
+      <span>{legal ? 'Legal' : `${problems.length} issue${problems.length === 1 ? '' : 's'}`}</span>
+    </div>
+  )
+}
@@ -0,0 +1,124 @@
+/**
+ * Tests for PC box organization: sorting, compacting, bulk moves and write-back
+ */
+
+import { describe, expect, it } from 'vitest'
+import { BoxManager } from '../core/boxManager'
+import type { PokemonBase } from '../core/PokemonBase'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import type { SaveData } from '../core/types'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+// Minimal stand-ins for the fields the sort keys read
+const stub = (speciesId: number, level: number, nickname: string, isEgg = false) =>
+  ({ speciesId, level, nickname, isEgg }) as PokemonBase
+
+const withBoxes = (boxes: (PokemonBase | null)[][], skipped: SaveData['skipped_slots'] = []) =>
+  ({ party_pokemon: [], boxes, skipped_slots: skipped }) as unknown as SaveData
+
+const emptyBox = () => new Array<PokemonBase | null>(30).fill(null)
+
+const names = (box: readonly (PokemonBase | null)[]) => box.map(p => p?.nickname ?? null)
+
+describe('Box Manager', () => {
+  it('should sort a box by dex number, level or name and pack it into the first slots', () => {
+    const box = emptyBox()
+    box[3] = stub(252, 30, 'CHARLIE')
+    box[7] = stub(1, 5, 'ALPHA', true)
+    box[12] = stub(25, 50, 'BRAVO')
+    box[20] = stub(4, 10, 'DELTA')
+
+    const byDex = new BoxManager(withBoxes([box])).sortBox(0, 'dex').saveData.boxes![0]!
+    // Eggs always go last
+    expect(names(byDex).slice(0, 5)).toEqual(['DELTA', 'BRAVO', 'CHARLIE', 'ALPHA', null])
+
+    const byLevel = new BoxManager(withBoxes([box])).sortBox(0, 'level', { descending: true })
+    expect(names(byLevel.saveData.boxes![0]!).slice(0, 4)).toEqual([
+      'BRAVO',
+      'CHARLIE',
+      'DELTA',
+      'ALPHA',
+    ])
+
+    const byName = new BoxManager(withBoxes([box])).sortBox(0, 'name').saveData.boxes![0]!
+    expect(names(byName).slice(0, 4)).toEqual(['BRAVO', 'CHARLIE', 'DELTA', 'ALPHA'])
+    // The source save is untouched
+    expect(box[3]!.nickname).toBe('CHARLIE')
+  })
+
+  it('should compact a box around corrupt slots', () => {
+    const box = emptyBox()
+    box[2] = stub(1, 5, 'A')
+    box[5] = stub(2, 5, 'B')
+    box[9] = stub(3, 5, 'C')
+    // Box 1 slot 2 failed the sanity checks, so its bytes stay where they are
+    const skipped = [{ box: 1, slot: 2, reason: 'checksum-mismatch' }] as SaveData['skipped_slots']
+
+    const compacted = new BoxManager(withBoxes([box], skipped)).compactBox(0).saveData.boxes![0]!
+    expect(names(compacted).slice(0, 5)).toEqual(['A', null, 'B', 'C', null])
+  })
+
+  it('should move selected Pokemon into the first free slots of another box', () => {
+    const first = emptyBox()
+    first[0] = stub(1, 5, 'A')
+    first[4] = stub(2, 5, 'B')
+    const second = emptyBox()
+    second[0] = stub(3, 5, 'C')
+
+    const moved = new BoxManager(withBoxes([first, second])).moveToBox(
+      [
+        { box: 0, slot: 4 },
+        { box: 0, slot: 0 },
+        { box: 1, slot: 0 },
+      ],
+      1
+    ).saveData.boxes!
+    expect(moved[0]!.every(p => p === null)).toBe(true)
+    expect(names(moved[1]!).slice(0, 4)).toEqual(['C', 'B', 'A', null])
+  })
+
+  it('should reject moves that do not fit without changing anything', () => {
+    const full = emptyBox().map((_, slot) => stub(1, 5, `F${slot}`))
+    const other = emptyBox()
+    other[0] = stub(2, 5, 'A')
+    const manager = new BoxManager(withBoxes([full, other]))
+
+    expect(() => manager.moveToBox([{ box: 1, slot: 0 }], 0)).toThrow(
+      'Box 1 has 0 free slots, 1 Pokemon selected'
+    )
+    expect(() => manager.moveToBox([{ box: 1, slot: 1 }], 0)).toThrow('Box 2 slot 2 is empty')
+    expect(() => manager.sortBox(14, 'dex')).toThrow('Box 15 does not exist')
+    expect(manager.saveData.boxes![1]![0]!.nickname).toBe('A')
+  })
+
+  it('should write organized boxes back to the save', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const parsed = await parser.parse(loadSave('emerald.sav'))
+    const copies = ['CHARLIE', 'ALPHA', 'BRAVO'].map(name => {
+      const pokemon = parsed.party_pokemon[0]!.toBoxPokemon()
+      pokemon.nickname = name
+      return pokemon
+    })
+    const boxes = parsed.boxes!.map(box => [...box])
+    boxes[0]![4] = copies[0]!
+    boxes[0]![9] = copies[1]!
+    boxes[3]![0] = copies[2]!
+
+    const manager = new BoxManager({ ...parsed, boxes })
+      .moveToBox([{ box: 3, slot: 0 }], 0)
+      .sortBox(0, 'name')
+    const reparsed = await parser.parse(manager.write(parser))
+
+    expect(names(reparsed.boxes![0]!).slice(0, 4)).toEqual(['ALPHA', 'BRAVO', 'CHARLIE', null])
+    expect(reparsed.boxes![3]!.every(p => p === null)).toBe(true)
+    expect(reparsed.boxes![0]![0]!.isChecksumValid).toBe(true)
+    expect(reparsed.party_pokemon).toHaveLength(1)
+  })
+
+  it('should require PC boxes', () => {
+    expect(() => new BoxManager({ party_pokemon: [] } as unknown as SaveData)).toThrow(
+      'PC boxes are not available for this save'
+    )
+  })
+})
@@ -3,8 +3,8 @@
  * Tests argument parsing, outputs, error handling, and file validation
  */
 
-import { execSync } from 'child_process'
-import { writeFileSync, mkdirSync, rmSync } from 'fs'
+import { execSync, spawnSync } from 'child_process'
+import { copyFileSync, mkdirSync, readdirSync, readFileSync, rmSync, writeFileSync } from 'fs'
 import { dirname, resolve } from 'path'
 import { fileURLToPath } from 'url'
 import { beforeAll, afterAll, describe, expect, it } from 'vitest'
@@ -69,6 +69,81 @@ describe('Parser CLI Tests', () => {
     })
   })
 
+  describe('Subcommand help', () => {
+    it("should print one command's usage and examples with --help", () => {
+      const result = execSync(`tsx "${cliPath}" organize --help`, { encoding: 'utf8' })
+      expect(result).toContain('Usage: tsx cli.ts organize [savefile.sav] --box=N')
+      expect(result).toContain('tsx cli.ts organize mysave.sav --move=1:3,2:7 --to=5')
+      expect(result).not.toContain('tsx cli.ts heal')
+    })
+
+    it('should print the full usage for help without a command', () => {
+      const result = execSync(`tsx "${cliPath}" help`, { encoding: 'utf8' })
+      expect(result).toContain('Usage: tsx cli.ts [savefile.sav] [options]')
+      expect(result).toContain('tsx cli.ts <command> --help')
+    })
+  })
+
+  describe('Subcommands', () => {
+    const emeraldPath = resolve(testDataDir, 'emerald.sav')
+
+    it("should reject flags the command doesn't take", () => {
+      expect(() =>
+        execSync(`tsx "${cliPath}" heal "${emeraldPath}" --dry-rn`, { stdio: 'pipe' })
+      ).toThrow(/Unknown option --dry-rn for heal/)
+      expect(() =>
+        execSync(`tsx "${cliPath}" heal "${emeraldPath}" --jobs=3`, { stdio: 'pipe' })
+      ).toThrow(/Unknown option --jobs for heal/)
+      expect(() =>
+        execSync(`tsx "${cliPath}" export "${emeraldPath}" --websocket`, { stdio: 'pipe' })
+      ).toThrow(/Unknown option --websocket for export/)
+    })
+
+    it('should keep everything after the first = of a flag value', () => {
+      const outPath = resolve(tempDir, 'healed=copy.sav')
+      execSync(`tsx "${cliPath}" heal "${emeraldPath}" --output="${outPath}"`, { stdio: 'pipe' })
+      expect(readdirSync(tempDir)).toContain('healed=copy.sav')
+    })
+
+    it('should run parse explicitly and editing commands under edit', () => {
+      const parsed = execSync(`tsx "${cliPath}" parse "${emeraldPath}" --query=player_name`, {
+        encoding: 'utf8',
+      })
+      expect(JSON.parse(parsed)).toBe('EMERALD')
+
+      const savePath = resolve(tempDir, 'edit-heal.sav')
+      copyFileSync(emeraldPath, savePath)
+      const result = execSync(`tsx "${cliPath}" edit heal "${savePath}" --dry-run`, {
+        encoding: 'utf8',
+      })
+      expect(result).toContain('Dry run: nothing written to')
+      expect(readFileSync(savePath)).toEqual(readFileSync(emeraldPath))
+    })
+
+    it('should report checks with verify and exit with 1 on problems', () => {
+      const { stdout, status } = spawnSync('tsx', [cliPath, 'verify', emeraldPath, '--json'], {
+        encoding: 'utf8',
+      })
+      const report = JSON.parse(stdout)
+      expect(Array.isArray(report.warnings)).toBe(true)
+      expect(status).toBe(report.valid ? 0 : 1)
+    })
+
+    it('should compare two saves with diff', () => {
+      const copyPath = resolve(tempDir, 'diff-copy.sav')
+      copyFileSync(emeraldPath, copyPath)
+      const summary = execSync(`tsx "${cliPath}" diff "${emeraldPath}" "${copyPath}"`, {
+        encoding: 'utf8',
+      })
+      expect(summary).toContain('No changes')
+
+      const diff = execSync(`tsx "${cliPath}" diff "${emeraldPath}" "${copyPath}" --json`, {
+        encoding: 'utf8',
+      })
+      expect(JSON.parse(diff).pokemon).toEqual({ added: [], removed: [], changed: [] })
+    })
+  })
+
   describe('String conversion utilities', () => {
     it('should convert PIKACHU to GBA bytes with --toBytes', () => {
       const result = execSync(`tsx "${cliPath}" --toBytes=PIKACHU`, { encoding: 'utf8' })
@@ -94,6 +169,24 @@ describe('Parser CLI Tests', () => {
       expect(result).toContain('Player Name: John')
     })
 
+    it('should keep stdout valid JSON when writing a trainer card with --json', () => {
+      const cardPath = resolve(tempDir, 'card.json')
+      const result = execSync(
+        `tsx "${cliPath}" "${testSavePath}" --json --trainer-card="${cardPath}"`,
+        { encoding: 'utf8' }
+      )
+      expect(JSON.parse(result).player_name).toBe('John')
+      expect(JSON.parse(readFileSync(cardPath, 'utf8')).trainer.name).toBe('John')
+    })
+
+    it('should read the save from stdin for -', () => {
+      const result = execSync(`tsx "${cliPath}" - --query=player_name`, {
+        encoding: 'utf8',
+        input: readFileSync(testSavePath),
+      })
+      expect(JSON.parse(result)).toBe('John')
+    })
+
     it('should show debug output with --debug flag', () => {
       const result = execSync(`tsx "${cliPath}" "${testSavePath}" --debug`, { encoding: 'utf8' })
       expect(result).toContain('--- Party Pokémon Summary (FILE MODE) ---')
@@ -129,6 +222,143 @@ describe('Parser CLI Tests', () => {
     })
   })
 
+  describe('Save writing', () => {
+    const emeraldPath = resolve(testDataDir, 'emerald.sav')
+
+    it('should back up a save before overwriting it', () => {
+      const savePath = resolve(tempDir, 'overwrite.sav')
+      copyFileSync(emeraldPath, savePath)
+
+      const result = execSync(`tsx "${cliPath}" heal "${savePath}" --output="${savePath}"`, {
+        encoding: 'utf8',
+      })
+      const backups = readdirSync(tempDir).filter(name =>
+        /^overwrite\.sav\.\d{8}T\d+Z\.bak$/.test(name)
+      )
+      expect(backups).toHaveLength(1)
+      expect(result).toContain(`Backup: ${resolve(tempDir, backups[0]!)}`)
+      expect(readFileSync(resolve(tempDir, backups[0]!))).toEqual(readFileSync(emeraldPath))
+      expect(readFileSync(savePath)).not.toEqual(readFileSync(emeraldPath))
+      expect(readdirSync(tempDir).some(name => name.endsWith('.tmp'))).toBe(false)
+    })
+
+    it('should skip the backup with --no-backup', () => {
+      const savePath = resolve(tempDir, 'no-backup.sav')
+      copyFileSync(emeraldPath, savePath)
+
+      execSync(`tsx "${cliPath}" heal "${savePath}" --output="${savePath}" --no-backup`, {
+        encoding: 'utf8',
+      })
+      expect(readdirSync(tempDir).some(name => name.startsWith('no-backup.sav.'))).toBe(false)
+    })
+
+    it('should leave the save untouched on a dry run', () => {
+      const savePath = resolve(tempDir, 'dry-run.sav')
+      copyFileSync(emeraldPath, savePath)
+
+      const result = execSync(
+        `tsx "${cliPath}" heal "${savePath}" --output="${savePath}" --dry-run`,
+        { encoding: 'utf8' }
+      )
+      expect(result).toContain('--- Changes (')
+      expect(result).toContain(`Dry run: nothing written to ${savePath}`)
+      expect(readFileSync(savePath)).toEqual(readFileSync(emeraldPath))
+    })
+
+    it('should leave the save untouched when normalizing on a dry run', () => {
+      const savePath = resolve(tempDir, 'dry-run-normalize.sav')
+      copyFileSync(emeraldPath, savePath)
+
+      const result = execSync(
+        `tsx "${cliPath}" normalize "${savePath}" --container=64k --output="${savePath}" --dry-run`,
+        { encoding: 'utf8' }
+      )
+      expect(result).toContain('-> 64k')
+      expect(result).toContain(`Dry run: nothing written to ${savePath}`)
+      expect(readFileSync(savePath)).toEqual(readFileSync(emeraldPath))
+    })
+
+    it('should still accept the deprecated --out= spelling', () => {
+      const savePath = resolve(tempDir, 'deprecated-out.sav')
+
+      const { stderr, status } = spawnSync(
+        'tsx',
+        [cliPath, 'heal', emeraldPath, `--out=${savePath}`, '--no-backup'],
+        { encoding: 'utf8' }
+      )
+      expect(status).toBe(0)
+      expect(stderr).toContain('--out is deprecated; use --output=')
+      expect(readFileSync(savePath)).not.toEqual(readFileSync(emeraldPath))
+    })
+  })
+
+  describe('Batches', () => {
+    it('should keep input order and fail only the broken save across worker threads', () => {
+      const savesDir = resolve(tempDir, 'jobs')
+      mkdirSync(savesDir, { recursive: true })
+      const files = ['b.sav', 'broken.sav', 'a.sav', 'c.sav'].map(name => resolve(savesDir, name))
+      copyFileSync(testSavePath, files[0]!)
+      writeFileSync(files[1]!, 'not a save')
+      copyFileSync(resolve(testDataDir, 'emerald.sav'), files[2]!)
+      copyFileSync(testSavePath, files[3]!)
+
+      const run = (jobs: number) =>
+        spawnSync('tsx', [cliPath, ...files, '--ndjson', `--jobs=${jobs}`], { encoding: 'utf8' })
+      const batch = run(2)
+      expect(batch.status).toBe(0)
+      const lines = batch.stdout.trim().split('\n').map(line => JSON.parse(line))
+      expect(lines.map(line => line.path)).toEqual(files)
+      expect(lines.map(line => line.player_name)).toEqual(['John', undefined, 'EMERALD', 'John'])
+      expect(lines[1].error).toBeTruthy()
+      expect(batch.stderr).toContain('Parsed 3 of 4 saves (1 failed)')
+
+      // More workers than saves, and a single worker, print the same lines
+      expect(run(8).stdout).toBe(batch.stdout)
+      expect(run(1).stdout).toBe(batch.stdout)
+    })
+  })
+
+  describe('Output files', () => {
+    it("should write the result to --output with the format's extension", () => {
+      const result = execSync(
+        `tsx "${cliPath}" "${testSavePath}" --json --output="${resolve(tempDir, 'party')}"`,
+        { encoding: 'utf8' }
+      )
+      const outPath = resolve(tempDir, 'party.json')
+      expect(result.trim()).toBe(`💾 Output written to ${outPath}`)
+      expect(JSON.parse(readFileSync(outPath, 'utf8')).player_name).toBe('John')
+
+      expect(() =>
+        execSync(`tsx "${cliPath}" "${testSavePath}" --output=party.txt`, { stdio: 'pipe' })
+      ).toThrow(/--output needs --json/)
+    })
+
+    it('should give an edited save without an extension the .sav extension', () => {
+      const result = execSync(
+        `tsx "${cliPath}" heal "${testSavePath}" --output="${resolve(tempDir, 'healed')}"`,
+        { encoding: 'utf8' }
+      )
+      expect(result).toContain(resolve(tempDir, 'healed.sav'))
+      expect(readdirSync(tempDir)).toContain('healed.sav')
+    })
+
+    it('should write one file per save of a batch to --output-dir', () => {
+      const savesDir = resolve(tempDir, 'batch')
+      mkdirSync(savesDir, { recursive: true })
+      copyFileSync(testSavePath, resolve(savesDir, 'quetzal.sav'))
+      copyFileSync(resolve(testDataDir, 'emerald.sav'), resolve(savesDir, 'emerald.sav'))
+      writeFileSync(resolve(savesDir, 'broken.sav'), 'not a save')
+
+      const outDir = resolve(tempDir, 'parsed')
+      execSync(`tsx "${cliPath}" "${savesDir}" --ndjson --output-dir="${outDir}"`, {
+        stdio: 'pipe',
+      })
+      expect(readdirSync(outDir).sort()).toEqual(['emerald.json', 'quetzal.json'])
+      const emerald = JSON.parse(readFileSync(resolve(outDir, 'emerald.json'), 'utf8'))
+      expect(emerald.player_name).toBe('EMERALD')
+    })
+  })
+
   describe('CLI flag combinations', () => {
     it('should prioritize string conversion over file parsing', () => {
       const result = execSync(`tsx "${cliPath}" "${testSavePath}" --toBytes=PIKACHU`, {
@@ -139,4 +369,40 @@ describe('Parser CLI Tests', () => {
       expect(result).not.toContain('Active save slot:')
     })
   })
+
+  describe('Config file', () => {
+    const configHome = resolve(tempDir, 'config')
+    const env = { ...process.env, XDG_CONFIG_HOME: configHome }
+
+    beforeAll(() => {
+      mkdirSync(resolve(configHome, 'pokemon-save-parser'), { recursive: true })
+      writeFileSync(
+        resolve(configHome, 'pokemon-save-parser', 'config.yaml'),
+        `# Defaults for tests\nsave_dir: '${testDataDir}'\nformat: markdown\ngame: vanilla\n`
+      )
+    })
+
+    it('should find saves in save_dir and print the configured format', () => {
+      const result = execSync(`tsx "${cliPath}" emerald.sav`, {
+        encoding: 'utf8',
+        cwd: tempDir,
+        env,
+      })
+      expect(result).toContain('| # | Pokémon | Lv |')
+      expect(result).toContain('Treecko')
+    })
+
+    it('should let flags override the config file', () => {
+      const result = execSync(`tsx "${cliPath}" emerald.sav --query=player_name`, {
+        encoding: 'utf8',
+        cwd: tempDir,
+        env,
+      })
+      expect(JSON.parse(result)).toBe('EMERALD')
+
+      expect(() =>
+        execSync(`tsx "${cliPath}" emerald.sav --game=johto`, { cwd: tempDir, env, stdio: 'pipe' })
+      ).toThrow(/Unknown game "johto"/)
+    })
+  })
 })
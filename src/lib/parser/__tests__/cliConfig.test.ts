@@ -0,0 +1,51 @@
+/**
+ * Tests for the CLI config file
+ */
+
+import { describe, expect, it } from 'vitest'
+import { cliConfigPath, parseCliConfig } from '../core/cliConfig'
+
+describe('CLI Config File', () => {
+  it('should read settings, comments and quoted values', () => {
+    const config = parseCliConfig(
+      [
+        '# pokemon-save-parser defaults',
+        '---',
+        'save_dir: ~/Games/saves  # GBA saves',
+        'format: markdown',
+        'game: "quetzal"',
+        'port: 7102',
+        '',
+      ].join('\n')
+    )
+    expect(config).toEqual({
+      save_dir: '~/Games/saves',
+      format: 'markdown',
+      game: 'quetzal',
+      port: 7102,
+    })
+    expect(parseCliConfig("save_dir: 'C:\\Saves ''Hoenn'' #1' # quoted")).toEqual({
+      save_dir: "C:\\Saves 'Hoenn' #1",
+    })
+    expect(parseCliConfig('format:\nport:')).toEqual({})
+  })
+
+  it('should report the line of anything it cannot use', () => {
+    expect(() => parseCliConfig('format: html\ncolour: red')).toThrow(
+      'Line 2: unknown setting "colour"'
+    )
+    expect(() => parseCliConfig('port: 70000')).toThrow('Line 1: port must be a number')
+    expect(() => parseCliConfig('format: pdf')).toThrow('Line 1: unknown format "pdf"')
+    expect(() => parseCliConfig('  nested: true')).toThrow('Line 1: expected "setting: value"')
+    expect(() => parseCliConfig("game: 'quetzal")).toThrow('Line 1: unterminated')
+  })
+
+  it('should live under XDG_CONFIG_HOME or ~/.config', () => {
+    expect(cliConfigPath({}, '/home/ash')).toBe(
+      '/home/ash/.config/pokemon-save-parser/config.yaml'
+    )
+    expect(cliConfigPath({ XDG_CONFIG_HOME: '/xdg' }, '/home/ash')).toBe(
+      '/xdg/pokemon-save-parser/config.yaml'
+    )
+  })
+})
@@ -0,0 +1,63 @@
+/**
+ * Tests for shell completion scripts
+ */
+
+import { describe, expect, it } from 'vitest'
+import { COMPLETION_SHELLS, type CompletionSpec, completionScript } from '../core/completion'
+
+const SPEC: CompletionSpec = {
+  program: 'pokemon-save-parser',
+  commands: [
+    { name: 'heal', summary: "Restore the party's HP" },
+    { name: 'index', summary: 'Maintain a catalog', arguments: ['add', 'list'] },
+  ],
+  options: ['--format=', '--json', '--out='],
+  values: { '--format': ['html', 'markdown'] },
+}
+
+describe('Shell Completion', () => {
+  it('should register a completion for the program in every shell', () => {
+    expect(completionScript('bash', SPEC)).toContain(
+      'complete -o filenames -F _pokemon_save_parser pokemon-save-parser'
+    )
+    expect(completionScript('zsh', SPEC)).toMatch(/^#compdef pokemon-save-parser\n/)
+    expect(completionScript('zsh', SPEC)).toContain(
+      'compdef _pokemon-save-parser pokemon-save-parser'
+    )
+    expect(completionScript('fish', SPEC)).toContain('complete -c pokemon-save-parser -f\n')
+    expect(completionScript('powershell', SPEC)).toContain(
+      "Register-ArgumentCompleter -Native -CommandName 'pokemon-save-parser'"
+    )
+  })
+
+  it('should offer commands, their arguments and option choices', () => {
+    const bash = completionScript('bash', SPEC)
+    expect(bash).toContain("local commands='heal index'")
+    expect(bash).toContain("local options='--format= --json --out='")
+    expect(bash).toContain("--format) choices='html markdown' ;;")
+    expect(bash).toContain("index) choices='add list' ;;")
+
+    const fish = completionScript('fish', SPEC)
+    expect(fish).toContain("complete -c pokemon-save-parser -l format -x -a 'html markdown'")
+    expect(fish).toContain('complete -c pokemon-save-parser -l out -r -F')
+    expect(fish).toContain('complete -c pokemon-save-parser -l json\n')
+    expect(fish).toContain("-n '__fish_seen_subcommand_from index' -a 'add list'")
+  })
+
+  it('should complete save files from the file system', () => {
+    expect(completionScript('bash', SPEC)).toContain("compgen -f -X '!*.[sS][aA][vV]'")
+    expect(completionScript('zsh', SPEC)).toContain("_files -g '*.(sav|SAV)'")
+    expect(completionScript('fish', SPEC)).toContain("-a '(__fish_complete_suffix .sav)'")
+    expect(completionScript('powershell', SPEC)).toContain("$_.Extension -eq '.sav'")
+  })
+
+  it('should quote summaries for each shell', () => {
+    expect(completionScript('zsh', SPEC)).toContain("'heal:Restore the party'\\''s HP'")
+    expect(completionScript('fish', SPEC)).toContain("-d 'Restore the party'\\''s HP'")
+    expect(completionScript('powershell', SPEC)).toContain("'heal' = 'Restore the party''s HP'")
+  })
+
+  it('should support the shells the CLI lists', () => {
+    expect(COMPLETION_SHELLS).toEqual(['bash', 'zsh', 'fish', 'powershell'])
+  })
+})
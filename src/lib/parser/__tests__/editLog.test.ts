@@ -0,0 +1,104 @@
+/**
+ * Tests for the edit transaction log: byte diffs, undo/redo and dry-run previews
+ */
+
+import { describe, expect, it } from 'vitest'
+import { EditLog, diffSaveBytes } from '../core/editLog'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+describe('Edit Log', () => {
+  it('should merge nearby changed bytes into one range', () => {
+    const before = new Uint8Array(64)
+    const after = before.slice()
+    after[4] = 1
+    after[10] = 2
+    after[40] = 3
+
+    expect(diffSaveBytes(before, after)).toEqual([
+      { offset: 4, before: new Uint8Array(7), after: Uint8Array.of(1, 0, 0, 0, 0, 0, 2) },
+      { offset: 40, before: new Uint8Array(1), after: Uint8Array.of(3) },
+    ])
+    expect(diffSaveBytes(before, before.slice())).toEqual([])
+    expect(() => diffSaveBytes(before, new Uint8Array(32))).toThrow(
+      'Save sizes differ: 64 and 32 bytes'
+    )
+  })
+
+  it('should undo and redo recorded transactions', () => {
+    const log = new EditLog(new Uint8Array(16))
+    const first = Uint8Array.of(...new Array(15).fill(0), 1)
+    const second = first.slice()
+    second[0] = 2
+
+    expect(log.record('first', first)?.changes).toHaveLength(1)
+    expect(log.record('no-op', first.slice())).toBeNull()
+    log.record('second', second)
+    expect(log.history.map(({ label }) => label)).toEqual(['first', 'second'])
+
+    expect(log.undo()?.label).toBe('second')
+    expect(log.current).toEqual(first)
+    expect(log.undo()?.label).toBe('first')
+    expect(log.current).toEqual(new Uint8Array(16))
+    expect(log.undo()).toBeNull()
+
+    expect(log.redo()?.label).toBe('first')
+    expect(log.current).toEqual(first)
+    expect(log.canRedo).toBe(true)
+
+    // A new edit drops the undone one
+    log.record('third', new Uint8Array(16).fill(3))
+    expect(log.canRedo).toBe(false)
+    expect(log.redo()).toBeNull()
+  })
+
+  it('should preview an edit without changing the loaded save', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = parser.healParty(await parser.parse(loadSave('emerald.sav')))
+    const healed = parser.reconstructSaveFile(saveData.party_pokemon)
+    const changes = parser.previewEdit(healed)
+
+    expect(changes.length).toBeGreaterThan(0)
+    // Healing only touches the party, in SaveBlock1's first sector of the active slot
+    const sectors = new Set(changes.map(({ offset }) => Math.floor(offset / 0x1000)))
+    expect(sectors.size).toBe(1)
+    // Nothing was applied: previewing again diffs against the same loaded save
+    expect(parser.previewEdit(healed)).toEqual(changes)
+    expect(parser.canUndo).toBe(false)
+  })
+
+  it('should apply, undo and redo edits through the parser', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const original = await parser.parse(loadSave('emerald.sav'))
+    const healed = parser.healParty(original)
+
+    const edited = await parser.applyEdit(
+      'Heal party',
+      parser.reconstructSaveFile(healed.party_pokemon)
+    )
+    expect(edited.party_pokemon[0]!.currentHp).toBe(20)
+    expect(parser.editHistory.map(({ label }) => label)).toEqual(['Heal party'])
+    expect(parser.saveContainer).toBe('128k-rtc')
+
+    const undone = await parser.undo()
+    expect(undone?.party_pokemon[0]!.currentHp).toBe(18)
+    expect(parser.canUndo).toBe(false)
+    expect(parser.canRedo).toBe(true)
+
+    const redone = await parser.redo()
+    expect(redone?.party_pokemon[0]!.currentHp).toBe(20)
+    expect(redone?.party_pokemon[0]!.isChecksumValid).toBe(true)
+    expect(await parser.redo()).toBeNull()
+  })
+
+  it('should start a fresh log when another save is loaded', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = parser.healParty(await parser.parse(loadSave('emerald.sav')))
+    await parser.applyEdit('Heal party', parser.reconstructSaveFile(saveData.party_pokemon))
+
+    await parser.parse(loadSave('emerald.sav'))
+    expect(parser.editHistory).toEqual([])
+    expect(await parser.undo()).toBeNull()
+  })
+})
@@ -0,0 +1,33 @@
+/**
+ * Tests for batch input glob patterns
+ */
+
+import { describe, expect, it } from 'vitest'
+import { globBase, globToRegExp, isGlob } from '../core/glob'
+
+describe('Glob Patterns', () => {
+  it('should keep * and ? within one directory and let ** cross any number', () => {
+    expect(globToRegExp('saves/*.sav').test('saves/emerald.sav')).toBe(true)
+    expect(globToRegExp('saves/*.sav').test('saves/2024/emerald.sav')).toBe(false)
+    expect(globToRegExp('saves/**/*.sav').test('saves/emerald.sav')).toBe(true)
+    expect(globToRegExp('saves/**/*.sav').test('saves/2024/run 1/emerald.sav')).toBe(true)
+    expect(globToRegExp('run?.sav').test('run1.sav')).toBe(true)
+    expect(globToRegExp('run?.sav').test('run10.sav')).toBe(false)
+  })
+
+  it('should support classes and alternatives and match everything else literally', () => {
+    expect(globToRegExp('run[12].sav').test('run2.sav')).toBe(true)
+    expect(globToRegExp('run[!12].sav').test('run2.sav')).toBe(false)
+    expect(globToRegExp('*.{sav,srm}').test('ruby.srm')).toBe(true)
+    expect(globToRegExp('*.{sav,srm}').test('ruby.sav.bak')).toBe(false)
+    expect(globToRegExp('nuzlocke (v1.2)+.sav').test('nuzlocke (v1.2)+.sav')).toBe(true)
+    expect(globToRegExp('[.sav').test('[.sav')).toBe(true)
+  })
+
+  it('should search from the directories before the first wildcard', () => {
+    expect(globBase('saves/2024/*.sav')).toBe('saves/2024')
+    expect(globBase('/home/ash/**/*.sav')).toBe('/home/ash')
+    expect(globBase('*.sav')).toBe('.')
+    expect(isGlob('saves/emerald.sav')).toBe(false)
+  })
+})
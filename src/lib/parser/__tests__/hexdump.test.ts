@@ -0,0 +1,50 @@
+/**
+ * Tests for the annotated save hexdump
+ */
+
+import { describe, expect, it } from 'vitest'
+import { annotateSave, formatHexdump } from '../core/hexdump'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadTestData } from './testData'
+
+const bytes = loadTestData('emerald.sav')
+const layout = annotateSave(bytes, new VanillaConfig())
+const field = (label: string) => layout.fields.find(region => region.label === label)
+
+describe('Save Hexdump', () => {
+  it('should title every sector with its block, slot, counter and checksum', () => {
+    expect(layout.sections).toHaveLength(32)
+    expect(layout.sections[7]!.label).toBe(
+      'sector 7: slot 1 SaveBlock2 (ID 0, save #8, checksum ok)'
+    )
+    expect(layout.sections[8]!.label).toMatch(/^sector 8: slot 1 SaveBlock1 chunk 1 \(ID 1,/)
+    expect(layout.sections[28]!.label).toMatch(/^sector 28: Hall of Fame \(part 1\)/)
+  })
+
+  it('should place fields in the physical sectors holding their chunk', () => {
+    // Sector 8 holds SaveBlock1 chunk 1, where the party starts at 0x238
+    expect(field('party 1 nickname')).toMatchObject({ offset: 0x8240, length: 10 })
+    expect(field('footer: signature')).toMatchObject({ offset: 0xff8, length: 4 })
+    // Secret bases run past the end of chunk 2 into chunk 3 (sector 10)
+    expect(field('secret bases')).toEqual({ offset: 0x9b1c, length: 1124, label: 'secret bases' })
+    expect(field('secret bases (cont.)')?.offset).toBe(0xa000)
+    expect(layout.fields.filter(region => region.label.includes('(encrypted)'))).toContainEqual({
+      offset: 0x8264,
+      length: 12,
+      label: 'party 1 growth (encrypted)',
+    })
+  })
+
+  it('should print hex, the game text and the fields starting on each line', () => {
+    const lines = formatHexdump(bytes, layout).split('\n')
+
+    expect(lines[0]).toBe('── sector 0: slot 1 PC storage chunk 3 (ID 7, save #8, checksum ok)')
+    expect(lines[2]).toBe('*')
+    expect(lines).toContain(
+      '00008240  ce cc bf bf bd c5 c9 ff  01 00 02 02 bf c7 bf cc  |TREECKO.....EMER|  ' +
+        'party 1 nickname @08240 (10 bytes), party 1 OT name @0824c (7 bytes)'
+    )
+    // The file's length, including the emulator's 16-byte clock footer
+    expect(lines.at(-1)).toBe('00020010')
+  })
+})
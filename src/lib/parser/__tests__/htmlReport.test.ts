@@ -0,0 +1,47 @@
+/**
+ * Tests for the standalone HTML save report
+ */
+
+import { describe, expect, it } from 'vitest'
+import { escapeHtml, renderHtmlReport } from '../core/htmlReport'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+describe('HTML Report', () => {
+  it('should render the trainer, party cards and Pokedex progress', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const html = renderHtmlReport(saveData, parser.gameConfig!, new Date('2024-01-01T00:00:00Z'))
+
+    expect(html.startsWith('<!DOCTYPE html>')).toBe(true)
+    expect(html).toContain('<h1>EMERALD</h1>')
+    expect(html).toContain('Trainer ID: 07327')
+    expect(html).toContain('<h3>TREECKO</h3>')
+    expect(html).toContain('Treecko · Lv 5 · Hasty')
+    expect(html).toContain('HP 18/20')
+    expect(html).toContain('<li>Pound</li><li>Leer</li>')
+    // The best stat in the party fills its bar; the rest are relative to it
+    expect(html).toContain('<span>14</span><div class="bar"><span style="width: 70%">')
+    expect(html).toContain(`Seen ${saveData.pokedex!.seen_count} / `)
+    expect(html).toContain('Generated 2024-01-01T00:00:00.000Z')
+  })
+
+  it('should be self-contained', async () => {
+    const parser = new PokemonSaveParser()
+    const saveData = await parser.parse(loadSave('quetzal.sav'))
+    const html = renderHtmlReport(saveData, parser.gameConfig!)
+
+    expect(html).toContain('<h3>Steelix</h3>')
+    expect(html).not.toMatch(/<script|<link|src=|href=/)
+  })
+
+  it('should escape names', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const html = renderHtmlReport({ ...saveData, player_name: '<b>"A&B"</b>' }, parser.gameConfig!)
+
+    expect(html).toContain('<h1>&lt;b&gt;&quot;A&amp;B&quot;&lt;/b&gt;</h1>')
+    expect(escapeHtml("it's")).toBe('it&#39;s')
+  })
+})
@@ -0,0 +1,62 @@
+/**
+ * Tests for --query path and filter expressions
+ */
+
+import { describe, expect, it } from 'vitest'
+import { evaluateQuery } from '../core/jsonQuery'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { toSaveJson } from '../core/saveJson'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const DOCUMENT = {
+  party_pokemon: [
+    { nickname: 'TREECKO', level: 5, is_shiny: false, origin: { met_level: 5 } },
+    { nickname: 'ZIGGY', level: 52, is_shiny: true, origin: { met_level: 3 } },
+  ],
+  boxes: [
+    [null, { nickname: 'ZUBAT', level: 8, is_shiny: true, origin: { met_level: 8 } }],
+    [{ nickname: 'ABRA', level: 60, is_shiny: false, origin: { met_level: 9 } }, null],
+  ],
+}
+
+describe('JSON Query', () => {
+  it('should follow fields and array indexes to a single value', () => {
+    expect(evaluateQuery('party_pokemon[0].nickname', DOCUMENT)).toBe('TREECKO')
+    expect(evaluateQuery('party_pokemon[-1].origin.met_level', DOCUMENT)).toBe(3)
+    expect(evaluateQuery('party_pokemon[6].nickname', DOCUMENT)).toBeNull()
+    expect(evaluateQuery('', DOCUMENT)).toBe(DOCUMENT)
+  })
+
+  it('should flatten wildcards and filters into one array', () => {
+    expect(evaluateQuery('party_pokemon[*].nickname', DOCUMENT)).toEqual(['TREECKO', 'ZIGGY'])
+    expect(evaluateQuery('boxes[*][?is_shiny].nickname', DOCUMENT)).toEqual(['ZUBAT'])
+    expect(evaluateQuery('boxes[*][?!is_shiny].nickname', DOCUMENT)).toEqual(['ABRA'])
+    expect(evaluateQuery('party_pokemon[?level >= 50].nickname', DOCUMENT)).toEqual(['ZIGGY'])
+    expect(evaluateQuery("boxes[*][?nickname == 'ABRA'].level", DOCUMENT)).toEqual([60])
+    expect(evaluateQuery('party_pokemon[?origin.met_level < 4].nickname', DOCUMENT)).toEqual([
+      'ZIGGY',
+    ])
+  })
+
+  it('should report where a query stops making sense', () => {
+    expect(() => evaluateQuery('party_pokemon[', DOCUMENT)).toThrow(
+      'Invalid query at character 15: expected an array index, * or ?'
+    )
+    expect(() => evaluateQuery("boxes[*][?nickname == 'ABRA]", DOCUMENT)).toThrow(
+      "expected a closing '"
+    )
+  })
+
+  it('should query the --json document of a save', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const document = toSaveJson(saveData, parser.gameConfig!, { boxes: true })
+    const json = JSON.parse(JSON.stringify(document))
+
+    expect(evaluateQuery('player_name', json)).toBe('EMERALD')
+    expect(evaluateQuery('party_pokemon[*].species_name', json)).toEqual(['Treecko'])
+    expect(evaluateQuery("party_pokemon[?species_name == 'Treecko'].level", json)).toEqual([5])
+    expect(evaluateQuery('boxes', json)).toHaveLength(14)
+  })
+})
@@ -0,0 +1,52 @@
+/**
+ * Tests for the markdown team summary and Hidden Power calculation
+ */
+
+import { describe, expect, it } from 'vitest'
+import { escapeMarkdownCell, formatMarkdownTeam } from '../core/markdownTeam'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { getHiddenPower } from '../core/utils'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+describe('Hidden Power', () => {
+  it('should derive the type and power from the IVs', () => {
+    expect(getHiddenPower([31, 31, 31, 31, 31, 31])).toEqual({ type: 'dark', power: 70 })
+    expect(getHiddenPower([0, 0, 0, 0, 0, 0])).toEqual({ type: 'fighting', power: 30 })
+    // IVs are in save order: HP, Atk, Def, Spe, SpA, SpD
+    expect(getHiddenPower([30, 31, 31, 31, 30, 31])).toEqual({ type: 'grass', power: 70 })
+    expect(getHiddenPower([31, 30, 31, 30, 30, 31])).toEqual({ type: 'fire', power: 70 })
+  })
+})
+
+describe('Markdown Team', () => {
+  it('should format the party as a table', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const lines = formatMarkdownTeam(saveData, parser.gameConfig!).split('\n')
+    const { type, power } = saveData.party_pokemon[0]!.hiddenPower
+
+    expect(lines[0]).toBe("**EMERALD**'s team (Pokemon Emerald (Vanilla))")
+    expect(lines[2]).toBe('| # | Pokémon | Lv | Nature | Ability | Item | Moves | Hidden Power |')
+    expect(lines[4]).toMatch(/^\| 1 \| Treecko \| 5 \| Hasty \| .* \|  \| Pound, Leer \| /)
+    expect(lines[4]!.endsWith(` | ${type[0]!.toUpperCase()}${type.slice(1)} ${power} |`)).toBe(true)
+    expect(lines).toHaveLength(5)
+  })
+
+  it('should mark shinies and show nicknames next to the species', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const treecko = saveData.party_pokemon[0]!
+    treecko.nickname = 'LEAFY'
+    treecko.makeShiny()
+    const markdown = formatMarkdownTeam(saveData, parser.gameConfig!)
+
+    expect(markdown).toContain('| 1 | ★ LEAFY (Treecko) | 5 |')
+    expect(markdown.endsWith('★ Shiny')).toBe(true)
+  })
+
+  it('should escape table syntax in cells', () => {
+    expect(escapeMarkdownCell('A|B')).toBe('A\\|B')
+    expect(escapeMarkdownCell('*hi*\nthere')).toBe('\\*hi\\* there')
+  })
+})
@@ -0,0 +1,55 @@
+/**
+ * Tests for MessagePack encoding of save data
+ */
+
+import { describe, expect, it } from 'vitest'
+import { decodeMsgPack, encodeMsgPack } from '../core/msgpack'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { toSaveJson } from '../core/saveJson'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const hex = (bytes: Uint8Array) => Buffer.from(bytes).toString('hex')
+
+describe('MessagePack', () => {
+  it('should use the smallest encoding for each value', () => {
+    expect(hex(encodeMsgPack(null))).toBe('c0')
+    expect(hex(encodeMsgPack(true))).toBe('c3')
+    expect(hex(encodeMsgPack(5))).toBe('05')
+    expect(hex(encodeMsgPack(-1))).toBe('ff')
+    expect(hex(encodeMsgPack(200))).toBe('ccc8')
+    expect(hex(encodeMsgPack(-200))).toBe('d1ff38')
+    expect(hex(encodeMsgPack(7327))).toBe('cd1c9f')
+    expect(hex(encodeMsgPack(0.5))).toBe('cb3fe0000000000000')
+    expect(hex(encodeMsgPack('Pound'))).toBe('a5506f756e64')
+    expect(hex(encodeMsgPack(new Uint8Array([1, 2])))).toBe('c4020102')
+    expect(hex(encodeMsgPack({ a: [1], b: undefined }))).toBe('81a1619101')
+  })
+
+  it('should round-trip values of every size', () => {
+    const value = {
+      ints: [0, 127, 128, 65535, 65536, 2 ** 32, -32, -33, -32768, -(2 ** 31) - 1],
+      text: 'é'.repeat(40) + 'x'.repeat(70000),
+      list: Array.from({ length: 20 }, (_, i) => i),
+      bytes: new Uint8Array(300).fill(7),
+      nested: { empty: {}, none: null, flag: false },
+    }
+    expect(decodeMsgPack(encodeMsgPack(value))).toEqual(value)
+  })
+
+  it('should match the JSON document of a parsed save', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const json = toSaveJson(saveData, parser.gameConfig!, { boxes: true })
+    const packed = encodeMsgPack(json)
+
+    expect(decodeMsgPack(packed)).toEqual(JSON.parse(JSON.stringify(json)))
+    expect(packed.length).toBeLessThan(JSON.stringify(json).length)
+  })
+
+  it('should reject truncated and trailing data', () => {
+    const packed = encodeMsgPack({ player_name: 'EMERALD' })
+    expect(() => decodeMsgPack(packed.subarray(0, -1))).toThrow('ends early')
+    expect(() => decodeMsgPack(new Uint8Array([...packed, 0]))).toThrow('1 trailing bytes')
+  })
+})
@@ -0,0 +1,76 @@
+/**
+ * Tests for the Parquet export and the writer underneath it
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { createParquet, type ParquetColumn } from '../core/parquet'
+import { PARQUET_POKEMON_COLUMNS, saveToParquetRows, savesToParquet } from '../core/parquetExport'
+import { allPokemon } from '../core/query'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const COLUMNS: readonly ParquetColumn[] = [
+  { name: 'id', type: 'int64' },
+  { name: 'name', type: 'string', optional: true },
+  { name: 'shiny', type: 'boolean' },
+]
+
+const indexOf = (haystack: Uint8Array, needle: readonly number[]) =>
+  Buffer.from(haystack).indexOf(Buffer.from(needle))
+
+describe('Parquet Writer', () => {
+  it('should frame the data pages and footer with PAR1 magic', () => {
+    const file = createParquet(COLUMNS, [
+      { id: 252, name: 'Treecko', shiny: true },
+      { id: 4_000_000_000, name: null, shiny: false },
+    ])
+    const view = new DataView(file.buffer)
+    const magic = [0x50, 0x41, 0x52, 0x31]
+
+    expect([...file.subarray(0, 4)]).toEqual(magic)
+    expect([...file.subarray(-4)]).toEqual(magic)
+    const footerLength = view.getUint32(file.length - 8, true)
+    const footer = Buffer.from(file.subarray(file.length - 8 - footerLength, file.length - 8))
+    for (const column of COLUMNS) expect(footer.includes(column.name)).toBe(true)
+
+    // PLAIN values: little-endian int64s and length-prefixed UTF-8
+    expect(indexOf(file, [252, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x28, 0x6b, 0xee])).toBeGreaterThan(4)
+    expect(indexOf(file, [7, 0, 0, 0, ...Buffer.from('Treecko')])).toBeGreaterThan(4)
+  })
+
+  it('should reject nulls in required columns and values of the wrong type', () => {
+    expect(() => createParquet(COLUMNS, [{ id: null, name: 'A', shiny: true }])).toThrow(
+      'Row 1 has no id'
+    )
+    expect(() => createParquet(COLUMNS, [{ id: 1.5, name: 'A', shiny: true }])).toThrow(
+      'Row 1 has a non-int64 id'
+    )
+  })
+})
+
+describe('Parquet Export', () => {
+  it('should give every Pokemon in the save a row', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const source = { saveData, config: parser.gameConfig!, fingerprint: 'abc', path: 'emerald.sav' }
+    const rows = saveToParquetRows(source)
+
+    expect(rows).toHaveLength([...allPokemon(saveData)].length)
+    expect(rows[0]).toMatchObject({
+      save: 'abc',
+      path: 'emerald.sav',
+      player_name: 'EMERALD',
+      area: 'party',
+      box: null,
+      slot: 1,
+      species_id: 252,
+      species_name: 'Treecko',
+      level: 5,
+      is_egg: false,
+      move1: 'Pound',
+    })
+    expect(Object.keys(rows[0]!)).toEqual(PARQUET_POKEMON_COLUMNS.map(column => column.name))
+    expect(() => savesToParquet([source, source])).not.toThrow()
+  })
+})
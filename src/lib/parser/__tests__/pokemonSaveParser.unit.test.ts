@@ -3,11 +3,21 @@
  * Tests core functionality independent of specific game configurations
  */
 
-import { beforeAll, describe, expect, it } from 'vitest'
+import { beforeAll, describe, expect, it, vi } from 'vitest'
 import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { PokemonBase } from '../core/PokemonBase'
+import { MgbaWebSocketClient } from '../../mgba/websocket-client'
 import { QuetzalConfig } from '../games/quetzal/config'
 import { VanillaConfig } from '../games/vanilla/config'
 import { bytesToGbaString } from '../core/utils'
+import {
+  findMappingByName,
+  findRawIdByName,
+  getMappingIndex,
+  normalizeMappingName,
+  toRawId,
+} from '../core/mappingIndex'
+import { loadSave, loadTestData } from './testData'
 
 describe('Pokemon Save Parser - Unit Tests', () => {
   let quetzalConfig: QuetzalConfig
@@ -79,6 +89,68 @@ describe('Pokemon Save Parser - Unit Tests', () => {
     })
   })
 
+  describe('Chunked Loading', () => {
+    it('should assemble chunks and parse like a single buffer', async () => {
+      const bytes = loadTestData('emerald.sav')
+
+      const parser = new PokemonSaveParser(undefined, vanillaConfig)
+      parser.beginLoad('emerald.sav')
+      for (let offset = 0; offset < bytes.length; offset += 10000) {
+        parser.writeChunk(bytes.subarray(offset, offset + 10000))
+      }
+      const chunked = await parser.finishLoad()
+
+      const directParser = new PokemonSaveParser(undefined, vanillaConfig)
+      const direct = await directParser.parse(bytes.slice().buffer)
+      expect(chunked.player_name).toBe(direct.player_name)
+      expect(chunked.party_pokemon.map(p => p.speciesId)).toEqual(
+        direct.party_pokemon.map(p => p.speciesId)
+      )
+      expect(parser.saveFileName).toBe('emerald.sav')
+    })
+
+    it('should reject chunks without beginLoad', () => {
+      const parser = new PokemonSaveParser(undefined, vanillaConfig)
+      expect(() => parser.writeChunk(new Uint8Array(4))).toThrow('No chunked load in progress')
+    })
+
+    it('should reject payloads larger than a save file', () => {
+      const parser = new PokemonSaveParser(undefined, vanillaConfig)
+      parser.beginLoad()
+      expect(() => parser.writeChunk(new Uint8Array(256 * 1024))).toThrow('exceeds maximum size')
+    })
+  })
+
+  describe('Quick Summary', () => {
+    it('should decode a vanilla preview without a full parse', async () => {
+      const parser = new PokemonSaveParser(undefined, vanillaConfig)
+      const summary = await parser.parseSummary(loadSave('emerald.sav'))
+
+      expect(summary).toMatchObject({
+        game: 'Pokemon Emerald (Vanilla)',
+        player_name: 'EMERALD',
+        play_time: { hours: 0, minutes: 26 },
+        badge_count: 0,
+        money: 3000,
+        pokedex: { seen: 4, caught: 1 },
+        party: [{ species_id: 252, level: 5 }],
+      })
+      expect(summary.badges).toHaveLength(8)
+    })
+
+    it('should omit SaveBlock fields for configs without a mapped layout', async () => {
+      const bytes = loadTestData('quetzal.sav')
+      const parser = new PokemonSaveParser()
+      const summary = await parser.parseSummary(new Uint8Array(bytes).buffer)
+      const full = await new PokemonSaveParser().parse(new Uint8Array(bytes).buffer)
+
+      expect(summary.money).toBeUndefined()
+      expect(summary.pokedex).toBeUndefined()
+      expect(summary.player_name).toBe(full.player_name)
+      expect(summary.party.map(p => p.species_id)).toEqual(full.party_pokemon.map(p => p.speciesId))
+    })
+  })
+
   describe('Configuration Constants Validation', () => {
     it('should have valid Quetzal constants defined', () => {
       expect(quetzalConfig.saveLayout.sectorSize).toBeDefined()
@@ -255,6 +327,24 @@ describe('Pokemon Save Parser - Unit Tests', () => {
       expect(quetzalConfig.mappings.moves.size).toBeGreaterThan(0)
       expect(quetzalConfig.mappings.items.size).toBeGreaterThan(0)
     })
+
+    it('should build mappings once and share them across config instances', () => {
+      expect(new VanillaConfig().mappings.pokemon).toBe(vanillaConfig.mappings.pokemon)
+      expect(getMappingIndex(vanillaConfig.mappings.pokemon)).toBe(
+        getMappingIndex(new VanillaConfig().mappings.pokemon)
+      )
+    })
+
+    it('should resolve reverse lookups through the cached index', () => {
+      const { pokemon } = vanillaConfig.mappings
+      // National dex 252 (Treecko) is internal species 277
+      expect(toRawId(pokemon, 252)).toBe(277)
+      expect(findRawIdByName(pokemon, 'treecko')).toBe(277)
+      expect(findRawIdByName(pokemon, 'TREECKO')).toBe(277)
+      expect(findMappingByName(pokemon, 'Mr. Mime')?.id).toBe(122)
+      expect(findRawIdByName(pokemon, 'not-a-pokemon')).toBeUndefined()
+      expect(normalizeMappingName('Nidoran♀')).toBe('nidoranf')
+    })
   })
 
   describe('Common EV/IV Writing Tests', () => {
@@ -308,4 +398,77 @@ describe('Pokemon Save Parser - Unit Tests', () => {
       }).toThrow('IVs array must have 6 values')
     })
   })
+
+  describe('Memory Write-Back', () => {
+    const loadEmerald = async () => {
+      const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+      return parser.parse(loadSave('emerald.sav'))
+    }
+
+    // A client whose reads and writes go to a byte map instead of a running mGBA
+    const createEmulator = (party: readonly PokemonBase[]) => {
+      const { partyData, partyCount } = vanillaConfig.memoryAddresses
+      const { pokemonSize } = vanillaConfig
+      const memory = new Map<number, number>()
+      party.forEach((pokemon, i) => {
+        pokemon.rawBytes.forEach((byte, j) => memory.set(partyData + i * pokemonSize + j, byte))
+      })
+      memory.set(partyCount, party.length)
+
+      const client = new MgbaWebSocketClient()
+      vi.spyOn(client, 'isConnected').mockReturnValue(true)
+      vi.spyOn(client, 'getGameTitle').mockResolvedValue('POKEMON EMER')
+      vi.spyOn(client, 'readBytes').mockImplementation(async (address, size) =>
+        Uint8Array.from({ length: size }, (_, i) => memory.get(address + i) ?? 0)
+      )
+      const writeBytes = vi
+        .spyOn(client, 'writeBytes')
+        .mockImplementation(async (address, data) => {
+          data.forEach((byte, i) => memory.set(address + i, byte))
+        })
+      return { client, memory, writeBytes }
+    }
+
+    it('should write edited Pokemon back to their party slots', async () => {
+      const { party_pokemon: party } = await loadEmerald()
+      const { client, writeBytes } = createEmulator(party)
+      const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+      const live = await parser.parse(client)
+      expect(live.party_pokemon[0]!.currentHp).toBe(18)
+
+      live.party_pokemon[0]!.heal()
+      await parser.writePartyToMemory(live.party_pokemon)
+      expect(writeBytes).toHaveBeenCalledTimes(1)
+      expect(writeBytes).toHaveBeenCalledWith(
+        vanillaConfig.memoryAddresses.partyData,
+        live.party_pokemon[0]!.rawBytes
+      )
+
+      const reread = await parser.getCurrentSaveData()
+      expect(reread.party_pokemon[0]!.currentHp).toBe(20)
+      expect(reread.party_pokemon[0]!.isChecksumValid).toBe(true)
+    })
+
+    it('should update the party count when the party grows', async () => {
+      const { party_pokemon: party } = await loadEmerald()
+      const { client, memory } = createEmulator(party)
+      const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+      const live = await parser.parse(client)
+
+      const copy = new PokemonBase(live.party_pokemon[0]!.rawBytes.slice(), vanillaConfig)
+      await parser.writePartyToMemory([...live.party_pokemon, copy])
+      expect(memory.get(vanillaConfig.memoryAddresses.partyCount)).toBe(2)
+
+      const reread = await parser.getCurrentSaveData()
+      expect(reread.party_pokemon.map(pokemon => pokemon.nickname)).toEqual(['TREECKO', 'TREECKO'])
+    })
+
+    it('should refuse to write outside memory mode', async () => {
+      const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+      const { party_pokemon: party } = await parser.parse(loadSave('emerald.sav'))
+      await expect(parser.writePartyToMemory(party)).rejects.toThrow(
+        'writePartyToMemory only available in memory mode'
+      )
+    })
+  })
 })
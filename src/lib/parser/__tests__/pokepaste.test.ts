@@ -0,0 +1,63 @@
+/**
+ * Tests for the pokepast.es export and upload
+ */
+
+import { describe, expect, it, vi } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { createPokepaste, POKEPASTE_URL, uploadPokepaste } from '../core/pokepaste'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const pokepaste = { title: 'Team', author: 'EMERALD', notes: '', paste: 'Treecko\n- Pound' }
+
+describe('Pokepaste Export', () => {
+  it('should default the title, author and notes to the trainer and game', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const result = createPokepaste(saveData, parser.gameConfig!)
+
+    expect(result.title).toBe("EMERALD's Pokemon Emerald (Vanilla) team")
+    expect(result.author).toBe('EMERALD')
+    expect(result.notes).toBe('Exported from a Pokemon Emerald (Vanilla) save at 0:26 play time')
+    expect(result.paste).toBe(parser.exportShowdown(saveData))
+    expect(createPokepaste(saveData, parser.gameConfig!, { title: 'Run' }).title).toBe('Run')
+  })
+
+  it('should name Hidden Power with its type', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const pokemon = saveData.party_pokemon[0]!
+    pokemon.setMove(1, 237, 15) // Hidden Power
+    pokemon.setIvs([31, 31, 31, 31, 31, 31])
+
+    const lines = createPokepaste(saveData, parser.gameConfig!).paste.split('\n')
+    expect(lines.filter(line => line.startsWith('- '))).toEqual([
+      '- Pound',
+      '- Hidden Power [Dark]',
+    ])
+  })
+})
+
+describe('Pokepaste Upload', () => {
+  it('should post the paste as a form and return the URL it redirects to', async () => {
+    const fetchImpl = vi.fn(async () => ({ ok: true, status: 200, url: `${POKEPASTE_URL}/abc123` }))
+    const url = await uploadPokepaste(pokepaste, fetchImpl as unknown as typeof fetch)
+
+    expect(url).toBe('https://pokepast.es/abc123')
+    const [target, init] = fetchImpl.mock.calls[0] as unknown as [string, RequestInit]
+    expect(target).toBe('https://pokepast.es/create')
+    expect(init.method).toBe('POST')
+    expect(Object.fromEntries(init.body as URLSearchParams)).toEqual(pokepaste)
+  })
+
+  it('should fail on an error response or a missing redirect', async () => {
+    const respond = (response: object) => vi.fn(async () => response) as unknown as typeof fetch
+
+    await expect(uploadPokepaste(pokepaste, respond({ ok: false, status: 503 }))).rejects.toThrow(
+      'HTTP 503'
+    )
+    await expect(
+      uploadPokepaste(pokepaste, respond({ ok: true, status: 200, url: `${POKEPASTE_URL}/create` }))
+    ).rejects.toThrow('did not return a paste URL')
+  })
+})
@@ -0,0 +1,91 @@
+/**
+ * Tests for QR code encoding/decoding and Pokemon QR payloads
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import {
+  decodePokemonQr,
+  decodeQrCode,
+  encodePokemonQr,
+  encodeQrCode,
+  parsePokemonQrPayload,
+  qrCodeToSvg,
+  type QrErrorCorrection,
+  toPokemonQrPayload,
+} from '../core/qrCode'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const bytes = (length: number) => Uint8Array.from({ length }, (_, i) => (i * 37 + 11) & 0xff)
+
+describe('QR Code', () => {
+  it('should pick the smallest version that fits', () => {
+    expect(encodeQrCode(bytes(17), 'L').version).toBe(1)
+    expect(encodeQrCode(bytes(18), 'L').version).toBe(2)
+    expect(encodeQrCode(bytes(106), 'M').version).toBe(6)
+    expect(encodeQrCode(bytes(107), 'M').version).toBe(7)
+    expect(encodeQrCode(bytes(1)).size).toBe(21)
+    expect(() => encodeQrCode(bytes(3000), 'L')).toThrow("don't fit")
+  })
+
+  it('should draw the finder and timing patterns', () => {
+    const { modules, size } = encodeQrCode(bytes(20))
+    const finderRow = [true, true, true, true, true, true, true, false]
+    expect(modules[0]!.slice(0, 8)).toEqual(finderRow)
+    expect(modules[0]!.slice(size - 8).reverse()).toEqual(finderRow)
+    expect(modules[6]!.slice(8, size - 8)).toEqual(
+      Array.from({ length: size - 16 }, (_, i) => i % 2 === 0)
+    )
+    // The module above the bottom-left separator is always dark
+    expect(modules[size - 8]![8]).toBe(true)
+  })
+
+  it.each([
+    [1, 'L'],
+    [40, 'M'],
+    [140, 'M'],
+    [300, 'Q'],
+    [1000, 'H'],
+    [2900, 'L'],
+  ] as const)('should round-trip %i bytes at level %s', (length, level: QrErrorCorrection) => {
+    const data = bytes(length)
+    expect(decodeQrCode(encodeQrCode(data, level).modules)).toEqual(data)
+  })
+
+  it('should detect damaged data', () => {
+    const { modules } = encodeQrCode(bytes(100))
+    const damaged = modules.map(row => [...row])
+    damaged[30]![30] = !damaged[30]![30]
+    expect(() => decodeQrCode(damaged)).toThrow('damaged')
+    expect(() => decodeQrCode(modules.slice(1))).toThrow('modules wide')
+  })
+
+  it('should render an SVG with a quiet zone', () => {
+    const svg = qrCodeToSvg(encodeQrCode(bytes(1)))
+    expect(svg).toContain('viewBox="0 0 29 29"')
+    expect(svg).toContain('M4 4h1v1h-1z')
+  })
+})
+
+describe('Pokemon QR', () => {
+  it('should carry a .pk3 between saves', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const treecko = (await parser.parse(loadSave('emerald.sav'))).party_pokemon[0]!
+    const qr = encodePokemonQr(treecko)
+
+    expect(qr.version).toBe(7)
+    expect(decodePokemonQr(qr.modules)).toEqual(treecko.exportPK3('box'))
+
+    const imported = parser.importQrPayload(toPokemonQrPayload(treecko))
+    expect(imported.nickname).toBe('TREECKO')
+    expect(imported.level).toBe(5)
+    expect(imported.stats).toEqual(treecko.stats)
+  })
+
+  it('should reject other payloads', () => {
+    expect(() => parsePokemonQrPayload('https://example.com')).toThrow('Not a Pokemon QR code')
+    expect(() => parsePokemonQrPayload('PK3:!!!')).toThrow('base64')
+    expect(() => parsePokemonQrPayload(`PK3:${btoa('short')}`)).toThrow('holds 5 bytes')
+  })
+})
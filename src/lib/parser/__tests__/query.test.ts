@@ -0,0 +1,89 @@
+/**
+ * Tests for the Pokemon search/query API
+ */
+
+import { beforeAll, describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { formatPokemonLocation } from '../core/query'
+import type { SaveData } from '../core/types'
+import { loadSave } from './testData'
+
+describe('Pokemon Query', () => {
+  let parser: PokemonSaveParser
+  let saveData: SaveData
+
+  beforeAll(async () => {
+    parser = new PokemonSaveParser()
+    saveData = await parser.parse(loadSave('quetzal.sav'))
+  })
+
+  const speciesOf = (query: Parameters<PokemonSaveParser['findPokemon']>[1]) =>
+    parser.findPokemon(saveData, query).map(match => match.pokemon.speciesId)
+
+  it('should return every Pokemon for an empty query', () => {
+    const matches = parser.findPokemon(saveData, {})
+    expect(matches).toHaveLength(saveData.party_pokemon.length)
+    expect(matches[0]!.location).toEqual({ area: 'party', slot: 0 })
+  })
+
+  it('should filter by species number or name', () => {
+    expect(speciesOf({ species: 143 })).toEqual([143])
+    expect(speciesOf({ species: 'SNORLAX' })).toEqual([143])
+    expect(speciesOf({ species: 'missingno' })).toEqual([])
+  })
+
+  it('should filter by level range', () => {
+    expect(speciesOf({ minLevel: 45 })).toEqual([286, 143, 272])
+    expect(speciesOf({ minLevel: 45, maxLevel: 45 })).toEqual([286, 272])
+  })
+
+  it('should filter by original trainer name or ID', () => {
+    expect(speciesOf({ ot: 'john' })).toHaveLength(6)
+    expect(speciesOf({ ot: '08202' })).toHaveLength(6)
+    expect(speciesOf({ ot: 'someone-else' })).toEqual([])
+  })
+
+  it('should filter by known move ID or name', () => {
+    expect(speciesOf({ move: 34 })).toEqual([143])
+    expect(speciesOf({ move: 'Air Slash' })).toEqual([6, 561])
+    expect(speciesOf({ move: 'not a move' })).toEqual([])
+  })
+
+  it('should combine filters and apply shiny and IV thresholds', () => {
+    const shiny = speciesOf({ shiny: true })
+    const notShiny = speciesOf({ shiny: false })
+    expect(shiny.length + notShiny.length).toBe(6)
+
+    expect(speciesOf({ minIv: 0 })).toHaveLength(6)
+    expect(speciesOf({ minIv: 32 })).toEqual([])
+    expect(speciesOf({ move: 'Air Slash', maxLevel: 40 })).toEqual([561])
+  })
+
+  it('should walk boxes and daycare after the party', () => {
+    const [first, second] = saveData.party_pokemon
+    const withStorage: SaveData = {
+      ...saveData,
+      boxes: [[null, first!], [], [null, null, second!]],
+      daycare: {
+        slots: [null, { pokemon: second!, steps: 0, level_on_withdraw: second!.level }],
+        egg_pending: false,
+        offspring_personality: 0,
+        step_counter: 0,
+      },
+    }
+
+    const locations = [...parser.allPokemon(withStorage)].map(match => match.location)
+    expect(locations.slice(saveData.party_pokemon.length)).toEqual([
+      { area: 'box', box: 0, slot: 1 },
+      { area: 'box', box: 2, slot: 2 },
+      { area: 'daycare', slot: 1 },
+    ])
+    expect(parser.findPokemon(withStorage, { species: first!.speciesId })).toHaveLength(2)
+  })
+
+  it('should format storage locations for display', () => {
+    expect(formatPokemonLocation({ area: 'party', slot: 0 })).toBe('Party slot 1')
+    expect(formatPokemonLocation({ area: 'box', box: 2, slot: 11 })).toBe('Box 3 slot 12')
+    expect(formatPokemonLocation({ area: 'daycare', slot: 1 })).toBe('Daycare slot 2')
+  })
+})
@@ -8,10 +8,12 @@ import { dirname, resolve } from 'path'
 import { fileURLToPath } from 'url'
 import { beforeAll, describe, expect, it } from 'vitest'
 import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { PokemonBase } from '../core/PokemonBase'
 import { QuetzalConfig } from '../games/quetzal/config'
 import { VanillaConfig } from '../games/vanilla/config'
 import type { SaveData } from '../core/types'
 import { calculateTotalStats, natures } from '../core/utils'
+import { loadSave } from './testData'
 
 // Hash function for comparing buffers
 const hashBuffer = async (buf: ArrayBuffer | Uint8Array) => {
@@ -264,6 +266,17 @@ describe('Pokemon Quetzal Tests', () => {
     })
   })
 
+  describe('Shiny Editing', () => {
+    it('should set the stored shiny byte and keep the nature', async () => {
+      const result = await parser.parse(testSaveData)
+      const pokemon = result.party_pokemon.find(p => !p.isShiny)!
+      const { nature } = pokemon
+      pokemon.makeShiny()
+      expect(pokemon.isShiny).toBe(true)
+      expect(pokemon.nature).toBe(nature)
+    })
+  })
+
   describe('EV Writing and Persistence', () => {
     it('should allow writing and reading EVs back correctly', async () => {
       const parsedData = await parser.parse(testSaveData)
@@ -474,6 +487,35 @@ describe('Pokemon Quetzal Tests', () => {
       )
     })
 
+    it('should swap party slots and keep the order through write-back', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const swapped = parser.swapPartySlots(parsed, 1, 3)
+
+      expect(swapped.party_pokemon[1]).toBe(parsed.party_pokemon[3])
+      expect(swapped.party_pokemon[3]).toBe(parsed.party_pokemon[1])
+
+      const reparsed = await parser.parse(parser.reconstructSaveFile(swapped.party_pokemon))
+      expect(reparsed.party_pokemon.map(p => p.speciesId)).toEqual(
+        swapped.party_pokemon.map(p => p.speciesId)
+      )
+      expect(() => parser.swapPartySlots(parsed, 0, 6)).toThrow('out of range')
+    })
+
+    it('should remove a party member and shrink the party count', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const removed = parser.removeFromParty(parsed, 0)
+      expect(removed.party_pokemon).toHaveLength(parsed.party_pokemon.length - 1)
+
+      const reparsed = await parser.parse(parser.reconstructSaveFile(removed.party_pokemon))
+      expect(reparsed.party_pokemon.map(p => p.speciesId)).toEqual(
+        parsed.party_pokemon.slice(1).map(p => p.speciesId)
+      )
+
+      let single: SaveData = parsed
+      while (single.party_pokemon.length > 1) single = parser.removeFromParty(single, 0)
+      expect(() => parser.removeFromParty(single, 0)).toThrow('last Pokemon')
+    })
+
     it('should maintain data integrity when modifying Pokemon stats and EVs/IVs', async () => {
       const parsed = await parser.parse(testSaveData)
 
@@ -534,6 +576,149 @@ describe('Pokemon Quetzal Tests', () => {
     })
   })
 
+  describe('Egg Detection', () => {
+    it('should not report hatched party members as eggs', async () => {
+      const result = await parser.parse(testSaveData)
+      result.party_pokemon.forEach(pokemon => {
+        expect(pokemon.isEgg).toBe(false)
+        expect(pokemon.eggCycles).toBeUndefined()
+      })
+    })
+
+    it('should read hatch cycles from the friendship byte of an egg', async () => {
+      const result = await parser.parse(testSaveData)
+      const bytes = result.party_pokemon[0]!.rawBytes
+      bytes[0x53]! |= 0x40 // IV word bit 30 (isEgg)
+      bytes[0x31] = 20 // Growth substruct friendship byte
+
+      const egg = new PokemonBase(bytes, new QuetzalConfig())
+      expect(egg.isEgg).toBe(true)
+      expect(egg.eggCycles).toBe(20)
+      expect(egg.eggStepsRemaining).toBe(20 * 256)
+      expect(JSON.parse(JSON.stringify(egg)).is_egg).toBe(true)
+    })
+  })
+
+  describe('Stat Verification', () => {
+    it('should skip verification for rebalanced base stats', async () => {
+      const result = await parser.parse(testSaveData)
+      expect(result.party_pokemon[0]!.expectedStats).toBeUndefined()
+      expect(parser.verifyStats(result)).toEqual([])
+    })
+
+    it('should not report Gen 3 types or abilities for modern species data', async () => {
+      const result = await parser.parse(testSaveData)
+      expect(result.party_pokemon[0]!.types).toBeUndefined()
+      expect(result.party_pokemon[0]!.ability).toBeUndefined()
+    })
+  })
+
+  describe('PK3 Export', () => {
+    it('should refuse .pk3 export for the custom Quetzal Pokemon layout', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(() => parsed.party_pokemon[0]!.exportPK3()).toThrow('vanilla Pokemon layout')
+    })
+  })
+
+  describe('Unmapped SaveBlock Data', () => {
+    it('should leave SaveBlock extras unparsed until the layout is mapped', async () => {
+      const result = await parser.parse(testSaveData)
+      expect(result.boxes).toBeUndefined()
+      expect(result.box_metadata).toBeUndefined()
+      expect(result.bag).toBeUndefined()
+      expect(result.pokedex).toBeUndefined()
+      expect(result.progress).toBeUndefined()
+      expect(result.currency).toBeUndefined()
+      expect(result.options).toBeUndefined()
+      expect(result.daycare).toBeUndefined()
+      expect(result.mail).toBeUndefined()
+      expect(result.secret_bases).toBeUndefined()
+      expect(result.mystery_gift).toBeUndefined()
+      expect(result.game_stats).toBeUndefined()
+      expect(result.roamer).toBeUndefined()
+      expect(result.location).toBeUndefined()
+      expect(result.misc).toBeUndefined()
+      expect(result.rival).toBeUndefined()
+      expect(result.starter).toBeUndefined()
+      expect(result.optional_sections).toBeUndefined()
+    })
+  })
+
+  describe('Species Mapping', () => {
+    it('should map internal species IDs to National Dex numbers exactly once', async () => {
+      const result = await parser.parse(testSaveData)
+      const bytes = result.party_pokemon[0]!.rawBytes
+      new DataView(bytes.buffer).setUint16(0x28, 1209, true) // Ursaluna
+
+      const pokemon = new PokemonBase(bytes, new QuetzalConfig())
+      expect(pokemon.internalSpeciesId).toBe(1209)
+      expect(pokemon.speciesId).toBe(901)
+      expect(pokemon.nameId).toBe('ursaluna')
+    })
+  })
+
+  describe('Forms', () => {
+    it('should resolve forms stored as separate species IDs', async () => {
+      const result = await parser.parse(testSaveData)
+      const formOf = (rawSpecies: number) => {
+        const bytes = result.party_pokemon[0]!.rawBytes
+        new DataView(bytes.buffer).setUint16(0x28, rawSpecies, true)
+        const pokemon = new PokemonBase(bytes, new QuetzalConfig())
+        return [pokemon.form, pokemon.formNameId]
+      }
+
+      expect(formOf(201)).toEqual([undefined, 'unown'])
+      expect(formOf(1001)).toEqual(['b', 'unown-b'])
+      expect(formOf(1031)).toEqual(['attack', 'deoxys-attack'])
+      expect(formOf(900)).toEqual(['megax', 'charizard-megax'])
+      expect(formOf(949)).toEqual(['alola', 'rattata-alola'])
+    })
+  })
+
+  describe('Vanilla Conversion', () => {
+    it('should convert a Quetzal Pokemon with Gen 3 moves to vanilla Emerald', async () => {
+      const snorlax = (await parser.parse(testSaveData)).party_pokemon[2]!
+      const converted = snorlax.convertTo(new VanillaConfig())
+
+      expect(converted.rawBytes).toHaveLength(100)
+      expect(converted.isChecksumValid).toBe(true)
+      expect(converted.speciesId).toBe(143)
+      expect(converted.itemIdName).toBe('leftovers')
+      expect(converted.moveIds).toEqual(snorlax.moveIds)
+      expect(converted.ppValues).toEqual(snorlax.ppValues)
+      expect(converted.nickname).toBe(snorlax.nickname)
+      expect(converted.nature).toBe(snorlax.nature)
+      expect(converted.level).toBe(snorlax.level)
+      expect(converted.ivs).toEqual(snorlax.ivs)
+      expect(converted.evs).toEqual(snorlax.evs)
+      // Quetzal's modern base stats give way to the Gen 3 ones
+      expect(converted.stats).toEqual(converted.expectedStats)
+    })
+
+    it('should reject Pokemon that know moves vanilla Emerald lacks', async () => {
+      const steelix = (await parser.parse(testSaveData)).party_pokemon[0]!
+      expect(() => steelix.convertTo(new VanillaConfig())).toThrow('has no moves ID 446')
+    })
+
+    it('should move a vanilla party into a Quetzal save', async () => {
+      const vanilla = new PokemonSaveParser(undefined, new VanillaConfig())
+      const [treecko] = (await vanilla.parse(loadSave('emerald.sav'))).party_pokemon
+      await parser.parse(testSaveData)
+      const converted = parser.importFromGame(treecko!)
+      expect(converted.rawBytes).toHaveLength(104)
+
+      const reparsed = await parser.parse(parser.reconstructSaveFile([converted]))
+      const [pokemon] = reparsed.party_pokemon
+      expect(reparsed.party_pokemon).toHaveLength(1)
+      expect(pokemon!.nameId).toBe('treecko')
+      expect(pokemon!.nickname).toBe(treecko!.nickname)
+      expect(pokemon!.nature).toBe(treecko!.nature)
+      expect(pokemon!.moveIds).toEqual(treecko!.moveIds)
+      expect(pokemon!.stats).toEqual(treecko!.stats)
+      expect(pokemon!.currentHp).toBe(treecko!.currentHp)
+    })
+  })
+
   describe('Data Structure Validation', () => {
     it('should create properly structured Pokemon data', async () => {
       const result = await parser.parse(testSaveData)
@@ -602,5 +787,19 @@ describe('Pokemon Quetzal Tests', () => {
         expect([0, 1, 2]).toContain(pokemon.shinyNumber)
       })
     })
+
+    it('should report a stored shiny byte without a XOR threshold', async () => {
+      const result = await parser.parse(testSaveData)
+
+      result.party_pokemon.forEach(pokemon => {
+        expect(pokemon.shinyInfo).toEqual({
+          value: pokemon.shinyNumber,
+          threshold: undefined,
+          odds: undefined,
+          is_shiny: pokemon.isShiny,
+          is_radiant: pokemon.isRadiant,
+        })
+      })
+    })
   })
 })
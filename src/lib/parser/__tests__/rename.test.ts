@@ -0,0 +1,94 @@
+/**
+ * Tests for batch nickname/OT renames: templates, owner filters, previews and write-back
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { RENAME_PRESETS } from '../core/rename'
+import type { SaveData } from '../core/types'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+// The Emerald save's own TREECKO in the party plus a nicknamed one from another trainer in box 1
+async function withTradedPokemon(parser: PokemonSaveParser): Promise<SaveData> {
+  const parsed = await parser.parse(loadSave('emerald.sav'))
+  const traded = parsed.party_pokemon[0]!.toBoxPokemon()
+  traded.setOriginalTrainer('BRENDAN', 0x1234abcd, 'male')
+  traded.nickname = 'SPIKY'
+  const boxes = parsed.boxes!.map(box => [...box])
+  boxes[0]![2] = traded
+  return { ...parsed, boxes }
+}
+
+describe('Batch Rename', () => {
+  it('should preview template renames without changing any Pokemon', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await withTradedPokemon(parser)
+
+    const changes = parser.planRename(saveData, { nickname: '{nickname}{nickname}' })
+    // Names are cut to the 10 characters a nickname can hold
+    expect(changes).toEqual([
+      {
+        location: { area: 'party', slot: 0 },
+        field: 'nickname',
+        before: 'TREECKO',
+        after: 'TREECKOTRE',
+      },
+      {
+        location: { area: 'box', box: 0, slot: 2 },
+        field: 'nickname',
+        before: 'SPIKY',
+        after: 'SPIKYSPIKY',
+      },
+    ])
+    expect(saveData.party_pokemon[0]!.nickname).toBe('TREECKO')
+  })
+
+  it('should only strip nicknames of traded Pokemon with the preset', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await withTradedPokemon(parser)
+
+    expect(parser.planRename(saveData, RENAME_PRESETS['strip-trade-nicknames'])).toEqual([
+      {
+        location: { area: 'box', box: 0, slot: 2 },
+        field: 'nickname',
+        before: 'SPIKY',
+        after: 'TREECKO',
+      },
+    ])
+    expect(
+      parser.planRename(saveData, { otName: 'ASH', owner: 'own' }).map(({ location }) => location)
+    ).toEqual([{ area: 'party', slot: 0 }])
+  })
+
+  it('should write renamed Pokemon back to the save', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await withTradedPokemon(parser)
+    const changes = parser.planRename(saveData, { nickname: 'LEAF{level}', otName: 'ASH' })
+    const updated = parser.applyRename(saveData, changes)
+
+    const reparsed = await parser.parse(
+      parser.reconstructSaveFile(updated.party_pokemon, updated.boxes)
+    )
+    const [treecko] = reparsed.party_pokemon
+    const traded = reparsed.boxes![0]![2]!
+    expect(treecko!.nickname).toBe(`LEAF${treecko!.level}`)
+    expect(treecko!.otName).toBe('ASH')
+    expect(traded.nickname).toBe(`LEAF${traded.level}`)
+    expect(traded.otName).toBe('ASH')
+    // Only the unencrypted name fields change; the OT ID (and so the encryption key) stays
+    expect(traded.otId).toBe(0x1234abcd)
+    expect(treecko!.isChecksumValid).toBe(true)
+    expect(reparsed.skipped_slots).toEqual([])
+  })
+
+  it('should need trainer IDs to filter by owner', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = { ...(await parser.parse(loadSave('emerald.sav'))), trainer: undefined }
+
+    expect(() => parser.planRename(saveData, { nickname: '{species}', owner: 'traded' })).toThrow(
+      'Trainer IDs are not available for this save'
+    )
+    expect(parser.planRename(saveData, { nickname: '{species}' })).toEqual([])
+  })
+})
@@ -0,0 +1,63 @@
+/**
+ * Tests for the interactive save browser (tui subcommand)
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import {
+  type BrowserKey,
+  createSaveBrowser,
+  handleBrowserKey,
+  INITIAL_BROWSER_STATE,
+  renderSaveBrowser,
+  type SaveBrowser,
+} from '../core/saveBrowser'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const loadBrowser = async () => {
+  const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+  const saveData = await parser.parse(loadSave('emerald.sav'))
+  return createSaveBrowser(saveData, parser.gameConfig!)
+}
+
+const press = (browser: SaveBrowser, keys: readonly BrowserKey[]) =>
+  keys.reduce((state, key) => handleBrowserKey(browser, state, key), INITIAL_BROWSER_STATE)
+
+describe('Save Browser', () => {
+  it('should list the party and a tab per PC box', async () => {
+    const browser = await loadBrowser()
+    const screen = renderSaveBrowser(browser, INITIAL_BROWSER_STATE, 24)
+
+    expect(browser.tabs).toHaveLength(15)
+    expect(browser.tabs[1]!.label).toMatch(/^Box 1/)
+    expect(screen[0]).toBe('EMERALD · Pokemon Emerald (Vanilla)')
+    expect(screen[1]).toBe('Party (1/15)')
+    expect(screen[3]).toMatch(/^› {2}1 Treecko +Lv 5/)
+  })
+
+  it('should switch tabs and open the detail view with the keyboard', async () => {
+    const browser = await loadBrowser()
+
+    expect(press(browser, [{ name: 'left' }]).tab).toBe(14)
+    const detail = press(browser, [{ name: 'return' }])
+    const screen = renderSaveBrowser(browser, detail, 30)
+    expect(screen).toContain('Party slot 1')
+    expect(screen.some(line => /^Moves: Pound \(\d+ PP\)/.test(line))).toBe(true)
+    expect(press(browser, [{ name: 'return' }, { name: 'escape' }]).detail).toBe(false)
+    expect(press(browser, [{ text: 'q' }]).quit).toBe(true)
+  })
+
+  it('should search the whole save from typed text', async () => {
+    const browser = await loadBrowser()
+    const typed = [...'pound'].map(text => ({ text }))
+    const state = press(browser, [{ text: '/' }, ...typed, { name: 'return' }])
+
+    expect(state.query).toBeNull()
+    expect(state.results?.label).toBe('Search "pound"')
+    expect(state.results?.entries[0]?.pokemon.species_name).toBe('Treecko')
+    // q is text while typing a query, not quit
+    expect(press(browser, [{ text: '/' }, { text: 'q' }]).query).toBe('q')
+    expect(handleBrowserKey(browser, state, { name: 'escape' }).results).toBeNull()
+  })
+})
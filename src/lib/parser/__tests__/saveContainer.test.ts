@@ -0,0 +1,68 @@
+/**
+ * Tests for converting saves between emulator containers (128KB, 128KB + RTC footer, 64KB)
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import {
+  FLASH_128K_SIZE,
+  FLASH_64K_SIZE,
+  RTC_FOOTER_SIZE,
+  convertSaveContainer,
+  detectSaveContainer,
+} from '../core/saveContainer'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadTestData } from './testData'
+
+describe('Save Containers', () => {
+  it('should detect containers by file size', () => {
+    const save = loadTestData('emerald.sav')
+    expect(detectSaveContainer(save)).toBe('128k-rtc')
+    expect(detectSaveContainer(save.subarray(0, FLASH_128K_SIZE))).toBe('128k')
+    expect(detectSaveContainer(save.subarray(0, FLASH_64K_SIZE))).toBe('64k')
+    expect(detectSaveContainer(save.subarray(0, 1000))).toBeUndefined()
+  })
+
+  it('should drop and re-add the RTC footer', () => {
+    const save = loadTestData('emerald.sav')
+    const raw = convertSaveContainer(save, '128k')
+    expect(raw).toEqual(save.subarray(0, FLASH_128K_SIZE))
+    expect(convertSaveContainer(save, '128k-rtc')).toEqual(save)
+
+    const now = new Date('2024-01-01T00:00:00Z')
+    const withRtc = convertSaveContainer(raw, '128k-rtc', now)
+    expect(withRtc).toHaveLength(FLASH_128K_SIZE + RTC_FOOTER_SIZE)
+    const footer = withRtc.subarray(FLASH_128K_SIZE)
+    expect([...footer.subarray(0, 8)]).toEqual([0x00, 0x01, 0x01, 0x06, 0x00, 0x00, 0x00, 0x40])
+    expect(new DataView(footer.buffer, footer.byteOffset).getBigUint64(8, true)).toBe(1704067200n)
+  })
+
+  it('should keep the newest slot when shrinking to 64KB', async () => {
+    const save = loadTestData('emerald.sav')
+    const original = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(save.buffer)
+    const small = convertSaveContainer(save, '64k')
+    expect(small).toHaveLength(FLASH_64K_SIZE)
+
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const parsed = await parser.parse(small.slice().buffer)
+    expect(parser.saveContainer).toBe('64k')
+    expect(parsed.active_slot).toBe(0)
+    expect(parsed.player_name).toBe(original.player_name)
+    expect(parsed.play_time).toEqual(original.play_time)
+    expect(parsed.party_pokemon).toHaveLength(original.party_pokemon.length)
+  })
+
+  it('should pad a 64KB save with erased Flash', () => {
+    const small = convertSaveContainer(loadTestData('emerald.sav'), '64k')
+    const padded = convertSaveContainer(small, '128k')
+    expect(padded).toHaveLength(FLASH_128K_SIZE)
+    expect(padded.subarray(0, FLASH_64K_SIZE)).toEqual(small)
+    expect(padded.subarray(FLASH_64K_SIZE).every(byte => byte === 0xff)).toBe(true)
+  })
+
+  it('should reject unknown sizes', () => {
+    expect(() => convertSaveContainer(new Uint8Array(1000), '128k')).toThrow(
+      'Unrecognized save size: 1000 bytes'
+    )
+  })
+})
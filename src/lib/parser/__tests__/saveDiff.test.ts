@@ -0,0 +1,115 @@
+/**
+ * Tests for the structured save diff
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { diffSaves, formatSaveDiff, SAVE_DIFF_VERSION } from '../core/saveDiff'
+import type { SaveData } from '../core/types'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+async function parseEmerald() {
+  const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+  return { saveData: await parser.parse(loadSave('emerald.sav')), config: parser.gameConfig! }
+}
+
+describe('Save Diff', () => {
+  it('should report nothing for the same save', async () => {
+    const before = await parseEmerald()
+    const after = await parseEmerald()
+    const diff = diffSaves(before.saveData, after.saveData, after.config)
+
+    expect(diff).toEqual({
+      version: SAVE_DIFF_VERSION,
+      play_time_seconds: 0,
+      pokemon: { added: [], removed: [], changed: [] },
+      items: { gained: [], lost: [] },
+      flags: [],
+      counters: [],
+    })
+    expect(formatSaveDiff(diff)).toBe('Play time: +0:00\nNo changes')
+  })
+
+  it('should list edited fields of a Pokemon found in both saves', async () => {
+    const before = await parseEmerald()
+    const after = await parseEmerald()
+    after.saveData.party_pokemon[0]!.setMove(1, 33, 35) // Tackle in place of Leer
+
+    const { changed } = diffSaves(before.saveData, after.saveData, after.config).pokemon
+    expect(changed).toHaveLength(1)
+    expect(changed[0]).toMatchObject({
+      nickname: 'TREECKO',
+      species_name: 'Treecko',
+      location: { area: 'party', slot: 0 },
+      moved_from: null,
+    })
+    expect(changed[0]!.changes.find(change => change.field === 'moves')).toEqual({
+      field: 'moves',
+      from: [1, 43, 0, 0],
+      to: [1, 33, 0, 0],
+    })
+  })
+
+  it('should follow Pokemon between the party and the boxes', async () => {
+    const before = await parseEmerald()
+    const after = await parseEmerald()
+    const [treecko] = after.saveData.party_pokemon
+    const slot = after.saveData.boxes![0]!.indexOf(null)
+    const boxes = after.saveData.boxes!.map((box, i) =>
+      i === 0 ? box.map((pokemon, j) => (j === slot ? treecko! : pokemon)) : box
+    )
+    const moved: SaveData = { ...after.saveData, party_pokemon: [], boxes }
+
+    const diff = diffSaves(before.saveData, moved, after.config)
+    expect(diff.pokemon.added).toEqual([])
+    expect(diff.pokemon.removed).toEqual([])
+    expect(diff.pokemon.changed).toEqual([
+      expect.objectContaining({
+        location: { area: 'box', box: 0, slot },
+        moved_from: { area: 'party', slot: 0 },
+        changes: [],
+      }),
+    ])
+
+    const released = diffSaves(
+      before.saveData,
+      { ...after.saveData, party_pokemon: [] },
+      after.config
+    )
+    expect(released.pokemon.removed.map(ref => ref.nickname)).toEqual(['TREECKO'])
+    expect(formatSaveDiff(released)).toContain('- TREECKO (Treecko) in Party slot 1')
+  })
+
+  it('should report items, flags and counters', async () => {
+    const before = await parseEmerald()
+    const { saveData, config } = await parseEmerald()
+    const { bag, progress, currency, play_time: time } = saveData
+    const hasStoneBadge = progress!.badges[0]!
+    const after: SaveData = {
+      ...saveData,
+      play_time: { ...time, hours: time.hours + 1 },
+      bag: {
+        ...bag!,
+        items: [
+          ...bag!.items,
+          { slot: 29, item_id: 9999, raw_item_id: 9999, name: 'Test Item', quantity: 3 },
+        ],
+      },
+      progress: { ...progress!, badges: [!hasStoneBadge, ...progress!.badges.slice(1)] },
+      currency: { ...currency!, money: currency!.money + 500 },
+    }
+
+    const diff = diffSaves(before.saveData, after, config)
+    expect(diff.play_time_seconds).toBe(3600)
+    expect(diff.items).toEqual({
+      gained: [{ pocket: 'items', item_id: 9999, name: 'Test Item', from: 0, to: 3 }],
+      lost: [],
+    })
+    expect(diff.flags).toEqual([{ flag: 'badge.stone', from: hasStoneBadge, to: !hasStoneBadge }])
+    expect(diff.counters).toEqual([
+      { field: 'currency.money', from: currency!.money, to: currency!.money + 500 },
+    ])
+    expect(formatSaveDiff(diff)).toContain('+ Test Item x3 (0 → 3)')
+  })
+})
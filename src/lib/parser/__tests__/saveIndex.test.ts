@@ -0,0 +1,151 @@
+/**
+ * Tests for the deduplicated save library index
+ */
+
+import { describe, expect, it } from 'vitest'
+import {
+  SAVE_INDEX_VERSION,
+  addSaveToIndex,
+  createSaveIndex,
+  findInSaveIndex,
+  pruneSaveIndex,
+  readSaveIndex,
+  saveIndexTimeline,
+  summarizeSaveIndex,
+  writeSaveIndex,
+} from '../core/saveIndex'
+import { loadTestData } from './testData'
+
+// node:sqlite ships with Node.js 22.5+
+const sqlite = await import('node:sqlite').catch(() => null)
+
+describe('Save Library Index', () => {
+  it('should catalog summary metadata keyed by fingerprint', async () => {
+    const { index, result, entry } = await addSaveToIndex(
+      createSaveIndex(),
+      loadTestData('emerald.sav'),
+      'saves/emerald.sav',
+      new Date('2024-01-01T00:00:00Z')
+    )
+
+    expect(result).toBe('added')
+    expect(entry.fingerprint).toMatch(/^[0-9a-f]{64}$/)
+    expect(entry).toMatchObject({
+      paths: ['saves/emerald.sav'],
+      game: 'Pokemon Emerald (Vanilla)',
+      player_name: 'EMERALD',
+      money: 3000,
+      party: [{ species_id: 252, level: 5 }],
+      indexed_at: '2024-01-01T00:00:00.000Z',
+    })
+    expect(Object.keys(index.entries)).toEqual([entry.fingerprint])
+  })
+
+  it('should deduplicate identical saves stored under different paths', async () => {
+    const bytes = loadTestData('emerald.sav')
+    let { index } = await addSaveToIndex(createSaveIndex(), bytes, 'a.sav')
+
+    const again = await addSaveToIndex(index, bytes, 'a.sav')
+    expect(again.result).toBe('unchanged')
+
+    const copy = await addSaveToIndex(index, bytes, 'backup/a.sav')
+    expect(copy.result).toBe('duplicate')
+    expect(copy.entry.paths).toEqual(['a.sav', 'backup/a.sav'])
+    ;({ index } = copy)
+
+    ;({ index } = await addSaveToIndex(index, loadTestData('quetzal.sav'), 'quetzal.sav'))
+    expect(Object.keys(index.entries)).toHaveLength(2)
+  })
+
+  it('should prune missing paths', async () => {
+    let { index } = await addSaveToIndex(createSaveIndex(), loadTestData('emerald.sav'), 'gone.sav')
+    ;({ index } = await addSaveToIndex(index, loadTestData('quetzal.sav'), 'kept.sav'))
+
+    const pruned = pruneSaveIndex(index, path => path === 'kept.sav')
+    expect(Object.values(pruned.entries).map(e => e.paths)).toEqual([['kept.sav']])
+  })
+
+  it.skipIf(!sqlite)('should round-trip through its SQLite database', async () => {
+    let { index } = await addSaveToIndex(createSaveIndex(), loadTestData('emerald.sav'), 'a.sav')
+    ;({ index } = await addSaveToIndex(index, loadTestData('emerald.sav'), 'b.sav'))
+    ;({ index } = await addSaveToIndex(index, loadTestData('quetzal.sav'), 'quetzal.sav'))
+
+    const db = new sqlite!.DatabaseSync(':memory:')
+    expect(readSaveIndex(db)).toEqual(createSaveIndex())
+    writeSaveIndex(db, index)
+    writeSaveIndex(db, index)
+    expect(readSaveIndex(db)).toEqual(index)
+    expect(db.prepare('SELECT COUNT(*) AS n FROM saves').get()).toEqual({ n: 2 })
+    expect(db.prepare('SELECT COUNT(*) AS n FROM paths').get()).toEqual({ n: 3 })
+    db.close()
+  })
+
+  it('should move a path whose content changed to its new entry', async () => {
+    let { index } = await addSaveToIndex(createSaveIndex(), loadTestData('emerald.sav'), 'run.sav')
+    ;({ index } = await addSaveToIndex(index, loadTestData('emerald.sav'), 'copy.sav'))
+
+    const { result, entry } = await addSaveToIndex(index, loadTestData('quetzal.sav'), 'run.sav')
+    expect(result).toBe('added')
+    ;({ index } = await addSaveToIndex(index, loadTestData('quetzal.sav'), 'run.sav'))
+    expect(Object.values(index.entries).map(e => e.paths)).toEqual([['copy.sav'], ['run.sav']])
+
+    // The old entry goes once its last path has moved
+    ;({ index } = await addSaveToIndex(index, loadTestData('quetzal.sav'), 'copy.sav'))
+    expect(Object.keys(index.entries)).toEqual([entry.fingerprint])
+    expect(index.entries[entry.fingerprint]!.paths).toEqual(['run.sav', 'copy.sav'])
+  })
+
+  it.skipIf(!sqlite)('should reject other versions instead of starting over', () => {
+    const db = new sqlite!.DatabaseSync(':memory:')
+    db.exec(`PRAGMA user_version = ${SAVE_INDEX_VERSION + 1}`)
+    expect(() => readSaveIndex(db)).toThrow(
+      `Unsupported save index version: ${SAVE_INDEX_VERSION + 1}`
+    )
+    db.close()
+  })
+
+  it('should search and total the catalog without the saves', async () => {
+    let { index } = await addSaveToIndex(createSaveIndex(), loadTestData('emerald.sav'), 'a.sav')
+    ;({ index } = await addSaveToIndex(index, loadTestData('emerald.sav'), 'b.sav'))
+
+    const matches = findInSaveIndex(index, { species: 'treecko', maxLevel: 10 })
+    expect(matches.map(({ slot, pokemon }) => [slot, pokemon.species_id])).toEqual([[0, 252]])
+    expect(findInSaveIndex(index, { species: 252, minLevel: 6 })).toEqual([])
+    expect(() => findInSaveIndex(index, { ot: 'BRENDAN' })).toThrow('filter by ot')
+
+    expect(summarizeSaveIndex(index)).toMatchObject({
+      saves: 1,
+      files: 2,
+      games: { 'Pokemon Emerald (Vanilla)': 1 },
+      party_pokemon: 1,
+      species: 1,
+    })
+  })
+
+  it('should order each playthrough by play time with the progress between saves', async () => {
+    const entry = (
+      await addSaveToIndex(createSaveIndex(), loadTestData('emerald.sav'), 'a.sav')
+    ).entry
+    const later = {
+      ...entry,
+      fingerprint: 'later',
+      paths: ['b.sav'],
+      play_time: { ...entry.play_time, hours: entry.play_time.hours + 2 },
+      badge_count: entry.badge_count + 1,
+      party: [...entry.party, { species_id: 263, level: 3, is_shiny: false }],
+    }
+    const index = { ...createSaveIndex(), entries: { later, [entry.fingerprint]: entry } }
+
+    const [run, ...others] = saveIndexTimeline(index)
+    expect(others).toEqual([])
+    expect(run).toMatchObject({ game: entry.game, player_name: 'EMERALD' })
+    expect(run!.saves.map(save => save.entry.fingerprint)).toEqual([entry.fingerprint, 'later'])
+    expect(run!.saves[0]!.since_previous).toBeUndefined()
+    expect(run!.saves[1]!.since_previous).toEqual({
+      play_time_seconds: 7200,
+      badges: 1,
+      caught: 0,
+      new_species: [263],
+    })
+  })
+})
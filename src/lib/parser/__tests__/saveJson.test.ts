@@ -0,0 +1,191 @@
+/**
+ * Tests for the versioned save JSON output and its JSON Schema
+ */
+
+import { readFileSync } from 'fs'
+import { dirname, resolve } from 'path'
+import { fileURLToPath } from 'url'
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import {
+  BATCH_CSV_COLUMNS,
+  SAVE_JSON_SCHEMA_VERSION,
+  toCanonicalJson,
+  toCsvLine,
+  toNdjsonLine,
+  toSaveJson,
+} from '../core/saveJson'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+// Handle ES modules in Node.js
+const __filename = fileURLToPath(import.meta.url)
+const __dirname = dirname(__filename)
+
+type Schema = Record<string, any>
+
+const schema: Schema = JSON.parse(
+  readFileSync(resolve(__dirname, '..', 'schema', 'save-data.schema.json'), 'utf8')
+)
+
+const typeOf = (value: unknown) => {
+  if (value === null) return 'null'
+  if (Array.isArray(value)) return 'array'
+  if (Number.isInteger(value)) return 'integer'
+  return typeof value
+}
+
+// Just enough of JSON Schema for the keywords save-data.schema.json uses; returns error paths
+function validate(value: unknown, node: Schema, path = '$'): string[] {
+  if (node.$ref) return validate(value, schema.$defs[node.$ref.split('/').pop()], path)
+  if (node.oneOf) {
+    const matches = node.oneOf.filter((option: Schema) => !validate(value, option, path).length)
+    return matches.length === 1 ? [] : [`${path}: matches ${matches.length} oneOf options`]
+  }
+  if ('const' in node && value !== node.const) return [`${path}: expected ${node.const}`]
+  if (node.enum && !node.enum.includes(value)) return [`${path}: ${String(value)} not in enum`]
+  if (node.type) {
+    const types = [node.type].flat()
+    const actual = typeOf(value)
+    if (!types.includes(actual) && !(actual === 'integer' && types.includes('number'))) {
+      return [`${path}: expected ${types.join('|')}, got ${actual}`]
+    }
+  }
+
+  const errors: string[] = []
+  if (Array.isArray(value)) {
+    if (node.minItems !== undefined && value.length < node.minItems) errors.push(`${path}: short`)
+    if (node.maxItems !== undefined && value.length > node.maxItems) errors.push(`${path}: long`)
+    value.forEach((item, i) => {
+      if (node.items) errors.push(...validate(item, node.items, `${path}[${i}]`))
+    })
+  } else if (typeOf(value) === 'object') {
+    const record = value as Record<string, unknown>
+    for (const key of node.required ?? []) {
+      if (!(key in record)) errors.push(`${path}.${key}: missing`)
+    }
+    for (const [key, item] of Object.entries(record)) {
+      const property = node.properties?.[key]
+      if (property) errors.push(...validate(item, property, `${path}.${key}`))
+      else if (node.additionalProperties === false) errors.push(`${path}.${key}: not in schema`)
+      else if (typeof node.additionalProperties === 'object') {
+        errors.push(...validate(item, node.additionalProperties, `${path}.${key}`))
+      }
+    }
+  }
+  return errors
+}
+
+// Every object key in the output, at any depth
+function collectKeys(value: unknown, keys = new Set<string>()): Set<string> {
+  if (Array.isArray(value)) for (const item of value) collectKeys(item, keys)
+  else if (value && typeof value === 'object') {
+    for (const [key, item] of Object.entries(value)) {
+      keys.add(key)
+      collectKeys(item, keys)
+    }
+  }
+  return keys
+}
+
+describe('Save JSON', () => {
+  it('should match the JSON Schema for a vanilla save with every optional section', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const buffer = loadSave('emerald.sav')
+    const saveData = await parser.parse(buffer)
+    const slots = await parser.parseAllSlots(buffer)
+    const json = JSON.parse(
+      JSON.stringify(
+        toSaveJson(saveData, parser.gameConfig!, {
+          boxes: true,
+          slots: [slots.slot1, slots.slot2],
+          legality: parser.checkLegality(saveData),
+        })
+      )
+    )
+
+    expect(validate(json, schema)).toEqual([])
+    expect(json.schema_version).toBe(SAVE_JSON_SCHEMA_VERSION)
+    expect(schema.properties.schema_version.const).toBe(SAVE_JSON_SCHEMA_VERSION)
+    expect(json.boxes).toHaveLength(14)
+  })
+
+  it('should match the JSON Schema for a hack with its own mappings', async () => {
+    const parser = new PokemonSaveParser()
+    const saveData = await parser.parse(loadSave('quetzal.sav'))
+    const json = JSON.parse(JSON.stringify(toSaveJson(saveData, parser.gameConfig!)))
+
+    expect(validate(json, schema)).toEqual([])
+    expect(json.game).toBe('Pokemon Quetzal')
+    expect(json.party_pokemon[0].species_name).toBe('Steelix')
+  })
+
+  it('should resolve species, item and move names', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const [treecko] = toSaveJson(saveData, parser.gameConfig!).party_pokemon
+
+    expect(treecko!.species_name).toBe('Treecko')
+    expect(treecko!.item_name).toBeNull()
+    expect(treecko!.move_names).toHaveLength(4)
+    expect(treecko!.move_names[0]).toBe('Pound')
+    expect(treecko!.move_names.slice(2)).toEqual([null, null])
+  })
+
+  it('should only use snake_case keys', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const json = JSON.parse(JSON.stringify(toSaveJson(saveData, parser.gameConfig!)))
+
+    const keys = [...collectKeys(json)]
+    expect(keys.filter(key => !/^[a-z0-9]+(_[a-z0-9]+)*$/.test(key))).toEqual([])
+  })
+
+  it('should write one NDJSON line per save', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const line = toNdjsonLine('saves/emerald.sav', toSaveJson(saveData, parser.gameConfig!))
+
+    expect(line.endsWith('\n')).toBe(true)
+    expect(line.indexOf('\n')).toBe(line.length - 1)
+    const record = JSON.parse(line)
+    expect(record.path).toBe('saves/emerald.sav')
+    expect(record.schema_version).toBe(SAVE_JSON_SCHEMA_VERSION)
+    expect(record.player_name).toBe('EMERALD')
+
+    expect(JSON.parse(toNdjsonLine('bad.sav', new Error('Invalid save file')))).toEqual({
+      path: 'bad.sav',
+      error: 'Invalid save file',
+    })
+  })
+
+  it('should write one CSV row per save, quoting fields that need it', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const line = toCsvLine('saves/run 1, final.sav', toSaveJson(saveData, parser.gameConfig!))
+
+    expect(line.endsWith('\n')).toBe(true)
+    expect(line.startsWith('"saves/run 1, final.sav",,')).toBe(true)
+    expect(line).toContain(',EMERALD,')
+    expect(line).toContain('Treecko Lv5')
+    expect(toCsvLine('bad.sav', new Error('Invalid "save" file'))).toBe(
+      `bad.sav,"Invalid ""save"" file"${','.repeat(BATCH_CSV_COLUMNS.length - 2)}\n`
+    )
+  })
+
+  it('should print canonical JSON with sorted keys in compact and pretty modes', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const json = toSaveJson(saveData, parser.gameConfig!)
+    const reordered = Object.fromEntries(Object.entries(json).reverse())
+
+    expect(toCanonicalJson({ b: [{ d: 1, c: null }], a: 'x' })).toBe(
+      '{"a":"x","b":[{"c":null,"d":1}]}'
+    )
+    expect(toCanonicalJson(reordered)).toBe(toCanonicalJson(json))
+    expect(toCanonicalJson(json)).not.toContain('\n')
+    const pretty = toCanonicalJson(json, { pretty: true })
+    expect(pretty).toContain('\n  "')
+    expect(JSON.parse(pretty)).toEqual(JSON.parse(JSON.stringify(json)))
+  })
+})
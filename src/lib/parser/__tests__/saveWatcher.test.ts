@@ -0,0 +1,109 @@
+/**
+ * Tests for re-parsing a watched save file
+ */
+
+import { copyFileSync, mkdtempSync, readFileSync, rmSync, writeFileSync } from 'fs'
+import { tmpdir } from 'os'
+import { join } from 'path'
+import { afterEach, beforeEach, describe, expect, it, vi } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { type SaveUpdate, type SaveWatcher, postSaveJson, watchSaveFile } from '../core/saveWatcher'
+import { toNdjsonLine } from '../core/saveJson'
+import { loadTestData, testDataPath } from './testData'
+
+describe('Save Watcher', () => {
+  let dir: string
+  let savePath: string
+  let watcher: SaveWatcher | undefined
+
+  beforeEach(() => {
+    dir = mkdtempSync(join(tmpdir(), 'save-watch-'))
+    savePath = join(dir, 'run.sav')
+    copyFileSync(testDataPath('emerald.sav'), savePath)
+  })
+
+  afterEach(async () => {
+    await watcher?.close()
+    watcher = undefined
+    rmSync(dir, { recursive: true, force: true })
+  })
+
+  const watch = (options: { post?: string; fetch?: typeof fetch } = {}) => {
+    const lines: string[] = []
+    const errors: string[] = []
+    const updates: SaveUpdate[] = []
+    watcher = watchSaveFile(savePath, {
+      interval: 50,
+      settleMs: 20,
+      onUpdate: update => {
+        updates.push(update)
+        lines.push(toNdjsonLine(savePath, update.json))
+      },
+      onError: message => errors.push(message),
+      ...options,
+    })
+    return { lines, errors, updates }
+  }
+
+  it('should emit an NDJSON line for the first parse and each rewrite with a new party', async () => {
+    const { lines, errors } = watch()
+    await watcher!.ready
+    expect(lines).toHaveLength(1)
+    expect(JSON.parse(lines[0]!)).toMatchObject({ path: savePath, player_name: 'EMERALD' })
+
+    writeFileSync(savePath, loadTestData('quetzal.sav'))
+    await vi.waitFor(() => expect(lines).toHaveLength(2), { timeout: 5000 })
+    expect(JSON.parse(lines[1]!)).toMatchObject({ path: savePath, player_name: 'John' })
+    expect(errors).toEqual([])
+  })
+
+  it('should skip a rewrite that leaves the party unchanged', async () => {
+    const parser = new PokemonSaveParser()
+    await parser.parse(readFileSync(savePath))
+    const blocks = parser.getSaveBlocks()
+    parser.setBagItem(blocks, 'pokeBalls', 4, 10) // Poke Ball
+    const edited = parser.writeSaveFile({ saveblock1: blocks.saveblock1 })
+
+    const parse = vi.spyOn(PokemonSaveParser.prototype, 'parse')
+    const { updates } = watch()
+    await watcher!.ready
+    writeFileSync(savePath, edited)
+    // The edited save is parsed, but its party is the same
+    await vi.waitFor(() => expect(parse).toHaveBeenCalledTimes(2), { timeout: 5000 })
+    await watcher!.close()
+    expect(updates).toHaveLength(1)
+    parse.mockRestore()
+  })
+
+  it('should POST each update and report failed POSTs without stopping', async () => {
+    const fetch = vi.fn<typeof globalThis.fetch>(async () => new Response(null, { status: 503 }))
+    const { updates, errors } = watch({ post: 'http://localhost:3000/party', fetch })
+    await watcher!.ready
+
+    expect(fetch).toHaveBeenCalledTimes(1)
+    const [url, init] = fetch.mock.calls[0]!
+    expect(url).toBe('http://localhost:3000/party')
+    expect(init?.method).toBe('POST')
+    expect(JSON.parse(init!.body as string)).toEqual(
+      JSON.parse(JSON.stringify(updates[0]!.json))
+    )
+    expect(errors).toEqual(['POST http://localhost:3000/party failed: 503'])
+
+    writeFileSync(savePath, loadTestData('quetzal.sav'))
+    await vi.waitFor(() => expect(fetch).toHaveBeenCalledTimes(2), { timeout: 5000 })
+  })
+
+  it('should throw when a POST is refused or fails', async () => {
+    await expect(
+      postSaveJson('http://tracker/', {}, async () => new Response(null, { status: 500 }))
+    ).rejects.toThrow('POST http://tracker/ failed: 500')
+    await expect(
+      postSaveJson('http://tracker/', {}, async () => {
+        throw new Error('connection refused')
+      })
+    ).rejects.toThrow('POST http://tracker/ failed: connection refused')
+    await expect(
+      postSaveJson('http://tracker/', {}, async () => new Response(null, { status: 204 }))
+    ).resolves.toBeUndefined()
+  })
+})
@@ -0,0 +1,116 @@
+/**
+ * Tests for the SQLite export: table rows, script escaping and re-exporting a save
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { fingerprintSave } from '../core/saveIndex'
+import {
+  SQL_EXPORT_SCHEMA,
+  saveToSqlStatements,
+  toSqlScript,
+  type SqlStatement,
+} from '../core/sqlExport'
+import { GAME_STAT_NAMES } from '../core/types'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadTestData } from './testData'
+
+const saveBytes = loadTestData('emerald.sav')
+
+// node:sqlite ships with Node.js 22.5+
+const sqlite = await import('node:sqlite').catch(() => null)
+
+async function exportEmerald() {
+  const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+  const saveData = await parser.parse(saveBytes.slice().buffer)
+  return {
+    saveData,
+    statements: saveToSqlStatements({
+      saveData,
+      config: parser.gameConfig!,
+      fingerprint: fingerprintSave(saveBytes),
+      path: 'emerald.sav',
+      exportedAt: new Date('2024-01-01T00:00:00Z'),
+    }),
+  }
+}
+
+const rowsFor = (statements: readonly SqlStatement[], table: string) =>
+  statements.filter(({ sql }) => sql.startsWith(`INSERT INTO ${table} `))
+
+describe('SQLite Export', () => {
+  it('should export the save into the meta, pokemon, items, dex and stats tables', async () => {
+    const { saveData, statements } = await exportEmerald()
+    const { hours, minutes, seconds } = saveData.play_time
+
+    const [meta] = rowsFor(statements, 'meta')
+    expect(meta!.params).toEqual([
+      fingerprintSave(saveBytes),
+      'emerald.sav',
+      'Pokemon Emerald (Vanilla)',
+      'EMERALD',
+      7327,
+      41355,
+      hours * 3600 + minutes * 60 + seconds,
+      saveData.currency!.money,
+      saveData.progress!.badges.filter(Boolean).length,
+      '2024-01-01T00:00:00.000Z',
+    ])
+
+    const pokemon = rowsFor(statements, 'pokemon')
+    expect(pokemon).toHaveLength(1)
+    expect(pokemon[0]!.sql).toContain('species_name, nickname, level')
+    expect(pokemon[0]!.params.slice(1, 8)).toEqual(['party', null, 1, 252, 'Treecko', 'TREECKO', 5])
+    expect(pokemon[0]!.params.slice(-4)).toEqual(['Pound', 'Leer', null, null])
+
+    expect(rowsFor(statements, 'dex')).toHaveLength(
+      saveData.pokedex!.seen.filter((seen, i) => seen || saveData.pokedex!.caught[i]).length
+    )
+    expect(rowsFor(statements, 'stats')).toHaveLength(GAME_STAT_NAMES.length)
+    const items = rowsFor(statements, 'items')
+    expect(items.every(({ params }) => typeof params[1] === 'string')).toBe(true)
+    expect(items.map(({ params }) => params[1])).not.toContain('keyItems')
+  })
+
+  it('should replace the rows of an earlier export of the same save', async () => {
+    const { statements } = await exportEmerald()
+    const deletes = statements.filter(({ sql }) => sql.startsWith('DELETE FROM'))
+
+    expect(deletes.map(({ sql }) => sql.split(' ')[2])).toEqual([
+      'pokemon',
+      'items',
+      'dex',
+      'stats',
+      'meta',
+    ])
+    expect(statements.indexOf(deletes.at(-1)!)).toBeLessThan(
+      statements.indexOf(rowsFor(statements, 'meta')[0]!)
+    )
+  })
+
+  it('should inline parameters as escaped SQL literals in scripts', () => {
+    const script = toSqlScript([
+      {
+        sql: 'INSERT INTO meta (save, player_name, money) VALUES (?, ?, ?)',
+        params: ['a', "O'K", null],
+      },
+    ])
+
+    expect(script.startsWith(`${SQL_EXPORT_SCHEMA[0]};`)).toBe(true)
+    expect(script).toContain("VALUES ('a', 'O''K', NULL);")
+    expect(script.trimEnd().endsWith('COMMIT;')).toBe(true)
+  })
+
+  it.skipIf(!sqlite)('should load into a SQLite database without duplicating rows', async () => {
+    const { statements } = await exportEmerald()
+    const db = new sqlite!.DatabaseSync(':memory:')
+    for (const sql of SQL_EXPORT_SCHEMA) db.exec(sql)
+    for (let run = 0; run < 2; run++) {
+      for (const { sql, params } of statements) db.prepare(sql).run(...params)
+    }
+
+    expect(db.prepare('SELECT COUNT(*) AS n FROM meta').get()).toEqual({ n: 1 })
+    expect(db.prepare('SELECT nickname FROM pokemon').all()).toEqual([{ nickname: 'TREECKO' }])
+    db.close()
+  })
+})
@@ -0,0 +1,95 @@
+/**
+ * Tests for the team card renderer and its GIF/PNG image helpers
+ */
+
+import { readFileSync } from 'fs'
+import { dirname, resolve } from 'path'
+import { fileURLToPath } from 'url'
+import { inflateSync } from 'zlib'
+import { describe, expect, it } from 'vitest'
+import { createImage, decodeGif, encodePng, type RgbaImage, trimImage } from '../core/image'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { getSpritePath, renderTeamCard } from '../core/teamCard'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+// Handle ES modules in Node.js
+const __filename = fileURLToPath(import.meta.url)
+const __dirname = dirname(__filename)
+
+const spritesDir = resolve(__dirname, '../../../../public/sprites')
+const loadSprite = (spritePath: string) =>
+  decodeGif(new Uint8Array(readFileSync(resolve(spritesDir, spritePath))))
+
+// Reassemble the IDAT chunks of a PNG and inflate them back to filtered scanlines
+function pngScanlines(png: Uint8Array) {
+  const view = new DataView(png.buffer, png.byteOffset, png.byteLength)
+  const idat: number[] = []
+  let size = { width: 0, height: 0 }
+  for (let pos = 8; pos < png.length; ) {
+    const length = view.getUint32(pos)
+    const type = String.fromCharCode(...png.subarray(pos + 4, pos + 8))
+    if (type === 'IHDR') size = { width: view.getUint32(pos + 8), height: view.getUint32(pos + 12) }
+    if (type === 'IDAT') idat.push(...png.subarray(pos + 8, pos + 8 + length))
+    pos += length + 12
+  }
+  return { ...size, scanlines: new Uint8Array(inflateSync(new Uint8Array(idat))) }
+}
+
+describe('Image Helpers', () => {
+  it('should decode the first frame of a sprite GIF with transparency', () => {
+    const treecko = loadSprite('treecko.gif')
+
+    expect([treecko.width, treecko.height]).toEqual([43, 63])
+    expect(treecko.data[3]).toBe(0)
+    const trimmed = trimImage(treecko)
+    expect(trimmed.width).toBeLessThan(treecko.width)
+    expect(trimmed.data.some((_, i) => i % 4 === 3 && trimmed.data[i] === 255)).toBe(true)
+  })
+
+  it('should encode pixels as a PNG', () => {
+    const image: RgbaImage = createImage(2, 1, [255, 0, 0, 255])
+    image.data.set([0, 0, 255, 128], 4)
+    const png = encodePng(image)
+
+    expect([...png.subarray(0, 8)]).toEqual([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a])
+    const { width, height, scanlines } = pngScanlines(png)
+    expect([width, height]).toEqual([2, 1])
+    expect([...scanlines]).toEqual([0, 255, 0, 0, 255, 0, 0, 255, 128])
+  })
+})
+
+describe('Team Card', () => {
+  it('should resolve sprites through the embedded index', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const treecko = (await parser.parse(loadSave('emerald.sav'))).party_pokemon[0]!
+
+    expect(getSpritePath(treecko)).toBe('treecko.gif')
+    treecko.makeShiny()
+    expect(getSpritePath(treecko)).toBe('shiny/treecko.gif')
+  })
+
+  it('should render one cell per party member', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const requested: string[] = []
+    const card = renderTeamCard(saveData, spritePath => {
+      requested.push(spritePath)
+      return loadSprite(spritePath)
+    })
+
+    expect(requested).toEqual(['treecko.gif'])
+    expect([card.width, card.height]).toEqual([528, 208])
+    const { scanlines } = pngScanlines(encodePng(card))
+    expect(scanlines.length).toBe(card.height * (card.width * 4 + 1))
+  })
+
+  it('should draw a placeholder when a sprite is missing', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const withSprite = renderTeamCard(saveData, loadSprite)
+    const without = renderTeamCard(saveData, () => undefined)
+
+    expect(without.data).not.toEqual(withSprite.data)
+  })
+})
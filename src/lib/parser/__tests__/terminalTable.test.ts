@@ -0,0 +1,69 @@
+/**
+ * Tests for the terminal tables behind the CLI's party and PC box summaries
+ */
+
+import { describe, expect, it } from 'vitest'
+import { displayWidth, renderTable, type TableColumn, truncateText } from '../core/terminalTable'
+
+interface Row {
+  readonly nickname: string
+  readonly level: number
+  readonly ot: string
+  readonly egg?: boolean
+  readonly shiny?: boolean
+}
+
+const ROWS: readonly Row[] = [
+  { nickname: 'TREECKO', level: 5, ot: 'EMERALD' },
+  { nickname: 'Quetzalcoatlus', level: 100, ot: 'ASH', shiny: true },
+  { nickname: 'EGG', level: 1, ot: 'EMERALD', egg: true },
+]
+
+const COLUMNS: readonly TableColumn<Row>[] = [
+  { label: 'Slot', key: true, align: 'right', value: (_row, i) => String(i + 1) },
+  { label: 'Nickname', key: true, minWidth: 8, value: row => row.nickname },
+  { label: 'Lv', align: 'right', value: row => String(row.level) },
+  { label: 'OT Name', priority: 1, value: row => row.ot },
+]
+
+const note = (row: Row) => (row.egg ? '🥚 Egg' : undefined)
+
+describe('Terminal Tables', () => {
+  it('should size columns to their longest value', () => {
+    expect(renderTable(ROWS, COLUMNS, { note })).toEqual([
+      'Slot  Nickname         Lv  OT Name',
+      '----------------------------------',
+      '   1  TREECKO           5  EMERALD',
+      '   2  Quetzalcoatlus  100  ASH',
+      '   3  EGG             🥚 Egg',
+    ])
+  })
+
+  it('should leave out low priority columns and then cut text to fit the width', () => {
+    expect(renderTable(ROWS, COLUMNS, { width: 27 })[3]).toBe('   2  Quetzalcoatlus  100')
+    expect(renderTable(ROWS, COLUMNS, { width: 22 })).toEqual([
+      'Slot  Nickname      Lv',
+      '----------------------',
+      '   1  TREECKO        5',
+      '   2  Quetzalcoa…  100',
+      '   3  EGG            1',
+    ])
+  })
+
+  it('should only color when asked to', () => {
+    const rowColor = (row: Row) => (row.shiny ? '33' : undefined)
+    expect(renderTable(ROWS, COLUMNS, { rowColor }).join('\n')).not.toContain('\x1b[')
+    const colored = renderTable(ROWS, COLUMNS, { color: true, rowColor })
+    expect(colored[0]).toBe('\x1b[1mSlot  Nickname         Lv  OT Name\x1b[0m')
+    expect(colored[3]).toContain('\x1b[33mQuetzalcoatlus\x1b[0m')
+    expect(colored[2]).not.toContain('\x1b[')
+  })
+
+  it('should measure emoji and full-width text in terminal cells', () => {
+    expect(displayWidth('🥚 Egg')).toBe(6)
+    expect(displayWidth('ポケモン')).toBe(8)
+    expect(displayWidth('\x1b[31mHP\x1b[0m')).toBe(2)
+    expect(truncateText('ポケモンです', 5)).toBe('ポケ…')
+    expect(truncateText('TREECKO', 7)).toBe('TREECKO')
+  })
+})
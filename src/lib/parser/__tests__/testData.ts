@@ -0,0 +1,18 @@
+/**
+ * Shared access to the fixtures in test_data/
+ */
+
+import { readFileSync } from 'fs'
+import { dirname, resolve } from 'path'
+import { fileURLToPath } from 'url'
+
+const testDataDir = resolve(dirname(fileURLToPath(import.meta.url)), 'test_data')
+
+/** Absolute path of a file in test_data/ */
+export const testDataPath = (name: string) => resolve(testDataDir, name)
+
+/** A fresh copy of a test_data/ file's bytes */
+export const loadTestData = (name: string) => new Uint8Array(readFileSync(testDataPath(name)))
+
+/** A test_data/ save as the ArrayBuffer PokemonSaveParser.parse takes */
+export const loadSave = (name: string) => loadTestData(name).buffer
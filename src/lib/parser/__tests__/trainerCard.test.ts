@@ -0,0 +1,97 @@
+/**
+ * Tests for trainer card snapshot export
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import {
+  TRAINER_CARD_SNAPSHOT_VERSION,
+  compareTrainerCardSnapshots,
+  createTrainerCard,
+  parseTrainerCardSnapshot,
+  serializeTrainerCardSnapshot,
+} from '../core/trainerCard'
+import { GAME_STAT_NAMES, type GameStats, type SaveData } from '../core/types'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+describe('Trainer Card Snapshot', () => {
+  it('should capture trainer info, badges, play time and party', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const snapshot = await parser.getTrainerCardSnapshot(saveData)
+
+    expect(snapshot.version).toBe(TRAINER_CARD_SNAPSHOT_VERSION)
+    expect(snapshot.game).toBe('Pokemon Emerald (Vanilla)')
+    expect(snapshot.trainer).toEqual({
+      name: 'EMERALD',
+      gender: 'male',
+      trainer_id: 7327,
+      secret_id: 41355,
+    })
+    expect(snapshot.badges).toHaveLength(8)
+    expect(snapshot.badge_count).toBe(0)
+    expect(snapshot.play_time.minutes).toBe(26)
+    expect(snapshot.party).toHaveLength(1)
+    expect(snapshot.party[0]?.species_id).toBe(252)
+  })
+
+  it('should round-trip through JSON and compare snapshots', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const before = await parser.getTrainerCardSnapshot(await parser.parse(loadSave('emerald.sav')))
+    const after = parseTrainerCardSnapshot(
+      serializeTrainerCardSnapshot({
+        ...before,
+        badges: before.badges.map((_, i) => i === 0),
+        play_time: { ...before.play_time, hours: before.play_time.hours + 1 },
+        party: before.party.map(p => ({ ...p, level: p.level + 2 })),
+      })
+    )
+
+    const diff = compareTrainerCardSnapshots(before, after)
+    expect(diff.play_time_seconds).toBe(3600)
+    expect(diff.badges_gained).toEqual([1])
+    expect(diff.party_added).toHaveLength(0)
+    expect(diff.level_ups).toEqual([{ species_id: 252, from: 5, to: 7 }])
+  })
+
+  it('should reject snapshots from newer versions', () => {
+    expect(() => parseTrainerCardSnapshot(JSON.stringify({ version: 999 }))).toThrow(
+      'Unsupported trainer card snapshot version'
+    )
+  })
+})
+
+describe('Trainer Card', () => {
+  it('should combine the in-game trainer card fields', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const card = parser.getTrainerCard(await parser.parse(loadSave('emerald.sav')))
+
+    expect(card).toMatchObject({
+      game: 'Pokemon Emerald (Vanilla)',
+      name: 'EMERALD',
+      gender: 'male',
+      trainer_id: 7327,
+      money: 3000,
+      pokedex: { seen: 4, caught: 1 },
+      badge_count: 0,
+      hall_of_fame_debut: null,
+      link_battles: { wins: 0, losses: 0 },
+      trades: 0,
+    })
+    expect(card.play_time.minutes).toBe(26)
+  })
+
+  it('should unpack the Hall of Fame debut time', () => {
+    const stats = Object.fromEntries(GAME_STAT_NAMES.map(name => [name, 0])) as GameStats
+    const card = createTrainerCard('Test', {
+      player_name: 'MAY',
+      play_time: { hours: 30, minutes: 0, seconds: 0 },
+      game_stats: { ...stats, first_hof_play_time: (25 << 16) | (4 << 8) | 59 },
+    } as SaveData)
+
+    expect(card.hall_of_fame_debut).toEqual({ hours: 25, minutes: 4, seconds: 59 })
+    expect(card.trainer_id).toBeNull()
+    expect(card.money).toBeNull()
+  })
+})
@@ -0,0 +1,91 @@
+/**
+ * Tests for the Gen 3 type chart, move types and party type coverage
+ */
+
+import { describe, expect, it } from 'vitest'
+import { getMoveType, isTypedAttack } from '../core/moves'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import {
+  analyzeTypeCoverage,
+  formatCoverageMatrix,
+  getTypeEffectiveness,
+  POKEMON_TYPES,
+} from '../core/typeCoverage'
+import { QuetzalConfig } from '../games/quetzal/config'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+describe('Type Chart', () => {
+  it('should multiply effectiveness across both defending types', () => {
+    expect(getTypeEffectiveness('ice', ['dragon', 'flying'])).toBe(4)
+    expect(getTypeEffectiveness('fire', ['fire', 'rock'])).toBe(0.25)
+    expect(getTypeEffectiveness('electric', ['water', 'ground'])).toBe(0)
+    expect(getTypeEffectiveness('water', ['normal'])).toBe(1)
+  })
+
+  it('should use Gen 3 matchups', () => {
+    // Steel lost these resistances in Gen 6
+    expect(getTypeEffectiveness('ghost', ['steel'])).toBe(0.5)
+    expect(getTypeEffectiveness('dark', ['steel'])).toBe(0.5)
+    expect(POKEMON_TYPES).toHaveLength(17)
+  })
+
+  it('should know which moves deal typed damage', () => {
+    expect(getMoveType(53)).toBe('fire') // Flamethrower
+    expect(getMoveType(44)).toBe('dark') // Bite
+    expect(getMoveType(174)).toBeUndefined() // Curse has the ??? type
+    expect(isTypedAttack(89)).toBe(true) // Earthquake
+    expect(isTypedAttack(43)).toBe(false) // Leer
+    expect(isTypedAttack(69)).toBe(false) // Seismic Toss
+    expect(isTypedAttack(355)).toBe(false)
+  })
+})
+
+describe('Type Coverage', () => {
+  it('should analyze the party', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const coverage = analyzeTypeCoverage(saveData, parser.gameConfig!)
+
+    // TREECKO knows Pound and Leer: only Pound counts
+    expect(coverage.members).toEqual([
+      { nickname: 'TREECKO', species_name: 'Treecko', types: ['grass'], attack_types: ['normal'] },
+    ])
+    expect(coverage.offense.find(entry => entry.type === 'ghost')!.best).toBe(0)
+    expect(coverage.uncovered).toEqual(POKEMON_TYPES)
+    expect(coverage.defense.find(entry => entry.type === 'fire')).toEqual({
+      type: 'fire',
+      members: [2],
+      weak: 1,
+      resist: 0,
+      immune: 0,
+    })
+    expect(coverage.shared_weaknesses).toEqual(['flying', 'poison', 'bug', 'fire', 'ice'])
+  })
+
+  it('should resolve Hidden Power from IVs', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const treecko = saveData.party_pokemon[0]!
+    treecko.setMove(1, 237, 15) // Hidden Power in place of Leer
+
+    const [member] = analyzeTypeCoverage(saveData, parser.gameConfig!).members
+    expect(member!.attack_types).toEqual(['normal', treecko.hiddenPower.type])
+  })
+
+  it('should print offense and defense matrices', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const matrix = formatCoverageMatrix(analyzeTypeCoverage(saveData, parser.gameConfig!))
+
+    expect(matrix).toContain('TREECKO')
+    expect(matrix).toMatch(/^fire\s+2x\s+1\s+0$/m)
+    expect(matrix).toContain('Shared weaknesses: flying, poison, bug, fire, ice')
+  })
+
+  it('should reject games without Gen 3 species data', async () => {
+    const parser = new PokemonSaveParser(undefined, new QuetzalConfig())
+    const saveData = await parser.parse(loadSave('quetzal.sav'))
+    expect(() => analyzeTypeCoverage(saveData, parser.gameConfig!)).toThrow('Gen 3 species')
+  })
+})
@@ -0,0 +1,58 @@
+/**
+ * Tests for save data validation warnings
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import type { BagSlot } from '../core/types'
+import { validateItemLegality } from '../core/validation'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+const slot = (pocket: BagSlot['pocket'], rawItemId: number, index = 0): BagSlot => ({
+  pocket,
+  slot: index,
+  rawItemId,
+  quantity: 1,
+})
+
+describe('Item Legality Validation', () => {
+  const config = new VanillaConfig()
+
+  it('should not flag a clean vanilla save', async () => {
+    const parser = new PokemonSaveParser(undefined, config)
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    expect(parser.validateItems(saveData)).toEqual([])
+  })
+
+  it('should attach parse-time warnings to the save data', async () => {
+    const parser = new PokemonSaveParser(undefined, config)
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    expect(saveData.warnings).toEqual(parser.validateItems(saveData))
+  })
+
+  it('should accept items in their proper pockets', () => {
+    const bag = [slot('items', 13), slot('pokeBalls', 4), slot('tmHm', 289), slot('berries', 133)]
+    expect(validateItemLegality(config, [], bag)).toEqual([])
+  })
+
+  it('should flag items stored in the wrong pocket', () => {
+    const warnings = validateItemLegality(config, [], [slot('items', 289), slot('tmHm', 13)])
+    expect(warnings.map(w => w.code)).toEqual(['bag-item-wrong-pocket', 'bag-item-wrong-pocket'])
+  })
+
+  it('should report a likely config mismatch when most items are impossible', () => {
+    const bag = [500, 600, 700, 800].map((id, i) => slot('items', id, i))
+    const warnings = validateItemLegality(config, [], bag)
+
+    expect(warnings.filter(w => w.code === 'bag-item-unknown')).toHaveLength(4)
+    expect(warnings.at(-1)).toMatchObject({ code: 'config-mismatch', severity: 'error' })
+  })
+
+  it('should skip bag checks for configs without a mapped SaveBlock layout', async () => {
+    const parser = new PokemonSaveParser()
+    const saveData = await parser.parse(loadSave('quetzal.sav'))
+    const warnings = parser.validateItems(saveData)
+    expect(warnings.some(w => w.code.startsWith('bag-'))).toBe(false)
+  })
+})
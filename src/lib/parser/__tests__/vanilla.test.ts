@@ -8,10 +8,30 @@ import { dirname, resolve } from 'path'
 import { fileURLToPath } from 'url'
 import { beforeAll, describe, expect, it } from 'vitest'
 import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { parseShowdownPaste } from '../core/showdown'
 import { VanillaConfig } from '../games/vanilla/config'
 import { QuetzalConfig } from '../games/quetzal/config'
 import { PokemonBase } from '../core/PokemonBase'
-import type { SaveData } from '../core/types'
+import { GAME_STAT_NAMES, VANILLA_SAVE_LAYOUT, type SaveData } from '../core/types'
+import {
+  GENDER_RATIO_FEMALE_ONLY,
+  GENDER_RATIO_GENDERLESS,
+  GENDER_RATIO_MALE_ONLY,
+  calculateStats,
+  experienceForLevel,
+  findSpeciesIdByName,
+  getGenderFromPersonality,
+  getSpeciesAbility,
+  getSpeciesInfo,
+  getSpeciesName,
+  getUnownForm,
+} from '../core/species'
+import { applySecurityKey, gbaStringToBytes, getCharacteristic } from '../core/utils'
+import { checkPokemonSanity, pocketForItem } from '../core/validation'
+import { getMapName } from '../core/maps'
+import { getMaxMovePP } from '../core/moves'
+import { checkLegality, findPidIvMethod } from '../core/legality'
+import { loadSave } from './testData'
 
 // Hash function for comparing buffers
 const hashBuffer = async (buf: ArrayBuffer | Uint8Array) => {
@@ -260,6 +280,23 @@ describe('Vanilla Pokemon Emerald Tests', () => {
         expect(pokemon.evs[2]).toBe(200)
       }
     })
+
+    it('should enforce EV and IV limits and recompute stats', async () => {
+      const parsedData = await parser.parse(testSaveData)
+      const pokemon = parsedData.party_pokemon[0]!
+
+      expect(() => pokemon.editEvs([256, 0, 0, 0, 0, 0])).toThrow('between 0 and 255')
+      expect(() => pokemon.editEvs([255, 255, 1, 0, 0, 0])).toThrow('EV total 511')
+      expect(() => pokemon.editIvs([32, 0, 0, 0, 0, 0])).toThrow('between 0 and 31')
+      expect(pokemon.evs).toEqual([0, 0, 0, 0, 0, 0])
+
+      pokemon.editEvs([255, 255, 255, 0, 0, 0], { force: true })
+      pokemon.editIvs([31, 31, 31, 31, 31, 31])
+      expect(pokemon.totalEVs).toBe(765)
+      expect(pokemon.stats).toEqual(pokemon.expectedStats)
+      expect(pokemon.currentHp).toBe(pokemon.maxHp - 2) // was at 18/20 HP
+      expect(pokemon.isChecksumValid).toBe(true)
+    })
   })
 
   describe('IV Writing and Persistence', () => {
@@ -484,4 +521,1494 @@ describe('Vanilla Pokemon Emerald Tests', () => {
       }
     })
   })
+
+  describe('Contest Stats', () => {
+    it('should parse contest condition bytes', async () => {
+      const parsed = await parser.parse(testSaveData)
+      // Fresh starter has no Pokeblocks fed yet
+      expect(parsed.party_pokemon[0]!.contestStats).toEqual({
+        cool: 0,
+        beauty: 0,
+        cute: 0,
+        smart: 0,
+        tough: 0,
+        sheen: 0,
+      })
+    })
+  })
+
+  describe('Markings and Gender', () => {
+    it('should parse box markings and derive gender from personality', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.markings).toEqual({ circle: false, square: false, triangle: false, heart: false })
+      // Treecko is 87.5% male (ratio 31); personality low byte 0x84 is above the threshold
+      expect(pokemon.gender).toBe('male')
+    })
+
+    it('should round-trip markings through the raw byte', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      pokemon.markingsRaw = 0b1010
+      expect(pokemon.markings).toEqual({ circle: false, square: true, triangle: false, heart: true })
+      expect(pokemon.rawBytes[0x1b]).toBe(0b1010)
+    })
+
+    it('should apply gender ratio thresholds', () => {
+      expect(getGenderFromPersonality(GENDER_RATIO_GENDERLESS, 0)).toBe('genderless')
+      expect(getGenderFromPersonality(GENDER_RATIO_FEMALE_ONLY, 0xff)).toBe('female')
+      expect(getGenderFromPersonality(GENDER_RATIO_MALE_ONLY, 0)).toBe('male')
+      expect(getGenderFromPersonality(127, 0x7e)).toBe('female')
+      expect(getGenderFromPersonality(127, 0x7f)).toBe('male')
+    })
+  })
+
+  describe('Species Mapping', () => {
+    it('should expose the internal species ID next to the National Dex number', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      // Hoenn species follow a gap in the internal order: Treecko is internal 277, dex 252
+      expect(pokemon.internalSpeciesId).toBe(277)
+      expect(pokemon.speciesId).toBe(252)
+      expect(JSON.parse(JSON.stringify(pokemon))).toMatchObject({
+        species_id: 252,
+        internal_species_id: 277,
+      })
+    })
+  })
+
+  describe('Forms', () => {
+    it('should report the default form for species without alternate forms', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.form).toBeUndefined()
+      expect(pokemon.formNameId).toBe('treecko')
+    })
+
+    it('should derive Unown letters from personality', () => {
+      expect(getUnownForm(0)).toBe('a')
+      expect(getUnownForm(0x03030303)).toBe('d') // 255 % 28
+      expect(getUnownForm(0x00010202)).toBe('exclamation')
+      expect(getUnownForm(0x00010203)).toBe('question')
+    })
+  })
+
+  describe('Sanity Checks', () => {
+    const corrupt = (bytes: Uint8Array, edit: (bytes: Uint8Array) => void) => {
+      const copy = new Uint8Array(bytes)
+      edit(copy)
+      return new PokemonBase(copy, new VanillaConfig())
+    }
+
+    it('should accept valid Pokemon and keep the checksum valid after edits', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(parsed.skipped_slots).toEqual([])
+      expect(pokemon.isChecksumValid).toBe(true)
+      expect(pokemon.isBadEgg).toBe(false)
+
+      pokemon.evs = [4, 8, 12, 16, 20, 24]
+      expect(pokemon.isChecksumValid).toBe(true)
+    })
+
+    it('should classify why a slot is invalid', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const bytes = parsed.party_pokemon[0]!.rawBytes
+      const config = new VanillaConfig()
+      const reasonFor = (edit: (bytes: Uint8Array) => void) =>
+        checkPokemonSanity(corrupt(bytes, edit), config)?.reason
+
+      expect(reasonFor(b => (b[0x1c]! ^= 0xff))).toBe('checksum-mismatch')
+      expect(reasonFor(b => (b[0x13]! |= 1))).toBe('bad-egg')
+      expect(reasonFor(b => (b[0x54] = 0))).toBe('level-out-of-range')
+      expect(reasonFor(() => {})).toBeUndefined()
+    })
+
+    it('should report skipped slots and leave them untouched on write-back', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const badEgg = corrupt(parsed.party_pokemon[0]!.rawBytes, b => (b[0x1c]! ^= 0xff))
+      const corrupted = parser.reconstructSaveFile([badEgg])
+
+      const reparsed = await parser.parse(corrupted)
+      expect(reparsed.party_pokemon).toEqual([])
+      expect(reparsed.skipped_slots).toEqual([
+        expect.objectContaining({ slot: 1, reason: 'checksum-mismatch' }),
+      ])
+      expect(parser.reconstructSaveFile(reparsed.party_pokemon)).toEqual(corrupted)
+    })
+  })
+
+  describe('Trainer Info', () => {
+    it('should parse player gender with the public and secret trainer IDs', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(parsed.trainer).toEqual({
+        gender: 'male',
+        trainer_id: 7327,
+        secret_id: 41355,
+        ot_id: 41355 * 0x10000 + 7327,
+      })
+      // The player's own Pokemon carry the full 32-bit ID used for shiny checks
+      expect(parsed.party_pokemon[0]!.otId).toBe(parsed.trainer!.ot_id)
+    })
+
+    it('should edit the trainer profile and carry the party over with cascade', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      const profile = {
+        name: 'RUBY',
+        gender: 'female',
+        trainer_id: 12345,
+        secret_id: 54321,
+      } as const
+      expect(parser.setTrainerProfile(blocks, profile, { cascade: true })).toBe(1)
+
+      const written = parser.writeSaveFile({
+        saveblock1: blocks.saveblock1,
+        saveblock2: blocks.saveblock2,
+        pokemonStorage: blocks.pokemonStorage,
+      })
+      const parsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(parsed.player_name).toBe('RUBY')
+      expect(parsed.trainer).toEqual({
+        gender: 'female',
+        trainer_id: 12345,
+        secret_id: 54321,
+        ot_id: 54321 * 0x10000 + 12345,
+      })
+      const treecko = parsed.party_pokemon[0]!
+      expect(treecko.otName).toBe('RUBY')
+      expect(treecko.otId).toBe(parsed.trainer!.ot_id)
+      expect(treecko.otGender).toBe('female')
+      expect(treecko.isChecksumValid).toBe(true)
+      expect([treecko.speciesId, treecko.level, treecko.nature]).toEqual([252, 5, 'Hasty'])
+    })
+
+    it('should leave Pokemon with the old OT unless cascading', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      expect(parser.setTrainerProfile(blocks, { trainer_id: 1 })).toBe(0)
+
+      const written = parser.writeSaveFile({ saveblock2: blocks.saveblock2 })
+      const parsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(parsed.player_name).toBe(groundTruth.player_name)
+      expect(parsed.trainer!.trainer_id).toBe(1)
+      expect(parsed.party_pokemon[0]!.otId).toBe(41355 * 0x10000 + 7327)
+
+      expect(() => parser.setTrainerProfile(blocks, { name: 'TOOLONGNAME' })).toThrow(
+        'not a valid player name'
+      )
+      expect(() => parser.setTrainerProfile(blocks, { secret_id: 70000 })).toThrow(
+        'secret_id must be between 0 and 65535'
+      )
+    })
+  })
+
+  describe('Anonymize', () => {
+    it('should replace the player and OT data while keeping shininess and ownership', async () => {
+      const original = await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      const result = parser.anonymizeSave(blocks, () => 0.5)
+      expect(result).toMatchObject({
+        player_name: 'PLAYER',
+        trainer_id: 0x8000,
+        secret_id: 0x8000 ^ 7327 ^ 41355,
+        pokemon: 1,
+      })
+
+      const parsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(
+        parser.writeSaveFile(blocks)
+      )
+      expect(parsed.player_name).toBe('PLAYER')
+      expect(parsed.trainer!.trainer_id).toBe(0x8000)
+      const treecko = parsed.party_pokemon[0]!
+      expect(treecko.otName).toBe('PLAYER')
+      expect(treecko.otId).toBe(parsed.trainer!.ot_id)
+      expect(treecko.isShiny).toBe(original.party_pokemon[0]!.isShiny)
+      expect(treecko.shinyNumber).toBe(original.party_pokemon[0]!.shinyNumber)
+      expect(treecko.isChecksumValid).toBe(true)
+
+      const names = [
+        ...(parsed.mail ?? []).map(mail => mail.sender),
+        ...[parsed.secret_bases?.own, ...(parsed.secret_bases?.friends ?? [])]
+          .filter(base => base)
+          .map(base => base!.owner_name),
+      ]
+      expect(names.every(name => name === 'PLAYER' || name === 'TRAINER')).toBe(true)
+    })
+
+    it('should refuse games without a mapped trainer layout', async () => {
+      const quetzalParser = new PokemonSaveParser(undefined, new QuetzalConfig())
+      await quetzalParser.parse(loadSave('quetzal.sav'))
+      expect(() => quetzalParser.anonymizeSave(quetzalParser.getSaveBlocks())).toThrow(
+        'The trainer data layout of Pokemon Quetzal is not mapped'
+      )
+    })
+  })
+
+  describe('Game Options', () => {
+    it('should decode the options bitfield and button mode', async () => {
+      const { options } = await parser.parse(testSaveData)
+      expect(options).toEqual({
+        text_speed: 'mid',
+        battle_scene: true,
+        battle_style: 'shift',
+        sound: 'mono',
+        button_mode: 'normal',
+        frame_type: 0,
+      })
+    })
+  })
+
+  describe('Currency', () => {
+    it('should decrypt money and coins and read Battle Points', async () => {
+      const { currency } = await parser.parse(testSaveData)
+      expect(currency).toEqual({ money: 3000, coins: 0, battle_points: 0 })
+    })
+
+    it('should write money, coins and Battle Points clamped to the game caps', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      const applied = parser.setCurrency(blocks, { money: 1234567, coins: 50, battle_points: -5 })
+      expect(applied).toEqual({ money: 999999, coins: 50, battle_points: 0 })
+
+      parser.setCurrency(blocks, { battle_points: 120 }) // other values are kept
+      const written = parser.writeSaveFile({
+        saveblock1: blocks.saveblock1,
+        saveblock2: blocks.saveblock2,
+      })
+      const reparsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(reparsed.currency).toEqual({ money: 999999, coins: 50, battle_points: 120 })
+    })
+
+    it('should apply the security key symmetrically', () => {
+      const key = 0xbb8dafa0
+      expect(applySecurityKey(applySecurityKey(3000, key), key)).toBe(3000)
+      expect(applySecurityKey(0xafa0 ^ 50, key, 16)).toBe(50)
+      expect(applySecurityKey(0, key, 16)).toBe(0xafa0)
+    })
+  })
+
+  describe('Story Progress', () => {
+    it('should parse badges and story milestones from the event flags', async () => {
+      const { progress } = await parser.parse(testSaveData)
+      expect(progress).toEqual({
+        badges: [false, false, false, false, false, false, false, false],
+        badge_count: 0,
+        received_starter: true,
+        received_pokedex: false,
+        received_pokenav: false,
+        running_shoes: false,
+        national_dex: false,
+        game_cleared: false,
+      })
+    })
+
+    it('should set badges and story milestones through a save round trip', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      const badges = [true, true, true, false, false, false, false, false]
+      parser.setStoryProgress(blocks, { badges, running_shoes: true, national_dex: true })
+
+      const written = parser.writeSaveFile({
+        saveblock1: blocks.saveblock1,
+        saveblock2: blocks.saveblock2,
+      })
+      const parsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(parsed.progress).toMatchObject({
+        badges,
+        badge_count: 3,
+        received_starter: true,
+        received_pokedex: false,
+        running_shoes: true,
+        national_dex: true,
+      })
+
+      parser.setStoryProgress(blocks, { national_dex: false })
+      const reverted = parser.writeSaveFile({
+        saveblock1: blocks.saveblock1,
+        saveblock2: blocks.saveblock2,
+      })
+      const reparsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(reverted)
+      expect(reparsed.progress!.national_dex).toBe(false)
+      expect(() => parser.setStoryProgress(blocks, { badges: [true] })).toThrow('Expected 8 badges')
+    })
+  })
+
+  describe('Pokedex', () => {
+    it('should decode per-species seen and caught flags', async () => {
+      const { pokedex } = await parser.parse(testSaveData)
+      const dexNumbers = (flags: readonly boolean[]) =>
+        flags.flatMap((flag, i) => (flag ? [i + 1] : []))
+
+      expect(pokedex!.seen).toHaveLength(386)
+      expect(dexNumbers(pokedex!.caught)).toEqual([252])
+      expect(dexNumbers(pokedex!.seen)).toEqual([252, 261, 263, 265])
+      expect(pokedex).toMatchObject({ seen_count: 4, caught_count: 1 })
+    })
+
+    it('should set seen and caught flags with their mirrors', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      const allSpecies = Array.from({ length: 386 }, (_, i) => i + 1)
+      parser.setPokedexFlags(blocks, allSpecies, { caught: true })
+      parser.setPokedexFlags(blocks, [151], { seen: false }) // clears caught as well
+
+      const written = parser.writeSaveFile({
+        saveblock1: blocks.saveblock1,
+        saveblock2: blocks.saveblock2,
+      })
+      const { pokedex } = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(pokedex).toMatchObject({ seen_count: 385, caught_count: 385 })
+      expect([pokedex!.seen[150], pokedex!.caught[150]]).toEqual([false, false])
+      expect(() => parser.setPokedexFlags(blocks, [387], { seen: true })).toThrow('1-386')
+    })
+
+    it('should resync the SaveBlock1 seen mirrors', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      // Clear Zigzagoon (#263) from the first mirror, so the game stops counting it as seen
+      const mirrorByte = VANILLA_SAVE_LAYOUT.pokedexSeen1 + (262 >> 3)
+      blocks.saveblock1[mirrorByte] = blocks.saveblock1[mirrorByte]! & ~(1 << (262 & 7))
+      const desynced = parser.writeSaveFile({ saveblock1: blocks.saveblock1 })
+      const broken = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(desynced)
+      expect(broken.pokedex!.seen_count).toBe(3)
+
+      expect(parser.syncPokedex(blocks)).toEqual([263])
+      const repaired = parser.writeSaveFile({ saveblock1: blocks.saveblock1 })
+      const fixed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(repaired)
+      expect(fixed.pokedex!.seen_count).toBe(4)
+    })
+  })
+
+  describe('Bag', () => {
+    it('should parse every pocket with decrypted quantities and mapped names', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(parsed.bag).toEqual({
+        items: [
+          {
+            slot: 0,
+            item_id: 17,
+            raw_item_id: 13,
+            id_name: 'potion',
+            name: 'Potion',
+            quantity: 1,
+          },
+        ],
+        keyItems: [],
+        pokeBalls: [],
+        tmHm: [],
+        berries: [],
+      })
+    })
+    it('should add, update and remove bag items through a save round trip', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      parser.setBagItem(blocks, 'items', 17, 5) // Potion: update the existing stack
+      parser.setBagItem(blocks, 'pokeBalls', 4, 10) // Poke Ball
+      parser.setBagItem(blocks, 'tmHm', 309, 1) // TM05
+      parser.setBagItem(blocks, 'tmHm', 305, 2) // TM01 sorts ahead of TM05
+      parser.setBagItem(blocks, 'keyItems', 527, 1) // Mach Bike
+
+      const written = parser.writeSaveFile({ saveblock1: blocks.saveblock1 })
+      const { bag } = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(bag!.items).toMatchObject([{ slot: 0, id_name: 'potion', quantity: 5 }])
+      expect(bag!.pokeBalls).toMatchObject([{ id_name: 'poke-ball', quantity: 10 }])
+      expect(bag!.tmHm.map(item => [item.id_name, item.quantity])).toEqual([
+        ['tm01', 2],
+        ['tm05', 1],
+      ])
+      expect(bag!.keyItems).toMatchObject([{ id_name: 'mach-bike', quantity: 1 }])
+
+      parser.setBagItem(blocks, 'items', 17, 0)
+      const removed = parser.writeSaveFile({ saveblock1: blocks.saveblock1 })
+      const reparsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(removed)
+      expect(reparsed.bag!.items).toEqual([])
+    })
+
+    it('should enforce pockets and capacities when editing the bag', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+
+      expect(() => parser.setBagItem(blocks, 'pokeBalls', 17, 1)).toThrow(
+        'Potion cannot be stored in the "pokeBalls" pocket'
+      )
+      expect(() => parser.setBagItem(blocks, 'items', 17, 100)).toThrow('between 0 and 99')
+      expect(() => parser.setBagItem(blocks, 'keyItems', 527, 2)).toThrow('between 0 and 1')
+      expect(() => parser.setBagItem(blocks, 'items', 99999, 1)).toThrow('does not exist')
+
+      // Potion already fills one of the 30 slots
+      const otherItems = [
+        ...new Set(
+          [...new VanillaConfig().mappings.items.values()]
+            .filter(item => item.id !== null && item.id !== 17)
+            .filter(item => pocketForItem(item.id_name) === null)
+            .map(item => item.id!)
+        ),
+      ]
+      for (const id of otherItems.slice(0, 29)) parser.setBagItem(blocks, 'items', id, 1)
+      expect(() => parser.setBagItem(blocks, 'items', otherItems[29]!, 1)).toThrow(
+        'The "items" pocket is full (30 slots)'
+      )
+    })
+  })
+
+  // Write bytes into a save block of the active slot (SaveBlock1 starts at sector 1, PC storage
+  // at sector 5) and fix the touched sectors' checksums; patches must not cross a sector
+  const patchSaveBlock = async (firstSectorId: number, patches: [number, Uint8Array][]) => {
+    const save = new Uint8Array(testSaveData.slice(0))
+    const { sector_map } = await parser.parse(testSaveData)
+    const touched = new Set<number>()
+    for (const [offset, data] of patches) {
+      const sectorIdx = sector_map!.get(firstSectorId + Math.floor(offset / 3968))!
+      save.set(data, sectorIdx * 4096 + (offset % 3968))
+      touched.add(sectorIdx)
+    }
+    for (const sectorIdx of touched) {
+      const view = new DataView(save.buffer, sectorIdx * 4096, 4096)
+      let sum = 0
+      for (let i = 0; i < 3968; i += 4) sum = (sum + view.getUint32(i, true)) >>> 0
+      view.setUint16(0xff6, ((sum >>> 16) + (sum & 0xffff)) & 0xffff, true)
+    }
+    return save
+  }
+
+  describe('PC Boxes', () => {
+    // Place 80-byte box Pokemon in the PC storage sectors
+    const withBoxPokemon = (entries: [box: number, slot: number, data: Uint8Array][]) =>
+      patchSaveBlock(
+        5,
+        entries.map(([box, slot, data]) => [4 + (box * 30 + slot) * 80, data.subarray(0, 80)])
+      )
+
+    it('should parse 14 boxes of 30 empty slots', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(parsed.boxes).toHaveLength(14)
+      expect(parsed.boxes!.every(box => box.length === 30 && box.every(p => p === null))).toBe(
+        true
+      )
+    })
+
+    it('should parse box names, wallpapers and the current box', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(parsed.current_box).toBe(0)
+      expect(parsed.box_metadata).toHaveLength(14)
+      expect(parsed.box_metadata![0]).toEqual({
+        name: 'BOX1',
+        wallpaper: 0,
+        wallpaper_name: 'Forest',
+      })
+      expect(parsed.box_metadata![13]).toMatchObject({ name: 'BOX14', wallpaper: 1 })
+    })
+
+    it('should decode box Pokemon and recompute their battle data', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      const save = await withBoxPokemon([
+        [0, 0, treecko.rawBytes],
+        [2, 4, treecko.rawBytes],
+      ])
+
+      const { boxes } = await parser.parse(save)
+      const boxed = boxes![0]![0]!
+      expect(boxes!.flat().filter(p => p !== null)).toHaveLength(2)
+      expect(boxes![2]![4]).not.toBeNull()
+      expect(boxed.speciesId).toBe(252)
+      expect(boxed.nickname).toBe(treecko.nickname)
+      expect(boxed.level).toBe(treecko.level)
+      expect(boxed.stats).toEqual(treecko.stats)
+      expect(boxed.currentHp).toBe(boxed.maxHp)
+    })
+
+    it('should report corrupt box slots as skipped', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const bytes = new Uint8Array(parsed.party_pokemon[0]!.rawBytes)
+      bytes[0x1c]! ^= 0xff
+      const reparsed = await parser.parse(await withBoxPokemon([[1, 2, bytes]]))
+
+      expect(reparsed.boxes![1]![2]).toBeNull()
+      expect(reparsed.skipped_slots).toEqual([
+        expect.objectContaining({ box: 2, slot: 3, reason: 'checksum-mismatch' }),
+      ])
+    })
+
+    it('should withdraw and deposit Pokemon through a save round trip', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      const withdrawn = parser.withdrawFromBox(
+        await parser.parse(await withBoxPokemon([[0, 0, treecko.rawBytes]])),
+        0,
+        0
+      )
+      expect(withdrawn.party_pokemon).toHaveLength(2)
+      expect(withdrawn.party_pokemon[1]!.stats).toEqual(treecko.stats)
+      expect(withdrawn.boxes![0]![0]).toBeNull()
+
+      const afterWithdraw = await parser.parse(
+        parser.reconstructSaveFile(withdrawn.party_pokemon, withdrawn.boxes)
+      )
+      expect(afterWithdraw.party_pokemon.map(p => p.speciesId)).toEqual([252, 252])
+      expect(afterWithdraw.boxes!.flat().every(p => p === null)).toBe(true)
+
+      const deposited = parser.depositToBox(afterWithdraw, 1, 3)
+      const afterDeposit = await parser.parse(
+        parser.reconstructSaveFile(deposited.party_pokemon, deposited.boxes)
+      )
+      expect(afterDeposit.party_pokemon).toHaveLength(1)
+      expect(afterDeposit.boxes![3]![0]?.speciesId).toBe(252)
+      expect(afterDeposit.boxes![3]![0]?.level).toBe(treecko.level)
+    })
+
+    it('should validate box moves', async () => {
+      const treecko = (await parser.parse(testSaveData)).party_pokemon[0]!
+      const save = await parser.parse(await withBoxPokemon([[0, 0, treecko.rawBytes]]))
+
+      expect(() => parser.depositToBox(save, 0, 1)).toThrow('last Pokemon')
+      expect(() => parser.withdrawFromBox(save, 0, 1)).toThrow('Box 1 slot 2 is empty')
+      expect(() => parser.withdrawFromBox(save, 14, 0)).toThrow('Box 15 does not exist')
+
+      const withdrawn = parser.withdrawFromBox(save, 0, 0)
+      expect(() => parser.depositToBox({ ...withdrawn, boxes: save.boxes }, 1, 0, 0)).toThrow(
+        'Box 1 slot 1 is not empty'
+      )
+      const fullParty = { ...save, party_pokemon: Array(6).fill(treecko) }
+      expect(() => parser.withdrawFromBox(fullParty, 0, 0)).toThrow('Party is full')
+    })
+
+    it('should release box and party Pokemon through a save round trip', async () => {
+      const treecko = (await parser.parse(testSaveData)).party_pokemon[0]!
+      const save = await parser.parse(
+        await withBoxPokemon([
+          [0, 0, treecko.rawBytes],
+          [0, 1, treecko.rawBytes],
+        ])
+      )
+
+      const fromBox = parser.releasePokemon(save, { area: 'box', box: 0, slot: 1 })
+      expect(fromBox.boxes![0]![1]).toBeNull()
+      const withdrawn = parser.withdrawFromBox(fromBox, 0, 0)
+      const fromParty = parser.releasePokemon(withdrawn, { area: 'party', slot: 0 })
+      expect(fromParty.party_pokemon).toHaveLength(1)
+
+      const reparsed = await parser.parse(
+        parser.reconstructSaveFile(fromParty.party_pokemon, fromParty.boxes)
+      )
+      expect(reparsed.party_pokemon).toHaveLength(1)
+      expect(reparsed.party_pokemon[0]!.currentHp).toBe(reparsed.party_pokemon[0]!.maxHp)
+      expect(reparsed.boxes!.flat().every(p => p === null)).toBe(true)
+    })
+
+    it('should refuse releases the game refuses', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(() => parser.releasePokemon(parsed, { area: 'party', slot: 0 })).toThrow(
+        'Cannot release the last Pokemon in the party that can battle'
+      )
+      expect(() => parser.releasePokemon(parsed, { area: 'box', box: 0, slot: 0 })).toThrow(
+        'Box 1 slot 1 is empty'
+      )
+
+      const faintedBytes = parsed.party_pokemon[0]!.rawBytes.slice()
+      faintedBytes.fill(0, 0x56, 0x58) // current HP
+      const fainted = new PokemonBase(faintedBytes, new VanillaConfig())
+      const party = { ...parsed, party_pokemon: [parsed.party_pokemon[0]!, fainted] }
+      expect(() => parser.releasePokemon(party, { area: 'party', slot: 0 })).toThrow('can battle')
+      expect(parser.releasePokemon(party, { area: 'party', slot: 1 }).party_pokemon).toHaveLength(1)
+    })
+  })
+
+  describe('Daycare', () => {
+    it('should parse an empty daycare with its step counter', async () => {
+      const { daycare } = await parser.parse(testSaveData)
+      expect(daycare).toEqual({
+        slots: [null, null],
+        egg_pending: false,
+        offspring_personality: 0,
+        step_counter: 0x6a,
+      })
+    })
+
+    it('should decode deposited Pokemon, steps and a pending egg', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      const u32 = (value: number) => {
+        const bytes = new Uint8Array(4)
+        new DataView(bytes.buffer).setUint32(0, value, true)
+        return bytes
+      }
+      // Daycare slot 2 starts at 0x30bc; its step count is the slot's last u32
+      const save = await patchSaveBlock(1, [
+        [0x30bc, treecko.rawBytes.subarray(0, 80)],
+        [0x30bc + 0x88, u32(100)],
+        [0x3148, u32(0x12345678)],
+      ])
+
+      const { daycare } = await parser.parse(save)
+      expect(daycare!.slots[0]).toBeNull()
+      expect(daycare!.slots[1]).toMatchObject({ steps: 100, level_on_withdraw: 7 })
+      expect(daycare!.slots[1]!.pokemon.speciesId).toBe(252)
+      expect(daycare!.slots[1]!.pokemon.level).toBe(treecko.level)
+      expect(daycare).toMatchObject({ egg_pending: true, offspring_personality: 0x12345678 })
+    })
+  })
+
+  describe('Mail', () => {
+    it('should report no mail for a save with empty mail slots', async () => {
+      const { mail, party_pokemon } = await parser.parse(testSaveData)
+      expect(mail).toEqual([])
+      expect(party_pokemon[0]!.mailId).toBeUndefined()
+    })
+
+    it('should decode held mail and link it to the party Pokemon', async () => {
+      const letter = new Uint8Array(36).fill(0xff)
+      const view = new DataView(letter.buffer)
+      view.setUint16(0, 0x0201, true) // first Easy Chat word
+      letter.set(gbaStringToBytes('MAY', 8), 0x12)
+      view.setUint32(0x1a, 0x12345678, true)
+      view.setUint16(0x1e, 277, true) // Treecko (internal ID)
+      view.setUint16(0x20, 121, true) // Orange Mail
+      view.setUint16(0x22, 0, true)
+
+      // Mail slot 0 sits at SaveBlock1 0x2be0; party slot 1's mail byte at 0x238 + 0x55
+      const save = await patchSaveBlock(1, [
+        [0x2be0, letter],
+        [0x238 + 0x55, new Uint8Array([0])],
+      ])
+      const { mail, party_pokemon } = await parser.parse(save)
+
+      expect(party_pokemon[0]!.mailId).toBe(0)
+      expect(mail).toEqual([
+        {
+          slot: 0,
+          held_by: 1,
+          words: [0x0201, null, null, null, null, null, null, null, null],
+          sender: 'MAY',
+          sender_ot_id: 0x12345678,
+          species_id: 252,
+          item_id: 515,
+          item_name: 'Orange Mail',
+        },
+      ])
+    })
+
+    it('should not carry mail over to box Pokemon', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const boxed = PokemonBase.fromBoxData(parsed.party_pokemon[0]!.rawBytes, new VanillaConfig())
+      expect(boxed.mailId).toBeUndefined()
+    })
+  })
+
+  describe('Secret Bases', () => {
+    it('should report no bases for a save without a secret base', async () => {
+      const { secret_bases } = await parser.parse(testSaveData)
+      expect(secret_bases).toEqual({ own: null, friends: [] })
+    })
+
+    it('should decode the own base and a friend base received by record mixing', async () => {
+      const base = (id: number, name: string, flags: number) => {
+        const record = new Uint8Array(0xa0)
+        const view = new DataView(record.buffer)
+        record[0] = id
+        record[1] = flags
+        record.set(gbaStringToBytes(name, 7), 0x2)
+        view.setUint32(0x9, 0x12345678, true)
+        record[0x10] = 3 // times entered
+        return { record, view }
+      }
+
+      const own = base(12, 'EMERALD', 0x00)
+      own.record[0x12] = 10 // decoration ID
+      own.record[0x22] = 0x34 // x 3, y 4
+      const friend = base(45, 'MAY', 0x50) // female, registered
+      friend.view.setUint16(0x4c, 33, true) // Tackle
+      friend.view.setUint16(0x7c, 277, true) // Treecko (internal ID)
+      friend.view.setUint16(0x88, 13, true) // Potion
+      friend.record[0x94] = 5
+
+      // Secret bases start at SaveBlock1 0x1a9c, 0xa0 bytes each
+      const save = await patchSaveBlock(1, [
+        [0x1a9c, own.record],
+        [0x1a9c + 2 * 0xa0, friend.record],
+      ])
+      const { secret_bases } = await parser.parse(save)
+
+      expect(secret_bases?.own).toMatchObject({
+        location_id: 12,
+        owner_name: 'EMERALD',
+        owner_gender: 'male',
+        owner_ot_id: 0x12345678,
+        registered: false,
+        times_entered: 3,
+        decorations: [{ decoration_id: 10, x: 3, y: 4 }],
+        party: [],
+      })
+      expect(secret_bases?.friends).toEqual([
+        {
+          location_id: 45,
+          owner_name: 'MAY',
+          owner_gender: 'female',
+          owner_ot_id: 0x12345678,
+          registered: true,
+          times_entered: 3,
+          decorations: [],
+          party: [{ species_id: 252, level: 5, item_id: 17, moves: [33, 0, 0, 0] }],
+        },
+      ])
+    })
+  })
+
+  describe('Mystery Gift', () => {
+    it('should report an untouched Mystery Gift section', async () => {
+      const { mystery_gift } = await parser.parse(testSaveData)
+      expect(mystery_gift).toEqual({
+        mystery_gift_enabled: false,
+        mystery_event_enabled: false,
+        wonder_card: null,
+        wonder_news_title: null,
+        event_tickets: {
+          eon_ticket: false,
+          aurora_ticket: false,
+          old_sea_map: false,
+          mystic_ticket: false,
+        },
+        enigma_berry: null,
+        ram_script: false,
+      })
+    })
+
+    it('should decode a stored Wonder Card and the Aurora Ticket flag', async () => {
+      const card = new Uint8Array(0x150).fill(0xff)
+      const view = new DataView(card.buffer)
+      view.setUint32(0, 0xbeef, true) // card CRC
+      view.setUint16(0x4, 1000, true) // flag ID
+      view.setUint16(0x6, 277, true) // Treecko (internal ID)
+      view.setUint32(0x8, 7, true) // card ID
+      card.set(gbaStringToBytes('AURORA', 40), 0x4 + 0xa)
+
+      // Flag 0x8d5 lives in the event flags at SaveBlock1 0x1270; the card CRC at 0x322c + 0x1c0
+      const save = await patchSaveBlock(1, [
+        [0x1270 + (0x8d5 >> 3), new Uint8Array([1 << (0x8d5 & 7)])],
+        [0x322c + 0x1c0, card],
+      ])
+      const { mystery_gift } = await parser.parse(save)
+
+      expect(mystery_gift?.event_tickets.aurora_ticket).toBe(true)
+      expect(mystery_gift?.wonder_card).toMatchObject({
+        card_id: 7,
+        flag_id: 1000,
+        icon_species_id: 252,
+        title: 'AURORA',
+        subtitle: '',
+      })
+      expect(mystery_gift?.wonder_card?.body).toEqual(['', '', '', ''])
+    })
+  })
+
+  describe('Game Stats', () => {
+    it('should decrypt the game stat counters', async () => {
+      const { game_stats } = await parser.parse(testSaveData)
+      expect(game_stats).toMatchObject({
+        saved_game: 2,
+        steps: 371,
+        total_battles: 4,
+        wild_battles: 4,
+        trainer_battles: 0,
+        hatched_eggs: 0,
+        jumped_down_ledges: 1,
+      })
+      expect(Object.keys(game_stats!)).toEqual([...GAME_STAT_NAMES])
+    })
+  })
+
+  describe('Roamer', () => {
+    it('should report the inactive roamer before the Elite Four', async () => {
+      const { roamer } = await parser.parse(testSaveData)
+      expect(roamer).toMatchObject({ species_id: 380, active: false, level: 0, hp: 0 })
+    })
+
+    it('should decode an active roamer and its packed IVs', async () => {
+      const record = new Uint8Array(0x1c)
+      const view = new DataView(record.buffer)
+      // IVs HP 31, Attack 0, Defense 15, Speed 31, Sp. Attack 1, Sp. Defense 30
+      view.setUint32(0, (31 | (15 << 10) | (31 << 15) | (1 << 20) | (30 << 25)) >>> 0, true)
+      view.setUint32(0x4, 0xdeadbeef, true)
+      view.setUint16(0x8, 408, true) // Latios (internal ID)
+      view.setUint16(0xa, 120, true)
+      record[0xc] = 40
+      record[0x13] = 1
+
+      // Roamer struct at SaveBlock1 0x31dc
+      const { roamer } = await parser.parse(await patchSaveBlock(1, [[0x31dc, record]]))
+      expect(roamer).toEqual({
+        species_id: 381,
+        active: true,
+        level: 40,
+        hp: 120,
+        status: 0,
+        personality: 0xdeadbeef,
+        ivs: [31, 0, 15, 31, 1, 30],
+      })
+    })
+  })
+
+  describe('Current Location', () => {
+    it('should decode the map, position and last heal location', async () => {
+      const { location } = await parser.parse(testSaveData)
+      expect(location).toEqual({
+        map_group: 0,
+        map_num: 10,
+        map_name: 'Oldale Town',
+        x: 10,
+        y: 13,
+        last_heal_location: { map_group: 0, map_num: 10, map_name: 'Oldale Town' },
+      })
+    })
+
+    it('should name routes and indoor maps but not dungeons', () => {
+      expect(getMapName(0, 16)).toBe('Route 101')
+      expect(getMapName(0, 49)).toBe('Route 134')
+      expect(getMapName(1, 0)).toBe('Littleroot Town (Indoors)')
+      expect(getMapName(24, 0)).toBeNull()
+    })
+  })
+
+  describe('Misc', () => {
+    it('should decode the lottery number and minor state', async () => {
+      const { misc } = await parser.parse(testSaveData)
+      expect(misc).toEqual({
+        lottery_number: 39793,
+        safari_mode: false,
+        repel_steps: 0,
+        ash_collected: 0,
+      })
+    })
+
+    it('should read Repel steps from the event variables', async () => {
+      // VAR_REPEL_STEP_COUNT (0x4021) in the vars at SaveBlock1 0x139c
+      const save = await patchSaveBlock(1, [[0x139c + 0x21 * 2, new Uint8Array([250, 0])]])
+      const { misc } = await parser.parse(save)
+      expect(misc?.repel_steps).toBe(250)
+    })
+  })
+
+  describe('Starter and Rival', () => {
+    it('should detect the chosen starter and the rival', async () => {
+      const { starter, rival } = await parser.parse(testSaveData)
+      expect(starter).toEqual({ species_id: 252, name: 'Treecko' })
+      expect(rival).toEqual({ name: 'MAY', gender: 'female' })
+    })
+
+    it('should map VAR_STARTER_MON to the starter species', async () => {
+      // VAR_STARTER_MON (0x4023) = 2 in the vars at SaveBlock1 0x139c
+      const save = await patchSaveBlock(1, [[0x139c + 0x23 * 2, new Uint8Array([2, 0])]])
+      const { starter } = await parser.parse(save)
+      expect(starter).toEqual({ species_id: 258, name: 'Mudkip' })
+    })
+
+    it('should report no starter before FLAG_SYS_POKEMON_GET is set', async () => {
+      // Flag 0x860 is bit 0 of byte 0x10c in the event flags at SaveBlock1 0x1270
+      const flagByte = 0x1270 + (0x860 >> 3)
+      const save = await patchSaveBlock(1, [[flagByte, new Uint8Array([0])]])
+      const { starter } = await parser.parse(save)
+      expect(starter).toBeNull()
+    })
+  })
+
+  describe('Optional Sections', () => {
+    // Write a Hall of Fame sector with a proper footer (id, checksum, signature)
+    const writeHallOfFameSector = (save: Uint8Array, sectorIdx: number, fill: number) => {
+      const view = new DataView(save.buffer, sectorIdx * 4096, 4096)
+      save.fill(fill, sectorIdx * 4096, sectorIdx * 4096 + 3968)
+      let sum = 0
+      for (let i = 0; i < 3968; i += 4) sum = (sum + view.getUint32(i, true)) >>> 0
+      view.setUint16(0xff4, sectorIdx, true)
+      view.setUint16(0xff6, ((sum >>> 16) + (sum & 0xffff)) & 0xffff, true)
+      view.setUint32(0xff8, 0x08012025, true)
+    }
+
+    it('should report a save that never used the optional sections', async () => {
+      const { optional_sections } = await parser.parse(testSaveData)
+      expect(optional_sections).toEqual({
+        hall_of_fame: { present: false, valid: undefined },
+        trainer_hill: { present: false },
+        recorded_battle: { present: false },
+        wonder_news: { present: false },
+        wonder_card: { present: false },
+      })
+    })
+
+    it('should validate Hall of Fame checksums and detect raw sectors', async () => {
+      const save = new Uint8Array(testSaveData.slice(0))
+      writeHallOfFameSector(save, 28, 0x11)
+      writeHallOfFameSector(save, 29, 0x22)
+      save[30 * 4096 + 8] = 0x42 // Trainer Hill data
+      const { optional_sections } = await parser.parse(save)
+      expect(optional_sections?.hall_of_fame).toEqual({ present: true, valid: true })
+      expect(optional_sections?.trainer_hill.present).toBe(true)
+      expect(optional_sections?.recorded_battle.present).toBe(false)
+
+      save[29 * 4096] = 0x23
+      const corrupted = await parser.parse(save)
+      expect(corrupted.optional_sections?.hall_of_fame).toEqual({ present: true, valid: false })
+    })
+  })
+
+  describe('Save Slots', () => {
+    it('should parse both slots and mark the newer one active', async () => {
+      const { active, slot1, slot2 } = await parser.parseAllSlots(testSaveData)
+      expect(active).toBe(2)
+      expect(slot1?.counter).toBe(8)
+      expect(slot2?.counter).toBe(9)
+      expect(slot1?.data.active_slot).toBe(0)
+      expect(slot2?.data.active_slot).toBe(14)
+      expect(slot1?.data.player_name).toBe(slot2?.data.player_name)
+    })
+
+    it('should only read slot 1 sectors when slot 1 is forced', async () => {
+      const slot1Parser = new PokemonSaveParser(1, new VanillaConfig())
+      const { sector_map } = await slot1Parser.parse(testSaveData)
+      expect(sector_map?.size).toBe(14)
+      expect([...sector_map!.values()].every(i => i < 14)).toBe(true)
+    })
+
+    it('should report an empty slot as null', async () => {
+      const save = new Uint8Array(testSaveData.slice(0))
+      save.fill(0, 0, 14 * 4096)
+      const { active, slot1, slot2 } = await parser.parseAllSlots(save.buffer)
+      expect(active).toBe(2)
+      expect(slot1).toBeNull()
+      expect(slot2?.counter).toBe(9)
+    })
+
+    it('should roll back by copying the backup slot over the active one', async () => {
+      const before = await parser.parseAllSlots(testSaveData)
+      await parser.parse(testSaveData)
+      const rolledBack = parser.copySaveSlot(1)
+
+      const { active, slot1, slot2 } = await parser.parseAllSlots(rolledBack.buffer)
+      expect(active).toBe(2)
+      expect(slot1?.counter).toBe(8)
+      expect(slot2?.counter).toBe(10)
+      expect(slot2?.data.play_time).toEqual(before.slot1?.data.play_time)
+      expect(slot2?.data.sector_map?.size).toBe(14)
+    })
+
+    it('should refuse to copy an incomplete slot', async () => {
+      const save = new Uint8Array(testSaveData.slice(0))
+      save.fill(0, 0, 4096)
+      await parser.parse(save.buffer)
+      expect(() => parser.copySaveSlot(1)).toThrow('Slot 1 is incomplete (13 of 14 valid sectors)')
+    })
+  })
+
+  describe('Save Repair', () => {
+    it('should leave an intact save unchanged', async () => {
+      await parser.parse(testSaveData)
+      const { data, repairs } = parser.repairSave()
+      expect(repairs).toEqual([])
+      expect(data).toEqual(new Uint8Array(testSaveData))
+    })
+
+    it('should fix stale checksums and restore wiped sectors from the backup', async () => {
+      const { sector_map } = await parser.parse(testSaveData)
+      const save = new Uint8Array(testSaveData.slice(0))
+      // A hex edit without a checksum update, and a sector wiped by an interrupted save
+      const edited = sector_map!.get(4)! * 4096 + 0x10
+      save[edited] = save[edited]! ^ 0xff
+      const destroyed = sector_map!.get(2)!
+      save.fill(0, destroyed * 4096, (destroyed + 1) * 4096)
+
+      const damaged = new PokemonSaveParser(undefined, new VanillaConfig())
+      await damaged.parse(save.buffer)
+      const { data, repairs } = damaged.repairSave()
+      expect(repairs).toEqual([
+        { sector_id: 2, sector_index: destroyed, action: 'restored-from-backup' },
+        { sector_id: 4, sector_index: sector_map!.get(4), action: 'checksum-fixed' },
+      ])
+      expect(data[edited]).toBe(save[edited])
+
+      const repaired = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(data)
+      expect(repaired.active_slot).toBe(14)
+      expect(repaired.sector_map?.size).toBe(14)
+      expect(repaired.player_name).toBe(groundTruth.player_name)
+      expect(repaired.party_pokemon).toHaveLength(1)
+    })
+  })
+
+  describe('Save Writing', () => {
+    it('should write edited SaveBlocks back with valid sector checksums', async () => {
+      await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      blocks.saveblock2[0x13] = 1 // button mode: LR
+      blocks.saveblock1[0x139c + 0x21 * 2] = 99 // Repel steps
+      const written = parser.writeSaveFile({
+        saveblock1: blocks.saveblock1,
+        saveblock2: blocks.saveblock2,
+      })
+
+      const reparsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(reparsed.sector_map?.size).toBe(14)
+      expect(reparsed.options?.button_mode).toBe('lr')
+      expect(reparsed.misc?.repel_steps).toBe(99)
+      expect(reparsed.player_name).toBe('EMERALD')
+    })
+
+    it('should write PC storage so boxed Pokemon survive a round trip', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const blocks = parser.getSaveBlocks()
+      blocks.pokemonStorage.set(parsed.party_pokemon[0]!.rawBytes.subarray(0, 80), 4)
+      const written = parser.writeSaveFile({ pokemonStorage: blocks.pokemonStorage })
+
+      const reparsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      expect(reparsed.sector_map?.size).toBe(14)
+      expect(reparsed.boxes?.[0]?.[0]?.speciesId).toBe(252)
+    })
+
+    it('should leave the save unchanged when writing unmodified blocks', async () => {
+      await parser.parse(testSaveData)
+      const written = parser.writeSaveFile(parser.getSaveBlocks())
+      expect(written).toEqual(new Uint8Array(testSaveData))
+    })
+  })
+
+  describe('Pokemon Editing', () => {
+    it('should heal the party through a save round trip', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(parsed.party_pokemon[0]!.currentHp).toBe(18)
+      expect(parsed.party_pokemon[0]!.pp1).toBe(32)
+
+      const healed = parser.healParty(parsed)
+      const written = parser.reconstructSaveFile(healed.party_pokemon)
+      const treecko = (await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written))
+        .party_pokemon[0]!
+      expect(treecko.currentHp).toBe(20)
+      expect(treecko.status).toBe(0)
+      expect(treecko.ppValues).toEqual([35, 30, 0, 0]) // Pound, Leer, two empty slots
+      expect(treecko.isChecksumValid).toBe(true)
+    })
+
+    it('should make a Pokemon shiny without changing its nature, gender or data', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+      const before = pokemon.toJSON()
+      const { personality } = pokemon
+      expect(pokemon.isShiny).toBe(false)
+
+      pokemon.makeShiny()
+      const written = parser.reconstructSaveFile(parsed.party_pokemon)
+      const treecko = (await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written))
+        .party_pokemon[0]!
+      expect(treecko.isShiny).toBe(true)
+      expect(treecko.personality).not.toBe(personality)
+      expect(treecko.personality & 1).toBe(personality & 1)
+      expect(treecko.isChecksumValid).toBe(true)
+      expect(treecko.toJSON()).toMatchObject({
+        nature: before.nature,
+        gender: before.gender,
+        species_id: before.species_id,
+        experience: before.experience,
+        ivs: before.ivs,
+        evs: before.evs,
+        moves: before.moves,
+        origin: before.origin,
+      })
+    })
+
+    it('should apply PP Ups to the Gen 3 base PP', () => {
+      expect(getMaxMovePP(33)).toBe(35) // Tackle
+      expect(getMaxMovePP(33, 3)).toBe(56)
+      expect(getMaxMovePP(354)).toBe(5) // Psycho Boost
+      expect(getMaxMovePP(0)).toBeUndefined()
+    })
+
+    it('should keep nickname, EV and move edits through a save round trip', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+      pokemon.nickname = 'LEAFY'
+      pokemon.setEvs([4, 252, 0, 252, 0, 0])
+      pokemon.setMove(3, 33, 35) // Tackle
+      expect(pokemon.isChecksumValid).toBe(true)
+
+      const written = parser.reconstructSaveFile(parsed.party_pokemon)
+      const reparsed = await new PokemonSaveParser(undefined, new VanillaConfig()).parse(written)
+      const edited = reparsed.party_pokemon[0]!
+
+      expect(edited.nickname).toBe('LEAFY')
+      expect(edited.evs).toEqual([4, 252, 0, 252, 0, 0])
+      expect(edited.move4).toBe(33)
+      expect(edited.pp4).toBe(35)
+      expect(edited.isChecksumValid).toBe(true)
+      expect(edited.isBadEgg).toBe(false)
+    })
+
+    it('should reject out-of-range move slots', async () => {
+      const parsed = await parser.parse(testSaveData)
+      expect(() => parsed.party_pokemon[0]!.setMove(4, 33, 35)).toThrow(
+        'Move index must be between 0 and 3'
+      )
+    })
+  })
+
+  describe('Legality', () => {
+    it('should find no issues on an untouched wild Pokemon', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      expect(findPidIvMethod(treecko.personality, treecko.ivs)).toBe(1)
+      expect(parser.checkLegality(parsed)[0]).toEqual({ legal: true, issues: [] })
+    })
+
+    it('should flag a rerolled PID that no longer matches the IVs', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      treecko.makeShiny()
+      const report = checkLegality(treecko, new VanillaConfig())
+      expect(report.legal).toBe(false)
+      expect(report.issues.map(({ code }) => code)).toEqual(['pid-iv-mismatch'])
+    })
+
+    it('should flag PP above the move maximum and duplicate moves', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      treecko.setMove(0, 1, 99) // Pound has 35 PP
+      treecko.setMove(2, 1, 35)
+      const { issues } = checkLegality(treecko, new VanillaConfig())
+      expect(issues.map(({ code }) => code)).toEqual(['pp-above-max', 'move-duplicate'])
+      expect(issues[0]!.context).toEqual({ slot: 1, pp: 99, max_pp: 35 })
+    })
+
+    it('should skip games without Gen 3 species data', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const report = checkLegality(parsed.party_pokemon[0]!, new QuetzalConfig())
+      expect(report.legal).toBe(true)
+      expect(report.issues.map(({ code }) => code)).toEqual(['legality-unsupported'])
+    })
+  })
+
+  describe('PK3 Export', () => {
+    it('should export decrypted, unshuffled .pk3 data in party and box sizes', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+      const pk3 = treecko.exportPK3()
+      const view = new DataView(pk3.buffer)
+
+      expect(pk3).toHaveLength(100)
+      expect(pk3.subarray(0, 0x20)).toEqual(treecko.rawBytes.subarray(0, 0x20))
+      expect(pk3.subarray(0x50)).toEqual(treecko.rawBytes.subarray(0x50))
+      expect(view.getUint16(0x20, true)).toBe(treecko.internalSpeciesId)
+      expect(view.getUint32(0x48, true) & 0x1f).toBe(treecko.ivs[0]) // Misc substruct IVs
+
+      let sum = 0
+      for (let i = 0x20; i < 0x50; i += 2) sum = (sum + view.getUint16(i, true)) & 0xffff
+      expect(sum).toBe(view.getUint16(0x1c, true))
+
+      expect(treecko.exportPK3('box')).toEqual(pk3.subarray(0, 80))
+    })
+  })
+
+  describe('PK3 Import', () => {
+    it('should re-encrypt exported .pk3 files back to the original bytes', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+
+      expect(parser.importPK3(treecko.exportPK3()).rawBytes).toEqual(treecko.rawBytes)
+      const fromBox = parser.importPK3(treecko.exportPK3('box'))
+      expect(fromBox.stats).toEqual(treecko.stats)
+      expect(fromBox.currentHp).toBe(fromBox.maxHp)
+    })
+
+    it('should reject malformed or tampered .pk3 files', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pk3 = parsed.party_pokemon[0]!.exportPK3()
+
+      expect(() => parser.importPK3(pk3.subarray(0, 64))).toThrow('80 or 100 bytes')
+      const tampered = new Uint8Array(pk3)
+      tampered[0x20]! ^= 0xff // species
+      expect(() => parser.importPK3(tampered)).toThrow('checksum-mismatch')
+    })
+
+    it('should round-trip encrypted .ek3 files', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = parsed.party_pokemon[0]!
+
+      expect(treecko.exportEK3()).toEqual(treecko.rawBytes)
+      expect(treecko.exportEK3('box')).toEqual(treecko.rawBytes.subarray(0, 80))
+      expect(parser.importEK3(treecko.exportEK3()).rawBytes).toEqual(treecko.rawBytes)
+      expect(parser.importEK3(treecko.exportEK3('box')).stats).toEqual(treecko.stats)
+      // A decrypted .pk3 read as .ek3 fails the checksum
+      expect(() => parser.importEK3(treecko.exportPK3())).toThrow('Invalid .ek3')
+    })
+
+    it('should inject into party and box slots through a save round trip', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parser.importPK3(parsed.party_pokemon[0]!.exportPK3())
+
+      const toParty = parser.injectPokemon(parsed, pokemon, { area: 'party', slot: 1 })
+      const toBox = parser.injectPokemon(toParty, pokemon, { area: 'box', box: 2, slot: 5 })
+      const reparsed = await parser.parse(
+        parser.reconstructSaveFile(toBox.party_pokemon, toBox.boxes)
+      )
+
+      expect(reparsed.party_pokemon.map(p => p.speciesId)).toEqual([252, 252])
+      expect(reparsed.boxes![2]![5]?.speciesId).toBe(252)
+      expect(() => parser.injectPokemon(parsed, pokemon, { area: 'party', slot: 3 })).toThrow(
+        'out of range'
+      )
+    })
+  })
+
+  describe('Showdown Export', () => {
+    it('should export the party as a Showdown paste', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const lines = parser.exportShowdown(parsed).split('\n')
+
+      expect(lines[0]).toMatch(/^Treecko( \([MF]\))?$/)
+      expect(lines).toContain('Ability: Overgrow')
+      expect(lines).toContain('Level: 5')
+      expect(lines).toContain('Hasty Nature')
+      expect(lines.filter(line => line.startsWith('- '))).toEqual(['- Pound', '- Leer'])
+    })
+
+    it('should show nicknames, items and non-default EVs and IVs', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+      pokemon.nickname = 'LEAFY'
+      pokemon.setItem(13) // Potion
+      pokemon.setEvs([4, 252, 0, 252, 0, 0])
+      pokemon.setIvs([31, 31, 31, 31, 0, 31])
+      const paste = parser.exportShowdown(parsed)
+
+      expect(paste).toMatch(/^LEAFY \(Treecko\)( \([MF]\))? @ Potion$/m)
+      expect(paste).toContain('EVs: 4 HP / 252 Atk / 252 Spe')
+      expect(paste).toContain('IVs: 0 SpA')
+    })
+  })
+
+  describe('Showdown Import', () => {
+    it('should parse Showdown sets', () => {
+      const [set] = parseShowdownPaste(
+        'Ziggy (Zigzagoon) (F) @ Leftovers\nAbility: Pickup\nLevel: 50\nShiny: Yes\n' +
+          'EVs: 252 HP / 4 Def / 252 Spe\nJolly Nature\nIVs: 0 SpA\n' +
+          '- Tackle\n- Hidden Power [Ground]'
+      )
+      expect(set).toEqual({
+        nickname: 'Ziggy',
+        species: 'Zigzagoon',
+        gender: 'female',
+        item: 'Leftovers',
+        ability: 'Pickup',
+        level: 50,
+        shiny: true,
+        friendship: undefined,
+        evs: [252, 0, 4, 252, 0, 0],
+        ivs: [31, 31, 31, 31, 0, 31],
+        nature: 'Jolly',
+        moves: ['Tackle', 'Hidden Power [Ground]'],
+      })
+    })
+
+    it('should build legal Pokemon that survive a save round trip', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const paste =
+        'Starmie @ Leftovers\nAbility: Natural Cure\nLevel: 42\nShiny: Yes\n' +
+        'EVs: 252 SpA / 4 SpD / 252 Spe\nTimid Nature\n- Surf\n- Psychic\n\n' +
+        'Unown-B\nLevel: 10\n- Hidden Power [Psychic]'
+      const imported = parser.importShowdown(parsed, paste)
+      const reparsed = await parser.parse(parser.reconstructSaveFile(imported.party_pokemon))
+      const [, starmie, unown] = reparsed.party_pokemon
+
+      expect(reparsed.party_pokemon).toHaveLength(3)
+      expect(starmie!.speciesId).toBe(121)
+      expect(starmie!.nickname).toBe('STARMIE')
+      expect(starmie!.otName).toBe(parsed.player_name)
+      expect(starmie!.otId).toBe(parsed.trainer!.ot_id)
+      expect(starmie!.itemIdName).toBe('leftovers')
+      expect(starmie!.level).toBe(42)
+      expect(starmie!.isShiny).toBe(true)
+      expect(starmie!.nature).toBe('Timid')
+      expect(starmie!.evs).toEqual([0, 0, 0, 252, 252, 4])
+      expect(starmie!.personality & 1).toBe(1) // Natural Cure is the second ability slot
+      expect(starmie!.isChecksumValid).toBe(true)
+      expect(starmie!.currentHp).toBe(starmie!.maxHp)
+      expect(unown!.form).toBe('b')
+    })
+
+    it('should reject sets that are impossible in Gen 3', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const treecko = (lines: string) => () => parser.importShowdown(parsed, `Treecko\n${lines}`)
+
+      expect(treecko('Ability: Levitate\n- Pound')).toThrow("Treecko can't have Levitate")
+      expect(treecko('EVs: 252 HP / 252 Atk / 252 Spe\n- Pound')).toThrow('EVs must be')
+      expect(treecko('- Moonblast')).toThrow('Unknown move "Moonblast"')
+      expect(() => parser.importShowdown(parsed, 'Starmie (M)\n- Surf')).toThrow(
+        "Starmie can't be male"
+      )
+      expect(() =>
+        parser.importShowdown(parsed, Array(6).fill('Treecko\n- Pound').join('\n\n'))
+      ).toThrow('Party size cannot exceed 6')
+    })
+  })
+
+  describe('Shiny Classification', () => {
+    it('should report the XOR value against the Gen 3 threshold', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.shinyInfo).toEqual({
+        value: 11355,
+        threshold: 8,
+        odds: 8192,
+        is_shiny: false,
+        is_radiant: false,
+      })
+      expect(JSON.parse(JSON.stringify(pokemon)).shiny.threshold).toBe(8)
+    })
+  })
+
+  describe('Nature Effect', () => {
+    it('should report the stats a nature raises and lowers', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.nature).toBe('Hasty')
+      expect(pokemon.natureEffect).toEqual({ plus: 'speed', minus: 'defense' })
+
+      pokemon.natureRaw = 12 // Serious
+      expect(pokemon.natureEffect).toEqual({ plus: null, minus: null })
+    })
+  })
+
+  describe('Characteristic', () => {
+    it('should derive the characteristic from the highest IV', async () => {
+      const parsed = await parser.parse(testSaveData)
+      // Highest IV is Sp. Atk 27; 27 % 5 = 2
+      expect(parsed.party_pokemon[0]!.characteristic).toBe('Thoroughly cunning')
+    })
+
+    it('should break IV ties starting from personality % 6', () => {
+      const ivs = [31, 31, 31, 31, 31, 31]
+      expect(getCharacteristic(ivs, 0)).toBe('Takes plenty of siestas')
+      expect(getCharacteristic(ivs, 4)).toBe('Mischievous')
+      expect(getCharacteristic([31, 0, 0, 0, 0, 31], 1)).toBe('Somewhat vain')
+    })
+  })
+
+  describe('Stat Verification', () => {
+    it('should recompute stored stats from base stats, IVs, EVs, nature and level', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.expectedStats).toEqual([20, 10, 8, 14, 12, 11])
+      expect(pokemon.expectedStats).toEqual(pokemon.stats)
+      expect(parser.verifyStats(parsed)).toEqual([])
+    })
+
+    it('should flag Pokemon whose stored stats were edited', async () => {
+      const parsed = await parser.parse(testSaveData)
+      parsed.party_pokemon[0]!.attack = 99
+
+      const warnings = parser.verifyStats(parsed)
+      expect(warnings).toHaveLength(1)
+      expect(warnings[0]).toMatchObject({
+        code: 'stats-mismatch',
+        context: { slot: 1, stored: '20/99/8/14/12/11', expected: '20/10/8/14/12/11' },
+      })
+    })
+
+    it('should handle species with fixed HP and form-dependent base stats', () => {
+      const ivs = [31, 31, 31, 31, 31, 31]
+      const evs = [0, 0, 0, 0, 0, 0]
+      expect(calculateStats(292, 50, ivs, evs, 'Hardy')![0]).toBe(1)
+      expect(calculateStats(386, 50, ivs, evs, 'Hardy', 'speed')).toEqual([
+        125, 115, 110, 200, 115, 110,
+      ])
+      expect(calculateStats(9999, 50, ivs, evs, 'Hardy')).toBeUndefined()
+    })
+  })
+
+  describe('Species Data', () => {
+    it('should expose types and ability from the embedded species table', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.types).toEqual(['grass'])
+      expect(pokemon.ability).toBe('overgrow')
+      expect(JSON.parse(JSON.stringify(pokemon))).toMatchObject({
+        types: ['grass'],
+        ability: 'overgrow',
+      })
+    })
+
+    it('should cover every Gen 3 species', () => {
+      for (let id = 1; id <= 386; id++) {
+        const info = getSpeciesInfo(id)!
+        expect(info.name).toBeTruthy()
+        expect(info.base_stats).toHaveLength(6)
+        expect(info.types.length).toBeGreaterThanOrEqual(1)
+        expect(info.abilities.length).toBeGreaterThanOrEqual(1)
+      }
+      expect(getSpeciesInfo(387)).toBeUndefined()
+    })
+
+    it('should look up species by name and ability slot', () => {
+      expect(getSpeciesName(122)).toBe('Mr. Mime')
+      expect(findSpeciesIdByName('mr-mime')).toBe(122)
+      expect(findSpeciesIdByName('Nidoran♀')).toBe(29)
+      expect(findSpeciesIdByName('MissingNo')).toBeUndefined()
+      expect(getSpeciesAbility(25, 0)).toBe('static')
+      // Single-ability species fall back to the first slot
+      expect(getSpeciesAbility(25, 1)).toBe('static')
+      expect(getSpeciesAbility(130, 0)).toBe('intimidate')
+      expect(getSpeciesAbility(95, 1)).toBe('sturdy')
+    })
+  })
+
+  describe('Experience', () => {
+    it('should parse experience and level-up progress', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      // Treecko is Medium Slow: level 5 starts at 135 exp, level 6 at 179
+      expect(pokemon.experience).toBe(152)
+      expect(pokemon.growthRate).toBe('medium_slow')
+      expect(pokemon.expToNextLevel).toBe(27)
+      expect(pokemon.levelProgress).toBeCloseTo(17 / 44)
+    })
+
+    it('should match the Gen 3 experience tables', () => {
+      expect(experienceForLevel('medium_slow', 1)).toBe(0)
+      expect(experienceForLevel('medium_slow', 2)).toBe(9)
+      expect(experienceForLevel('fast', 100)).toBe(800000)
+      expect(experienceForLevel('medium_fast', 100)).toBe(1000000)
+      expect(experienceForLevel('medium_slow', 100)).toBe(1059860)
+      expect(experienceForLevel('slow', 100)).toBe(1250000)
+      expect(experienceForLevel('erratic', 100)).toBe(600000)
+      expect(experienceForLevel('fluctuating', 100)).toBe(1640000)
+    })
+  })
+
+  describe('Origin Data', () => {
+    it('should parse met location, met level, origin game and Poke Ball', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const pokemon = parsed.party_pokemon[0]!
+
+      expect(pokemon.metLocation).toBe(16)
+      expect(pokemon.metLocationName).toBe('Route 101')
+      expect(pokemon.metLevel).toBe(5)
+      expect(pokemon.originGameName).toBe('Emerald')
+      expect(pokemon.pokeballName).toBe('Poke Ball')
+      expect(pokemon.otGender).toBe('male')
+    })
+
+    it('should include origin data in JSON output', async () => {
+      const parsed = await parser.parse(testSaveData)
+      const json = JSON.parse(JSON.stringify(parsed.party_pokemon[0]))
+
+      expect(json.species_id).toBe(252)
+      expect(json.origin.met_location_name).toBe('Route 101')
+      expect(json.origin.pokeball).toBe(4)
+    })
+  })
 })
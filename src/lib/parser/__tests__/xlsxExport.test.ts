@@ -0,0 +1,102 @@
+/**
+ * Tests for the Excel workbook export and the ZIP writer underneath it
+ */
+
+import { describe, expect, it } from 'vitest'
+import { PokemonSaveParser } from '../core/PokemonSaveParser'
+import { createXlsx, saveToXlsxSheets } from '../core/xlsxExport'
+import { crc32, createZip } from '../core/zip'
+import { VanillaConfig } from '../games/vanilla/config'
+import { loadSave } from './testData'
+
+// Walk the local file headers of a stored ZIP and check each entry's CRC
+function unzip(zip: Uint8Array): Map<string, string> {
+  const view = new DataView(zip.buffer, zip.byteOffset, zip.byteLength)
+  const decoder = new TextDecoder()
+  const entries = new Map<string, string>()
+  for (let pos = 0; view.getUint32(pos, true) === 0x04034b50; ) {
+    const size = view.getUint32(pos + 18, true)
+    const nameLength = view.getUint16(pos + 26, true)
+    const name = decoder.decode(zip.subarray(pos + 30, pos + 30 + nameLength))
+    const data = zip.subarray(pos + 30 + nameLength, pos + 30 + nameLength + size)
+    expect(crc32(data)).toBe(view.getUint32(pos + 14, true))
+    entries.set(name, decoder.decode(data))
+    pos += 30 + nameLength + size
+  }
+  return entries
+}
+
+describe('ZIP Writer', () => {
+  it('should compute the standard CRC-32', () => {
+    expect(crc32(new TextEncoder().encode('123456789'))).toBe(0xcbf43926)
+  })
+
+  it('should end with a central directory covering every entry', () => {
+    const zip = createZip([
+      { name: 'a.txt', data: new TextEncoder().encode('hello') },
+      { name: 'dir/b.txt', data: new Uint8Array(0) },
+    ])
+    const view = new DataView(zip.buffer)
+    const end = zip.length - 22
+
+    expect(view.getUint32(end, true)).toBe(0x06054b50)
+    expect(view.getUint16(end + 10, true)).toBe(2)
+    const centralOffset = view.getUint32(end + 16, true)
+    expect(view.getUint32(centralOffset, true)).toBe(0x02014b50)
+    expect(centralOffset + view.getUint32(end + 12, true)).toBe(end)
+    expect([...unzip(zip)]).toEqual([
+      ['a.txt', 'hello'],
+      ['dir/b.txt', ''],
+    ])
+  })
+})
+
+describe('XLSX Export', () => {
+  it('should package sheets with a bold header and escaped inline strings', () => {
+    const parts = unzip(
+      createXlsx([
+        {
+          name: 'Party',
+          rows: [
+            ['Name', 'Level', 'Shiny', 'Item'],
+            ['A&B <C>', 5, true, null],
+          ],
+        },
+      ])
+    )
+
+    expect([...parts.keys()]).toEqual([
+      '[Content_Types].xml',
+      '_rels/.rels',
+      'xl/workbook.xml',
+      'xl/_rels/workbook.xml.rels',
+      'xl/styles.xml',
+      'xl/worksheets/sheet1.xml',
+    ])
+    expect(parts.get('xl/workbook.xml')).toContain('<sheet name="Party" sheetId="1" r:id="rId1"/>')
+    const sheet = parts.get('xl/worksheets/sheet1.xml')!
+    expect(sheet).toContain('<c r="A1" s="1" t="inlineStr"><is><t>Name</t></is></c>')
+    expect(sheet).toContain('<t>A&amp;B &lt;C&gt;</t>')
+    expect(sheet).toContain('<c r="B2"><v>5</v></c><c r="C2" t="b"><v>1</v></c></row>')
+    expect(sheet).toContain('state="frozen"')
+  })
+
+  it('should give a save its Party, Boxes, Items and Pokedex sheets', async () => {
+    const parser = new PokemonSaveParser(undefined, new VanillaConfig())
+    const saveData = await parser.parse(loadSave('emerald.sav'))
+    const sheets = saveToXlsxSheets(saveData, parser.gameConfig!)
+
+    expect(sheets.map(sheet => sheet.name)).toEqual(['Party', 'Boxes', 'Items', 'Pokédex'])
+    const [party, boxes, items, dex] = sheets.map(sheet => sheet.rows)
+    expect(party).toHaveLength(2)
+    expect(party![1]!.slice(0, 5)).toEqual([1, 'Treecko', 'TREECKO', 5, 'Hasty'])
+    expect(party![1]).toContain('Pound')
+    expect(party![1]!.slice(14, 20)).toEqual([20, 10, 8, 14, 12, 11])
+    expect(boxes![0]!.slice(0, 3)).toEqual(['Box', 'Slot', 'Species'])
+
+    const bag = Object.values(saveData.bag!).flat()
+    expect(items).toHaveLength(bag.length + 1)
+    expect(dex).toHaveLength(saveData.pokedex!.seen.length + 1)
+    expect(dex![252]).toEqual([252, 'Treecko', true, true])
+  })
+})
@@ -0,0 +1,55 @@
+/**
+ * Worker thread for --ndjson/--csv batches
+ * Parses the saves the CLI hands it, one at a time, and replies with each save's --json document
+ * or its error (Node-only: used by the CLI)
+ */
+
+import fs from 'fs'
+import { parentPort, workerData } from 'worker_threads'
+import { PokemonSaveParser } from './core/PokemonSaveParser'
+import { legalityWarnings } from './core/legality'
+import { toSaveJson } from './core/saveJson'
+import { gameConfigsByName } from './games'
+
+export interface BatchWorkerData {
+  readonly boxes: boolean
+  readonly legality: boolean
+  /** --game name to use instead of detecting the game */
+  readonly game?: string
+}
+
+export interface BatchJob {
+  readonly index: number
+  readonly file: string
+}
+
+// The document travels as JSON text: it is only ever written out as JSON, and structured cloning
+// would keep values JSON.stringify drops
+export type BatchResult =
+  | { readonly index: number; readonly json: string }
+  | { readonly index: number; readonly error: string }
+
+const options = workerData as BatchWorkerData
+const ForcedConfig = options.game === undefined ? undefined : gameConfigsByName().get(options.game)
+
+parentPort!.on('message', async ({ index, file }: BatchJob) => {
+  let result: BatchResult
+  try {
+    const parser = new PokemonSaveParser(undefined, ForcedConfig && new ForcedConfig())
+    const saveData = await parser.parse(new Uint8Array(await fs.promises.readFile(file)))
+    const legality = options.legality ? parser.checkLegality(saveData) : undefined
+    const json = toSaveJson(saveData, parser.gameConfig!, {
+      boxes: options.boxes,
+      legality,
+      warnings: [
+        ...(saveData.warnings ?? []),
+        ...(legality ? legalityWarnings(saveData.party_pokemon, legality) : []),
+      ],
+    })
+    result = { index, json: JSON.stringify(json) }
+  } catch (error) {
+    // Every error is sent back so one save can't end the batch
+    result = { index, error: error instanceof Error ? error.message : 'Unknown error' }
+  }
+  parentPort!.postMessage(result)
+})
@@ -1,59 +1,402 @@
 #!/usr/bin/env -S npx tsx
+import { once } from 'events'
 import fs from 'fs'
+import os from 'os'
 import path from 'path'
+import readline from 'readline'
+import type { Writable } from 'stream'
+import tty from 'tty'
+import { fileURLToPath } from 'url'
+import { Worker } from 'worker_threads'
+import zlib from 'zlib'
 import { PokemonSaveParser } from './core/PokemonSaveParser'
 import type { PokemonBase } from './core/PokemonBase'
-import type { SaveData } from './core/types'
+import {
+  type BagPocketName,
+  type GameConfig,
+  HOENN_BADGE_NAMES,
+  type SaveData,
+  type SaveSlots,
+  type SaveWarning,
+  type SkippedSlot,
+  VANILLA_SAVE_LAYOUT,
+  type WarningSeverity,
+} from './core/types'
+import type { ByteRangeChange } from './core/editLog'
 import { bytesToGbaString, gbaStringToBytes } from './core/utils'
+import { getSpeciesName } from './core/species'
+import { serializeTrainerCardSnapshot } from './core/trainerCard'
+import {
+  convertSaveContainer,
+  detectSaveContainer,
+  FLASH_128K_SIZE,
+  SAVE_CONTAINERS,
+  type SaveContainer,
+} from './core/saveContainer'
+import { formatPokemonLocation, type PokemonMatch, type PokemonQuery } from './core/query'
+import type { InjectTarget } from './core/party'
+import { BOX_SORT_KEYS, BoxManager, type BoxSortKey } from './core/boxManager'
+import { RENAME_PRESETS, type RenamePreset, type RenameRule } from './core/rename'
+import {
+  BATCH_CSV_COLUMNS,
+  summarizeSaveSlot,
+  toCanonicalJson,
+  toCsvLine,
+  toNdjsonLine,
+  toSaveJson,
+  type SaveJson,
+} from './core/saveJson'
+import { globBase, globToRegExp, isGlob } from './core/glob'
+import { renderHtmlReport } from './core/htmlReport'
+import { formatMarkdownTeam } from './core/markdownTeam'
+import { saveToXlsx } from './core/xlsxExport'
+import { createPokepaste, uploadPokepaste } from './core/pokepaste'
+import { annotateSave, formatHexdump } from './core/hexdump'
+import { analyzeTypeCoverage, formatCoverageMatrix } from './core/typeCoverage'
+import { diffSaves, formatSaveDiff } from './core/saveDiff'
+import { encodeMsgPack } from './core/msgpack'
+import { evaluateQuery } from './core/jsonQuery'
+import {
+  COMPLETION_SHELLS,
+  type CompletionShell,
+  type CompletionSpec,
+  completionScript,
+} from './core/completion'
+import { decodeGif, encodePng } from './core/image'
+import { renderTeamCard } from './core/teamCard'
+import { renderTable, type TableColumn } from './core/terminalTable'
+import {
+  createSaveBrowser,
+  handleBrowserKey,
+  INITIAL_BROWSER_STATE,
+  renderSaveBrowser,
+} from './core/saveBrowser'
+import {
+  encodePokemonQr,
+  qrCodeToSvg,
+  qrCodeToText,
+  toPokemonQrPayload,
+} from './core/qrCode'
+import {
+  SQL_EXPORT_SCHEMA,
+  saveToSqlStatements,
+  toSqlScript,
+  type SqlStatement,
+} from './core/sqlExport'
+import { savesToParquet, type ParquetExportSource } from './core/parquetExport'
+import { findRawIdByName, toRawId } from './core/mappingIndex'
+import { pocketForItem } from './core/validation'
+import {
+  addSaveToIndex,
+  findInSaveIndex,
+  fingerprintSave,
+  pruneSaveIndex,
+  readSaveIndex,
+  saveIndexTimeline,
+  summarizeSaveIndex,
+  writeSaveIndex,
+} from './core/saveIndex'
+import {
+  cliConfigPath,
+  type CliConfig,
+  OUTPUT_FORMATS,
+  type OutputFormat,
+  parseCliConfig,
+} from './core/cliConfig'
+import { watchSaveFile } from './core/saveWatcher'
+import type { BatchJob, BatchResult, BatchWorkerData } from './batchWorker'
+import { legalityWarnings } from './core/legality'
+import { gameConfigsByName } from './games'
 import { MgbaWebSocketClient } from '../mgba/websocket-client'
 
-// New: Define columns for party table in a single array for maintainability
-const PARTY_COLUMNS = [
-  { label: 'Slot', width: 5, value: (_p: PokemonBase, i: number) => (i + 1).toString() },
-  { label: 'Dex ID', width: 8, value: (p: PokemonBase) => p.speciesId.toString() },
-  { label: 'Nickname', width: 12, value: (p: PokemonBase) => p.nickname },
-  { label: 'Lv', width: 4, value: (p: PokemonBase) => p.level.toString() },
-  { label: 'Ability', width: 8, value: (p: PokemonBase) => p.abilityNumber.toString() },
-  { label: 'Nature', width: 10, value: (p: PokemonBase) => p.nature },
-  { label: 'Shiny', width: 6, value: (p: PokemonBase) => p.shinyNumber.toString() },
+// Game config chosen with --game (or the config file's game) instead of detecting it
+let gameOverride: (() => GameConfig) | undefined
+
+const createParser = () => new PokemonSaveParser(undefined, gameOverride?.())
+
+// ANSI colors in the tables and warnings; off with --no-color, a NO_COLOR environment variable
+// (https://no-color.org) or when stdout isn't a terminal
+const useColor =
+  Boolean(process.stdout.isTTY) && !process.argv.includes('--no-color') && !process.env.NO_COLOR
+
+const colorize = (sgr: number | string, text: string) =>
+  useColor ? `\x1b[${sgr}m${text}\x1b[0m` : text
+
+// Piped output isn't cut to a width; a terminal's width is read per table so watch mode follows
+// resizes
+const terminalWidth = () => process.stdout.columns || Infinity
+
+// Green above half HP, yellow above a fifth and red below, like the in-game bar
+const hpColor = (p: PokemonBase) => {
+  if (p.currentHp * 2 > p.maxHp) return '32'
+  if (p.currentHp * 5 > p.maxHp) return '33'
+  return '31'
+}
+
+/** A table row: a Pokémon and its party or box slot (0-based) */
+interface PokemonRow {
+  readonly pokemon: PokemonBase
+  readonly slot: number
+}
+
+// Columns of the party table; when the terminal is too narrow the lowest priority columns are
+// left out, then nicknames and OT names are cut short
+const PARTY_COLUMNS: readonly TableColumn<PokemonRow>[] = [
+  { label: 'Slot', key: true, align: 'right', value: ({ slot }) => (slot + 1).toString() },
+  {
+    label: 'Dex ID',
+    key: true,
+    align: 'right',
+    priority: 4,
+    value: ({ pokemon: p }) => p.speciesId.toString(),
+  },
+  { label: 'Nickname', key: true, minWidth: 8, value: ({ pokemon: p }) => p.nickname },
+  { label: 'Lv', align: 'right', value: ({ pokemon: p }) => p.level.toString() },
+  {
+    label: 'Ability',
+    align: 'right',
+    priority: 3,
+    value: ({ pokemon: p }) => p.abilityNumber.toString(),
+  },
+  { label: 'Nature', priority: 10, value: ({ pokemon: p }) => p.nature },
+  {
+    label: 'Shiny',
+    priority: 11,
+    value: ({ pokemon: p }) => (p.isRadiant ? 'Rad' : p.isShiny ? 'Yes' : 'No'),
+  },
   {
     label: 'HP',
-    width: 32,
-    value: (p: PokemonBase) => {
+    color: ({ pokemon }) => hpColor(pokemon),
+    value: ({ pokemon: p }) => {
       const hpBars = p.maxHp > 0 ? Math.round((20 * p.currentHp) / p.maxHp) : 0
       return `[${'█'.repeat(hpBars)}${'░'.repeat(20 - hpBars)}] ${p.currentHp}/${p.maxHp}`
     },
   },
-  { label: 'Atk', width: 5, value: (p: PokemonBase) => p.attack.toString() },
-  { label: 'Def', width: 5, value: (p: PokemonBase) => p.defense.toString() },
-  { label: 'Spe', width: 5, value: (p: PokemonBase) => p.speed.toString() },
-  { label: 'SpA', width: 5, value: (p: PokemonBase) => p.spAttack.toString() },
-  { label: 'SpD', width: 5, value: (p: PokemonBase) => p.spDefense.toString() },
-  { label: 'OT Name', width: 10, value: (p: PokemonBase) => p.otName },
-  { label: 'IDNo', width: 7, value: (p: PokemonBase) => p.otId_str },
+  { label: 'Atk', align: 'right', priority: 8, value: ({ pokemon: p }) => p.attack.toString() },
+  { label: 'Def', align: 'right', priority: 7, value: ({ pokemon: p }) => p.defense.toString() },
+  { label: 'Spe', align: 'right', priority: 9, value: ({ pokemon: p }) => p.speed.toString() },
+  { label: 'SpA', align: 'right', priority: 6, value: ({ pokemon: p }) => p.spAttack.toString() },
+  { label: 'SpD', align: 'right', priority: 5, value: ({ pokemon: p }) => p.spDefense.toString() },
+  { label: 'OT Name', minWidth: 7, priority: 2, value: ({ pokemon: p }) => p.otName },
+  { label: 'IDNo', priority: 1, value: ({ pokemon: p }) => p.otId_str },
 ]
 
+// Shiny rows in yellow, radiant ones in magenta
+const shinyColor = ({ pokemon: p }: PokemonRow) => {
+  if (p.isRadiant) return '35'
+  return p.isShiny ? '33' : undefined
+}
+
 function pad(str: string, width: number) {
   return str.toString().padEnd(width)
 }
 
+/** The value of a `--name=value` flag: everything after the first '=', which values may contain */
+function flagValue(argv: readonly string[], name: string): string | undefined {
+  const prefix = `--${name}=`
+  return argv.find(arg => arg.startsWith(prefix))?.slice(prefix.length)
+}
+
+/** Print Pokémon as a table fitted to the terminal. */
+const displayPokemonTable = (
+  rows: readonly PokemonRow[],
+  columns: readonly TableColumn<PokemonRow>[]
+) => {
+  const lines = renderTable(rows, columns, {
+    width: terminalWidth(),
+    color: useColor,
+    rowColor: shinyColor,
+    // Eggs have no meaningful stats yet, so only identify the slot and hatch progress
+    note: ({ pokemon: p }) =>
+      p.isEgg
+        ? `🥚 Egg - ${p.eggCycles} hatch cycles left (~${p.eggStepsRemaining} steps)`
+        : undefined,
+  })
+  for (const line of lines) console.log(line)
+}
+
 /** Display party Pokémon in a formatted table. */
 const displayPartyPokemon = (party: readonly PokemonBase[], mode = 'FILE') => {
   console.log(`\n--- Party Pokémon Summary (${mode} MODE) ---`)
   if (!party.length) return void console.log('No Pokémon found in party.')
-  const header = PARTY_COLUMNS.map(col => pad(col.label, col.width)).join('')
-  console.log(header, `\n${'-'.repeat(header.length)}`)
-  party.forEach((p, i) => {
-    const row = PARTY_COLUMNS.map(col => pad(col.value(p, i), col.width)).join('')
-    console.log(row)
-  })
+  displayPokemonTable(party.map((pokemon, slot) => ({ pokemon, slot })), PARTY_COLUMNS)
 }
 
 /** Display player and save game info. */
-const displaySaveblock2Info = ({ player_name, play_time }: SaveData, mode = 'FILE') => {
+const displaySaveblock2Info = (saveData: SaveData, mode = 'FILE') => {
+  const { player_name, play_time, trainer, currency, pokedex, progress } = saveData
+  const { options, daycare } = saveData
   console.log(`\n--- SaveBlock2 Data (${mode} MODE) ---`)
   console.log(`Player Name: ${player_name}`)
+  if (trainer) {
+    const id = trainer.trainer_id.toString().padStart(5, '0')
+    console.log(`Trainer: ${trainer.gender}, ID ${id}, SID ${trainer.secret_id}`)
+  }
   console.log(`Play Time: ${play_time.hours}h ${play_time.minutes}m ${play_time.seconds}s`)
+  if (saveData.starter !== undefined) {
+    const rival = saveData.rival ? `, rival ${saveData.rival.name}` : ''
+    console.log(`Starter: ${saveData.starter?.name ?? 'not chosen'}${rival}`)
+  }
+  if (saveData.location) {
+    const { map_group, map_num, map_name, x, y } = saveData.location
+    console.log(`Location: ${map_name ?? `map ${map_group}.${map_num}`} (${x}, ${y})`)
+  }
+  if (currency) {
+    const { money, coins, battle_points } = currency
+    console.log(`Money: ₽${money}  Coins: ${coins}  BP: ${battle_points}`)
+  }
+  if (pokedex) console.log(`Pokédex: ${pokedex.seen_count} seen, ${pokedex.caught_count} caught`)
+  if (progress) {
+    const earned = HOENN_BADGE_NAMES.filter((_, i) => progress.badges[i])
+    const names = earned.length ? ` (${earned.join(', ')})` : ''
+    console.log(`Badges: ${progress.badge_count}/8${names}`)
+    if (progress.game_cleared) console.log('Hall of Fame: entered')
+  }
+  if (daycare) {
+    const deposited = daycare.slots.flatMap(slot =>
+      slot ? [`${slot.pokemon.nickname} Lv${slot.level_on_withdraw} (${slot.steps} steps)`] : []
+    )
+    const egg = daycare.egg_pending ? ', egg waiting' : ''
+    console.log(`Daycare: ${deposited.join(', ') || 'empty'}${egg}`)
+  }
+  if (saveData.mail?.length) {
+    const held = saveData.mail.filter(mail => mail.held_by).length
+    console.log(`Mail: ${held} held, ${saveData.mail.length - held} in the PC mailbox`)
+  }
+  if (saveData.secret_bases) {
+    const { own, friends } = saveData.secret_bases
+    const base = own ? `${own.decorations.length} decorations` : 'not built'
+    console.log(`Secret Base: ${base}, ${friends.length} friends' bases`)
+  }
+  if (saveData.mystery_gift) {
+    const { wonder_card, event_tickets } = saveData.mystery_gift
+    const tickets = Object.entries(event_tickets)
+      .filter(([, unlocked]) => unlocked)
+      .map(([ticket]) => ticket.replaceAll('_', ' '))
+    console.log(
+      `Mystery Gift: card ${wonder_card ? `"${wonder_card.title}"` : 'none'}, ` +
+        `tickets ${tickets.join(', ') || 'none'}`
+    )
+  }
+  if (saveData.roamer?.active) {
+    const { species_id, level, hp, ivs } = saveData.roamer
+    const name = getSpeciesName(species_id) ?? `#${species_id}`
+    console.log(`Roamer: ${name} Lv${level}, ${hp} HP, IVs ${ivs.join('/')}`)
+  }
+  if (saveData.misc) {
+    const { lottery_number, safari_mode } = saveData.misc
+    const safari = safari_mode ? ', in the Safari Zone' : ''
+    console.log(`Lottery Ticket: ${lottery_number.toString().padStart(5, '0')}${safari}`)
+  }
+  if (saveData.optional_sections) {
+    const written = Object.entries(saveData.optional_sections).flatMap(([name, status]) => {
+      if (!status.present) return []
+      const corrupt = status.valid === false ? ' (corrupt)' : ''
+      return [`${name.replaceAll('_', ' ')}${corrupt}`]
+    })
+    console.log(`Optional Sections: ${written.join(', ') || 'none'}`)
+  }
+  if (saveData.game_stats) {
+    const { steps, total_battles, pokemon_captures, hatched_eggs } = saveData.game_stats
+    console.log(
+      `Stats: ${steps} steps, ${total_battles} battles, ${pokemon_captures} caught, ` +
+        `${hatched_eggs} eggs hatched`
+    )
+  }
+  if (options) {
+    const { text_speed, battle_scene, battle_style, sound, button_mode, frame_type } = options
+    console.log(
+      `Options: text ${text_speed}, battle scene ${battle_scene ? 'on' : 'off'}, ` +
+        `${battle_style} style, ${sound}, buttons ${button_mode}, frame ${frame_type + 1}`
+    )
+  }
+}
+
+const SEVERITY_STYLES: Record<WarningSeverity, { color: number; icon: string }> = {
+  info: { color: 36, icon: 'ℹ️ ' },
+  warning: { color: 33, icon: '⚠️ ' },
+  error: { color: 31, icon: '❌' },
+}
+
+/** Display validation warnings collected while parsing, colored by severity. */
+const displayWarnings = (warnings: readonly SaveWarning[]) => {
+  if (!warnings.length) return
+  console.log(`\n--- Warnings (${warnings.length}) ---`)
+  for (const { severity, code, message } of warnings) {
+    const { color, icon } = SEVERITY_STYLES[severity]
+    console.log(`${icon} ${colorize(color, `[${severity}] ${code}`)}: ${message}`)
+  }
+}
+
+/** List the byte ranges an edit changes, by Flash sector (anything past 128KB is the footer). */
+const displayEditPreview = (changes: readonly ByteRangeChange[], sectorSize: number) => {
+  const hex = (n: number, width: number) => `0x${n.toString(16).padStart(width, '0')}`
+  // The first 8 bytes are enough to recognize most edits
+  const bytes = (data: Uint8Array) =>
+    [...data.subarray(0, 8)].map(byte => byte.toString(16).padStart(2, '0')).join(' ') +
+    (data.length > 8 ? ' …' : '')
+
+  const total = changes.reduce((sum, { before }) => sum + before.length, 0)
+  console.log(`\n--- Changes (${changes.length} ranges, ${total} bytes) ---`)
+  for (const { offset, before, after } of changes) {
+    const where =
+      offset >= FLASH_128K_SIZE
+        ? `footer +${hex(offset - FLASH_128K_SIZE, 2)}`
+        : `sector ${Math.floor(offset / sectorSize)} +${hex(offset % sectorSize, 3)}`
+    console.log(`  ${hex(offset, 5)} (${where}): ${bytes(before)} → ${bytes(after)}`)
+  }
+}
+
+/** Explain which party and box slots were left out by the sanity checks and why. */
+const displaySkippedSlots = (skipped: readonly SkippedSlot[]) => {
+  if (!skipped.length) return
+  console.log(`\n--- Skipped Slots (${skipped.length}) ---`)
+  for (const { box, slot, reason, message } of skipped) {
+    const where = box ? `Box ${box} slot ${slot}` : `Slot ${slot}`
+    console.log(`🚫 ${where} ${colorize(31, reason)}: ${message}`)
+  }
+}
+
+// Box Pokemon have no stored battle data, so the box table leaves out HP and stats
+const BOX_COLUMNS = PARTY_COLUMNS.filter(
+  col => !['HP', 'Atk', 'Def', 'Spe', 'SpA', 'SpD'].includes(col.label)
+)
+
+const BAG_POCKET_LABELS: Record<BagPocketName, string> = {
+  items: 'Items',
+  pokeBalls: 'Poké Balls',
+  tmHm: 'TMs & HMs',
+  berries: 'Berries',
+  keyItems: 'Key Items',
+}
+
+/** Display the bag contents, one list per non-empty pocket. */
+const displayBag = (bag: SaveData['bag']) => {
+  if (!bag) return void console.log('\nBag contents are not supported for this game.')
+  console.log('\n--- Bag ---')
+  for (const [pocket, label] of Object.entries(BAG_POCKET_LABELS) as [BagPocketName, string][]) {
+    if (!bag[pocket].length) continue
+    console.log(`${label}:`)
+    for (const { name, quantity } of bag[pocket]) console.log(`  ${pad(name, 16)} x${quantity}`)
+  }
+}
+
+/** Display the non-empty PC boxes, one table per box. */
+const displayBoxes = ({ boxes, box_metadata, current_box }: SaveData) => {
+  if (!boxes) return void console.log('\nPC boxes are not supported for this game.')
+  boxes.forEach((box, b) => {
+    const count = box.filter(Boolean).length
+    if (!count) return
+    const meta = box_metadata?.[b]
+    const title = meta ? `${meta.name} [${meta.wallpaper_name}]` : `Box ${b + 1}`
+    const current = b === current_box ? ' (current)' : ''
+    console.log(`\n--- ${title}${current} (${count}/${box.length}) ---`)
+    // Slot numbers follow the box, so empty slots leave gaps in them
+    const rows = box.flatMap((pokemon, slot) => (pokemon ? [{ pokemon, slot }] : []))
+    displayPokemonTable(rows, BOX_COLUMNS)
+  })
+  const total = boxes.reduce((sum, box) => sum + box.filter(Boolean).length, 0)
+  console.log(`\nPC total: ${total} Pokémon`)
 }
 
 /** Display raw bytes for each party Pokémon. */
@@ -164,35 +507,110 @@ const displayPartyPokemonGraph = (party: readonly PokemonBase[]) => {
   })
 }
 
+/** Display both save slots side by side, marking the active one. */
+const displaySlots = (slots: SaveSlots) => {
+  console.log('\n--- Save Slots ---')
+  for (const [slot, data] of [[1, slots.slot1] as const, [2, slots.slot2] as const]) {
+    const summary = summarizeSaveSlot(data)
+    const active = slots.active === slot ? ' (active)' : ''
+    if (!summary) {
+      console.log(`Slot ${slot}: empty`)
+      continue
+    }
+    const { hours, minutes, seconds } = summary.play_time
+    console.log(
+      `Slot ${slot}${active}: save #${summary.counter}, ${summary.player_name}, ` +
+        `${hours}h ${minutes}m ${seconds}s, party ${summary.party.join(', ') || 'empty'}`
+    )
+  }
+}
+
 /**
  * Parse and display save data from either file or WebSocket
  */
 async function parseAndDisplay(
   input: string | MgbaWebSocketClient,
-  options: { debug: boolean; graph: boolean; skipDisplay?: boolean }
+  options: {
+    debug: boolean
+    graph: boolean
+    skipDisplay?: boolean
+    trainerCard?: string
+    json?: boolean
+    /** One-line --json instead of indented */
+    compact?: boolean
+    /** --query expression to print from the --json document instead of all of it */
+    query?: string
+    msgpack?: string
+    verifyStats?: boolean
+    legality?: boolean
+    boxes?: boolean
+    bag?: boolean
+    slots?: boolean
+  }
 ): Promise<SaveData> {
-  const parser = new PokemonSaveParser()
+  const parser = createParser()
   let result: SaveData
   let mode: string
+  let allSlots: SaveSlots | undefined
 
   if (typeof input === 'string') {
     // File mode
     mode = 'FILE'
-    const absPath = path.resolve(input)
-    const buffer = fs.readFileSync(absPath)
+    const buffer = readSaveFile(input)
     result = await parser.parse(buffer)
-    if (!options.skipDisplay) {
+    if (!options.skipDisplay && !options.json) {
       console.log(`📁 Detected game: ${parser.gameConfig?.name ?? 'unknown'}`)
     }
+    if (options.slots) {
+      allSlots = await parser.parseAllSlots(buffer)
+      if (!options.json) displaySlots(allSlots)
+    }
+    if (options.trainerCard) {
+      const snapshot = await parser.getTrainerCardSnapshot(result)
+      fs.writeFileSync(path.resolve(options.trainerCard), serializeTrainerCardSnapshot(snapshot))
+      if (!options.json) console.log(`🪪 Trainer card snapshot written to ${options.trainerCard}`)
+    }
   } else {
     // WebSocket mode
     mode = 'MEMORY'
     result = await parser.parse(input)
-    if (!options.skipDisplay) {
+    if (!options.skipDisplay && !options.json) {
       console.log(`🎮 Connected to: ${parser.gameConfig?.name ?? 'unknown'} (via mGBA WebSocket)`)
     }
   }
 
+  // Stat verification and legality checks are opt-in; their findings are reported alongside the
+  // parse warnings
+  const legality = options.legality ? parser.checkLegality(result) : undefined
+  const warnings = [
+    ...(result.warnings ?? []),
+    ...(options.verifyStats ? parser.verifyStats(result) : []),
+    ...(legality ? legalityWarnings(result.party_pokemon, legality) : []),
+  ]
+
+  if (options.json || options.msgpack) {
+    const json = toSaveJson(result, parser.gameConfig!, {
+      // Queries can reach into the PC boxes without --boxes
+      boxes: options.boxes || options.query !== undefined,
+      slots: allSlots && [allSlots.slot1, allSlots.slot2],
+      legality,
+      warnings,
+    })
+    if (options.msgpack) {
+      fs.writeFileSync(path.resolve(options.msgpack), encodeMsgPack(json))
+      if (!options.json) console.log(`📦 MessagePack written to ${options.msgpack}`)
+    }
+    if (options.json) {
+      // Queries run on the document as serialized, the same shape --json prints
+      const output =
+        options.query === undefined
+          ? json
+          : evaluateQuery(options.query, JSON.parse(JSON.stringify(json)))
+      printResult(toCanonicalJson(output, { pretty: !options.compact }), 'json')
+    }
+    return result
+  }
+
   if (!options.skipDisplay) {
     console.log(`Active save slot: ${result.active_slot}`)
 
@@ -208,263 +626,2178 @@ async function parseAndDisplay(
       if (options.debug) displayPartyPokemonRaw(result.party_pokemon)
       displaySaveblock2Info(result, mode)
     }
+    if (options.bag) displayBag(result.bag)
+    if (options.boxes) displayBoxes(result)
+    displaySkippedSlots(result.skipped_slots ?? [])
+    displayWarnings(warnings)
   }
 
   return result
 }
 
 /**
- * Clear screen and move cursor to top
+ * Build a search query from `find` subcommand flags
  */
-function clearScreen() {
-  process.stdout.write('\x1b[2J\x1b[H')
+function parseFindQuery(argv: readonly string[]): PokemonQuery {
+  const number = (name: string) => {
+    const raw = flagValue(argv, name)
+    return raw === undefined ? undefined : parseInt(raw, 10)
+  }
+  const species = flagValue(argv, 'species')
+  const move = flagValue(argv, 'move')
+
+  return {
+    species: species && /^\d+$/.test(species) ? parseInt(species, 10) : species,
+    minLevel: number('min-level'),
+    maxLevel: number('max-level'),
+    shiny: argv.includes('--shiny') ? true : argv.includes('--not-shiny') ? false : undefined,
+    ot: flagValue(argv, 'ot'),
+    move: move && /^\d+$/.test(move) ? parseInt(move, 10) : move,
+    minIv: number('min-iv'),
+  }
+}
+
+/** Display search results with where each Pokémon is stored. */
+const displayMatches = (matches: readonly PokemonMatch[]) => {
+  console.log(`\n--- Search Results (${matches.length} found) ---`)
+  for (const { pokemon, location } of matches) {
+    const where = formatPokemonLocation(location)
+    const shiny = pokemon.isShiny ? ' ✨' : ''
+    const species = `#${pokemon.speciesId}`
+    console.log(`${pad(where, 18)}${pad(species, 6)}${pad(pokemon.nickname, 12)}Lv ${pokemon.level}${shiny}`)
+  }
 }
 
 /**
- * Watch mode - continuously monitor and update display
+ * Search a save for Pokémon matching the `find` subcommand filters
  */
-async function watchMode(
-  input: string | MgbaWebSocketClient,
-  options: { debug: boolean; graph: boolean; interval: number }
-) {
-  if (typeof input === 'string') {
-    // File-based watch mode - use polling since files don't support push notifications
-    return watchModeFile(input, options)
-  } else {
-    // WebSocket-based watch mode - use event-driven updates
-    return watchModeWebSocket(input, options)
-  }
+async function findAndDisplay(input: string | MgbaWebSocketClient, query: PokemonQuery) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  displayMatches(parser.findPokemon(result, query))
 }
 
+/** JSON output in canonical key order; indented unless --compact (--pretty is the default) */
+const formatJson = (value: unknown, argv: readonly string[]) =>
+  toCanonicalJson(value, { pretty: !argv.includes('--compact') })
+
 /**
- * File-based watch mode - polling approach for file changes
+ * Print the party as a Pokémon Showdown paste (`--format=showdown`)
  */
-async function watchModeFile(
-  filePath: string,
-  options: { debug: boolean; graph: boolean; interval: number }
-) {
-  console.log(`🔄 Starting file watch mode (updating every ${options.interval}ms)...`)
-  console.log('Press Ctrl+C to exit')
+async function displayShowdown(input: string | MgbaWebSocketClient) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  printResult(parser.exportShowdown(result), 'showdown')
+}
 
-  // Create parser once and reuse it
-  const parser = new PokemonSaveParser()
-  let lastDataHash = ''
-  let isFirstRun = true
+/**
+ * Write a standalone HTML report of the save (`--format=html`) to --output=FILE, or print it
+ */
+async function writeHtmlReport(input: string | MgbaWebSocketClient) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  printResult(renderHtmlReport(result, parser.gameConfig!), 'html')
+}
 
-  // eslint-disable-next-line @typescript-eslint/no-unnecessary-condition
-  while (true) {
-    try {
-      // Parse save data without re-initializing parser
-      const absPath = path.resolve(filePath)
-      const buffer = fs.readFileSync(absPath)
-      const result = await parser.parse(buffer)
-
-      // Create a simple hash of the party data to detect changes
-      const dataHash = JSON.stringify(
-        result.party_pokemon.map(p => ({
-          species: p.speciesId,
-          level: p.level,
-          hp: p.currentHp,
-          nickname: p.nickname,
-        }))
-      )
+/**
+ * Print the party as a markdown table for Discord, Reddit or GitHub (`--format=markdown`)
+ */
+async function displayMarkdown(input: string | MgbaWebSocketClient) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  printResult(formatMarkdownTeam(result, parser.gameConfig!), 'markdown')
+}
 
-      // Only update display if party data changed or first run
-      if (dataHash !== lastDataHash || isFirstRun) {
-        clearScreen()
-        displayPartyPokemon(result.party_pokemon, 'FILE')
+/**
+ * Print the party as pokepast.es text (`--format=pokepaste`), or upload it with --upload and
+ * print the share URL
+ */
+async function exportPokepaste(input: string | MgbaWebSocketClient, argv: readonly string[]) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  const pokepaste = createPokepaste(result, parser.gameConfig!, {
+    title: flagValue(argv, 'title'),
+    author: flagValue(argv, 'author'),
+    notes: flagValue(argv, 'notes'),
+  })
+  if (!argv.includes('--upload')) return printResult(pokepaste.paste, 'pokepaste')
+  console.log(`🔗 ${await uploadPokepaste(pokepaste)}`)
+}
 
-        lastDataHash = dataHash
-        isFirstRun = false
-      }
-    } catch (error) {
-      console.error('❌ Error:', error instanceof Error ? error.message : 'Unknown error')
-    }
+/**
+ * Print the save file as an annotated hexdump (`--format=hexdump`): sectors, footers, SaveBlock
+ * fields and Pokémon substructures in the detected game's layout
+ */
+async function displayHexdump(savePath: string) {
+  const bytes = new Uint8Array(readSaveFile(savePath))
+  const parser = createParser()
+  await parser.parse(bytes)
+  printResult(formatHexdump(bytes, annotateSave(bytes, parser.gameConfig!)), 'hexdump')
+}
 
-    await new Promise(resolve => setTimeout(resolve, options.interval))
-  }
+/**
+ * `coverage <savefile> [--json]` - the party's offensive and defensive type coverage as a matrix,
+ * or as JSON
+ */
+async function displayCoverage(input: string | MgbaWebSocketClient, argv: readonly string[]) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  const coverage = analyzeTypeCoverage(result, parser.gameConfig!)
+  if (argv.includes('--json')) return printResult(formatJson(coverage, argv), 'json')
+  printResult(formatCoverageMatrix(coverage), 'coverage')
 }
 
 /**
- * WebSocket-based watch mode - event-driven approach using parser watch API
+ * Write an Excel workbook with Party, Boxes, Items and Pokédex sheets (`--format=xlsx`), to
+ * --output=FILE or next to the save
  */
-async function watchModeWebSocket(
-  client: MgbaWebSocketClient,
-  options: { debug: boolean; graph: boolean; interval: number }
-) {
-  console.log('🔄 Starting event-driven watch mode...')
-  console.log('Press Ctrl+C to exit')
+async function writeXlsxWorkbook(input: string | MgbaWebSocketClient) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  const outPath = outputFile(
+    'xlsx',
+    typeof input === 'string' ? outputPath(input, '.xlsx') : 'pokemon-save.xlsx'
+  )
+  fs.writeFileSync(outPath, saveToXlsx(result, parser.gameConfig!))
+  console.log(`📊 Workbook written to ${outPath}`)
+}
 
-  // Create parser once and reuse it
-  const parser = new PokemonSaveParser()
+/** File name for an exported Pokémon, e.g. `box03-07-252-TREECKO.pk3`. */
+const parseContainer = (argv: readonly string[]): SaveContainer | undefined => {
+  const container = flagValue(argv, 'container')
+  if (container === undefined) return undefined
+  if (!SAVE_CONTAINERS.includes(container as SaveContainer)) {
+    throw new Error(`Unknown container "${container}" (use ${SAVE_CONTAINERS.join(', ')})`)
+  }
+  return container as SaveContainer
+}
 
-  // Load the WebSocket client into memory mode
-  await parser.loadInputData(client)
+// `-` in place of a save file reads it from stdin: `curl … | pokemon-save-parser - --json`
+const STDIN_PATH = '-'
+let stdinSave: Buffer | undefined
 
-  // Get and display initial data
-  const initialData = await parser.getCurrentSaveData()
-  displayPartyPokemon(initialData.party_pokemon, 'MEMORY')
-  if (options.debug) displayPartyPokemonRaw(initialData.party_pokemon)
+/** A save's bytes; stdin is read once and kept, since a command may read its save more than once */
+function readSaveFile(savePath: string): Buffer {
+  if (savePath !== STDIN_PATH) return fs.readFileSync(path.resolve(savePath))
+  // File descriptor 0 directly: creating process.stdin would make a pipe non-blocking (EAGAIN)
+  stdinSave ??= fs.readFileSync(0)
+  return stdinSave
+}
 
-  // Set up watching with the new parser API
-  await parser.watch({
-    onPartyChange: partyPokemon => {
-      clearScreen()
-      displayPartyPokemon(partyPokemon, 'MEMORY')
-      if (options.debug) displayPartyPokemonRaw(partyPokemon)
-    },
-    onError: error => {
-      console.error('❌ Error processing memory change:', error.message)
-    },
-  })
-  console.log('✅ Memory watching started')
+/** Where output goes without --out: next to the save (stdin.sav for a save read from stdin) */
+const outputPath = (savePath: string, suffix: string) =>
+  (savePath === STDIN_PATH ? 'stdin.sav' : savePath).replace(/\.sav$/i, suffix)
 
-  // Keep the process alive and handle cleanup
-  return new Promise<void>(resolve => {
-    const cleanup = async () => {
-      await parser.stopWatching()
-      resolve()
-    }
+// --output=FILE: a command's result goes to FILE instead of stdout or its default file
+let resultFile: string | undefined
 
-    process.on('SIGINT', cleanup)
-    process.on('SIGTERM', cleanup)
-  })
+// Added to an --output name without an extension, so `--json --output=party` writes party.json
+const OUTPUT_EXTENSIONS: Readonly<Record<string, string>> = {
+  json: '.json',
+  ndjson: '.ndjson',
+  csv: '.csv',
+  html: '.html',
+  markdown: '.md',
+  xlsx: '.xlsx',
+  sav: '.sav',
+  png: '.png',
+  svg: '.svg',
+  sqlite: '.db',
+  parquet: '.parquet',
 }
 
-// CLI entry point
-async function main() {
-  const { argv } = process
+/** The --output file for a format; names without an extension get the format's (.txt for text) */
+const resultPath = (format: string) => {
+  if (resultFile === undefined) return undefined
+  const extension = path.extname(resultFile) ? '' : (OUTPUT_EXTENSIONS[format] ?? '.txt')
+  return path.resolve(`${resultFile}${extension}`)
+}
 
-  // Parse command line options
-  const debug = argv.includes('--debug')
-  const graph = argv.includes('--graph')
-  const watch = argv.includes('--watch')
-  const websocket = argv.includes('--websocket')
+/** Where a command writes its file: --output, or the command's default */
+const outputFile = (format: string, fallback: string) =>
+  resultPath(format) ?? path.resolve(fallback)
 
-  // Watch interval option
-  const intervalArg = argv.find(arg => arg.startsWith('--interval='))
-  const interval = intervalArg ? parseInt(intervalArg.split('=')[1] ?? '1000') : 1000
+/** Print a command's result, or write it to --output */
+function printResult(text: string, format: string) {
+  const outPath = resultPath(format)
+  if (outPath === undefined) return void console.log(text)
+  fs.writeFileSync(outPath, `${text}\n`)
+  console.log(`💾 Output written to ${outPath}`)
+}
 
-  // WebSocket URL option
-  const wsUrlArg = argv.find(arg => arg.startsWith('--ws-url='))
-  const wsUrl = wsUrlArg ? wsUrlArg.split('=')[1] : 'ws://localhost:7102/ws'
+/**
+ * Replace a save without ever leaving it half-written: the data goes to a temp file next to it,
+ * is flushed to disk and then renamed over the target. A save being replaced is first copied to
+ * `<file>.<timestamp>.bak` unless --no-backup is given
+ */
+function writeSaveFileAtomic(outPath: string, data: Uint8Array, argv: readonly string[]) {
+  const tempPath = `${outPath}.${process.pid}.tmp`
+  const fd = fs.openSync(tempPath, 'w')
+  try {
+    fs.writeFileSync(fd, data)
+    fs.fsyncSync(fd)
+  } finally {
+    fs.closeSync(fd)
+  }
 
-  // Utility string conversion functions
-  const toBytesArg = argv.find(arg => arg.startsWith('--toBytes='))
-  if (toBytesArg) {
-    const str = toBytesArg.split('=')[1] ?? ''
-    const bytes = gbaStringToBytes(str, str.length + 1) // +1 for null terminator
-    console.log(`GBA bytes for "${str}":`)
-    console.log([...bytes].map(b => b.toString(16).padStart(2, '0')).join(' '))
-    process.exit(0)
+  try {
+    if (!argv.includes('--no-backup') && fs.existsSync(outPath)) {
+      const timestamp = new Date().toISOString().replace(/[-:.]/g, '')
+      const backupPath = `${outPath}.${timestamp}.bak`
+      fs.copyFileSync(outPath, backupPath, fs.constants.COPYFILE_EXCL)
+      console.log(`Backup: ${backupPath}`)
+    }
+    fs.renameSync(tempPath, outPath)
+  } catch (error) {
+    fs.rmSync(tempPath, { force: true })
+    throw error
   }
+  console.log(`Saved: ${outPath}`)
+}
 
-  const toStringArg = argv.find(arg => arg.startsWith('--toString='))
-  if (toStringArg) {
-    const hexStr = toStringArg.split('=')[1] ?? ''
-    // Accepts space or comma separated hex bytes
-    const bytes = new Uint8Array(
-      hexStr
-        .trim()
-        .split(/\s+|,/)
-        .filter(Boolean)
-        .map(b => parseInt(b, 16))
-    )
-    const str = bytesToGbaString(bytes)
-    console.log(
-      `String for bytes [${[...bytes].map(b => b.toString(16).padStart(2, '0')).join(' ')}]:`
-    )
-    console.log(str)
-    process.exit(0)
+/**
+ * Write an edited save in the container given with --container=128k|128k-rtc|64k, or in the one
+ * the save was loaded from. With --dry-run the changed byte ranges are listed instead
+ */
+function writeSaveOutput(
+  outPath: string,
+  save: Uint8Array,
+  parser: PokemonSaveParser,
+  argv: readonly string[]
+) {
+  const container = parseContainer(argv) ?? parser.saveContainer
+  if (argv.includes('--dry-run')) {
+    displayEditPreview(parser.previewEdit(save), VANILLA_SAVE_LAYOUT.sectorSize)
+    console.log(`Dry run: nothing written to ${outPath}`)
+    return
   }
+  writeSaveFileAtomic(outPath, container ? convertSaveContainer(save, container) : save, argv)
+}
 
-  // Determine input source
-  let input: string | MgbaWebSocketClient
+const pk3FileName = (match: PokemonMatch, extension: 'pk3' | 'ek3') => {
+  const { pokemon, location } = match
+  const twoDigits = (n: number) => String(n + 1).padStart(2, '0')
+  const where =
+    location.area === 'box'
+      ? `box${twoDigits(location.box)}-${twoDigits(location.slot)}`
+      : `${location.area}-${location.slot + 1}`
+  const name = pokemon.nickname.replace(/[^\w-]+/g, '_') || 'pokemon'
+  return `${where}-${pokemon.speciesId}-${name}.${extension}`
+}
 
-  if (websocket) {
-    // WebSocket mode
-    console.log(`🔌 Connecting to mGBA WebSocket at ${wsUrl}...`)
-    const client = new MgbaWebSocketClient(wsUrl)
+/**
+ * `export <savefile> [--output=DIR] [--ek3]` - dump party (100-byte) and PC box (80-byte) Pokémon
+ * as decrypted .pk3 files, or encrypted .ek3 files with --ek3
+ */
+async function runExportCommand(savePath: string, argv: readonly string[]) {
+  // A directory, so no extension is added
+  const outDir = path.resolve(resultFile ?? '.')
+  const extension = argv.includes('--ek3') ? 'ek3' : 'pk3'
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
 
-    try {
-      await client.connect()
-      console.log('✅ Connected successfully!')
-      input = client
-
-      // Setup cleanup on exit
-      process.on('SIGINT', () => {
-        client.disconnect()
-        process.exit(0)
-      })
-    } catch (error) {
-      console.error(
-        '❌ Failed to connect to mGBA WebSocket:',
-        error instanceof Error ? error.message : 'Unknown error'
-      )
-      process.exit(1)
-    }
-  } else {
-    // File mode
-    const savePath = argv.find(arg => arg.match(/\.sav$/i) && fs.existsSync(path.resolve(arg)))
-    if (!savePath) {
-      console.error(`\nUsage: tsx cli.ts [savefile.sav] [options]
+  fs.mkdirSync(outDir, { recursive: true })
+  let count = 0
+  for (const match of parser.allPokemon(result)) {
+    if (match.location.area === 'daycare') continue
+    const format = match.location.area === 'party' ? 'party' : 'box'
+    const { pokemon } = match
+    const bytes = extension === 'ek3' ? pokemon.exportEK3(format) : pokemon.exportPK3(format)
+    fs.writeFileSync(path.join(outDir, pk3FileName(match, extension)), bytes)
+    count++
+  }
+  console.log(`📦 Exported ${count} Pokémon to ${outDir}`)
+}
 
-Options:
-  --websocket           Connect to mGBA via WebSocket instead of reading a file
-  --ws-url=URL          WebSocket URL (default: ws://localhost:7102/ws)
-  --watch               Continuously monitor for changes and update display
-  --interval=MS         Update interval in milliseconds for watch mode (default: 1000)
-  --debug               Show raw bytes for each party Pokémon after the summary table
-  --graph               Show colored hex/field graph for each party Pokémon (instead of summary table)
-  --toBytes=STRING      Convert a string to GBA byte encoding and print the result
-  --toString=HEX        Convert a space/comma-separated hex byte string to a decoded GBA string
+/**
+ * `diff <before.sav> <after.sav> [--json]` - what changed between two saves of a run: Pokémon
+ * added, removed, moved or edited, items gained or lost, flags toggled and counters. --json
+ * prints the versioned diff document for sync and backup tools
+ */
+async function runDiffCommand(savePath: string, argv: readonly string[]) {
+  const afterPath = argv.find(
+    arg =>
+      arg !== savePath &&
+      (arg === STDIN_PATH || (/\.sav$/i.test(arg) && fs.existsSync(path.resolve(arg))))
+  )
+  if (!afterPath) throw new Error('No second .sav file to compare with')
 
-Examples:
-  tsx cli.ts mysave.sav --debug
-  tsx cli.ts mysave.sav --graph --watch
-  tsx cli.ts --websocket --watch --interval=2000
-  tsx cli.ts --websocket --debug
-  tsx cli.ts --toBytes=PIKACHU
-  tsx cli.ts --toString="50 49 4b 41 43 48 55 00"
+  const beforeParser = createParser()
+  const before = await beforeParser.parse(readSaveFile(savePath))
+  const afterParser = createParser()
+  const after = await afterParser.parse(readSaveFile(afterPath))
+  const diff = diffSaves(before, after, afterParser.gameConfig!, beforeParser.gameConfig!)
 
-WebSocket Mode:
-  Requires mGBA Docker container to be running with WebSocket API enabled.
-`)
-      process.exit(1)
-    }
-    input = savePath
+  if (argv.includes('--json')) return printResult(formatJson(diff, argv), 'json')
+  printResult(formatSaveDiff(diff), 'diff')
+}
+
+/**
+ * `qr <savefile> --party=N | --box=B --slot=S [--output=FILE.svg]` - show a Pokémon as a QR code of
+ * its .pk3 for scanning into the web UI on another device, or write the code as an SVG
+ */
+async function runQrCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+
+  const box = flagValue(argv, 'box')
+  const party = flagValue(argv, 'party')
+  if (!box && !party) throw new Error('No --party or --box given')
+  const location: InjectTarget = box
+    ? {
+        area: 'box',
+        box: parseInt(box, 10) - 1,
+        slot: parseInt(flagValue(argv, 'slot') ?? '1', 10) - 1,
+      }
+    : { area: 'party', slot: parseInt(party!, 10) - 1 }
+  const pokemon =
+    location.area === 'box'
+      ? result.boxes?.[location.box]?.[location.slot]
+      : result.party_pokemon[location.slot]
+  if (!pokemon) throw new Error(`${formatPokemonLocation(location)} is empty`)
+
+  const qr = encodePokemonQr(pokemon)
+  const svgPath = resultPath('svg')
+  if (svgPath) {
+    fs.writeFileSync(svgPath, qrCodeToSvg(qr))
+    console.log(`🔳 QR code for ${pokemon.nickname} written to ${svgPath}`)
+    return
   }
+  console.log(qrCodeToText(qr))
+  console.log(`${pokemon.nickname} (${formatPokemonLocation(location)}): ${toPokemonQrPayload(pokemon)}`)
+}
 
-  // Parse options
-  const options = { debug, graph, interval }
+/**
+ * `tui <savefile>` - browse the party and PC boxes in the terminal: inspect a Pokémon's full
+ * detail or search the whole save by name, move, item, nature, ability or OT
+ */
+async function runTuiCommand(savePath: string) {
+  if (!process.stdin.isTTY || !process.stdout.isTTY) {
+    throw new Error('tui needs an interactive terminal')
+  }
+  const parser = createParser()
+  const saveData = await parser.parse(readSaveFile(savePath))
+  const browser = createSaveBrowser(saveData, parser.gameConfig!)
+  let state = INITIAL_BROWSER_STATE
 
-  try {
-    if (watch) {
-      // Watch mode - continuous monitoring
-      await watchMode(input, options)
-    } else {
-      // Single run mode
-      await parseAndDisplay(input, options)
+  const draw = () => {
+    const lines = renderSaveBrowser(browser, state, process.stdout.rows)
+    const screen = lines.map(line => line.slice(0, process.stdout.columns)).join('\n')
+    process.stdout.write(`\x1b[H\x1b[2J${screen}`)
+  }
 
-      // Cleanup WebSocket if used
-      // eslint-disable-next-line @typescript-eslint/no-unnecessary-condition
-      if (input instanceof MgbaWebSocketClient) {
-        input.disconnect()
+  // A key or resize that fails ends the browser instead of escaping the event handler, so the
+  // terminal is always restored
+  let onKeypress!: (text: string | undefined, key: readline.Key | undefined) => void
+  let onResize!: () => void
+  const done = new Promise<void>((resolve, reject) => {
+    onKeypress = (text, key) => {
+      try {
+        // Control characters (Enter, Escape, Backspace) only count as named keys
+        const printable = text && text >= ' ' && text !== '\x7f' ? text : undefined
+        state =
+          key?.ctrl && key.name === 'c'
+            ? { ...state, quit: true }
+            : handleBrowserKey(browser, state, { name: key?.name, text: printable })
+        if (state.quit) resolve()
+        else draw()
+      } catch (error) {
+        reject(error)
       }
     }
-  } catch (err) {
-    console.error(
-      '❌ Failed to parse save data:',
-      err instanceof Error ? err.message : 'Unknown error'
-    )
-
-    // Cleanup WebSocket if used
-    // eslint-disable-next-line @typescript-eslint/no-unnecessary-condition
-    if (input instanceof MgbaWebSocketClient) {
-      input.disconnect()
+    onResize = () => {
+      try {
+        draw()
+      } catch (error) {
+        reject(error)
+      }
     }
+  })
 
-    process.exit(1)
+  // Raw keys on the alternate screen, so the shell's scrollback is untouched on exit
+  readline.emitKeypressEvents(process.stdin)
+  process.stdin.setRawMode(true)
+  process.stdout.write('\x1b[?1049h\x1b[?25l')
+  process.stdin.on('keypress', onKeypress)
+  process.stdout.on('resize', onResize)
+  try {
+    draw()
+    await done
+  } finally {
+    process.stdin.off('keypress', onKeypress)
+    process.stdout.off('resize', onResize)
+    process.stdout.write('\x1b[?25h\x1b[?1049l')
+    process.stdin.setRawMode(false)
+    process.stdin.pause()
+  }
+}
+
+/**
+ * `render <savefile> [--output=FILE.png] [--sprites=DIR]` - draw the party as a shareable team card
+ * PNG. Sprites come from the repository's public/sprites unless --sprites points elsewhere;
+ * Pokémon without a sprite file get a placeholder
+ */
+async function runRenderCommand(savePath: string, argv: readonly string[]) {
+  const spritesDir = path.resolve(
+    flagValue(argv, 'sprites') ?? fileURLToPath(new URL('../../../public/sprites', import.meta.url))
+  )
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+
+  const image = renderTeamCard(result, spritePath => {
+    const file = path.join(spritesDir, spritePath)
+    return fs.existsSync(file) ? decodeGif(fs.readFileSync(file)) : undefined
+  })
+  const outPath = outputFile('png', outputPath(savePath, '-team.png'))
+  fs.writeFileSync(outPath, encodePng(image, data => zlib.deflateSync(data)))
+  console.log(`🖼️ Team card written to ${outPath}`)
+}
+
+/**
+ * `import <savefile> <file.pk3|file.ek3> [--party=N | --box=B --slot=S] [--output=FILE]` - inject a
+ * Pokémon (1-based slots; appends to the party by default) and write the edited save.
+ * A Showdown paste (`.txt`) is added to the party instead, or replaces it with --replace-party
+ */
+async function runImportCommand(savePath: string, argv: readonly string[]) {
+  const importPath = argv.find(arg => /\.([pe]k3|txt)$/i.test(arg))
+  if (!importPath) throw new Error('No .pk3, .ek3 or Showdown .txt file given')
+
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+  const outPath = outputFile('sav', outputPath(savePath, '-imported.sav'))
+
+  if (/\.txt$/i.test(importPath)) {
+    const paste = fs.readFileSync(path.resolve(importPath), 'utf8')
+    const replaceParty = argv.includes('--replace-party')
+    const updated = parser.importShowdown(result, paste, { replaceParty })
+    const added = updated.party_pokemon.slice(replaceParty ? 0 : result.party_pokemon.length)
+    for (const pokemon of added) {
+      console.log(`📥 Imported #${pokemon.speciesId} ${pokemon.nickname} (Lv ${pokemon.level})`)
+    }
+    writeSaveOutput(outPath, parser.reconstructSaveFile(updated.party_pokemon), parser, argv)
+    return
+  }
+
+  const bytes = new Uint8Array(fs.readFileSync(path.resolve(importPath)))
+  const pokemon = /\.ek3$/i.test(importPath) ? parser.importEK3(bytes) : parser.importPK3(bytes)
+  const box = flagValue(argv, 'box')
+  const target: InjectTarget = box
+    ? {
+        area: 'box',
+        box: parseInt(box, 10) - 1,
+        slot: parseInt(flagValue(argv, 'slot') ?? '1', 10) - 1,
+      }
+    : {
+        area: 'party',
+        slot: parseInt(flagValue(argv, 'party') ?? String(result.party_pokemon.length + 1), 10) - 1,
+      }
+
+  const updated = parser.injectPokemon(result, pokemon, target)
+  const saved = parser.reconstructSaveFile(updated.party_pokemon, updated.boxes)
+  console.log(
+    `📥 Imported #${pokemon.speciesId} ${pokemon.nickname} (Lv ${pokemon.level}) into ${formatPokemonLocation(target)}`
+  )
+  writeSaveOutput(outPath, saved, parser, argv)
+}
+
+/**
+ * `release <savefile> --party=N | --box=B --slot=S [--output=FILE]` - release a Pokémon (1-based
+ * slots) like the PC does; the party closes the gap and a box slot is cleared
+ */
+async function runReleaseCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+
+  const box = flagValue(argv, 'box')
+  const party = flagValue(argv, 'party')
+  if (!box && !party) throw new Error('No --party or --box given')
+  const location: InjectTarget = box
+    ? {
+        area: 'box',
+        box: parseInt(box, 10) - 1,
+        slot: parseInt(flagValue(argv, 'slot') ?? '1', 10) - 1,
+      }
+    : { area: 'party', slot: parseInt(party!, 10) - 1 }
+  const pokemon =
+    location.area === 'box'
+      ? result.boxes?.[location.box]?.[location.slot]
+      : result.party_pokemon[location.slot]
+
+  const updated = parser.releasePokemon(result, location)
+  const outPath = outputFile('sav', outputPath(savePath, '-released.sav'))
+  console.log(`👋 Released ${pokemon!.nickname} from ${formatPokemonLocation(location)}`)
+  writeSaveOutput(
+    outPath,
+    parser.reconstructSaveFile(updated.party_pokemon, updated.boxes),
+    parser,
+    argv
+  )
+}
+
+/**
+ * `organize <savefile> --box=N (--sort=dex|level|name [--desc] | --compact) [--output=FILE]` or
+ * `organize <savefile> --move=B:S,B:S --to=N [--output=FILE]` - sort or compact a PC box, or move
+ * Pokémon into another box (1-based boxes and slots)
+ */
+async function runOrganizeCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  const manager = new BoxManager(await parser.parse(readSaveFile(savePath)))
+
+  const move = flagValue(argv, 'move')
+  const sort = flagValue(argv, 'sort')
+  if (move) {
+    const to = flagValue(argv, 'to')
+    if (!to) throw new Error('No --to box given')
+    const selection = move.split(',').map(ref => {
+      const [box, slot] = ref.split(':').map(n => parseInt(n, 10) - 1)
+      if (box === undefined || slot === undefined || Number.isNaN(box) || Number.isNaN(slot)) {
+        throw new Error(`Invalid --move entry "${ref}" (expected BOX:SLOT)`)
+      }
+      return { box, slot }
+    })
+    manager.moveToBox(selection, parseInt(to, 10) - 1)
+    console.log(`📦 Moved ${selection.length} Pokémon to box ${to}`)
+  } else {
+    const box = flagValue(argv, 'box')
+    if (!box) throw new Error('No --box given')
+    if (sort) {
+      if (!BOX_SORT_KEYS.includes(sort as BoxSortKey)) {
+        throw new Error(`Unknown sort key "${sort}" (expected ${BOX_SORT_KEYS.join(', ')})`)
+      }
+      manager.sortBox(parseInt(box, 10) - 1, sort as BoxSortKey, {
+        descending: argv.includes('--desc'),
+      })
+      console.log(`📦 Sorted box ${box} by ${sort}`)
+    } else if (argv.includes('--compact')) {
+      manager.compactBox(parseInt(box, 10) - 1)
+      console.log(`📦 Compacted box ${box}`)
+    } else {
+      throw new Error('No --sort or --compact given')
+    }
+  }
+
+  const outPath = outputFile('sav', outputPath(savePath, '-organized.sav'))
+  writeSaveOutput(outPath, manager.write(parser), parser, argv)
+}
+
+/**
+ * `rename <savefile> [--preset=NAME] [--nickname=TEMPLATE] [--ot-name=TEMPLATE]
+ * [--owner=all|own|traded] [--output=FILE]` - rename party and box Pokémon from templates
+ * ({species}, {nickname}, {ot}, {level}), listing every change before the save is written
+ */
+async function runRenameCommand(savePath: string, argv: readonly string[]) {
+  const preset = flagValue(argv, 'preset')
+  if (preset && !(preset in RENAME_PRESETS)) {
+    throw new Error(
+      `Unknown preset "${preset}" (expected ${Object.keys(RENAME_PRESETS).join(', ')})`
+    )
+  }
+  const base: RenameRule = preset ? RENAME_PRESETS[preset as RenamePreset] : {}
+  const owner = flagValue(argv, 'owner')
+  if (owner && !['all', 'own', 'traded'].includes(owner)) {
+    throw new Error(`Unknown owner "${owner}" (expected all, own or traded)`)
+  }
+  const rule: RenameRule = {
+    nickname: flagValue(argv, 'nickname') ?? base.nickname,
+    otName: flagValue(argv, 'ot-name') ?? base.otName,
+    owner: (owner as RenameRule['owner']) ?? base.owner,
+  }
+  if (rule.nickname === undefined && rule.otName === undefined) {
+    throw new Error('No --preset, --nickname or --ot-name given')
+  }
+
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+  const changes = parser.planRename(result, rule)
+  if (changes.length === 0) {
+    console.log('No names to change')
+    return
+  }
+  console.log(`✏️  ${changes.length} name change${changes.length === 1 ? '' : 's'}:`)
+  for (const { location, field, before, after } of changes) {
+    const label = field === 'nickname' ? 'Nickname' : 'OT name'
+    console.log(`   ${formatPokemonLocation(location)}: ${label} "${before}" -> "${after}"`)
+  }
+
+  const updated = parser.applyRename(result, changes)
+  const outPath = outputFile('sav', outputPath(savePath, '-renamed.sav'))
+  writeSaveOutput(
+    outPath,
+    parser.reconstructSaveFile(updated.party_pokemon, updated.boxes),
+    parser,
+    argv
+  )
+}
+
+/**
+ * `give-item <savefile> --item=NAME|ID [--quantity=N] [--pocket=P] [--output=FILE]` - set how many
+ * of an item the bag holds (0 removes it); the pocket is inferred from the item unless given
+ */
+async function runGiveItemCommand(savePath: string, argv: readonly string[]) {
+  const item = flagValue(argv, 'item')
+  if (!item) throw new Error('No --item given')
+
+  const parser = createParser()
+  await parser.parse(readSaveFile(savePath))
+  const items = parser.gameConfig?.mappings?.items
+  const rawItemId = /^\d+$/.test(item)
+    ? toRawId(items, parseInt(item, 10))
+    : findRawIdByName(items, item)
+  const mapping = rawItemId === undefined ? undefined : items?.get(rawItemId)
+  if (!mapping || mapping.id === null) throw new Error(`Unknown item "${item}"`)
+
+  const pocket = (flagValue(argv, 'pocket') ??
+    pocketForItem(mapping.id_name) ??
+    'items') as BagPocketName
+  const quantity = parseInt(flagValue(argv, 'quantity') ?? '1', 10)
+  const blocks = parser.getSaveBlocks()
+  parser.setBagItem(blocks, pocket, mapping.id, quantity)
+
+  const outPath = outputFile('sav', outputPath(savePath, '-edited.sav'))
+  console.log(`🎒 ${mapping.name} x${quantity} in the ${pocket} pocket`)
+  writeSaveOutput(outPath, parser.writeSaveFile({ saveblock1: blocks.saveblock1 }), parser, argv)
+}
+
+/**
+ * `set-stats <savefile> [--party=N] [--evs=HP,ATK,DEF,SPE,SPA,SPD] [--ivs=...] [--force]
+ * [--output=FILE]` - set a party Pokémon's EVs and/or IVs (1-based slot) and recompute its stats.
+ * EV spreads the game can't produce (over 510 in total, or on an egg) need --force
+ */
+async function runSetStatsCommand(savePath: string, argv: readonly string[]) {
+  const statList = (name: string) => flagValue(argv, name)?.split(',').map(Number)
+  const evs = statList('evs')
+  const ivs = statList('ivs')
+  if (!evs && !ivs) throw new Error('No --evs or --ivs given')
+
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+  const slot = parseInt(flagValue(argv, 'party') ?? '1', 10)
+  const pokemon = result.party_pokemon[slot - 1]
+  if (!pokemon) throw new Error(`Party slot ${slot} is empty`)
+
+  if (evs) pokemon.editEvs(evs, { force: argv.includes('--force') })
+  if (ivs) pokemon.editIvs(ivs)
+
+  const outPath = outputFile('sav', outputPath(savePath, '-edited.sav'))
+  console.log(`📊 ${pokemon.nickname}: EVs ${pokemon.evs.join('/')}, IVs ${pokemon.ivs.join('/')}`)
+  console.log(`   Stats ${pokemon.stats.join('/')}`)
+  writeSaveOutput(outPath, parser.reconstructSaveFile(result.party_pokemon), parser, argv)
+}
+
+/**
+ * `make-shiny <savefile> [--party=N] [--output=FILE]` - reroll a party Pokémon's PID (1-based slot)
+ * so it is shiny for its original trainer, keeping nature, gender and ability
+ */
+async function runMakeShinyCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  const result = await parser.parse(readSaveFile(savePath))
+  const slot = parseInt(flagValue(argv, 'party') ?? '1', 10)
+  const pokemon = result.party_pokemon[slot - 1]
+  if (!pokemon) throw new Error(`Party slot ${slot} is empty`)
+
+  pokemon.makeShiny()
+  const outPath = outputFile('sav', outputPath(savePath, '-shiny.sav'))
+  console.log(`✨ ${pokemon.nickname} is now shiny (${pokemon.nature}, ${pokemon.gender ?? '?'})`)
+  writeSaveOutput(outPath, parser.reconstructSaveFile(result.party_pokemon), parser, argv)
+}
+
+/**
+ * `heal <savefile> [--output=FILE]` - restore the party's HP, status conditions and PP
+ */
+async function runHealCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  const result = parser.healParty(await parser.parse(readSaveFile(savePath)))
+
+  const outPath = outputFile('sav', outputPath(savePath, '-healed.sav'))
+  for (const pokemon of result.party_pokemon) {
+    console.log(`💊 ${pokemon.nickname}: ${pokemon.currentHp}/${pokemon.maxHp} HP`)
+  }
+  writeSaveOutput(outPath, parser.reconstructSaveFile(result.party_pokemon), parser, argv)
+}
+
+/**
+ * `repair <savefile> [--output=FILE]` - fix sectors of the newest save slot that fail their
+ * checksum or signature check, restoring them from the backup slot when the data itself is lost
+ */
+async function runRepairCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  await parser.parse(readSaveFile(savePath))
+  const { data, repairs } = parser.repairSave()
+  if (repairs.length === 0) {
+    console.log('✅ No corrupted sectors found')
+    return
+  }
+
+  for (const { sector_id, sector_index, action } of repairs) {
+    console.log(`🔧 Sector ${sector_id} (file sector ${sector_index}): ${action}`)
+  }
+  const outPath = outputFile('sav', outputPath(savePath, '-repaired.sav'))
+  writeSaveOutput(outPath, data, parser, argv)
+}
+
+/**
+ * `convert-party <savefile> --from=SOURCE.sav [--output=FILE]` - replace the party with the party
+ * of a save from another game (e.g. Quetzal into vanilla Emerald), converting each Pokémon's layout
+ */
+async function runConvertPartyCommand(savePath: string, argv: readonly string[]) {
+  const sourcePath = flagValue(argv, 'from')
+  if (!sourcePath) throw new Error('No --from save given')
+
+  const source = await createParser().parse(readSaveFile(sourcePath))
+  const parser = createParser()
+  await parser.parse(readSaveFile(savePath))
+  const party = source.party_pokemon.map(pokemon => parser.importFromGame(pokemon))
+
+  const outPath = outputFile('sav', outputPath(savePath, '-converted.sav'))
+  for (const pokemon of party) {
+    console.log(`🔁 Converted #${pokemon.speciesId} ${pokemon.nickname} (Lv ${pokemon.level})`)
+  }
+  writeSaveOutput(outPath, parser.reconstructSaveFile(party), parser, argv)
+}
+
+/**
+ * `rollback <savefile> [--output=FILE]` - copy the backup slot over the active one so the game
+ * loads the previous in-game save
+ */
+async function runRollbackCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  const saveData = await parser.parse(readSaveFile(savePath))
+  const backup = saveData.active_slot === 0 ? 2 : 1
+  const outPath = outputFile('sav', outputPath(savePath, '-rollback.sav'))
+  console.log(`⏪ Restored slot ${backup} as the active save`)
+  writeSaveOutput(outPath, parser.copySaveSlot(backup), parser, argv)
+}
+
+/**
+ * `normalize <savefile> --container=128k|128k-rtc|64k [--output=FILE]` - convert a save to the file
+ * size and footer another emulator expects. The size changes, so --dry-run only reports the
+ * conversion instead of listing changed bytes
+ */
+function runNormalizeCommand(savePath: string, argv: readonly string[]) {
+  const container = parseContainer(argv)
+  if (!container) throw new Error('No --container given')
+
+  const save = new Uint8Array(readSaveFile(savePath))
+  const converted = convertSaveContainer(save, container)
+  const outPath = outputFile('sav', outputPath(savePath, `-${container}.sav`))
+  const sizes = `${save.length} -> ${converted.length} bytes`
+  console.log(`📦 ${detectSaveContainer(save)} -> ${container} (${sizes})`)
+  if (container === '64k') console.log('⚠️  Only the newest save slot fits in 64KB')
+  if (argv.includes('--dry-run')) {
+    console.log(`Dry run: nothing written to ${outPath}`)
+    return
+  }
+  writeSaveFileAtomic(outPath, converted, argv)
+}
+
+/**
+ * `anonymize <savefile> [--output=FILE]` - replace the player's name and IDs and those of every
+ * other trainer in the save so it can be shared publicly, e.g. in a bug report
+ */
+async function runAnonymizeCommand(savePath: string, argv: readonly string[]) {
+  const parser = createParser()
+  await parser.parse(readSaveFile(savePath))
+  const blocks = parser.getSaveBlocks()
+  const result = parser.anonymizeSave(blocks)
+
+  const outPath = outputFile('sav', outputPath(savePath, '-anonymized.sav'))
+  const trainerId = String(result.trainer_id).padStart(5, '0')
+  console.log(`🕶️  Player is now ${result.player_name} (ID ${trainerId})`)
+  console.log(
+    `   ${result.pokemon} Pokémon, ${result.mail} mail and ${result.secret_bases} secret bases updated`
+  )
+  writeSaveOutput(outPath, parser.writeSaveFile(blocks), parser, argv)
+}
+
+/**
+ * `push-party <savefile> [--ws-url=URL]` - write a save file's party into the game running in mGBA,
+ * e.g. after editing the save with the other subcommands; it sticks once the player saves in-game
+ */
+async function runPushPartyCommand(savePath: string, argv: readonly string[]) {
+  const fileParser = createParser()
+  const { party_pokemon: party } = await fileParser.parse(readSaveFile(savePath))
+
+  const client = new MgbaWebSocketClient(flagValue(argv, 'ws-url') ?? 'ws://localhost:7102/ws')
+  await client.connect()
+  try {
+    // Reuse the save's config so a save from another game is refused
+    const parser = new PokemonSaveParser(undefined, fileParser.gameConfig ?? undefined)
+    await parser.parse(client)
+    await parser.writePartyToMemory(party)
+  } finally {
+    client.disconnect()
+  }
+  for (const pokemon of party) {
+    console.log(`📡 Pushed #${pokemon.speciesId} ${pokemon.nickname} (Lv ${pokemon.level})`)
+  }
+  console.log('Save in-game to keep the new party')
+}
+
+const toPosixPath = (file: string) => file.split(path.sep).join('/')
+
+/**
+ * .sav files matching a glob the shell left unexpanded; a matching directory contributes every
+ * save beneath it, as it would after shell expansion
+ */
+function expandSaveGlob(pattern: string): string[] {
+  const absPattern = toPosixPath(path.resolve(pattern))
+  const base = path.resolve(globBase(absPattern))
+  const regex = globToRegExp(absPattern)
+  if (!fs.existsSync(base)) return []
+  return collectSaveFiles([base]).filter(file => {
+    for (let entry = file; entry.length > base.length; entry = path.dirname(entry)) {
+      if (regex.test(toPosixPath(entry))) return true
+    }
+    return false
+  })
+}
+
+/** Recursively collect .sav files from files, directories and glob patterns. */
+const collectSaveFiles = (inputs: readonly string[]): string[] =>
+  inputs.flatMap(input => {
+    const absPath = path.resolve(input)
+    if (!fs.existsSync(absPath)) return isGlob(input) ? expandSaveGlob(input) : []
+    if (fs.statSync(absPath).isDirectory()) {
+      return collectSaveFiles(fs.readdirSync(absPath).map(name => path.join(absPath, name)))
+    }
+    return /\.sav$/i.test(absPath) ? [absPath] : []
+  })
+
+/**
+ * Open the catalog database at indexPath (created on first write). A catalog of another version
+ * is an error rather than being silently replaced
+ */
+async function openSaveIndex(indexPath: string) {
+  // node:sqlite ships with Node.js 22.5+
+  const sqlite = await import('node:sqlite').catch(() => null)
+  if (!sqlite) {
+    console.error('❌ The save index is a SQLite database and needs Node.js 22.5 or newer')
+    process.exit(1)
+  }
+  const db = new sqlite.DatabaseSync(indexPath)
+  try {
+    return { db, index: readSaveIndex(db) }
+  } catch (error) {
+    db.close()
+    const reason = error instanceof Error ? error.message : 'unreadable'
+    console.error(`❌ ${indexPath}: ${reason}`)
+    console.error(`Rebuild it with: rm "${indexPath}" && tsx cli.ts index add PATH...`)
+    process.exit(1)
+  }
+}
+
+const formatPlayTime = (seconds: number) =>
+  `${Math.floor(seconds / 3600)}h ${Math.floor((seconds % 3600) / 60)}m`
+
+/**
+ * `index add <paths...>` / `index list` / `index find [filters]` / `index stats [--json]` /
+ * `index timeline [--json]` - maintain a deduplicated SQLite catalog of a save library and
+ * search, total or replay it without re-parsing
+ */
+async function runIndexCommand(argv: readonly string[]) {
+  const [, action, ...rest] = argv.slice(argv.indexOf('index'))
+  if (!['add', 'list', 'find', 'stats', 'timeline'].includes(action ?? '')) {
+    console.error(
+      'Usage: tsx cli.ts index <add PATH... | list | find [filters] | stats | timeline> [--index=FILE]'
+    )
+    process.exit(1)
+  }
+  const indexPath = path.resolve(flagValue(argv, 'index') ?? '.pokemon-save-index.db')
+  // Reading commands don't create a catalog that isn't there
+  if (action !== 'add' && !fs.existsSync(indexPath)) {
+    console.error(`❌ No save index at ${indexPath}; create it with: tsx cli.ts index add PATH...`)
+    process.exit(1)
+  }
+  const { db, index: catalog } = await openSaveIndex(indexPath)
+  let index = catalog
+
+  try {
+    if (action === 'add') {
+      const files = collectSaveFiles(rest.filter(arg => !arg.startsWith('--')))
+      const counts = { added: 0, duplicate: 0, unchanged: 0, failed: 0 }
+      for (const file of files) {
+        try {
+          const update = await addSaveToIndex(index, new Uint8Array(fs.readFileSync(file)), file)
+          index = update.index
+          counts[update.result]++
+        } catch (error) {
+          counts.failed++
+          console.error(`❌ ${file}: ${error instanceof Error ? error.message : 'Unknown error'}`)
+        }
+      }
+      index = pruneSaveIndex(index, fs.existsSync)
+      writeSaveIndex(db, index)
+      console.log(
+        `🗂️  Indexed ${files.length} files: ${counts.added} added, ${counts.duplicate} duplicates, ${counts.unchanged} unchanged, ${counts.failed} failed`
+      )
+      console.log(`Catalog: ${indexPath} (${Object.keys(index.entries).length} unique saves)`)
+    } else if (action === 'list') {
+      for (const entry of Object.values(index.entries)) {
+        const { hours, minutes } = entry.play_time
+        const party = entry.party
+          .map(p => `#${p.species_id} Lv${p.level}${p.is_shiny ? ' ✨' : ''}`)
+          .join(', ')
+        console.log(
+          `${entry.fingerprint.slice(0, 12)}  ${pad(entry.game, 28)}${pad(entry.player_name, 10)}${pad(`${hours}h ${minutes}m`, 10)}${party}`
+        )
+        for (const file of entry.paths) console.log(`    ${file}`)
+      }
+    } else if (action === 'find') {
+      const matches = findInSaveIndex(index, parseFindQuery(argv))
+      console.log(`\n--- Search Results (${matches.length} found) ---`)
+      for (const { entry, slot, pokemon } of matches) {
+        const shiny = pokemon.is_shiny ? ' ✨' : ''
+        console.log(
+          `${entry.fingerprint.slice(0, 12)}  ${pad(entry.player_name, 10)}${pad(`Party slot ${slot + 1}`, 14)}${pad(`#${pokemon.species_id}`, 6)}Lv ${pokemon.level}${shiny}`
+        )
+      }
+    } else if (action === 'stats') {
+      const stats = summarizeSaveIndex(index)
+      if (argv.includes('--json')) return printResult(formatJson(stats, argv), 'json')
+      console.log(`Saves: ${stats.saves} unique (${stats.files} files)`)
+      for (const [game, count] of Object.entries(stats.games)) {
+        console.log(`  ${pad(game, 28)}${count}`)
+      }
+      console.log(`Play time: ${formatPlayTime(stats.play_time_seconds)}`)
+      console.log(
+        `Party Pokémon: ${stats.party_pokemon} (${stats.species} species, ${stats.shiny_pokemon} shiny)`
+      )
+    } else {
+      const runs = saveIndexTimeline(index)
+      if (argv.includes('--json')) return printResult(formatJson(runs, argv), 'json')
+      for (const { game, player_name, saves } of runs) {
+        console.log(`\n--- ${player_name} (${game}) ---`)
+        for (const { entry, since_previous: since } of saves) {
+          const { hours, minutes } = entry.play_time
+          const progress = since
+            ? [
+                `+${formatPlayTime(since.play_time_seconds)}`,
+                since.badges > 0 ? `+${since.badges} badges` : '',
+                since.caught ? `+${since.caught} caught` : '',
+                since.new_species.length > 0
+                  ? `new ${since.new_species.map(id => `#${id}`).join(', ')}`
+                  : '',
+              ]
+                .filter(Boolean)
+                .join(', ')
+            : 'first save'
+          console.log(
+            `${pad(`${hours}h ${minutes}m`, 10)}${pad(`${entry.badge_count} badges`, 11)}${pad(progress, 40)}${entry.paths[0]}`
+          )
+        }
+      }
+    }
+  } finally {
+    db.close()
+  }
+}
+
+/**
+ * Write export statements into a SQLite database file, creating the tables when needed
+ */
+async function writeSqliteDatabase(dbPath: string, statements: readonly SqlStatement[]) {
+  // node:sqlite ships with Node.js 22.5+; older versions can still write a .sql script
+  const sqlite = await import('node:sqlite').catch(() => null)
+  if (!sqlite) {
+    throw new Error(
+      'Writing a SQLite database needs Node.js 22.5 or newer; use --output=FILE.sql and load it with sqlite3'
+    )
+  }
+  const db = new sqlite.DatabaseSync(dbPath)
+  try {
+    for (const sql of SQL_EXPORT_SCHEMA) db.exec(sql)
+    db.exec('BEGIN')
+    for (const { sql, params } of statements) db.prepare(sql).run(...params)
+    db.exec('COMMIT')
+  } finally {
+    db.close()
+  }
+}
+
+/**
+ * `sqlite <paths...> [--output=FILE]` - export saves into a SQLite database (tables meta, pokemon,
+ * items, dex and stats) to query a collection with SQL; a .sql output is a script for sqlite3
+ */
+async function runSqliteCommand(argv: readonly string[]) {
+  const outPath = outputFile('sqlite', 'pokemon-saves.db')
+  const inputs = argv.slice(argv.indexOf('sqlite') + 1).filter(arg => !arg.startsWith('--'))
+  const files = collectSaveFiles(inputs)
+  if (files.length === 0) {
+    console.error('Usage: tsx cli.ts sqlite <PATH...> [--output=FILE.db|FILE.sql]')
+    process.exit(1)
+  }
+
+  const statements: SqlStatement[] = []
+  let failed = 0
+  for (const file of files) {
+    try {
+      const bytes = new Uint8Array(fs.readFileSync(file))
+      const parser = createParser()
+      const saveData = await parser.parse(bytes)
+      const fingerprint = fingerprintSave(bytes)
+      statements.push(
+        ...saveToSqlStatements({ saveData, config: parser.gameConfig!, fingerprint, path: file })
+      )
+    } catch (error) {
+      failed++
+      console.error(`❌ ${file}: ${error instanceof Error ? error.message : 'Unknown error'}`)
+    }
+  }
+
+  if (/\.sql$/i.test(outPath)) {
+    fs.writeFileSync(outPath, toSqlScript(statements))
+  } else {
+    await writeSqliteDatabase(outPath, statements)
+  }
+  console.log(`🗃️  Exported ${files.length - failed} of ${files.length} saves to ${outPath}`)
+}
+
+/**
+ * `parquet <paths...> [--output=FILE]` - export every Pokémon in the saves as one Parquet table
+ * (one row per party, box or daycare Pokémon) for DuckDB, pandas or Spark
+ */
+async function runParquetCommand(argv: readonly string[]) {
+  const outPath = outputFile('parquet', 'pokemon-saves.parquet')
+  const inputs = argv.slice(argv.indexOf('parquet') + 1).filter(arg => !arg.startsWith('--'))
+  const files = collectSaveFiles(inputs)
+  if (files.length === 0) {
+    console.error('Usage: tsx cli.ts parquet <PATH...> [--output=FILE.parquet]')
+    process.exit(1)
+  }
+
+  const sources: ParquetExportSource[] = []
+  for (const file of files) {
+    try {
+      const bytes = new Uint8Array(fs.readFileSync(file))
+      const parser = createParser()
+      const saveData = await parser.parse(bytes)
+      const fingerprint = fingerprintSave(bytes)
+      sources.push({ saveData, config: parser.gameConfig!, fingerprint, path: file })
+    } catch (error) {
+      console.error(`❌ ${file}: ${error instanceof Error ? error.message : 'Unknown error'}`)
+    }
+  }
+
+  fs.writeFileSync(outPath, savesToParquet(sources))
+  console.log(`📦 Exported ${sources.length} of ${files.length} saves to ${outPath}`)
+}
+
+/**
+ * Parse `files` on up to `jobs` worker threads, yielding each save's --json document or error in
+ * input order as soon as it and every save before it are done. Workers stay at most `jobs` saves
+ * ahead of the consumer, so slow output doesn't pile up parsed documents
+ */
+async function* parseInWorkers(
+  files: readonly string[],
+  jobs: number,
+  workerData: BatchWorkerData
+): AsyncGenerator<SaveJson | Error> {
+  const settle: ((result: SaveJson | Error) => void)[] = []
+  const results = files.map(
+    (_, index) =>
+      new Promise<SaveJson | Error>(resolve => {
+        settle[index] = resolve
+      })
+  )
+  let next = 0
+  let consumed = 0
+  const busy = new Map<Worker, number>()
+  const alive = new Set<Worker>()
+  const idle: Worker[] = []
+  const feed = (worker: Worker) => {
+    if (!alive.has(worker)) return
+    if (next >= files.length || next - consumed >= jobs) {
+      idle.push(worker)
+      return
+    }
+    busy.set(worker, next)
+    worker.postMessage({ index: next, file: files[next++]! } satisfies BatchJob)
+  }
+
+  const url = new URL('./batchWorker.ts', import.meta.url)
+  const workers = Array.from({ length: Math.min(jobs, files.length) }, () => {
+    const worker = new Worker(url, { workerData })
+    alive.add(worker)
+    worker.on('message', (result: BatchResult) => {
+      busy.delete(worker)
+      settle[result.index]!('json' in result ? JSON.parse(result.json) : new Error(result.error))
+      feed(worker)
+    })
+    // A crashed worker fails the save it had; once none are left, so do the saves not handed out
+    worker.on('error', error => {
+      const index = busy.get(worker)
+      busy.delete(worker)
+      if (index !== undefined) settle[index]!(error)
+      alive.delete(worker)
+      if (alive.size === 0) while (next < files.length) settle[next++]!(error)
+    })
+    return worker
+  })
+  workers.forEach(feed)
+
+  try {
+    for (const result of results) {
+      yield await result
+      consumed++
+      if (idle.length > 0) feed(idle.shift()!)
+    }
+  } finally {
+    await Promise.all(workers.map(worker => worker.terminate()))
+  }
+}
+
+/**
+ * `<paths...> --ndjson|--csv [--jobs=N] [--boxes] [--legality]` - parse every save under the given
+ * files, directories and globs on --jobs worker threads, writing one line per save in input order
+ * as soon as it is ready: its --json document (NDJSON), or a summary row (CSV). A save that fails
+ * becomes a line with its error so it doesn't stop the batch; a tally goes to stderr at the end.
+ * --output=FILE writes the lines to FILE, and --output-dir=DIR one document or row per save to DIR
+ */
+async function runBatchCommand(argv: readonly string[]) {
+  const csv = argv.includes('--csv')
+  const jobsValue = flagValue(argv, 'jobs')
+  const jobs = jobsValue !== undefined ? parseInt(jobsValue, 10) : os.availableParallelism()
+  const files = [...new Set(collectSaveFiles(argv.slice(2).filter(arg => !arg.startsWith('--'))))]
+  if (files.length === 0 || !Number.isInteger(jobs) || jobs < 1) {
+    console.error('Usage: tsx cli.ts <PATH...> --ndjson|--csv [--jobs=N] [--boxes] [--legality]')
+    process.exit(1)
+  }
+  // A consumer like `head` closing the pipe early ends the batch quietly
+  process.stdout.on('error', error => {
+    if ((error as { code?: string }).code === 'EPIPE') process.exit(0)
+    throw error
+  })
+
+  const header = `${BATCH_CSV_COLUMNS.join(',')}\n`
+  const outputDir = flagValue(argv, 'output-dir')
+  const names = outputDir === undefined ? [] : outputFileNames(files, csv ? '.csv' : '.json')
+  if (outputDir !== undefined) fs.mkdirSync(outputDir, { recursive: true })
+  const output = resultPath(csv ? 'csv' : 'ndjson')
+  const sink: Writable = output === undefined ? process.stdout : fs.createWriteStream(output)
+
+  if (csv && outputDir === undefined) sink.write(header)
+  let index = 0
+  let failed = 0
+  const game = flagValue(argv, 'game')
+  const parsed = parseInWorkers(files, jobs, {
+    boxes: argv.includes('--boxes'),
+    legality: argv.includes('--legality'),
+    game: game?.toLowerCase(),
+  })
+  for await (const result of parsed) {
+    const name = names[index]
+    const file = files[index++]!
+    if (result instanceof Error) failed++
+    if (outputDir !== undefined && name !== undefined) {
+      // A failed save gets no file; its error goes to stderr instead
+      if (result instanceof Error) console.error(`❌ ${file}: ${result.message}`)
+      else if (csv) fs.writeFileSync(path.join(outputDir, name), header + toCsvLine(file, result))
+      else fs.writeFileSync(path.join(outputDir, name), `${formatJson(result, argv)}\n`)
+      continue
+    }
+    const line = csv ? toCsvLine(file, result) : toNdjsonLine(file, result)
+    // Wait for a slow reader instead of buffering the whole batch in memory
+    if (!sink.write(line)) await once(sink, 'drain')
+  }
+  if (output !== undefined) {
+    sink.end()
+    await once(sink, 'finish')
+    console.log(`💾 Output written to ${output}`)
+  } else if (outputDir !== undefined) {
+    console.log(`💾 ${files.length - failed} files written to ${path.resolve(outputDir)}`)
+  }
+  console.error(`Parsed ${files.length - failed} of ${files.length} saves (${failed} failed)`)
+}
+
+/**
+ * --output-dir file names: each save's name with the extension, numbered from -2 when saves in
+ * different directories share a name
+ */
+function outputFileNames(files: readonly string[], extension: string): string[] {
+  const seen = new Map<string, number>()
+  return files.map(file => {
+    const name = path.basename(file).replace(/\.sav$/i, '')
+    const count = (seen.get(name.toLowerCase()) ?? 0) + 1
+    seen.set(name.toLowerCase(), count)
+    return count === 1 ? `${name}${extension}` : `${name}-${count}${extension}`
+  })
+}
+
+/**
+ * Clear screen and move cursor to top
+ */
+function clearScreen() {
+  process.stdout.write('\x1b[2J\x1b[H')
+}
+
+/**
+ * Watch mode - continuously monitor and update display
+ */
+async function watchMode(
+  input: string | MgbaWebSocketClient,
+  options: { debug: boolean; graph: boolean; interval: number; json?: boolean; post?: string }
+) {
+  if (typeof input === 'string') {
+    // File-based watch mode - reacts to file system change events
+    return watchModeFile(input, options)
+  } else {
+    // WebSocket-based watch mode - use event-driven updates
+    return watchModeWebSocket(input, options)
+  }
+}
+
+/**
+ * File-based watch mode - re-parse whenever the emulator flushes the save, printing the party
+ * (or with --json one NDJSON line per change) and POSTing each update to --post
+ */
+async function watchModeFile(
+  filePath: string,
+  options: { debug: boolean; graph: boolean; interval: number; json?: boolean; post?: string }
+) {
+  // Status goes to stderr with --json so stdout stays one document per line
+  const log = options.json ? console.error : console.log
+  log(`🔄 Watching ${filePath} for changes (polling every ${options.interval}ms as a fallback)...`)
+  log('Press Ctrl+C to exit')
+
+  const watcher = watchSaveFile(filePath, {
+    interval: options.interval,
+    parser: createParser(),
+    post: options.post,
+    onUpdate: ({ result, json }) => {
+      if (options.json) return void process.stdout.write(toNdjsonLine(filePath, json))
+      clearScreen()
+      displayPartyPokemon(result.party_pokemon, 'FILE')
+    },
+    onError: message => console.error('❌ Error:', message),
+  })
+
+  // Keep the process alive and handle cleanup
+  return new Promise<void>(resolve => {
+    const cleanup = async () => {
+      await watcher.close()
+      resolve()
+    }
+
+    process.on('SIGINT', cleanup)
+    process.on('SIGTERM', cleanup)
+  })
+}
+
+/**
+ * WebSocket-based watch mode - event-driven approach using parser watch API
+ */
+async function watchModeWebSocket(
+  client: MgbaWebSocketClient,
+  options: { debug: boolean; graph: boolean; interval: number }
+) {
+  console.log('🔄 Starting event-driven watch mode...')
+  console.log('Press Ctrl+C to exit')
+
+  // Create parser once and reuse it
+  const parser = createParser()
+
+  // Load the WebSocket client into memory mode
+  await parser.loadInputData(client)
+
+  // Get and display initial data
+  const initialData = await parser.getCurrentSaveData()
+  displayPartyPokemon(initialData.party_pokemon, 'MEMORY')
+  if (options.debug) displayPartyPokemonRaw(initialData.party_pokemon)
+
+  // Set up watching with the new parser API
+  await parser.watch({
+    onPartyChange: partyPokemon => {
+      clearScreen()
+      displayPartyPokemon(partyPokemon, 'MEMORY')
+      if (options.debug) displayPartyPokemonRaw(partyPokemon)
+    },
+    onError: error => {
+      console.error('❌ Error processing memory change:', error.message)
+    },
+  })
+  console.log('✅ Memory watching started')
+
+  // Keep the process alive and handle cleanup
+  return new Promise<void>(resolve => {
+    const cleanup = async () => {
+      await parser.stopWatching()
+      resolve()
+    }
+
+    process.on('SIGINT', cleanup)
+    process.on('SIGTERM', cleanup)
+  })
+}
+
+/**
+ * `parse <savefile> [options]`, the default command - print the save, its --json document or a
+ * --format= export of it, or keep it on screen with --watch
+ */
+async function runParseCommand(input: string | MgbaWebSocketClient, argv: readonly string[]) {
+  const format = flagValue(argv, 'format')
+  if (format !== undefined) {
+    if (!OUTPUT_FORMATS.some(known => known === format)) {
+      throw new Error(`Unknown format "${format}" (expected ${OUTPUT_FORMATS.join(', ')})`)
+    }
+    return FORMATS[format as OutputFormat](input, argv)
+  }
+
+  // Path/filter expression picking values out of the --json document (implies --json)
+  const query = flagValue(argv, 'query')
+  const options = {
+    debug: argv.includes('--debug'),
+    graph: argv.includes('--graph'),
+    interval: parseInt(flagValue(argv, 'interval') ?? '1000'),
+    // Tracker URL that watch mode POSTs each updated --json document to
+    post: flagValue(argv, 'post'),
+    trainerCard: flagValue(argv, 'trainer-card'),
+    json: argv.includes('--json') || query !== undefined,
+    compact: argv.includes('--compact'),
+    query,
+    // MessagePack copy of the --json document
+    msgpack: flagValue(argv, 'msgpack'),
+    verifyStats: argv.includes('--verify-stats'),
+    legality: argv.includes('--legality'),
+    boxes: argv.includes('--boxes'),
+    bag: argv.includes('--bag'),
+    slots: argv.includes('--slots'),
+  }
+
+  if (argv.includes('--watch')) return watchMode(input, options)
+  await parseAndDisplay(input, options)
+}
+
+/**
+ * `verify <savefile> [--json]` - check a save: what parsing flagged (checksums, skipped slots),
+ * stored stats against recomputed ones and the party's legality. Exits with status 1 when any
+ * check finds a warning or an error, so scripts can gate on it
+ */
+async function runVerifyCommand(input: string | MgbaWebSocketClient, argv: readonly string[]) {
+  const parser = createParser()
+  const result = await parser.parse(
+    typeof input === 'string' ? readSaveFile(input) : input
+  )
+  const warnings = [
+    ...(result.warnings ?? []),
+    ...parser.verifyStats(result),
+    ...legalityWarnings(result.party_pokemon, parser.checkLegality(result)),
+  ]
+  const skipped = result.skipped_slots ?? []
+  const problems = warnings.filter(({ severity }) => severity !== 'info').length + skipped.length
+  if (problems > 0) process.exitCode = 1
+
+  if (argv.includes('--json')) {
+    const report = { valid: problems === 0, warnings, skipped_slots: skipped }
+    return printResult(formatJson(report, argv), 'json')
+  }
+  displaySkippedSlots(skipped)
+  displayWarnings(warnings)
+  const plural = problems === 1 ? '' : 's'
+  console.log(problems === 0 ? '\n✅ No problems found' : `\n❌ ${problems} problem${plural} found`)
+}
+
+type CommandInput = string | MgbaWebSocketClient
+
+type Argv = readonly string[]
+
+type Exporter = (input: CommandInput, argv: Argv) => Promise<void>
+
+// --format= exports of the parse command, one for each of OUTPUT_FORMATS
+const FORMATS: Readonly<Record<OutputFormat, Exporter>> = {
+  showdown: input => displayShowdown(input),
+  html: writeHtmlReport,
+  markdown: input => displayMarkdown(input),
+  xlsx: writeXlsxWorkbook,
+  hexdump: async input => {
+    if (typeof input !== 'string') throw new Error('--format=hexdump needs a save file')
+    await displayHexdump(input)
+  },
+  pokepaste: exportPokepaste,
+}
+
+interface CommandHelp {
+  /** Arguments after the command name, one entry per form of the command */
+  readonly usage: readonly string[]
+  readonly summary: string
+}
+
+interface CommandBase extends CommandHelp {
+  /** Flags accepted beyond GLOBAL_FLAGS: `--name` for a switch, `--name=` for a value */
+  readonly flags: readonly string[]
+}
+
+/**
+ * A subcommand, by what it reads before running: one save file, a save file or the game in mGBA
+ * (--websocket), or nothing (commands that take their own paths or arguments)
+ */
+type Command = CommandBase &
+  (
+    | { readonly reads: 'file'; readonly run: (savePath: string, argv: Argv) => unknown }
+    | { readonly reads: 'save'; readonly run: (input: CommandInput, argv: Argv) => unknown }
+    | { readonly reads: 'nothing'; readonly run: (argv: Argv) => unknown }
+  )
+
+// Accepted by every command
+const GLOBAL_FLAGS = ['--help', '-h', '--game=', '--config=', '--no-color']
+
+// Every command that writes a save
+const WRITE_FLAGS = ['--output=', '--container=', '--dry-run', '--no-backup']
+
+// Commands that can read the game from mGBA instead of a save file
+const WEBSOCKET_FLAGS = ['--websocket', '--ws-url=']
+
+const JSON_FLAGS = ['--json', '--pretty', '--compact']
+
+const FIND_FLAGS = [
+  '--species=',
+  '--min-level=',
+  '--max-level=',
+  '--shiny',
+  '--not-shiny',
+  '--ot=',
+  '--move=',
+  '--min-iv=',
+]
+
+const SLOT_FLAGS = ['--party=', '--box=', '--slot=']
+
+// `edit <command> …` runs one of these, the commands that write an edited save
+const EDIT_COMMANDS = [
+  'import',
+  'release',
+  'organize',
+  'rename',
+  'give-item',
+  'heal',
+  'make-shiny',
+  'repair',
+  'rollback',
+  'convert-party',
+  'normalize',
+  'set-stats',
+  'anonymize',
+]
+
+/**
+ * Subcommands by name. main() runs the first of these in the arguments (parse without one),
+ * after checking every flag against the command's, and `<command> --help` prints the command's
+ * entry with its examples
+ */
+const COMMANDS: Readonly<Record<string, Command>> = {
+  parse: {
+    usage: ['[savefile.sav] [options]'],
+    summary: 'Print the save, its --json document or a --format= export (the default command)',
+    flags: [
+      ...WEBSOCKET_FLAGS,
+      ...JSON_FLAGS,
+      '--watch',
+      '--interval=',
+      '--post=',
+      '--debug',
+      '--graph',
+      '--query=',
+      '--msgpack=',
+      '--ndjson',
+      '--csv',
+      '--jobs=',
+      '--output=',
+      '--output-dir=',
+      '--toBytes=',
+      '--toString=',
+      '--trainer-card=',
+      '--verify-stats',
+      '--legality',
+      '--bag',
+      '--boxes',
+      '--slots',
+      '--format=',
+      '--upload',
+      '--title=',
+      '--author=',
+      '--notes=',
+    ],
+    reads: 'save',
+    run: runParseCommand,
+  },
+  verify: {
+    usage: ['[savefile.sav] [--json]'],
+    summary:
+      'Check checksums, skipped slots, stored stats and party legality; exits with 1 on any problem',
+    flags: [...WEBSOCKET_FLAGS, ...JSON_FLAGS, '--output='],
+    reads: 'save',
+    run: runVerifyCommand,
+  },
+  edit: {
+    usage: [`<${EDIT_COMMANDS.join('|')}> [savefile.sav] [options]`],
+    summary: 'Edit a save with one of the editing commands, the same as running it directly',
+    flags: [],
+    reads: 'nothing',
+    run: () => {
+      throw new Error(`edit needs a command: ${EDIT_COMMANDS.join(', ')}`)
+    },
+  },
+  find: {
+    usage: ['[savefile.sav] [filters]'],
+    summary: 'Search a save for Pokémon matching the Find Filters below',
+    flags: [...WEBSOCKET_FLAGS, ...FIND_FLAGS],
+    reads: 'save',
+    run: (input, argv) => findAndDisplay(input, parseFindQuery(argv)),
+  },
+  export: {
+    usage: ['[savefile.sav] [--output=DIR] [--ek3]'],
+    summary: 'Dump party and PC box Pokémon as .pk3 files, or encrypted .ek3 files with --ek3',
+    flags: ['--output=', '--ek3'],
+    reads: 'file',
+    run: runExportCommand,
+  },
+  render: {
+    usage: ['[savefile.sav] [--output=FILE.png] [--sprites=DIR]'],
+    summary: 'Draw the party as a shareable team card PNG',
+    flags: ['--output=', '--sprites='],
+    reads: 'file',
+    run: runRenderCommand,
+  },
+  tui: {
+    usage: ['[savefile.sav]'],
+    summary: 'Browse the party and PC boxes interactively, inspect Pokémon and search the save',
+    flags: [],
+    reads: 'file',
+    run: runTuiCommand,
+  },
+  qr: {
+    usage: ['[savefile.sav] --party=N | --box=B --slot=S [--output=FILE.svg]'],
+    summary: 'Show a Pokémon as a QR code of its .pk3, or write the code as an SVG',
+    flags: [...SLOT_FLAGS, '--output='],
+    reads: 'file',
+    run: runQrCommand,
+  },
+  coverage: {
+    usage: ['[savefile.sav] [--json]'],
+    summary: "The party's offensive and defensive type coverage as a matrix, or as JSON",
+    flags: [...WEBSOCKET_FLAGS, ...JSON_FLAGS, '--output='],
+    reads: 'save',
+    run: displayCoverage,
+  },
+  diff: {
+    usage: ['[before.sav] [after.sav] [--json]'],
+    summary: 'What changed between two saves of a run: Pokémon, items, flags and counters',
+    flags: [...JSON_FLAGS, '--output='],
+    reads: 'file',
+    run: runDiffCommand,
+  },
+  import: {
+    usage: [
+      '[savefile.sav] [file.pk3|file.ek3] [--party=N | --box=B --slot=S] [--output=FILE]',
+      '[savefile.sav] [team.txt] [--replace-party] [--output=FILE]',
+    ],
+    summary: 'Inject a .pk3/.ek3 Pokémon (into the party by default) or a Showdown paste into the party',
+    flags: [...WRITE_FLAGS, ...SLOT_FLAGS, '--replace-party'],
+    reads: 'file',
+    run: runImportCommand,
+  },
+  release: {
+    usage: ['[savefile.sav] --party=N | --box=B --slot=S [--output=FILE]'],
+    summary: 'Release a Pokémon like the PC does; the party closes the gap and a box slot is cleared',
+    flags: [...WRITE_FLAGS, ...SLOT_FLAGS],
+    reads: 'file',
+    run: runReleaseCommand,
+  },
+  organize: {
+    usage: [
+      '[savefile.sav] --box=N --sort=dex|level|name [--desc] [--output=FILE]',
+      '[savefile.sav] --box=N --compact [--output=FILE]',
+      '[savefile.sav] --move=B:S,B:S --to=N [--output=FILE]',
+    ],
+    summary: 'Sort or compact a PC box, or move Pokémon into another box',
+    flags: [...WRITE_FLAGS, '--box=', '--sort=', '--desc', '--compact', '--move=', '--to='],
+    reads: 'file',
+    run: runOrganizeCommand,
+  },
+  rename: {
+    usage: [
+      '[savefile.sav] [--preset=NAME] [--nickname=TEMPLATE] [--ot-name=TEMPLATE] [--owner=all|own|traded] [--output=FILE]',
+    ],
+    summary: 'Rename party and box Pokémon from templates ({species}, {nickname}, {ot}, {level})',
+    flags: [...WRITE_FLAGS, '--preset=', '--nickname=', '--ot-name=', '--owner='],
+    reads: 'file',
+    run: runRenameCommand,
+  },
+  'give-item': {
+    usage: ['[savefile.sav] --item=NAME|ID [--quantity=N] [--pocket=P] [--output=FILE]'],
+    summary: 'Set how many of an item the bag holds (0 removes it)',
+    flags: [...WRITE_FLAGS, '--item=', '--quantity=', '--pocket='],
+    reads: 'file',
+    run: runGiveItemCommand,
+  },
+  heal: {
+    usage: ['[savefile.sav] [--output=FILE]'],
+    summary: "Restore the party's HP, status conditions and PP",
+    flags: WRITE_FLAGS,
+    reads: 'file',
+    run: runHealCommand,
+  },
+  'make-shiny': {
+    usage: ['[savefile.sav] [--party=N] [--output=FILE]'],
+    summary: "Reroll a party Pokémon's PID so it is shiny, keeping nature, gender and ability",
+    flags: [...WRITE_FLAGS, '--party='],
+    reads: 'file',
+    run: runMakeShinyCommand,
+  },
+  repair: {
+    usage: ['[savefile.sav] [--output=FILE]'],
+    summary: 'Fix sectors of the newest save slot that fail their checksum or signature check',
+    flags: WRITE_FLAGS,
+    reads: 'file',
+    run: runRepairCommand,
+  },
+  rollback: {
+    usage: ['[savefile.sav] [--output=FILE]'],
+    summary: 'Copy the backup slot over the active one so the game loads the previous save',
+    flags: WRITE_FLAGS,
+    reads: 'file',
+    run: runRollbackCommand,
+  },
+  'convert-party': {
+    usage: ['[savefile.sav] --from=SOURCE.sav [--output=FILE]'],
+    summary: "Replace the party with another game's party, converting each Pokémon's layout",
+    flags: [...WRITE_FLAGS, '--from='],
+    reads: 'file',
+    run: runConvertPartyCommand,
+  },
+  normalize: {
+    usage: ['[savefile.sav] --container=128k|128k-rtc|64k [--dry-run] [--output=FILE]'],
+    summary: 'Convert a save to the file size and footer another emulator expects',
+    flags: WRITE_FLAGS,
+    reads: 'file',
+    run: runNormalizeCommand,
+  },
+  'set-stats': {
+    usage: ['[savefile.sav] [--party=N] [--evs=LIST] [--ivs=LIST] [--force] [--output=FILE]'],
+    summary: "Set a party Pokémon's EVs and/or IVs and recompute its stats",
+    flags: [...WRITE_FLAGS, '--party=', '--evs=', '--ivs=', '--force'],
+    reads: 'file',
+    run: runSetStatsCommand,
+  },
+  'push-party': {
+    usage: ['[savefile.sav] [--ws-url=URL]'],
+    summary: "Write a save file's party into the game running in mGBA",
+    flags: ['--ws-url='],
+    reads: 'file',
+    run: runPushPartyCommand,
+  },
+  anonymize: {
+    usage: ['[savefile.sav] [--output=FILE]'],
+    summary: "Replace the player's and other trainers' names and IDs so the save can be shared",
+    flags: WRITE_FLAGS,
+    reads: 'file',
+    run: runAnonymizeCommand,
+  },
+  index: {
+    usage: [
+      '<add PATH... | list> [--index=FILE]',
+      'find [filters] [--index=FILE]',
+      '<stats | timeline> [--json] [--index=FILE]',
+    ],
+    summary: 'Keep a deduplicated SQLite catalog of a save library to search, total and replay it',
+    flags: ['--index=', ...JSON_FLAGS, ...FIND_FLAGS, '--output='],
+    reads: 'nothing',
+    run: runIndexCommand,
+  },
+  sqlite: {
+    usage: ['<PATH...> [--output=FILE.db|FILE.sql]'],
+    summary: 'Export saves into a SQLite database (or a .sql script) to query with SQL',
+    flags: ['--output='],
+    reads: 'nothing',
+    run: runSqliteCommand,
+  },
+  parquet: {
+    usage: ['<PATH...> [--output=FILE.parquet]'],
+    summary: 'Export every Pokémon in the saves as one Parquet table',
+    flags: ['--output='],
+    reads: 'nothing',
+    run: runParquetCommand,
+  },
+  completion: {
+    usage: ['<bash|zsh|fish|powershell>'],
+    summary: 'Print a shell completion script for commands, options and .sav files',
+    flags: [],
+    reads: 'nothing',
+    run: runCompletionCommand,
+  },
+}
+
+/**
+ * The command the arguments run: the first command name among them, the command after `edit`,
+ * or parse when none is given
+ */
+function findCommand(args: readonly string[]): string {
+  const index = args.findIndex(arg => Object.hasOwn(COMMANDS, arg))
+  if (index === -1) return 'parse'
+  const next = args[index + 1]
+  return args[index] === 'edit' && next !== undefined && EDIT_COMMANDS.includes(next)
+    ? next
+    : args[index]!
+}
+
+/**
+ * The first flag the command doesn't accept, so a typo like --dry-rn isn't silently ignored
+ */
+function unknownFlag(args: readonly string[], { flags }: Command): string | undefined {
+  const accepted = [...GLOBAL_FLAGS, ...flags]
+  return args.find(arg => {
+    if (!arg.startsWith('-') || arg === STDIN_PATH) return false
+    const equals = arg.indexOf('=')
+    return !accepted.includes(equals === -1 ? arg : arg.slice(0, equals + 1))
+  })
+}
+
+const OPTIONS_HELP = `Options:
+  --websocket           Connect to mGBA via WebSocket instead of reading a file
+  --ws-url=URL          WebSocket URL (default: ws://localhost:7102/ws)
+  --game=NAME           Use this game config instead of detecting it (quetzal, vanilla)
+  --config=FILE         Read default settings from FILE instead of ~/.config/pokemon-save-parser/config.yaml
+  --watch               Re-parse and update the display whenever the save changes (--json: one line per change)
+  --interval=MS         Polling interval in milliseconds behind file change events in watch mode (default: 1000)
+  --post=URL            In file watch mode, POST each updated --json document to URL (trackers, overlays)
+  --debug               Show raw bytes for each party Pokémon after the summary table
+  --graph               Show colored hex/field graph for each party Pokémon (instead of summary table)
+  --no-color            Plain text tables and warnings (also when NO_COLOR is set or output isn't a terminal)
+  --json                Print parsed save data (including Pokémon origin data) as JSON
+  --pretty, --compact   Indented (default) or one-line JSON; keys are sorted either way so output diffs cleanly
+  --query=EXPR          Print part of the --json document: party_pokemon[*].nickname, boxes[*][?is_shiny], party_pokemon[?level >= 50]
+  --msgpack=FILE        Write the --json document as MessagePack (binary, no JSON string to parse)
+  --ndjson              Parse every save under the given files/directories/globs, one --json line each as it finishes
+  --csv                 Like --ndjson, but one CSV summary row per save (game, trainer, play time, party)
+  --jobs=N              Worker threads parsing --ndjson/--csv batches (default: CPU count)
+  --output=FILE         Write the result to FILE instead of stdout or the command's default file (.json, .md, .sav, … added if it has no extension); --out= is an old spelling
+  --output-dir=DIR      Write each save of an --ndjson/--csv batch to its own .json or .csv file in DIR
+  --toBytes=STRING      Convert a string to GBA byte encoding and print the result
+  --toString=HEX        Convert a space/comma-separated hex byte string to a decoded GBA string
+  --trainer-card=FILE   Write a versioned trainer card snapshot (trainer, badges, party) as JSON
+  --verify-stats        Warn about party Pokémon whose stored stats don't match recomputed values
+  --legality            Check party Pokémon for data the game can't produce (also in --json output)
+  --bag                 Show bag contents by pocket
+  --boxes               Show PC box contents, names and wallpapers (also added to --json output)
+  --slots               Compare both save slots (also added to --json output)
+  --format=showdown     Print the party as a Pokémon Showdown paste
+  --format=html         Standalone HTML report (party cards, stat bars, Pokédex progress); --output=FILE to save it
+  --format=markdown     Print the party as a markdown table (shiny markers, Hidden Power)
+  --format=xlsx         Excel workbook with Party, Boxes, Items and Pokédex sheets; --output=FILE.xlsx
+  --format=hexdump      Annotated hexdump: sectors, footers, SaveBlock fields, Pokémon substructures
+  --format=pokepaste    Print the party as pokepast.es text; --upload to share it (--title=, --author=, --notes=)
+  --container=FORMAT    Write edited saves as 128k, 128k-rtc (mGBA clock footer) or 64k
+  --dry-run             List the bytes an edit would change instead of writing the save
+  --no-backup           Don't keep a timestamped .bak copy of a save that gets overwritten
+
+Find Filters:
+  --species=ID|NAME     National Dex number or species name
+  --min-level=N         Minimum level
+  --max-level=N         Maximum level
+  --shiny, --not-shiny  Only shiny / only non-shiny Pokémon
+  --ot=NAME|ID          Original trainer name or 5-digit trainer ID
+  --move=ID|NAME        Must know this move
+  --min-iv=N            Every IV must be at least N`
+
+// Without the leading `tsx cli.ts `; a command's --help shows the examples starting with its name
+const EXAMPLES = [
+  'mysave.sav --debug',
+  'mysave.sav --graph --watch',
+  'mysave.sav --watch --post=http://localhost:3000/party',
+  '--websocket --watch --interval=2000',
+  '--websocket --debug',
+  'mysave.sav --trainer-card=card.json',
+  'mysave.sav --boxes',
+  'mysave.sav --format=html --output=report.html',
+  'mysave.sav --format=markdown',
+  'mysave.sav --format=xlsx --output=collection.xlsx',
+  'mysave.sav --format=hexdump | less',
+  'mysave.sav --format=pokepaste --upload --title="Elite Four run"',
+  'mysave.sav --msgpack=save.msgpack',
+  'parse mysave.sav --json',
+  'verify mysave.sav --json',
+  'edit heal mysave.sav --dry-run',
+  'find mysave.sav --species=treecko --min-iv=20',
+  'export mysave.sav --output=pk3/',
+  'render mysave.sav --output=team.png',
+  'tui mysave.sav',
+  'qr mysave.sav --party=1',
+  'coverage mysave.sav',
+  'diff yesterday.sav mysave.sav --json',
+  'mysave.sav --json --compact > save.json',
+  'mysave.sav --format=markdown --output=team',
+  "mysave.sav --query='boxes[*][?is_shiny].species_name'",
+  '- --json < mysave.sav',
+  'import mysave.sav treecko.pk3 --box=1 --slot=1',
+  'release mysave.sav --box=1 --slot=3',
+  'organize mysave.sav --box=1 --sort=level --desc',
+  'organize mysave.sav --move=1:3,2:7 --to=5',
+  'rename mysave.sav --preset=strip-trade-nicknames --dry-run',
+  'rename mysave.sav --nickname="{species}" --owner=own',
+  'give-item mysave.sav --item="Rare Candy" --quantity=99 --output=edited.sav',
+  'heal mysave.sav',
+  'heal mysave.sav --dry-run',
+  'heal mysave.sav --output=mysave.sav',
+  'make-shiny mysave.sav --party=2',
+  'repair corrupted.sav --output=fixed.sav',
+  'rollback mysave.sav',
+  'convert-party emerald.sav --from=quetzal.sav',
+  'normalize mysave.sav --container=128k-rtc',
+  'set-stats mysave.sav --party=1 --evs=4,252,0,252,0,0 --ivs=31,31,31,31,31,31',
+  'push-party mysave-healed.sav',
+  'anonymize mysave.sav',
+  'index add saves/',
+  'index find --species=treecko --shiny',
+  'index timeline',
+  'sqlite saves/ --output=collection.db',
+  'parquet saves/ --output=collection.parquet',
+  'completion bash >> ~/.bashrc',
+  "saves/ --ndjson | jq -c '{path, player_name}'",
+  "'saves/**/*.sav' --csv --jobs=8 > saves.csv",
+  'saves/ --ndjson --output-dir=parsed/',
+  '--toBytes=PIKACHU',
+  '--toString="50 49 4b 41 43 48 55 00"',
+]
+
+const example = (args: string) => `  tsx cli.ts ${args}`
+
+function usageText(): string {
+  const forms = [
+    '[savefile.sav] [options]',
+    ...Object.entries(COMMANDS).flatMap(([name, { usage }]) => usage.map(args => `${name} ${args}`)),
+    '<PATH...> --ndjson|--csv [--jobs=N] [--boxes] [--legality]',
+  ]
+  return `
+Usage: ${forms.map(form => `tsx cli.ts ${form}`).join('\n       ')}
+       tsx cli.ts <command> --help
+
+${OPTIONS_HELP}
+
+Examples:
+${EXAMPLES.map(example).join('\n')}
+
+WebSocket Mode:
+  Requires mGBA Docker container to be running with WebSocket API enabled.
+`
+}
+
+function commandHelpText(name: string, { usage, summary, flags }: Command): string {
+  const lines = [
+    `Usage: ${usage.map(args => `tsx cli.ts ${name} ${args}`).join('\n       ')}`,
+    '',
+    summary,
+  ]
+  // The default command's options are the ones described in the full usage
+  if (name === 'parse') lines.push('', OPTIONS_HELP)
+  else if (flags.length > 0) lines.push('', `Options: ${flags.join(' ')}`)
+  const examples = EXAMPLES.filter(args => args.startsWith(`${name} `))
+  if (examples.length > 0) lines.push('', 'Examples:', ...examples.map(example))
+  return lines.join('\n')
+}
+
+// Option values offered by shell completion beyond the choices spelled out in the help text
+const COMPLETION_VALUES: Readonly<Record<string, readonly string[]>> = {
+  '--format': OUTPUT_FORMATS,
+  '--container': SAVE_CONTAINERS,
+  '--sort': BOX_SORT_KEYS,
+  '--preset': Object.keys(RENAME_PRESETS),
+}
+
+// Words completed right after a command
+const COMPLETION_ARGUMENTS: Readonly<Record<string, readonly string[]>> = {
+  edit: EDIT_COMMANDS,
+  index: ['add', 'list', 'find', 'stats', 'timeline'],
+  completion: COMPLETION_SHELLS,
+}
+
+/**
+ * The commands and options for shell completion, read from COMMANDS and the help text: every
+ * command's flags and every `--option` the help mentions, with lowercase values such as
+ * `--format=html` or `--owner=all|own` as its choices
+ */
+function completionSpec(): CompletionSpec {
+  const text = [OPTIONS_HELP, ...Object.values(COMMANDS).flatMap(({ usage }) => usage)].join('\n')
+  const options = new Set(
+    [...GLOBAL_FLAGS, ...Object.values(COMMANDS).flatMap(({ flags }) => flags)].filter(flag =>
+      flag.startsWith('--')
+    )
+  )
+  const values = new Map<string, Set<string>>()
+  for (const [, name, equals, value] of text.matchAll(/(--[A-Za-z][\w-]*)(=?)([\w.|-]*)/g)) {
+    options.add(`${name}${equals}`)
+    if (!equals || !/^[a-z0-9]/.test(value!)) continue
+    const choices = values.get(name!) ?? new Set()
+    for (const choice of value!.split('|')) choices.add(choice)
+    values.set(name!, choices)
+  }
+  for (const [name, choices] of Object.entries(COMPLETION_VALUES)) {
+    values.set(name, new Set(choices))
+  }
+  values.set('--game', new Set(gameConfigsByName().keys()))
+  return {
+    program: 'pokemon-save-parser',
+    commands: Object.entries(COMMANDS).map(([name, { summary }]) => ({
+      name,
+      summary,
+      arguments: COMPLETION_ARGUMENTS[name],
+    })),
+    options: [...options].sort(),
+    values: Object.fromEntries([...values].map(([name, choices]) => [name, [...choices]])),
+  }
+}
+
+function runCompletionCommand(argv: readonly string[]) {
+  const shell = argv.find((arg): arg is CompletionShell =>
+    COMPLETION_SHELLS.includes(arg as CompletionShell)
+  )
+  if (!shell) {
+    console.error(`❌ completion needs a shell: ${COMPLETION_SHELLS.join(', ')}`)
+    process.exit(1)
+  }
+  process.stdout.write(completionScript(shell, completionSpec()))
+}
+
+/**
+ * The config file's settings: --config=FILE, or the default file when there is one
+ */
+function loadCliConfig(argv: readonly string[]): CliConfig {
+  const configArg = flagValue(argv, 'config')
+  const configPath = configArg ?? cliConfigPath(process.env, os.homedir())
+  if (configArg === undefined && !fs.existsSync(configPath)) return {}
+  try {
+    return parseCliConfig(fs.readFileSync(configPath, 'utf8'))
+  } catch (error) {
+    console.error(`❌ ${configPath}: ${error instanceof Error ? error.message : 'unreadable'}`)
+    process.exit(1)
+  }
+}
+
+// Flags that pick what the default command prints; the config file's format only applies without
+// any of them
+const OUTPUT_FLAGS = ['--format=', '--json', '--query=', '--graph', '--watch', '--ndjson', '--csv']
+
+/**
+ * The arguments with the config file's settings added as flags where the flag wasn't given, and
+ * save names missing from the current directory looked up in save_dir
+ */
+function withConfigDefaults(argv: readonly string[], command: string, config: CliConfig): string[] {
+  const given = (flag: string) => argv.some(arg => arg.startsWith(flag))
+  const saveDir = config.save_dir?.replace(/^~(?=$|[\\/])/, os.homedir())
+  const args = argv.map(arg => {
+    if (!saveDir || arg.startsWith('-') || !/\.sav$/i.test(arg) || fs.existsSync(arg)) return arg
+    const inSaveDir = path.resolve(saveDir, arg)
+    return fs.existsSync(inSaveDir) ? inSaveDir : arg
+  })
+  if (config.game && !given('--game=')) args.push(`--game=${config.game}`)
+  // Only for the commands that talk to mGBA
+  const usesWebSocket = COMMANDS[command]!.flags.includes('--ws-url=')
+  if (config.port !== undefined && usesWebSocket && !given('--ws-url=')) {
+    args.push(`--ws-url=ws://localhost:${config.port}/ws`)
+  }
+  // parseCliConfig already checked the format against OUTPUT_FORMATS
+  if (config.format && command === 'parse' && !OUTPUT_FLAGS.some(given)) {
+    args.push(config.format === 'json' ? '--json' : `--format=${config.format}`)
+  }
+  return args
+}
+
+/**
+ * The save file named in the arguments: `-` for stdin, or the first existing .sav
+ */
+function findSavePath(argv: readonly string[]): string {
+  const savePath = argv
+    .slice(2)
+    .find(arg => arg === STDIN_PATH || (/\.sav$/i.test(arg) && fs.existsSync(path.resolve(arg))))
+  if (savePath === STDIN_PATH && tty.isatty(0)) {
+    console.error('❌ No save on stdin for -: pipe one in, e.g. cat mysave.sav | tsx cli.ts -')
+    process.exit(1)
+  }
+  if (savePath === STDIN_PATH && argv.includes('--watch')) {
+    console.error('❌ --watch needs a save file; stdin can only be read once')
+    process.exit(1)
+  }
+  if (!savePath) {
+    console.error(usageText())
+    process.exit(1)
+  }
+  return savePath
+}
+
+/**
+ * Connect to the mGBA WebSocket server for --websocket
+ */
+async function connectWebSocket(argv: readonly string[]): Promise<MgbaWebSocketClient> {
+  const wsUrl = flagValue(argv, 'ws-url')
+  const url = wsUrl ?? 'ws://localhost:7102/ws'
+  console.log(`🔌 Connecting to mGBA WebSocket at ${url}...`)
+  const client = new MgbaWebSocketClient(url)
+  try {
+    await client.connect()
+  } catch (error) {
+    console.error(
+      '❌ Failed to connect to mGBA WebSocket:',
+      error instanceof Error ? error.message : 'Unknown error'
+    )
+    process.exit(1)
+  }
+  console.log('✅ Connected successfully!')
+  // Setup cleanup on exit
+  process.on('SIGINT', () => {
+    client.disconnect()
+    process.exit(0)
+  })
+  return client
+}
+
+// Old spellings of --output=, still accepted
+const DEPRECATED_OUTPUT_FLAGS = ['--out=', '--svg=']
+
+/** The arguments with deprecated output flags renamed to --output=, warning on stderr */
+function withOutputAliases(argv: readonly string[]): string[] {
+  return argv.map(arg => {
+    const alias = DEPRECATED_OUTPUT_FLAGS.find(flag => arg.startsWith(flag))
+    if (alias === undefined) return arg
+    console.error(`⚠️  ${alias.slice(0, -1)} is deprecated; use --output=`)
+    return `--output=${arg.slice(alias.length)}`
+  })
+}
+
+// CLI entry point
+async function main() {
+  const commandLine = withOutputAliases(process.argv)
+  const name = findCommand(commandLine.slice(2))
+  const command = COMMANDS[name]!
+
+  if (commandLine.includes('--help') || commandLine.includes('-h') || commandLine[2] === 'help') {
+    const named = commandLine.slice(2).some(arg => Object.hasOwn(COMMANDS, arg))
+    console.log(named ? commandHelpText(name, command) : usageText())
+    process.exit(0)
+  }
+
+  // Every flag has to belong to the command, so a typo or a flag of another command is an error
+  const unknown = unknownFlag(commandLine.slice(2), command)
+  if (unknown !== undefined) {
+    const option = unknown.split('=')[0]
+    console.error(`❌ Unknown option ${option} for ${name} (see tsx cli.ts ${name} --help)`)
+    process.exit(1)
+  }
+
+  // Settings from the config file stand in for flags that weren't given
+  const argv = withConfigDefaults(commandLine, name, loadCliConfig(commandLine))
+
+  // Use a game config instead of detecting one
+  const game = flagValue(argv, 'game')
+  if (game !== undefined) {
+    const games = gameConfigsByName()
+    const ForcedConfig = games.get(game.toLowerCase())
+    if (!ForcedConfig) {
+      console.error(`❌ Unknown game "${game}" (expected ${[...games.keys()].join(', ')})`)
+      process.exit(1)
+    }
+    gameOverride = () => new ForcedConfig()
+  }
+
+  // Check the query before reading the save, so a typo isn't reported as a parse failure
+  const query = flagValue(argv, 'query')
+  if (query !== undefined) {
+    try {
+      evaluateQuery(query, {})
+    } catch (error) {
+      console.error(`❌ ${error instanceof Error ? error.message : 'Invalid query'}`)
+      process.exit(1)
+    }
+  }
+
+  // Results written to files instead of stdout: one for a save or a batch, or one per save in a
+  // directory for a batch
+  const json = argv.includes('--json') || query !== undefined
+  const format = flagValue(argv, 'format')
+  const output = flagValue(argv, 'output')
+  resultFile = output || undefined
+  const batch = argv.includes('--ndjson') || argv.includes('--csv')
+  const outputDir = argv.some(arg => arg.startsWith('--output-dir='))
+  if (outputDir && (!batch || resultFile !== undefined)) {
+    console.error('❌ --output-dir is for --ndjson/--csv batches; use --output=FILE for one file')
+    process.exit(1)
+  }
+  // Every other command taking --output writes its whole result there; parse and verify only
+  // write a document, not their summary tables
+  const printsTables = name === 'parse' ? !json && format === undefined : name === 'verify' && !json
+  if (resultFile !== undefined && !batch && (argv.includes('--watch') || printsTables)) {
+    console.error('❌ --output needs --json or --format= here, and no --watch')
+    process.exit(1)
+  }
+
+  // Utility string conversion functions
+  const toBytes = flagValue(argv, 'toBytes')
+  if (toBytes !== undefined) {
+    const bytes = gbaStringToBytes(toBytes, toBytes.length + 1) // +1 for null terminator
+    console.log(`GBA bytes for "${toBytes}":`)
+    console.log([...bytes].map(b => b.toString(16).padStart(2, '0')).join(' '))
+    process.exit(0)
+  }
+
+  const hexStr = flagValue(argv, 'toString')
+  if (hexStr !== undefined) {
+    // Accepts space or comma separated hex bytes
+    const bytes = new Uint8Array(
+      hexStr
+        .trim()
+        .split(/\s+|,/)
+        .filter(Boolean)
+        .map(b => parseInt(b, 16))
+    )
+    const str = bytesToGbaString(bytes)
+    console.log(
+      `String for bytes [${[...bytes].map(b => b.toString(16).padStart(2, '0')).join(' ')}]:`
+    )
+    console.log(str)
+    process.exit(0)
+  }
+
+  // NDJSON/CSV batches take many files instead of one save
+  if (batch) {
+    await runBatchCommand(argv)
+    return
+  }
+
+  if (command.reads === 'nothing') {
+    try {
+      await command.run(argv)
+    } catch (err) {
+      console.error(`❌ ${err instanceof Error ? err.message : 'Unknown error'}`)
+      process.exit(1)
+    }
+    return
+  }
+
+  // Commands reading one save get the file, or with --websocket the game running in mGBA
+  const client = argv.includes('--websocket') ? await connectWebSocket(argv) : undefined
+  try {
+    if (command.reads === 'file') await command.run(findSavePath(argv), argv)
+    else await command.run(client ?? findSavePath(argv), argv)
+  } catch (err) {
+    console.error(
+      '❌ Failed to parse save data:',
+      err instanceof Error ? err.message : 'Unknown error'
+    )
+    process.exitCode = 1
+  } finally {
+    // Cleanup WebSocket if used
+    client?.disconnect()
   }
 }
 
@@ -139,7 +139,7 @@ export abstract class GameConfigBase {
   protected parsePokemonForDetection(
     saveblock1Data: Uint8Array,
     pokemonSize: number,
-    getSpeciesId: (data: Uint8Array, view: DataView) => number
+    isValidSpecies: (data: Uint8Array, view: DataView) => boolean
   ): number {
     let pokemonFound = 0
 
@@ -153,10 +153,9 @@ export abstract class GameConfigBase {
 
       try {
         const view = new DataView(data.buffer, data.byteOffset, data.byteLength)
-        const speciesId = getSpeciesId(data, view)
 
-        if (speciesId > 0 && speciesId < 1000) {
-          // Reasonable species ID range
+        if (isValidSpecies(data, view)) {
+          // Species exists in the config's species list
           pokemonFound++
         } else {
           break // Invalid or empty slot, stop looking
@@ -6,11 +6,51 @@
 import {
   VANILLA_POKEMON_OFFSETS,
   VANILLA_SAVE_LAYOUT,
+  VANILLA_SHINY_THRESHOLD,
   type GameConfig,
   type MoveData,
+  type NatureEffect,
+  type PokemonContestStats,
+  type PokemonGender,
+  type PokemonMarkings,
   type PokemonMoves,
+  type PokemonOrigin,
+  type ShinyInfo,
 } from './types'
-import { bytesToGbaString, natureEffects, natures, statStrings } from './utils'
+import {
+  bytesToGbaString,
+  gbaStringToBytes,
+  getCharacteristic,
+  getHiddenPower,
+  MAX_IV,
+  MAX_TOTAL_EV,
+  natureEffects,
+  natures,
+  originGames,
+  pokeballNames,
+  statNames,
+  statStrings,
+} from './utils'
+import { getMappingIndex, toRawId } from './mappingIndex'
+import { getMaxMovePP } from './moves'
+import {
+  MAX_LEVEL,
+  SPECIES_DEOXYS,
+  SPECIES_UNOWN,
+  calculateStats,
+  experienceForLevel,
+  getGenderFromPersonality,
+  getSpeciesAbility,
+  getSpeciesInfo,
+  getUnownForm,
+  levelForExperience,
+  type GrowthRate,
+  type PokemonType,
+} from './species'
+
+const MAIL_NONE = 0xff
+// Gen 3 keeps each EV in a byte; later games cap them at 252
+const MAX_STORED_EV = 255
 
 /**
  * Pokemon data class with vanilla Pokemon Emerald as the baseline
@@ -39,6 +79,154 @@ export class PokemonBase {
     this.config = config
   }
 
+  /**
+   * Build a Pokemon from the PC box format: the party format without the trailing battle data.
+   * Level, stats and HP are recomputed the way the game does when the Pokemon is withdrawn
+   */
+  static fromBoxData(boxData: Uint8Array, config: GameConfig): PokemonBase {
+    const data = new Uint8Array(config.pokemonSize)
+    data.set(boxData.subarray(0, config.saveLayout.boxPokemonSize))
+    const pokemon = new PokemonBase(data, config)
+    pokemon.restoreBattleData()
+    return pokemon
+  }
+
+  /**
+   * Build a Pokemon from a .pk3 file (80-byte box or 100-byte party), re-encrypting and shuffling
+   * the substructures with the Pokemon's own key. The stored checksum is kept, so a tampered file
+   * still reads as a Bad Egg; box files get their battle data recomputed like fromBoxData
+   */
+  static fromPK3(pk3: Uint8Array, config: GameConfig): PokemonBase {
+    const { boxPokemonSize } = config.saveLayout
+    PokemonBase.assertPKHeXFile(pk3, config, 'pk3')
+
+    const data = new Uint8Array(100)
+    data.set(pk3)
+    const pokemon = new PokemonBase(data, config)
+    const key = pokemon.getEncryptionKey(data)
+    const order = pokemon.getSubstructOrder(pokemon.personality)
+    const source = new DataView(pk3.buffer, pk3.byteOffset, pk3.byteLength)
+    for (let i = 0; i < 4; i++) {
+      for (let j = 0; j < 12; j += 4) {
+        const word = source.getUint32(0x20 + i * 12 + j, true)
+        pokemon.view.setUint32(0x20 + order[i]! * 12 + j, (word ^ key) >>> 0, true)
+      }
+    }
+    if (pk3.length === boxPokemonSize) pokemon.restoreBattleData()
+    return pokemon
+  }
+
+  /**
+   * Build a Pokemon from a PKHeX .ek3 file: the encrypted bytes exactly as the save stores them
+   */
+  static fromEK3(ek3: Uint8Array, config: GameConfig): PokemonBase {
+    PokemonBase.assertPKHeXFile(ek3, config, 'ek3')
+    if (ek3.length === config.saveLayout.boxPokemonSize) return PokemonBase.fromBoxData(ek3, config)
+    return new PokemonBase(new Uint8Array(ek3), config)
+  }
+
+  private static assertPKHeXFile(file: Uint8Array, config: GameConfig, extension: string): void {
+    const { boxPokemonSize } = config.saveLayout
+    if (config.getSubstruct || config.pokemonSize !== 100) {
+      throw new Error(`.${extension} files need the vanilla Pokemon layout (${config.name})`)
+    }
+    if (file.length !== boxPokemonSize && file.length !== 100) {
+      throw new Error(`A .${extension} file is ${boxPokemonSize} or 100 bytes, got ${file.length}`)
+    }
+  }
+
+  /**
+   * The PC box format of this Pokemon: the first 80 bytes, without mail, status and battle stats
+   */
+  toBoxData(): Uint8Array {
+    return this.data.slice(0, this.saveLayout.boxPokemonSize)
+  }
+
+  /**
+   * Copy this Pokemon through the box format, as the game does on deposit and withdrawal:
+   * party-only data is dropped, and level, stats and full HP are recomputed
+   */
+  toBoxPokemon(): PokemonBase {
+    return PokemonBase.fromBoxData(this.data, this.config)
+  }
+
+  /**
+   * Export as a PKHeX-compatible .pk3: the same layout with the four substructures decrypted and
+   * in Growth/Attacks/EVs/Misc order. 'party' keeps the 100-byte format, 'box' the 80-byte one
+   */
+  exportPK3(format: 'party' | 'box' = 'party'): Uint8Array {
+    const pk3 = this.exportEK3(format)
+    for (let i = 0; i < 4; i++) {
+      // The data checksum is a sum over the decrypted words, so it stays valid as is
+      pk3.set(this.getDecryptedSubstruct(this.data, i), 0x20 + i * 12)
+    }
+    return pk3
+  }
+
+  /**
+   * Export as a PKHeX .ek3: the encrypted, shuffled bytes as stored in the save (100 or 80 bytes)
+   */
+  exportEK3(format: 'party' | 'box' = 'party'): Uint8Array {
+    if (this.config.getSubstruct || this.config.pokemonSize !== 100) {
+      throw new Error(`PKHeX export needs the vanilla Pokemon layout (${this.config.name})`)
+    }
+    return this.data.slice(0, format === 'box' ? this.saveLayout.boxPokemonSize : 100)
+  }
+
+  /**
+   * Convert to another game's Pokemon layout, e.g. between vanilla Emerald's encrypted 100-byte
+   * format and Quetzal's unencrypted 104-byte one. Species, item and moves are remapped through
+   * their external IDs and the nature is kept; stats are recomputed when the target has Gen 3
+   * species data. Throws when the target game has no equivalent species, item or move
+   */
+  convertTo(target: GameConfig): PokemonBase {
+    const remap = (kind: 'pokemon' | 'items' | 'moves', externalId: number) => {
+      const mapping = target.mappings?.[kind]
+      if (externalId === 0 || !mapping) return externalId
+      const raw = getMappingIndex(mapping).rawByExternalId.get(externalId)
+      if (raw === undefined) throw new Error(`${target.name} has no ${kind} ID ${externalId}`)
+      return raw
+    }
+
+    const substructs = [0, 1, 2, 3].map(i => this.readSubstruct(i).slice())
+    const growth = new DataView(substructs[0]!.buffer)
+    growth.setUint16(0, remap('pokemon', this.speciesId), true)
+    growth.setUint16(2, remap('items', this.item), true)
+    const attacks = new DataView(substructs[1]!.buffer)
+    this.moveIds.forEach((moveId, i) => attacks.setUint16(i * 2, remap('moves', moveId), true))
+
+    const data = new Uint8Array(target.pokemonSize)
+    // PID, OT ID, names, language, flags and markings share the first 28 bytes in every layout
+    data.set(this.data.subarray(0, this.offsets.checksum))
+    const pokemon = new PokemonBase(data, target)
+    const { offsets } = pokemon
+    pokemon.view.setUint8(offsets.status, this.status)
+    pokemon.view.setUint8(offsets.level, this.level)
+    // Mail lives in the source save's SaveBlock1, so it doesn't travel with the Pokemon
+    pokemon.view.setUint8(offsets.mail, MAIL_NONE)
+    pokemon.view.setUint16(offsets.currentHp, this.currentHp, true)
+    pokemon.stats = this.stats
+    substructs.forEach((substruct, i) => {
+      if (target.setSubstruct) target.setSubstruct(data, pokemon.view, i, substruct)
+      else pokemon.setEncryptedSubstruct(i, substruct)
+    })
+
+    pokemon.natureRaw = this.natureRaw
+    pokemon.recalculateStats()
+    return pokemon
+  }
+
+  private restoreBattleData(): void {
+    this.view.setUint8(this.offsets.mail, MAIL_NONE)
+    const rate = this.growthRate
+    if (!rate) return
+    this.view.setUint8(this.offsets.level, levelForExperience(rate, this.experience))
+    const stats = this.expectedStats
+    if (!stats) return
+    this.stats = stats
+    this.view.setUint16(this.offsets.currentHp, stats[0]!, true)
+  }
+
   // Basic unencrypted properties (common to all games)
   get personality() {
     return this.view.getUint32(this.offsets.personality, true)
@@ -55,6 +243,17 @@ export class PokemonBase {
   get level() {
     return this.view.getUint8(this.offsets.level)
   }
+  get mailId(): number | undefined {
+    // Party-only index into the SaveBlock1 mail slots
+    const mail = this.view.getUint8(this.offsets.mail)
+    return mail === MAIL_NONE ? undefined : mail
+  }
+  get markingsRaw() {
+    return this.view.getUint8(this.offsets.markings)
+  }
+  set markingsRaw(value) {
+    this.view.setUint8(this.offsets.markings, value & 0x0f)
+  }
   get maxHp() {
     return this.view.getUint16(this.offsets.maxHp, true)
   }
@@ -168,6 +367,9 @@ export class PokemonBase {
       const origView = new DataView(this.data.buffer, this.data.byteOffset + substructOffset + i, 4)
       origView.setUint32(0, encrypted, true)
     }
+
+    // Keep the data checksum in sync so the game doesn't turn the edit into a Bad Egg
+    this.view.setUint16(this.offsets.checksum, this.computedChecksum, true)
   }
 
   protected getDecryptedSubstruct(data: Uint8Array, substructIndex: number): Uint8Array {
@@ -192,16 +394,29 @@ export class PokemonBase {
     return decryptedData
   }
 
-  // Game-specific data access with config overrides or vanilla defaults
-  get speciesId() {
-    if (this.config.getSpeciesId) {
-      const rawSpecies = this.config.getSpeciesId(this.data, this.view)
-      return this.config.mappings?.pokemon?.get(rawSpecies)?.id ?? rawSpecies
+  /**
+   * Read a decrypted substruct, letting configs with unencrypted layouts supply the bytes directly
+   */
+  protected readSubstruct(substructIndex: number): Uint8Array {
+    if (this.config.getSubstruct) {
+      return this.config.getSubstruct(this.data, this.view, substructIndex)
     }
+    return this.getDecryptedSubstruct(this.data, substructIndex)
+  }
+
+  // Game-specific data access with config overrides or vanilla defaults
+  get internalSpeciesId() {
+    // Species index as stored in the save; Gen 3 orders Hoenn species differently from the dex
+    if (this.config.getSpeciesId) return this.config.getSpeciesId(this.data, this.view)
     // Vanilla: species ID is first 2 bytes of decrypted substruct 0
     const substruct0 = this.getDecryptedSubstruct(this.data, 0)
     const subView = new DataView(substruct0.buffer, substruct0.byteOffset, substruct0.byteLength)
-    const rawSpecies = subView.getUint16(0, true)
+    return subView.getUint16(0, true)
+  }
+
+  get speciesId() {
+    // National Dex number, translated through the config's species mapping
+    const rawSpecies = this.internalSpeciesId
     return this.config.mappings?.pokemon?.get(rawSpecies)?.id ?? rawSpecies
   }
 
@@ -209,12 +424,8 @@ export class PokemonBase {
     if (this.config.getPokemonName) {
       return this.config.getPokemonName(this.data, this.view)
     }
-    // Vanilla: species ID is first 2 bytes of decrypted substruct 0
-    const substruct0 = this.getDecryptedSubstruct(this.data, 0)
-    const subView = new DataView(substruct0.buffer, substruct0.byteOffset, substruct0.byteLength)
-    const rawSpecies = subView.getUint16(0, true)
     // Access optional mapping safely; return undefined if not present
-    return this.config.mappings?.pokemon?.get(rawSpecies)?.id_name
+    return this.config.mappings?.pokemon?.get(this.internalSpeciesId)?.id_name
   }
 
   get item() {
@@ -250,18 +461,8 @@ export class PokemonBase {
     // Vanilla: write to bytes 2-3 of decrypted substruct 0
     const substruct0 = this.getDecryptedSubstruct(this.data, 0)
     const subView = new DataView(substruct0.buffer, substruct0.byteOffset, substruct0.byteLength)
-    // Convert mapped (external) ID back to raw internal using mapping if available
-    let rawValue = mappedId
-    const items = this.config.mappings?.items
-    if (items) {
-      for (const [raw, entry] of items.entries()) {
-        if (entry.id === mappedId) {
-          rawValue = raw
-          break
-        }
-      }
-    }
-    subView.setUint16(2, rawValue, true)
+    // Convert mapped (external) ID back to raw internal using the cached reverse index
+    subView.setUint16(2, toRawId(this.config.mappings?.items, mappedId), true)
     this.setEncryptedSubstruct(0, substruct0)
   }
 
@@ -313,6 +514,28 @@ export class PokemonBase {
     return this.config.mappings?.moves?.get(rawMove)?.id ?? rawMove
   }
 
+  /**
+   * Replace the move in slot 0-3 (mapped move ID, 0 for none) and set its current PP
+   * Vanilla data is re-encrypted and its checksum updated so the edit survives a save round trip
+   */
+  setMove(index: number, moveId: number, pp: number): void {
+    if (index < 0 || index > 3) throw new Error('Move index must be between 0 and 3')
+    if (this.config.setMove && this.config.setPP) {
+      this.config.setMove(this.data, this.view, index, moveId)
+      this.config.setPP(this.data, this.view, index, pp)
+      return
+    }
+    if (this.config.getMove) {
+      throw new Error(`${this.config.name} does not support editing moves`)
+    }
+    // Vanilla: moves are bytes 0-7 and PP bytes 8-11 of decrypted substruct 1
+    const substruct1 = this.getDecryptedSubstruct(this.data, 1)
+    const subView = new DataView(substruct1.buffer, substruct1.byteOffset, substruct1.byteLength)
+    subView.setUint16(index * 2, toRawId(this.config.mappings?.moves, moveId), true)
+    substruct1[8 + index] = Math.max(0, Math.min(255, pp))
+    this.setEncryptedSubstruct(1, substruct1)
+  }
+
   get pp1() {
     if (this.config.getPP) return this.config.getPP(this.data, this.view, 0)
     // Vanilla: pp1 is byte 8 of decrypted substruct 1
@@ -444,6 +667,19 @@ export class PokemonBase {
     }
   }
 
+  get contestStats(): PokemonContestStats {
+    // Condition bytes follow the 6 EVs in the EV/condition substruct (2)
+    const substruct2 = this.readSubstruct(2)
+    return {
+      cool: substruct2[6]!,
+      beauty: substruct2[7]!,
+      cute: substruct2[8]!,
+      smart: substruct2[9]!,
+      tough: substruct2[10]!,
+      sheen: substruct2[11]!,
+    }
+  }
+
   get ivs(): readonly number[] {
     if (this.config.getIVs) return this.config.getIVs(this.data, this.view)
     // Vanilla: IVs are packed into bytes 4-7 of decrypted substruct 3
@@ -481,15 +717,14 @@ export class PokemonBase {
 
   get isShiny(): boolean {
     if (this.config.isShiny) return this.config.isShiny(this.personality, this.otId)
-    // Vanilla: shiny if shiny number < 8
-    const personality = this.view.getUint32(0x00, true)
-    const otId = this.view.getUint32(0x04, true)
-    const trainerId = otId & 0xffff
-    const secretId = (otId >> 16) & 0xffff
-    const personalityLow = personality & 0xffff
-    const personalityHigh = (personality >> 16) & 0xffff
-    const shinyNumber = trainerId ^ secretId ^ personalityLow ^ personalityHigh
-    return shinyNumber < 8
+    // Vanilla: shiny if shiny number is below the game's threshold
+    return this.shinyNumber < (this.shinyThreshold ?? VANILLA_SHINY_THRESHOLD)
+  }
+
+  get shinyThreshold(): number | undefined {
+    // Games that decide shininess themselves (e.g. a stored shiny byte) have no XOR threshold
+    if (this.config.isShiny) return undefined
+    return this.config.shinyThreshold ?? VANILLA_SHINY_THRESHOLD
   }
 
   get shinyNumber(): number {
@@ -509,10 +744,241 @@ export class PokemonBase {
     return false // Vanilla doesn't have radiant
   }
 
+  get shinyInfo(): ShinyInfo {
+    const threshold = this.shinyThreshold
+    return {
+      value: this.shinyNumber,
+      threshold,
+      odds: threshold ? Math.round(0x10000 / threshold) : undefined,
+      is_shiny: this.isShiny,
+      is_radiant: this.isRadiant,
+    }
+  }
+
+  /**
+   * Reroll the PID so the Pokemon is shiny for its original trainer, keeping its nature, gender,
+   * ability slot (PID parity) and Unown letter. Vanilla data is re-encrypted with the new key
+   */
+  makeShiny(): void {
+    if (this.isShiny) return
+    if (this.config.makeShiny) {
+      this.config.makeShiny(this.data, this.view)
+      return
+    }
+
+    const { personality, otId, nature, gender } = this
+    const info = getSpeciesInfo(this.speciesId)
+    const isUnown = this.speciesId === SPECIES_UNOWN
+    const keepsTraits = (pid: number) =>
+      (pid & 1) === (personality & 1) &&
+      (this.config.calculateNature?.(pid) ?? natures[pid % 25]) === nature &&
+      (!info || getGenderFromPersonality(info.gender_ratio, pid) === gender) &&
+      (!isUnown || getUnownForm(pid) === getUnownForm(personality))
+
+    // The PID is shiny when its halves XOR the trainer and secret IDs to below the threshold;
+    // start from the current low half so gender and ability bits usually stay untouched
+    const trainerBits = (otId & 0xffff) ^ (otId >>> 16)
+    const threshold = this.shinyThreshold ?? VANILLA_SHINY_THRESHOLD
+    for (let i = 0; i < 0x10000; i++) {
+      const low = (personality + i) & 0xffff
+      for (let shinyValue = 0; shinyValue < threshold; shinyValue++) {
+        const pid = (((trainerBits ^ low ^ shinyValue) << 16) | low) >>> 0
+        if (keepsTraits(pid)) {
+          this.setPersonality(pid)
+          return
+        }
+      }
+    }
+    throw new Error(`No shiny PID keeps the nature and gender of ${this.nickname}`)
+  }
+
+  // Substruct order and encryption key both depend on the PID, so the data is re-encrypted
+  private setPersonality(value: number): void {
+    const substructs = [0, 1, 2, 3].map(i => this.getDecryptedSubstruct(this.data, i))
+    this.view.setUint32(this.offsets.personality, value >>> 0, true)
+    substructs.forEach((substruct, i) => this.setEncryptedSubstruct(i, substruct))
+  }
+
   get rawBytes() {
     return new Uint8Array(this.data)
   }
 
+  get markings(): PokemonMarkings {
+    // Box markings: bit 0 circle, bit 1 square, bit 2 triangle, bit 3 heart
+    const raw = this.markingsRaw
+    return {
+      circle: (raw & 1) !== 0,
+      square: (raw & 2) !== 0,
+      triangle: (raw & 4) !== 0,
+      heart: (raw & 8) !== 0,
+    }
+  }
+
+  get gender(): PokemonGender | undefined {
+    // Species outside the embedded National Dex table (hack-only forms) have no known ratio
+    const info = getSpeciesInfo(this.speciesId)
+    if (!info) return undefined
+    return getGenderFromPersonality(info.gender_ratio, this.personality)
+  }
+
+  get form(): string | undefined {
+    // Suffix of the non-default form ("b", "attack", "mega"); undefined for the default form
+    if (this.config.getForm) return this.config.getForm(this.data, this.view)
+    if (this.speciesId === SPECIES_UNOWN) {
+      const letter = getUnownForm(this.personality)
+      return letter === 'a' ? undefined : letter
+    }
+    // Castform's weather forms only exist in battle, so a stored Castform is always normal
+    if (this.speciesId === SPECIES_DEOXYS) {
+      const deoxysForm = this.config.deoxysForm ?? 'normal'
+      return deoxysForm === 'normal' ? undefined : deoxysForm
+    }
+    return undefined
+  }
+
+  get formNameId(): string | undefined {
+    // Species name with the form suffix, matching sprite filenames ("unown-b", "deoxys-speed")
+    const { form, nameId } = this
+    if (!form || !nameId) return nameId
+    const baseName = this.speciesId === SPECIES_DEOXYS ? 'deoxys' : nameId
+    return `${baseName}-${form}`
+  }
+
+  get friendship(): number {
+    // Growth substruct (0) byte 9; doubles as the remaining egg cycles while still an egg
+    return this.readSubstruct(0)[9]!
+  }
+
+  get experience(): number {
+    // Growth substruct (0) bytes 4-7
+    const substruct0 = this.readSubstruct(0)
+    return new DataView(substruct0.buffer, substruct0.byteOffset, 12).getUint32(4, true)
+  }
+
+  get growthRate(): GrowthRate | undefined {
+    return getSpeciesInfo(this.speciesId)?.growth_rate
+  }
+
+  get expToNextLevel(): number | undefined {
+    const rate = this.growthRate
+    if (!rate) return undefined
+    if (this.level >= MAX_LEVEL) return 0
+    return Math.max(0, experienceForLevel(rate, this.level + 1) - this.experience)
+  }
+
+  get levelProgress(): number | undefined {
+    // Fraction of the way from the current level to the next, clamped for UI progress bars
+    const rate = this.growthRate
+    if (!rate) return undefined
+    if (this.level >= MAX_LEVEL) return 1
+    const start = experienceForLevel(rate, this.level)
+    const end = experienceForLevel(rate, this.level + 1)
+    return Math.max(0, Math.min(1, (this.experience - start) / (end - start)))
+  }
+
+  get isEgg(): boolean {
+    // Misc substruct (3) IV word bit 30, mirrored by bit 2 of the unencrypted flags byte
+    const substruct3 = this.readSubstruct(3)
+    const ivWord = new DataView(substruct3.buffer, substruct3.byteOffset, 12).getUint32(4, true)
+    return ((ivWord >>> 30) & 1) === 1 || (this.view.getUint8(this.offsets.flags) & 4) !== 0
+  }
+
+  get checksum(): number {
+    return this.view.getUint16(this.offsets.checksum, true)
+  }
+
+  get computedChecksum(): number {
+    // Sum of the decrypted substructs' 16-bit words, truncated to 16 bits
+    let sum = 0
+    for (let i = 0; i < 4; i++) {
+      const substruct = this.getDecryptedSubstruct(this.data, i)
+      const subView = new DataView(substruct.buffer, substruct.byteOffset, substruct.byteLength)
+      for (let j = 0; j < 12; j += 2) sum += subView.getUint16(j, true)
+    }
+    return sum & 0xffff
+  }
+
+  get isChecksumValid(): boolean | undefined {
+    // Unencrypted hack layouts (substruct override) don't store a data checksum
+    if (this.config.getSubstruct) return undefined
+    return this.checksum === this.computedChecksum
+  }
+
+  get isBadEgg(): boolean {
+    // Flags byte bit 0 is set by the game when it detects a checksum failure
+    return (this.view.getUint8(this.offsets.flags) & 1) !== 0 || this.isChecksumValid === false
+  }
+
+  get eggCycles(): number | undefined {
+    return this.isEgg ? this.friendship : undefined
+  }
+
+  get eggStepsRemaining(): number | undefined {
+    // Each egg cycle is 256 steps in Gen 3
+    const cycles = this.eggCycles
+    return cycles === undefined ? undefined : cycles * 256
+  }
+
+  // Origin data: misc substruct (3) byte 1 is the met location, bytes 2-3 the origins word
+  get metLocation(): number {
+    return this.readSubstruct(3)[1]!
+  }
+
+  get metLocationName(): string {
+    const location = this.metLocation
+    return this.config.mappings?.locations?.get(location)?.name ?? `Unknown (${location})`
+  }
+
+  private get originsWord(): number {
+    const substruct3 = this.readSubstruct(3)
+    return new DataView(substruct3.buffer, substruct3.byteOffset, 12).getUint16(2, true)
+  }
+
+  get metLevel(): number {
+    // Bits 0-6; 0 means the Pokemon hatched from an egg
+    return this.originsWord & 0x7f
+  }
+
+  get originGame(): number {
+    return (this.originsWord >> 7) & 0xf
+  }
+
+  get originGameName(): string {
+    return originGames[this.originGame] ?? 'Unknown'
+  }
+
+  get pokeball(): number {
+    return (this.originsWord >> 11) & 0xf
+  }
+
+  get pokeballName(): string {
+    return pokeballNames[this.pokeball] ?? 'Unknown'
+  }
+
+  get otGender(): 'male' | 'female' {
+    return (this.originsWord >> 15) & 1 ? 'female' : 'male'
+  }
+
+  get fatefulEncounter(): boolean {
+    // Misc substruct (3) ribbon word bit 31: set on event Pokemon, which only obey with it
+    const substruct3 = this.readSubstruct(3)
+    const ribbons = new DataView(substruct3.buffer, substruct3.byteOffset, 12).getUint32(8, true)
+    return ribbons >>> 31 === 1
+  }
+
+  get origin(): PokemonOrigin {
+    return {
+      met_location: this.metLocation,
+      met_location_name: this.metLocationName,
+      met_level: this.metLevel,
+      origin_game: this.originGame,
+      origin_game_name: this.originGameName,
+      pokeball: this.pokeball,
+      pokeball_name: this.pokeballName,
+      ot_gender: this.otGender,
+    }
+  }
+
   // Computed properties
   get otId_str(): string {
     return (this.otId & 0xffff).toString().padStart(5, '0')
@@ -522,10 +988,39 @@ export class PokemonBase {
     return bytesToGbaString(this.nicknameRaw)
   }
 
+  // The nickname sits outside the encrypted substructs, so the data checksum is unaffected
+  set nickname(value: string) {
+    this.nicknameRaw.set(gbaStringToBytes(value, this.offsets.nicknameLength))
+  }
+
   get otName(): string {
     return bytesToGbaString(this.otNameRaw)
   }
 
+  // Like the nickname, the OT name is stored unencrypted; the OT ID and gender are kept
+  set otName(value: string) {
+    this.otNameRaw.set(gbaStringToBytes(value, this.offsets.otNameLength))
+  }
+
+  /**
+   * Hand the Pokemon to another original trainer (name, 32-bit OT ID, gender)
+   * The OT ID is part of the encryption key, so every substruct is re-encrypted
+   */
+  setOriginalTrainer(name: string, otId: number, gender: 'male' | 'female'): void {
+    if (this.config.getSubstruct) {
+      throw new Error(`${this.config.name} does not support editing the original trainer`)
+    }
+
+    const substructs = [0, 1, 2, 3].map(i => this.getDecryptedSubstruct(this.data, i))
+    const misc = new DataView(substructs[3]!.buffer, substructs[3]!.byteOffset, 12)
+    const origins = misc.getUint16(2, true)
+    misc.setUint16(2, (origins & 0x7fff) | (gender === 'female' ? 0x8000 : 0), true)
+
+    this.view.setUint32(this.offsets.otId, otId >>> 0, true)
+    this.otNameRaw.set(gbaStringToBytes(name, this.offsets.otNameLength))
+    substructs.forEach((substruct, i) => this.setEncryptedSubstruct(i, substruct))
+  }
+
   get nature(): string {
     // Use config override or vanilla Gen 3 standard formula
     return this.config.calculateNature?.(this.personality) ?? natures[this.personality % 25]!
@@ -572,6 +1067,14 @@ export class PokemonBase {
     }
   }
 
+  get characteristic(): string {
+    return getCharacteristic(this.ivs, this.personality)
+  }
+
+  get hiddenPower(): { type: PokemonType; power: number } {
+    return getHiddenPower(this.ivs)
+  }
+
   get natureModifiers(): { increased: number; decreased: number } {
     // Neutral natures shouldn't modify any stats
     return natureEffects[this.nature] ?? { increased: -1, decreased: -1 }
@@ -585,6 +1088,14 @@ export class PokemonBase {
     }
   }
 
+  get natureEffect(): NatureEffect {
+    const { increased, decreased } = this.natureModifiers
+    return {
+      plus: statNames[increased] ?? null,
+      minus: statNames[decreased] ?? null,
+    }
+  }
+
   get natureModifiersArray(): readonly number[] {
     // usage for statsArray
     // Nature modifiers: [hp, atk, def, spe, spa, spd]
@@ -617,6 +1128,16 @@ export class PokemonBase {
     this.view.setUint8(statusOffset, next)
   }
 
+  get types(): readonly PokemonType[] | undefined {
+    if (this.config.usesGen3SpeciesData === false) return undefined
+    return getSpeciesInfo(this.speciesId)?.types
+  }
+
+  get ability(): string | undefined {
+    if (this.config.usesGen3SpeciesData === false) return undefined
+    return getSpeciesAbility(this.speciesId, this.abilityNumber)
+  }
+
   get stats(): readonly number[] {
     return [this.maxHp, this.attack, this.defense, this.speed, this.spAttack, this.spDefense]
   }
@@ -631,6 +1152,12 @@ export class PokemonBase {
     this.spDefense = values[5]!
   }
 
+  get expectedStats(): readonly number[] | undefined {
+    // Stats the game would compute from base stats, IVs, EVs, nature and level
+    if (this.config.usesGen3SpeciesData === false) return undefined
+    return calculateStats(this.speciesId, this.level, this.ivs, this.evs, this.nature, this.form)
+  }
+
   setStats(values: readonly number[]): void {
     this.stats = values
   }
@@ -700,6 +1227,26 @@ export class PokemonBase {
     return [this.pp1, this.pp2, this.pp3, this.pp4]
   }
 
+  get ppUps(): readonly number[] {
+    // Growth substruct (0) byte 8: two bits of PP Ups per move slot
+    const bonuses = this.readSubstruct(0)[8]!
+    return [0, 1, 2, 3].map(i => (bonuses >> (i * 2)) & 3)
+  }
+
+  /**
+   * Heal like a Pokemon Center: full HP, no status condition and full PP (Gen 3 base PP plus
+   * PP Ups). Moves without Gen 3 data keep their PP
+   */
+  heal(): void {
+    this.view.setUint16(this.offsets.currentHp, this.maxHp, true)
+    this.view.setUint8(this.offsets.status, 0)
+    const { ppUps, ppValues } = this
+    this.moveIds.forEach((moveId, i) => {
+      const maxPP = getMaxMovePP(moveId, ppUps[i])
+      if (maxPP !== undefined && maxPP !== ppValues[i]) this.setMove(i, moveId, maxPP)
+    })
+  }
+
   setEvByIndex(statIndex: number, value: number): void {
     switch (statIndex) {
       case 0:
@@ -734,4 +1281,87 @@ export class PokemonBase {
     currentIvs[statIndex] = clampedValue
     this.ivs = currentIvs
   }
+
+  /**
+   * Set EVs within the game's limits and recompute the stats. Each EV must be 0-255; a total over
+   * 510 or EVs on an egg can't be earned in game and throw unless force is set
+   */
+  editEvs(values: readonly number[], options: { force?: boolean } = {}): void {
+    if (values.length !== 6) throw new Error('EVs array must have 6 values')
+    if (values.some(ev => !Number.isInteger(ev) || ev < 0 || ev > MAX_STORED_EV)) {
+      throw new Error(`EVs must be between 0 and ${MAX_STORED_EV}, got ${values.join('/')}`)
+    }
+    const total = values.reduce((sum, ev) => sum + ev, 0)
+    if (!options.force && total > MAX_TOTAL_EV) {
+      throw new Error(`EV total ${total} is over the legal ${MAX_TOTAL_EV} (force to allow)`)
+    }
+    if (!options.force && total > 0 && this.isEgg) {
+      throw new Error('Eggs cannot have EVs (force to allow)')
+    }
+    this.evs = values
+    this.recalculateStats()
+  }
+
+  /**
+   * Set IVs (0-31 each) and recompute the stats
+   */
+  editIvs(values: readonly number[]): void {
+    if (values.length !== 6) throw new Error('IVs array must have 6 values')
+    if (values.some(iv => !Number.isInteger(iv) || iv < 0 || iv > MAX_IV)) {
+      throw new Error(`IVs must be between 0 and ${MAX_IV}, got ${values.join('/')}`)
+    }
+    this.ivs = values
+    this.recalculateStats()
+  }
+
+  // Mirrors the game's CalculateMonStats: current HP moves with max HP and fainted stays fainted
+  private recalculateStats(): void {
+    const stats = this.expectedStats
+    if (!stats) return
+    const oldMaxHp = this.maxHp
+    const hp = this.currentHp
+    this.stats = stats
+    if (hp > 0) {
+      const newHp = Math.max(1, hp + stats[0]! - oldMaxHp)
+      this.view.setUint16(this.offsets.currentHp, newHp, true)
+    }
+  }
+
+  /**
+   * Plain JSON representation used by JSON.stringify (CLI output, exports)
+   */
+  toJSON() {
+    return {
+      species_id: this.speciesId,
+      internal_species_id: this.internalSpeciesId,
+      name_id: this.nameId,
+      nickname: this.nickname,
+      ot_name: this.otName,
+      ot_id: this.otId_str,
+      level: this.level,
+      nature: this.nature,
+      nature_effect: this.natureEffect,
+      characteristic: this.characteristic,
+      item: this.item,
+      is_shiny: this.isShiny,
+      shiny: this.shinyInfo,
+      is_egg: this.isEgg,
+      egg_cycles: this.eggCycles,
+      form: this.form,
+      types: this.types,
+      ability: this.ability,
+      gender: this.gender,
+      markings: this.markings,
+      experience: this.experience,
+      exp_to_next_level: this.expToNextLevel,
+      current_hp: this.currentHp,
+      stats: this.stats,
+      evs: this.evs,
+      ivs: this.ivs,
+      moves: this.moveIds,
+      pp: this.ppValues,
+      contest_stats: this.contestStats,
+      origin: this.origin,
+    }
+  }
 }
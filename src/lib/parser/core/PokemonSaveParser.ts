@@ -4,20 +4,150 @@
  */
 
 import {
+  type AnonymizeResult,
+  type Bag,
+  type BagItem,
+  type BagPocketName,
+  type BagSlot,
+  type BoxMetadata,
+  type Currency,
+  type CurrentLocation,
+  type Daycare,
+  type DaycareSlot,
+  type GameOptions,
+  type GameStats,
+  type MailMessage,
+  type MapLocation,
+  type Misc,
+  type MysteryGift,
   type GameConfig,
+  type LegalityReport,
+  type OptionalSections,
   type PlayTimeData,
+  type PokedexData,
+  type Rival,
+  type Roamer,
+  type SaveBlocks,
   type SaveData,
+  type SaveRepairResult,
+  type SaveSlot,
+  type SaveSlots,
+  type SaveSummary,
+  type SaveWarning,
+  type SecretBase,
+  type SecretBases,
   type SectorInfo,
+  type SectorRepair,
+  type SkippedSlot,
+  type Starter,
+  type StoryProgress,
+  type StoryProgressUpdate,
+  type TrainerInfo,
+  type TrainerProfileUpdate,
+  type TrainerCard,
+  type TrainerCardSnapshot,
+  GAME_STAT_NAMES,
+  HOENN_BADGE_NAMES,
+  HOENN_STARTERS,
+  VANILLA_CURRENCY_LIMITS,
   VANILLA_EMERALD_SIGNATURE,
 } from './types'
 
 import { MgbaWebSocketClient } from '../../mgba/websocket-client'
 import { GameConfigRegistry } from '../games'
 import { PokemonBase } from './PokemonBase'
+import {
+  depositToBox,
+  healParty,
+  injectPokemon,
+  releasePokemon,
+  removeFromParty,
+  swapPartySlots,
+  withdrawFromBox,
+  type InjectTarget,
+} from './party'
+import { allPokemon, findPokemon, type PokemonMatch, type PokemonQuery } from './query'
+import { applyRename, planRename, type RenameChange, type RenameRule } from './rename'
+import {
+  createShowdownPokemon,
+  exportShowdown,
+  parseShowdownPaste,
+  type ShowdownImportOptions,
+} from './showdown'
+import { createTrainerCard, createTrainerCardSnapshot } from './trainerCard'
+import { convertSaveContainer, detectSaveContainer, type SaveContainer } from './saveContainer'
+import { getMapName } from './maps'
+import { parsePokemonQrPayload } from './qrCode'
+import { checkLegality } from './legality'
+import { EditLog, diffSaveBytes, type ByteRangeChange, type EditTransaction } from './editLog'
+import { getSpeciesName, levelForExperience } from './species'
+import { applySecurityKey, bytesToGbaString, gbaStringToBytes } from './utils'
+import { toRawId } from './mappingIndex'
+import {
+  checkPokemonSanity,
+  isItemAllowedInPocket,
+  validateItemLegality,
+  validateStats,
+} from './validation'
 
 // Import character map for decoding text
 import charMap from '../data/pokemon_charmap.json'
 
+/**
+ * Largest accepted save payload (128KB save plus RTC/footer padding used by some emulators)
+ */
+const MAX_SAVE_FILE_SIZE = 131072 + 4096
+
+/**
+ * Number of species covered by the Gen 3 Pokedex flag arrays
+ */
+const NATIONAL_DEX_COUNT = 386
+const DEX_FLAG_BYTES = Math.ceil(NATIONAL_DEX_COUNT / 8)
+// Each save slot holds sectors 0-13 (SaveBlock2, SaveBlock1, PC storage) in rotated order
+const SLOT_SECTOR_COUNT = 14
+// EnableNationalPokedex writes these to SaveBlock2's Pokedex and VAR_NATIONAL_DEX
+const NATIONAL_DEX_MAGIC = 0xda
+const NATIONAL_DEX_VAR_VALUE = 0x302
+const DEX_MODE_HOENN = 0
+const DEX_MODE_NATIONAL = 1
+
+/**
+ * Most of one item a bag slot holds in Emerald: berries stack to 999, key items and HMs are unique
+ */
+const BAG_ITEM_CAPACITY = 99
+const BERRY_CAPACITY = 999
+
+// Names anonymizeSave gives the player and every other trainer in the save
+const ANONYMOUS_PLAYER_NAME = 'PLAYER'
+const ANONYMOUS_TRAINER_NAME = 'TRAINER'
+
+function getBagItemCapacity(pocket: BagPocketName, idName: string): number {
+  if (pocket === 'keyItems' || /^hm\d+$/.test(idName)) return 1
+  return pocket === 'berries' ? BERRY_CAPACITY : BAG_ITEM_CAPACITY
+}
+
+/**
+ * Emerald PC box wallpapers by ID (16-255 are unused)
+ */
+const BOX_WALLPAPERS = [
+  'Forest',
+  'City',
+  'Desert',
+  'Savanna',
+  'Crag',
+  'Volcano',
+  'Snow',
+  'Cave',
+  'Beach',
+  'Seafloor',
+  'River',
+  'Sky',
+  'Polka-Dot',
+  'PokéCenter',
+  'Machine',
+  'Plain',
+] as const
+
 /**
  * Decode Pokemon character-encoded text to string
  */
@@ -50,13 +180,24 @@ export class PokemonSaveParser {
   private readonly sectorMap = new Map<number, number>()
   private readonly forcedSlot: 1 | 2 | undefined
   private config: GameConfig | null = null
+  // Physical party slot of each parsed Pokemon and the slots the sanity checks dropped
+  private partySlots: readonly number[] = []
+  private skippedSlots: readonly SkippedSlot[] = []
   public saveFileName: string | null = null
+  // Container of the loaded file (64KB saves are padded to 128KB while loaded)
+  private inputContainer: SaveContainer | null = null
   public fileHandle: FileSystemFileHandle | null = null
+  // Edits applied through applyEdit() since the file was loaded
+  private editLog: EditLog | null = null
 
   // Memory mode properties
   private webSocketClient: MgbaWebSocketClient | null = null
   private isMemoryMode = false
 
+  // Chunked load properties (null when no chunked load is in progress)
+  private pendingChunks: Uint8Array[] | null = null
+  private pendingSize = 0
+
   // Watching properties
   private watchingChanges = false
   private readonly watchListeners: ((partyPokemon: PokemonBase[]) => void)[] = []
@@ -84,6 +225,7 @@ export class PokemonSaveParser {
     try {
       // Always clear sectorMap before loading new data to avoid stale state
       this.sectorMap.clear()
+      this.editLog = null
 
       // Check if input is a WebSocket client for memory mode using proper instanceof check
       if (input instanceof MgbaWebSocketClient) {
@@ -128,6 +270,11 @@ export class PokemonSaveParser {
       }
 
       this.saveData = new Uint8Array(buffer)
+      this.inputContainer = detectSaveContainer(this.saveData) ?? null
+      // A 64KB file holds the first half of the Flash; the rest reads as erased
+      if (this.inputContainer === '64k') {
+        this.saveData = convertSaveContainer(this.saveData, '128k')
+      }
 
       // Auto-detect config if not provided
       if (!this.config) {
@@ -143,6 +290,78 @@ export class PokemonSaveParser {
     }
   }
 
+  /**
+   * Start a chunked load for saves that arrive in pieces (multipart uploads, streamed fetches)
+   * Call writeChunk() for each piece and finishLoad() once the payload is complete
+   */
+  beginLoad(fileName?: string): void {
+    this.pendingChunks = []
+    this.pendingSize = 0
+    if (fileName !== undefined) this.saveFileName = fileName
+  }
+
+  /**
+   * Append a chunk of save data to the load started with beginLoad()
+   */
+  writeChunk(chunk: Uint8Array | ArrayBuffer): void {
+    if (!this.pendingChunks) {
+      throw new Error('No chunked load in progress. Call beginLoad() first.')
+    }
+
+    const bytes = chunk instanceof Uint8Array ? chunk : new Uint8Array(chunk)
+    if (this.pendingSize + bytes.length > MAX_SAVE_FILE_SIZE) {
+      this.pendingChunks = null
+      this.pendingSize = 0
+      throw new Error(`Save data exceeds maximum size of ${MAX_SAVE_FILE_SIZE} bytes`)
+    }
+
+    // Copy so callers can reuse their chunk buffers
+    this.pendingChunks.push(bytes.slice())
+    this.pendingSize += bytes.length
+  }
+
+  /**
+   * Complete a chunked load and parse the assembled save data
+   */
+  async finishLoad(): Promise<SaveData> {
+    if (!this.pendingChunks) {
+      throw new Error('No chunked load in progress. Call beginLoad() first.')
+    }
+
+    const buffer = new Uint8Array(this.pendingSize)
+    let offset = 0
+    for (const chunk of this.pendingChunks) {
+      buffer.set(chunk, offset)
+      offset += chunk.length
+    }
+    this.pendingChunks = null
+    this.pendingSize = 0
+
+    return this.parse(buffer.buffer)
+  }
+
+  /**
+   * Parse save data from a ReadableStream (e.g. fetch response body) using the chunked load API
+   */
+  async parseStream(stream: ReadableStream<Uint8Array>, fileName?: string): Promise<SaveData> {
+    this.beginLoad(fileName)
+    const reader = stream.getReader()
+    try {
+      for (;;) {
+        const { done, value } = await reader.read()
+        if (done) break
+        this.writeChunk(value)
+      }
+    } catch (error) {
+      this.pendingChunks = null
+      this.pendingSize = 0
+      throw error
+    } finally {
+      reader.releaseLock()
+    }
+    return this.finishLoad()
+  }
+
   /**
    * Initialize memory mode with WebSocket client and auto-detect config
    */
@@ -273,7 +492,8 @@ export class PokemonSaveParser {
     let sectorRange: number[]
     if (this.forcedSlot !== undefined) {
       if (this.forcedSlot === 1) {
-        sectorRange = Array.from({ length: 18 }, (_, i) => i)
+        // Slot 1 is sectors 0-13; reading further would pick up slot 2's sectors
+        sectorRange = Array.from({ length: 14 }, (_, i) => i)
       } else {
         sectorRange = Array.from({ length: 18 }, (_, i) => i + 14)
       }
@@ -291,12 +511,13 @@ export class PokemonSaveParser {
 
   /**
    * Extract SaveBlock1 data from sectors 1-4
+   * Passing a subset of sector IDs leaves the remaining chunks zero-filled
    */
-  private extractSaveblock1(): Uint8Array {
+  private extractSaveblock1(sectorIds: readonly number[] = [1, 2, 3, 4]): Uint8Array {
     if (!this.saveData || !this.config) {
       throw new Error('Save data and config not loaded')
     }
-    const saveblock1Sectors = [1, 2, 3, 4].filter(id => this.sectorMap.has(id))
+    const saveblock1Sectors = sectorIds.filter(id => this.sectorMap.has(id))
     if (saveblock1Sectors.length === 0) {
       // Instead of throwing, return a zero-filled buffer to allow parsing to continue gracefully
       return new Uint8Array(this.config.saveLayout.saveBlockSize)
@@ -319,6 +540,25 @@ export class PokemonSaveParser {
     return saveblock1Data
   }
 
+  /**
+   * SaveBlock1 sector IDs covering the given [start, end) byte ranges
+   */
+  private saveblock1SectorsFor(ranges: readonly [number, number][]): number[] {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { sectorDataSize } = this.config.saveLayout
+    const ids = new Set<number>()
+    for (const [start, end] of ranges) {
+      const last = Math.floor((end - 1) / sectorDataSize)
+      for (let chunk = Math.floor(start / sectorDataSize); chunk <= last; chunk++) {
+        ids.add(chunk + 1)
+      }
+    }
+    return [...ids].sort((a, b) => a - b)
+  }
+
   /**
    * Extract SaveBlock2 data from sector 0
    */
@@ -336,6 +576,94 @@ export class PokemonSaveParser {
     return this.saveData.slice(startOffset, startOffset + this.config.saveLayout.sectorDataSize)
   }
 
+  /**
+   * Extract PC storage from its sectors (5-13 in vanilla), zero-filling any missing sector
+   */
+  private extractPokemonStorage(): Uint8Array {
+    if (!this.saveData || !this.config) {
+      throw new Error('Save data and config not loaded')
+    }
+
+    const { sectorSize, sectorDataSize, pokemonStorageSectorStart, pokemonStorageSectorCount } =
+      this.config.saveLayout
+    const storageData = new Uint8Array(pokemonStorageSectorCount * sectorDataSize)
+
+    for (let i = 0; i < pokemonStorageSectorCount; i++) {
+      const sectorIdx = this.sectorMap.get(pokemonStorageSectorStart + i)
+      if (sectorIdx === undefined) continue
+      const startOffset = sectorIdx * sectorSize
+      storageData.set(
+        this.saveData.slice(startOffset, startOffset + sectorDataSize),
+        i * sectorDataSize
+      )
+    }
+
+    return storageData
+  }
+
+  /**
+   * Parse every PC box slot from storage data
+   * Empty slots are null; corrupt or impossible Pokemon are null and reported as skipped
+   */
+  private parseBoxes(storageData: Uint8Array): {
+    boxes: (PokemonBase | null)[][]
+    skipped: SkippedSlot[]
+  } {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { boxPokemon, boxCount, boxSlotCount, boxPokemonSize } = this.config.saveLayout
+    const boxes: (PokemonBase | null)[][] = []
+    const skipped: SkippedSlot[] = []
+
+    for (let box = 0; box < boxCount; box++) {
+      const slots: (PokemonBase | null)[] = []
+      for (let slot = 0; slot < boxSlotCount; slot++) {
+        const offset = boxPokemon + (box * boxSlotCount + slot) * boxPokemonSize
+        const pokemon = PokemonBase.fromBoxData(
+          storageData.subarray(offset, offset + boxPokemonSize),
+          this.config
+        )
+        if (pokemon.speciesId === 0) {
+          slots.push(null)
+          continue
+        }
+        const issue = checkPokemonSanity(pokemon, this.config)
+        if (issue) skipped.push({ box: box + 1, slot: slot + 1, ...issue })
+        slots.push(issue ? null : pokemon)
+      }
+      boxes.push(slots)
+    }
+
+    return { boxes, skipped }
+  }
+
+  /**
+   * Parse box names, wallpapers and the currently selected box from storage data
+   */
+  private parseBoxMetadata(storageData: Uint8Array): {
+    boxMetadata: BoxMetadata[]
+    currentBox: number
+  } {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { currentBox, boxCount, boxNames, boxNameLength, boxWallpapers } = this.config.saveLayout
+    const boxMetadata = Array.from({ length: boxCount }, (_, box): BoxMetadata => {
+      const nameOffset = boxNames + box * boxNameLength
+      const wallpaper = storageData[boxWallpapers + box] ?? 0
+      return {
+        name: decodePokemonText(storageData.slice(nameOffset, nameOffset + boxNameLength)),
+        wallpaper,
+        wallpaper_name: BOX_WALLPAPERS[wallpaper] ?? `Unknown (${wallpaper})`,
+      }
+    })
+
+    return { boxMetadata, currentBox: storageData[currentBox] ?? 0 }
+  }
+
   /**
    * Parse party Pokemon from SaveBlock1 data or memory
    */
@@ -369,6 +697,8 @@ export class PokemonSaveParser {
       )
 
       const pokemon: PokemonBase[] = []
+      const slots: number[] = []
+      const skipped: SkippedSlot[] = []
 
       for (let i = 0; i < partyCountValue; i++) {
         const pokemonOffset = i * this.config.pokemonSize
@@ -383,9 +713,17 @@ export class PokemonSaveParser {
           break
         }
 
+        const issue = checkPokemonSanity(pokemonInstance, this.config)
+        if (issue) {
+          skipped.push({ slot: i + 1, ...issue })
+          continue
+        }
         pokemon.push(pokemonInstance)
+        slots.push(i)
       }
 
+      this.partySlots = slots
+      this.skippedSlots = skipped
       return pokemon
     }
 
@@ -394,6 +732,8 @@ export class PokemonSaveParser {
       throw new Error('SaveBlock1 data required for file mode')
     }
     const partyPokemon: PokemonBase[] = []
+    const slots: number[] = []
+    const skipped: SkippedSlot[] = []
 
     for (let slot = 0; slot < this.config.maxPartySize; slot++) {
       const offset = this.config.saveLayout.partyOffset + slot * this.config.pokemonSize
@@ -409,13 +749,22 @@ export class PokemonSaveParser {
         if (pokemon.speciesId === 0) {
           break
         }
+        // Corrupt or impossible Pokemon are left out but reported on SaveData.skipped_slots
+        const issue = checkPokemonSanity(pokemon, this.config)
+        if (issue) {
+          skipped.push({ slot: slot + 1, ...issue })
+          continue
+        }
         partyPokemon.push(pokemon)
+        slots.push(slot)
       } catch (error) {
         console.warn(`Failed to parse Pokemon at slot ${slot}:`, error)
         break
       }
     }
 
+    this.partySlots = slots
+    this.skippedSlots = skipped
     return partyPokemon
   }
 
@@ -445,204 +794,1779 @@ export class PokemonSaveParser {
   }
 
   /**
-   * Calculate checksum for a sector's data
+   * Parse trainer gender and IDs from SaveBlock2 data
    */
-  private calculateSectorChecksum(sectorData: Uint8Array): number {
+  private parseTrainerInfo(saveblock2Data: Uint8Array): TrainerInfo {
     if (!this.config) {
       throw new Error('Config not loaded')
     }
 
-    if (sectorData.length < this.config.saveLayout.sectorDataSize) {
-      return 0
-    }
-
-    let checksum = 0
-    const view = new DataView(sectorData.buffer, sectorData.byteOffset)
+    const view = new DataView(saveblock2Data.buffer, saveblock2Data.byteOffset)
+    const { playerGender, trainerId } = this.config.saveLayout
 
-    for (let i = 0; i < this.config.saveLayout.sectorDataSize; i += 4) {
-      if (i + 4 <= sectorData.length) {
-        try {
-          const value = view.getUint32(i, true)
-          checksum += value
-        } catch {
-          break
-        }
-      }
+    return {
+      gender: view.getUint8(playerGender) === 1 ? 'female' : 'male',
+      trainer_id: view.getUint16(trainerId, true), // u16 visible ID
+      secret_id: view.getUint16(trainerId + 2, true), // u16 secret ID
+      ot_id: view.getUint32(trainerId, true), // both halves, as stored on caught Pokemon
     }
-
-    return ((checksum >>> 16) + (checksum & 0xffff)) & 0xffff
   }
 
   /**
-   * Update the party Pokémon in a SaveBlock1 buffer with the given PokemonInstance array.
-   * Returns a new Uint8Array with the updated party data.
-   * @param saveblock1 The original SaveBlock1 buffer
-   * @param party Array of PokemonInstance (max length = config.layout.party.maxSize)
+   * Parse non-empty mail slots from SaveBlock1 data, linking held mail to its party Pokemon
    */
-  private updatePartyInSaveblock1(
-    saveblock1: Uint8Array,
-    party: readonly PokemonBase[]
-  ): Uint8Array {
+  private parseMail(saveblock1Data: Uint8Array, party: readonly PokemonBase[]): MailMessage[] {
     if (!this.config) {
       throw new Error('Config not loaded')
     }
 
-    if (saveblock1.length < this.config.saveLayout.saveBlockSize) {
-      throw new Error(`SaveBlock1 must be at least ${this.config.saveLayout.saveBlockSize} bytes`)
-    }
-    if (party.length > this.config.maxPartySize) {
-      throw new Error(`Party size cannot exceed ${this.config.maxPartySize}`)
+    const { mappings, saveLayout: layout } = this.config
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const mail: MailMessage[] = []
+
+    for (let slot = 0; slot < layout.mailCount; slot++) {
+      const offset = layout.mail + slot * layout.mailSize
+      const rawItemId = view.getUint16(offset + 0x20, true) // u16 itemId
+      if (rawItemId === 0) continue
+
+      const rawSpecies = view.getUint16(offset + 0x1e, true) // u16 species
+      const heldBy = party.findIndex(pokemon => pokemon.mailId === slot)
+      mail.push({
+        slot,
+        held_by: heldBy === -1 ? undefined : (this.partySlots[heldBy] ?? heldBy) + 1,
+        words: Array.from({ length: 9 }, (_, i) => {
+          const word = view.getUint16(offset + i * 2, true) // u16 words[9]
+          return word === 0xffff ? null : word
+        }),
+        sender: decodePokemonText(saveblock1Data.slice(offset + 0x12, offset + 0x1a)),
+        sender_ot_id: view.getUint32(offset + 0x1a, true), // u8 trainerId[4]
+        species_id: mappings?.pokemon?.get(rawSpecies)?.id ?? rawSpecies,
+        item_id: mappings?.items?.get(rawItemId)?.id ?? rawItemId,
+        item_name: mappings?.items?.get(rawItemId)?.name ?? `Unknown (${rawItemId})`,
+      })
     }
 
-    const updated = new Uint8Array(saveblock1)
-    for (let i = 0; i < party.length; i++) {
-      const offset = this.config.saveLayout.partyOffset + i * this.config.pokemonSize
-      // Use the most up-to-date raw data for each Pokemon
-      updated.set(party[i]!.rawBytes, offset)
-    }
-    return updated
+    return mail
   }
 
   /**
-   * Parse input data and return structured data
-   * Supports both file and memory input via WebSocket
+   * Parse the player's secret base and the bases received from friends from SaveBlock1 data
    */
-  async parse(
-    input: File | ArrayBuffer | FileSystemFileHandle | MgbaWebSocketClient
-  ): Promise<SaveData> {
-    await this.loadInputData(input)
+  private parseSecretBases(saveblock1Data: Uint8Array): SecretBases {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
 
-    // Memory mode: read directly from emulator memory
-    if (this.isMemoryMode && this.webSocketClient) {
-      const partyPokemon = await this.parsePartyPokemon()
+    const { mappings, saveLayout: layout } = this.config
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const bases: (SecretBase | null)[] = []
 
-      // TODO: Implement memory support for player name and playtime
-      // These fields should be read from memory addresses when implemented
-      return {
-        party_pokemon: partyPokemon,
-        player_name: 'MEMORY', // TODO: Read from memory if needed
-        play_time: { hours: 0, minutes: 0, seconds: 0 }, // TODO: Read from memory if needed
-        active_slot: 0, // Memory doesn't have multiple save slots
+    for (let index = 0; index < layout.secretBaseCount; index++) {
+      const offset = layout.secretBases + index * layout.secretBaseSize
+      const locationId = view.getUint8(offset) // u8 secretBaseId, 0 when unused
+      if (locationId === 0) {
+        bases.push(null)
+        continue
       }
+
+      // u8 bitfield: toRegister (4 bits), gender, battledOwnerToday, registryStatus (2 bits)
+      const flags = view.getUint8(offset + 0x1)
+      const decorations = Array.from({ length: 16 }, (_, i) => ({
+        decoration_id: view.getUint8(offset + 0x12 + i),
+        x: view.getUint8(offset + 0x22 + i) >> 4,
+        y: view.getUint8(offset + 0x22 + i) & 0xf,
+      }))
+      // SecretBaseParty at 0x34: personality[6], moves[24], species[6], heldItems[6], levels[6]
+      const party = Array.from({ length: 6 }, (_, i) => {
+        const rawSpecies = view.getUint16(offset + 0x7c + i * 2, true)
+        const rawItem = view.getUint16(offset + 0x88 + i * 2, true)
+        return {
+          species_id: mappings?.pokemon?.get(rawSpecies)?.id ?? rawSpecies,
+          level: view.getUint8(offset + 0x94 + i),
+          item_id: mappings?.items?.get(rawItem)?.id ?? rawItem,
+          moves: Array.from({ length: 4 }, (_, m) => {
+            const rawMove = view.getUint16(offset + 0x4c + (i * 4 + m) * 2, true)
+            return mappings?.moves?.get(rawMove)?.id ?? rawMove
+          }),
+        }
+      })
+
+      bases.push({
+        location_id: locationId,
+        owner_name: decodePokemonText(saveblock1Data.slice(offset + 0x2, offset + 0x9)),
+        owner_gender: ((flags >> 4) & 1) === 1 ? 'female' : 'male',
+        owner_ot_id: view.getUint32(offset + 0x9, true), // u8 trainerId[4]
+        registered: flags >> 6 === 1,
+        times_entered: view.getUint8(offset + 0x10),
+        decorations: decorations.filter(decoration => decoration.decoration_id !== 0),
+        party: party.filter(member => member.species_id !== 0),
+      })
     }
 
-    // File mode: existing logic
-    this.determineActiveSlot()
-    this.buildSectorMap()
+    const [own = null, ...friends] = bases
+    return { own, friends: friends.filter(base => base !== null) }
+  }
 
-    const saveblock1Data = this.extractSaveblock1()
-    const saveblock2Data = this.extractSaveblock2()
+  /**
+   * Parse the daycare's deposited Pokemon, their steps and any pending egg from SaveBlock1 data
+   */
+  private parseDaycare(saveblock1Data: Uint8Array): Daycare {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
 
-    const playerName = this.parsePlayerName(saveblock2Data)
-    const partyPokemon = await this.parsePartyPokemon(saveblock1Data)
-    const playTime = this.parsePlayTime(saveblock2Data)
+    const config = this.config
+    const layout = config.saveLayout
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const slots = [0, 1].map((slot): DaycareSlot | null => {
+      const offset = layout.daycare + slot * layout.daycareSlotSize
+      const pokemon = PokemonBase.fromBoxData(
+        saveblock1Data.subarray(offset, offset + layout.boxPokemonSize),
+        config
+      )
+      if (pokemon.speciesId === 0) return null
+      const steps = view.getUint32(offset + layout.daycareSlotSteps, true)
+      const rate = pokemon.growthRate
+      return {
+        pokemon,
+        steps,
+        level_on_withdraw: rate
+          ? levelForExperience(rate, pokemon.experience + steps)
+          : pokemon.level,
+      }
+    })
+    const offspringPersonality = view.getUint32(layout.daycareOffspringPersonality, true)
 
     return {
-      party_pokemon: partyPokemon,
-      player_name: playerName,
-      play_time: playTime,
-      active_slot: this.activeSlotStart,
-      sector_map: this.sectorMap,
-      rawSaveData: this.saveData,
+      slots,
+      egg_pending: offspringPersonality !== 0,
+      offspring_personality: offspringPersonality,
+      step_counter: view.getUint8(layout.daycareStepCounter),
     }
   }
 
   /**
-   * Get the current game configuration
+   * Parse the options menu settings from SaveBlock2 data
    */
-  getGameConfig(): GameConfig | null {
-    return this.config
+  private parseOptions(saveblock2Data: Uint8Array): GameOptions {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const view = new DataView(saveblock2Data.buffer, saveblock2Data.byteOffset)
+    const { options, optionsButtonMode } = this.config.saveLayout
+    // u16 bitfield: text speed (3 bits), frame type (5), sound, battle style, battle scene off
+    const bits = view.getUint16(options, true)
+    const textSpeeds = ['slow', 'mid', 'fast'] as const
+    const buttonModes = ['normal', 'lr', 'l=a'] as const
+
+    return {
+      text_speed: textSpeeds[bits & 0x7] ?? 'unknown',
+      battle_scene: ((bits >> 10) & 1) === 0,
+      battle_style: ((bits >> 9) & 1) === 1 ? 'set' : 'shift',
+      sound: ((bits >> 8) & 1) === 1 ? 'stereo' : 'mono',
+      button_mode: buttonModes[view.getUint8(optionsButtonMode)] ?? 'unknown',
+      frame_type: (bits >> 3) & 0x1f,
+    }
   }
 
   /**
-   * Set the game configuration (useful for testing or manual override)
+   * Read a single event flag from SaveBlock1 data
    */
-  setGameConfig(config: GameConfig): void {
-    this.config = config
+  private readFlag(saveblock1Data: Uint8Array, flagId: number): boolean {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const byte = saveblock1Data[this.config.saveLayout.flags + (flagId >> 3)] ?? 0
+    return ((byte >> (flagId & 7)) & 1) === 1
   }
 
   /**
-   * Get the currently active game config
+   * Read a single event variable from SaveBlock1 data
    */
-  get gameConfig(): GameConfig | null {
-    return this.config
+  private readVar(saveblock1Data: Uint8Array, varId: number): number {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    return view.getUint16(this.config.saveLayout.vars + (varId - 0x4000) * 2, true)
   }
 
   /**
-   * Reconstruct the full save file from a new party (PokemonInstance[]).
-   * Updates SaveBlock1 with the given party and returns a new Uint8Array representing the reconstructed save file.
-   *
-   * @param partyPokemon Array of PokemonInstance to update party in SaveBlock1
+   * Parse minor flag and variable state (lottery, Safari Zone, Repel) from SaveBlock1 data
    */
-  reconstructSaveFile(partyPokemon: readonly PokemonBase[]): Uint8Array {
-    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
-
-    const baseSaveblock1 = this.extractSaveblock1()
-    const updatedSaveblock1 = this.updatePartyInSaveblock1(baseSaveblock1, partyPokemon)
-    const newSave = new Uint8Array(this.saveData)
-
-    // Helper to write a sector and update its checksum
-    const writeSector = (sectorId: number, data: Uint8Array) => {
-      if (!this.config)
-        throw new Error('Game configuration not loaded during sector reconstruction')
-
-      if (!this.sectorMap.has(sectorId)) return
-      const sectorIdx = this.sectorMap.get(sectorId)!
-      const startOffset = sectorIdx * this.config.saveLayout.sectorSize
-      newSave.set(data, startOffset)
-      // Recalculate checksum for this sector
-      const checksum = this.calculateSectorChecksum(data)
-      const footerOffset = startOffset + this.config.saveLayout.sectorSize - 12
-      const view = new DataView(newSave.buffer, newSave.byteOffset + footerOffset, 12)
-      view.setUint16(2, checksum, true)
+  private parseMisc(saveblock1Data: Uint8Array): Misc {
+    if (!this.config) {
+      throw new Error('Config not loaded')
     }
 
-    // Write SaveBlock1 (sectors 1-4)
-    for (let sectorId = 1; sectorId <= 4; sectorId++) {
-      const chunkOffset = (sectorId - 1) * this.config.saveLayout.sectorDataSize
-      const chunk = updatedSaveblock1.slice(
-        chunkOffset,
-        chunkOffset + this.config.saveLayout.sectorDataSize
-      )
-      writeSector(sectorId, chunk)
+    const { safariModeFlag, repelStepsVar, ashGatherCountVar, lotteryNumberVar } =
+      this.config.saveLayout
+    return {
+      lottery_number: this.readVar(saveblock1Data, lotteryNumberVar),
+      safari_mode: this.readFlag(saveblock1Data, safariModeFlag),
+      repel_steps: this.readVar(saveblock1Data, repelStepsVar),
+      ash_collected: this.readVar(saveblock1Data, ashGatherCountVar),
     }
-    return newSave
   }
 
   /**
-   * Check if parser is in memory mode
+   * Work out the rival from the player's gender; the game does not save the rival's name
    */
-  isInMemoryMode(): boolean {
-    return this.isMemoryMode
+  private parseRival(trainer: TrainerInfo): Rival {
+    return trainer.gender === 'male'
+      ? { name: 'MAY', gender: 'female' }
+      : { name: 'BRENDAN', gender: 'male' }
   }
 
   /**
-   * Get the WebSocket client (for memory mode)
+   * Read the chosen starter from SaveBlock1 data, or null if the player has not picked one yet
    */
-  getWebSocketClient(): MgbaWebSocketClient | null {
-    return this.webSocketClient
+  private parseStarter(saveblock1Data: Uint8Array): Starter | null {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { starterFlag, starterVar } = this.config.saveLayout
+    // VAR_STARTER_MON is 0 both before the choice and for Treecko, so check the flag first
+    if (!this.readFlag(saveblock1Data, starterFlag)) return null
+    const speciesId = HOENN_STARTERS[this.readVar(saveblock1Data, starterVar)]
+    if (speciesId === undefined) return null
+    return { species_id: speciesId, name: getSpeciesName(speciesId) ?? `#${speciesId}` }
   }
 
   /**
-   * Start watching for Pokemon party changes in memory mode
-   * This will automatically set up memory watching for the party data regions
-   * and call the provided callback when changes are detected
+   * Parse the eight gym badge flags from SaveBlock1 data
    */
-  async watch(
-    options: {
-      onPartyChange?: (partyPokemon: PokemonBase[]) => void
-      onError?: (error: Error) => void
-    } = {}
-  ): Promise<void> {
-    if (!this.isMemoryMode || !this.webSocketClient) {
-      throw new Error('Watch mode only available in memory mode (WebSocket connection)')
+  private parseBadges(saveblock1Data: Uint8Array): boolean[] {
+    if (!this.config) {
+      throw new Error('Config not loaded')
     }
 
-    if (this.watchingChanges) {
-      throw new Error('Already watching memory. Call stopWatching() first.')
-    }
+    const { badgeFlagStart } = this.config.saveLayout
+    return Array.from({ length: 8 }, (_, i) => this.readFlag(saveblock1Data, badgeFlagStart + i))
+  }
 
-    if (!this.config?.preloadRegions) {
+  /**
+   * Parse badges and key story milestones from the event flags in SaveBlock1 data
+   */
+  private parseStoryProgress(
+    saveblock1Data: Uint8Array,
+    saveblock2Data: Uint8Array
+  ): StoryProgress {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const layout = this.config.saveLayout
+    const badges = this.parseBadges(saveblock1Data)
+    return {
+      badges,
+      badge_count: badges.filter(Boolean).length,
+      received_starter: this.readFlag(saveblock1Data, layout.starterFlag),
+      received_pokedex: this.readFlag(saveblock1Data, layout.pokedexFlag),
+      received_pokenav: this.readFlag(saveblock1Data, layout.pokenavFlag),
+      running_shoes: this.readFlag(saveblock1Data, layout.runningShoesFlag),
+      // Same check as the game's IsNationalPokedexEnabled
+      national_dex:
+        saveblock2Data[layout.nationalDexMagic] === NATIONAL_DEX_MAGIC &&
+        this.readVar(saveblock1Data, layout.nationalDexVar) === NATIONAL_DEX_VAR_VALUE,
+      game_cleared: this.readFlag(saveblock1Data, layout.gameClearFlag),
+    }
+  }
+
+  /**
+   * Set or clear a single event flag in SaveBlock1 data
+   */
+  private writeFlag(saveblock1Data: Uint8Array, flagId: number, value: boolean): void {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const offset = this.config.saveLayout.flags + (flagId >> 3)
+    const mask = 1 << (flagId & 7)
+    const byte = saveblock1Data[offset]!
+    saveblock1Data[offset] = value ? byte | mask : byte & ~mask
+  }
+
+  /**
+   * Write a single event variable into SaveBlock1 data
+   */
+  private writeVar(saveblock1Data: Uint8Array, varId: number, value: number): void {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    view.setUint16(this.config.saveLayout.vars + (varId - 0x4000) * 2, value, true)
+  }
+
+  /**
+   * Parse the player's map, tile position and last heal location from SaveBlock1 data
+   */
+  private parseLocation(saveblock1Data: Uint8Array): CurrentLocation {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { playerPosition, location, lastHealLocation } = this.config.saveLayout
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const readMap = (offset: number): MapLocation => {
+      const mapGroup = view.getInt8(offset)
+      const mapNum = view.getInt8(offset + 1)
+      return { map_group: mapGroup, map_num: mapNum, map_name: getMapName(mapGroup, mapNum) }
+    }
+
+    return {
+      ...readMap(location),
+      x: view.getInt16(playerPosition, true),
+      y: view.getInt16(playerPosition + 2, true),
+      last_heal_location: readMap(lastHealLocation),
+    }
+  }
+
+  /**
+   * Parse the roaming Latios/Latias from SaveBlock1 data
+   */
+  private parseRoamer(saveblock1Data: Uint8Array): Roamer {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { mappings, saveLayout: layout } = this.config
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const ivData = view.getUint32(layout.roamer, true) // u32 ivs, packed like box Pokemon
+    const rawSpecies = view.getUint16(layout.roamer + 0x8, true)
+
+    return {
+      species_id: mappings?.pokemon?.get(rawSpecies)?.id ?? rawSpecies,
+      active: view.getUint8(layout.roamer + 0x13) !== 0,
+      level: view.getUint8(layout.roamer + 0xc),
+      hp: view.getUint16(layout.roamer + 0xa, true),
+      status: view.getUint8(layout.roamer + 0xd),
+      personality: view.getUint32(layout.roamer + 0x4, true),
+      ivs: Array.from({ length: 6 }, (_, i) => (ivData >> (i * 5)) & 0x1f),
+    }
+  }
+
+  /**
+   * Parse the Wonder Card, Wonder News, event ticket flags and e-Reader data from SaveBlock1 data
+   */
+  private parseMysteryGift(saveblock1Data: Uint8Array): MysteryGift {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { mappings, saveLayout: layout } = this.config
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const text = (offset: number) => decodePokemonText(saveblock1Data.slice(offset, offset + 40))
+    const lines = (offset: number, count: number) =>
+      Array.from({ length: count }, (_, i) => text(offset + i * 40))
+
+    // A slot is empty when its CRC is zero; u32 CRCs precede the news (0x0) and card (0x1c0)
+    const news = layout.mysteryGift + 0x4
+    const card = layout.mysteryGift + 0x1c4
+    const iconSpecies = view.getUint16(card + 0x2, true)
+    const wonderCard =
+      view.getUint32(card - 0x4, true) === 0
+        ? null
+        : {
+            card_id: view.getUint32(card + 0x4, true),
+            flag_id: view.getUint16(card, true),
+            icon_species_id: mappings?.pokemon?.get(iconSpecies)?.id ?? iconSpecies,
+            title: text(card + 0xa),
+            subtitle: text(card + 0x32),
+            body: lines(card + 0x5a, 4),
+            footer: lines(card + 0xfa, 2),
+          }
+    // EnigmaBerry ends with a u32 checksum; RamScript data starts with magic 51 after its checksum
+    const hasEnigmaBerry = view.getUint32(layout.enigmaBerry + 0x30, true) !== 0
+
+    return {
+      mystery_gift_enabled: this.readFlag(saveblock1Data, layout.mysteryGiftFlag),
+      mystery_event_enabled: this.readFlag(saveblock1Data, layout.mysteryEventFlag),
+      wonder_card: wonderCard,
+      wonder_news_title: view.getUint32(news - 0x4, true) === 0 ? null : text(news + 0x4),
+      event_tickets: {
+        eon_ticket: this.readFlag(saveblock1Data, layout.eonTicketFlag),
+        aurora_ticket: this.readFlag(saveblock1Data, layout.auroraTicketFlag),
+        old_sea_map: this.readFlag(saveblock1Data, layout.oldSeaMapFlag),
+        mystic_ticket: this.readFlag(saveblock1Data, layout.mysticTicketFlag),
+      },
+      enigma_berry: hasEnigmaBerry
+        ? decodePokemonText(saveblock1Data.slice(layout.enigmaBerry, layout.enigmaBerry + 7))
+        : null,
+      ram_script: view.getUint8(layout.ramScript + 0x4) === 51,
+    }
+  }
+
+  /**
+   * Report which optional save areas (Hall of Fame, Trainer Hill, recorded battle, Mystery Gift)
+   * have been written and, where a checksum can be checked, whether they are intact
+   */
+  private parseOptionalSections(saveblock1Data: Uint8Array): OptionalSections {
+    if (!this.saveData || !this.config) {
+      throw new Error('Save data and config not loaded')
+    }
+
+    const saveData = this.saveData
+    const { sectorSize, hallOfFameSector, trainerHillSector, recordedBattleSector, mysteryGift } =
+      this.config.saveLayout
+    // Trainer Hill and recorded battle sectors are written raw, without the usual footer
+    const isWritten = (sectorIndex: number) =>
+      saveData
+        .subarray(sectorIndex * sectorSize, (sectorIndex + 1) * sectorSize)
+        .some(byte => byte !== 0x00 && byte !== 0xff)
+    const hallOfFame = [hallOfFameSector, hallOfFameSector + 1].map(i => this.getSectorInfo(i))
+    const hasHallOfFame = hallOfFame.some(info => info.id === hallOfFameSector)
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+
+    return {
+      hall_of_fame: {
+        present: hasHallOfFame,
+        valid: hasHallOfFame ? hallOfFame.every(info => info.valid) : undefined,
+      },
+      trainer_hill: { present: isWritten(trainerHillSector) },
+      recorded_battle: { present: isWritten(recordedBattleSector) },
+      wonder_news: { present: view.getUint32(mysteryGift, true) !== 0 },
+      wonder_card: { present: view.getUint32(mysteryGift + 0x1c0, true) !== 0 },
+    }
+  }
+
+  /**
+   * Whether the active config maps SaveBlock data beyond party, name and play time
+   */
+  private hasExtendedSaveData(): boolean {
+    return this.config?.supportsExtendedSaveData !== false
+  }
+
+  /**
+   * Read the SaveBlock2 encryption key used for money, coins and bag quantities
+   */
+  private getSaveEncryptionKey(saveblock2Data: Uint8Array): number {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const view = new DataView(saveblock2Data.buffer, saveblock2Data.byteOffset)
+    return view.getUint32(this.config.saveLayout.encryptionKey, true)
+  }
+
+  /**
+   * Parse money and Game Corner coins from SaveBlock1 (stored XORed with the SaveBlock2 key)
+   * and Battle Points from the Battle Frontier data in SaveBlock2 (stored in the clear)
+   */
+  private parseCurrency(saveblock1Data: Uint8Array, saveblock2Data: Uint8Array): Currency {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { money, coins, battlePoints } = this.config.saveLayout
+    const view1 = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const view2 = new DataView(saveblock2Data.buffer, saveblock2Data.byteOffset)
+    const key = this.getSaveEncryptionKey(saveblock2Data)
+
+    return {
+      money: applySecurityKey(view1.getUint32(money, true), key), // u32 money (encrypted)
+      coins: applySecurityKey(view1.getUint16(coins, true), key, 16), // u16 coins (encrypted)
+      battle_points: view2.getUint16(battlePoints, true), // u16 frontier.battlePoints
+    }
+  }
+
+  /**
+   * Decrypt the game stat counters from SaveBlock1 data
+   */
+  private parseGameStats(saveblock1Data: Uint8Array, saveblock2Data: Uint8Array): GameStats {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { gameStats } = this.config.saveLayout
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const key = this.getSaveEncryptionKey(saveblock2Data)
+
+    return Object.fromEntries(
+      GAME_STAT_NAMES.map((name, i) => [
+        name,
+        applySecurityKey(view.getUint32(gameStats + i * 4, true), key),
+      ])
+    ) as GameStats
+  }
+
+  /**
+   * Read a Pokedex flag array across the National Dex range
+   */
+  private readDexFlags(data: Uint8Array, offset: number): boolean[] {
+    return Array.from(
+      { length: NATIONAL_DEX_COUNT },
+      (_, i) => (((data[offset + (i >> 3)] ?? 0) >> (i & 7)) & 1) === 1
+    )
+  }
+
+  /**
+   * Parse Pokedex seen/caught flags; like the game, seen requires SaveBlock2 and both
+   * SaveBlock1 mirrors to agree
+   */
+  private parsePokedex(saveblock1Data: Uint8Array, saveblock2Data: Uint8Array): PokedexData {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const layout = this.config.saveLayout
+    const caught = this.readDexFlags(saveblock2Data, layout.pokedexOwned)
+    const seen1 = this.readDexFlags(saveblock1Data, layout.pokedexSeen1)
+    const seen2 = this.readDexFlags(saveblock1Data, layout.pokedexSeen2)
+    const seen = this.readDexFlags(saveblock2Data, layout.pokedexSeen).map(
+      (flag, i) => flag && seen1[i]! && seen2[i]!
+    )
+
+    return {
+      seen,
+      caught,
+      seen_count: seen.filter(Boolean).length,
+      caught_count: caught.filter(Boolean).length,
+    }
+  }
+
+  /**
+   * Set or clear one species' bit in a Pokedex flag array
+   */
+  private writeDexFlag(data: Uint8Array, offset: number, speciesId: number, value: boolean): void {
+    const index = offset + ((speciesId - 1) >> 3)
+    const mask = 1 << ((speciesId - 1) & 7)
+    data[index] = value ? data[index]! | mask : data[index]! & ~mask
+  }
+
+  /**
+   * Bag pockets with their SaveBlock1 offset and slot count, in save order
+   */
+  private getBagPockets(): [BagPocketName, number, number][] {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const layout = this.config.saveLayout
+    return [
+      ['items', layout.bagItems, layout.bagItemsCount],
+      ['keyItems', layout.bagKeyItems, layout.bagKeyItemsCount],
+      ['pokeBalls', layout.bagPokeBalls, layout.bagPokeBallsCount],
+      ['tmHm', layout.bagTmHm, layout.bagTmHmCount],
+      ['berries', layout.bagBerries, layout.bagBerriesCount],
+    ]
+  }
+
+  /**
+   * Parse raw bag pocket slots from SaveBlock1 (quantities decrypted with the SaveBlock2 key)
+   */
+  private parseBagSlots(saveblock1Data: Uint8Array, saveblock2Data: Uint8Array): BagSlot[] {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const key = this.getSaveEncryptionKey(saveblock2Data)
+    const view = new DataView(saveblock1Data.buffer, saveblock1Data.byteOffset)
+    const slots: BagSlot[] = []
+
+    for (const [pocket, offset, count] of this.getBagPockets()) {
+      for (let slot = 0; slot < count; slot++) {
+        const slotOffset = offset + slot * 4
+        slots.push({
+          pocket,
+          slot,
+          rawItemId: view.getUint16(slotOffset, true), // u16 itemId
+          quantity: applySecurityKey(view.getUint16(slotOffset + 2, true), key, 16), // u16 quantity
+        })
+      }
+    }
+
+    return slots
+  }
+
+  /**
+   * Group non-empty bag slots by pocket, resolving item IDs and names through the item mapping
+   */
+  private buildBag(slots: readonly BagSlot[]): Bag {
+    const items = this.config?.mappings?.items
+    const bag: Record<BagPocketName, BagItem[]> = {
+      items: [],
+      keyItems: [],
+      pokeBalls: [],
+      tmHm: [],
+      berries: [],
+    }
+
+    for (const { pocket, slot, rawItemId, quantity } of slots) {
+      if (rawItemId === 0) continue
+      const mapping = items?.get(rawItemId)
+      bag[pocket].push({
+        slot,
+        item_id: mapping?.id ?? rawItemId,
+        raw_item_id: rawItemId,
+        id_name: mapping?.id_name,
+        name: mapping?.name ?? `Unknown (${rawItemId})`,
+        quantity,
+      })
+    }
+
+    return bag
+  }
+
+  /**
+   * Calculate checksum for a sector's data
+   */
+  private calculateSectorChecksum(sectorData: Uint8Array): number {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    if (sectorData.length < this.config.saveLayout.sectorDataSize) {
+      return 0
+    }
+
+    let checksum = 0
+    const view = new DataView(sectorData.buffer, sectorData.byteOffset)
+
+    for (let i = 0; i < this.config.saveLayout.sectorDataSize; i += 4) {
+      if (i + 4 <= sectorData.length) {
+        try {
+          const value = view.getUint32(i, true)
+          checksum += value
+        } catch {
+          break
+        }
+      }
+    }
+
+    return ((checksum >>> 16) + (checksum & 0xffff)) & 0xffff
+  }
+
+  /**
+   * Update the party Pokémon in a SaveBlock1 buffer with the given PokemonInstance array.
+   * Returns a new Uint8Array with the updated party data.
+   * @param saveblock1 The original SaveBlock1 buffer
+   * @param party Array of PokemonInstance (max length = config.layout.party.maxSize)
+   */
+  private updatePartyInSaveblock1(
+    saveblock1: Uint8Array,
+    party: readonly PokemonBase[]
+  ): Uint8Array {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    if (saveblock1.length < this.config.saveLayout.saveBlockSize) {
+      throw new Error(`SaveBlock1 must be at least ${this.config.saveLayout.saveBlockSize} bytes`)
+    }
+    if (party.length > this.config.maxPartySize) {
+      throw new Error(`Party size cannot exceed ${this.config.maxPartySize}`)
+    }
+
+    const { partyOffset, partyCountOffset } = this.config.saveLayout
+    const { pokemonSize, maxPartySize } = this.config
+    const updated = new Uint8Array(saveblock1)
+    // A party that grew or shrank is written compactly with a new count; skipped slots are dropped
+    const resized = party.length !== this.partySlots.length
+    for (let i = 0; i < party.length; i++) {
+      // Otherwise write back to the slot each Pokemon was read from so skipped slots stay untouched
+      const slot = resized ? i : (this.partySlots[i] ?? i)
+      const offset = partyOffset + slot * pokemonSize
+      // Use the most up-to-date raw data for each Pokemon
+      updated.set(party[i]!.rawBytes, offset)
+    }
+    if (resized) {
+      const end = partyOffset + maxPartySize * pokemonSize
+      updated.fill(0, partyOffset + party.length * pokemonSize, end)
+      updated[partyCountOffset] = party.length
+    }
+    return updated
+  }
+
+  /**
+   * Parse input data and return structured data
+   * Supports both file and memory input via WebSocket
+   */
+  async parse(
+    input: File | ArrayBuffer | FileSystemFileHandle | MgbaWebSocketClient
+  ): Promise<SaveData> {
+    await this.loadInputData(input)
+
+    // Memory mode: read directly from emulator memory
+    if (this.isMemoryMode && this.webSocketClient) {
+      const partyPokemon = await this.parsePartyPokemon()
+
+      // TODO: Implement memory support for player name and playtime
+      // These fields should be read from memory addresses when implemented
+      return {
+        party_pokemon: partyPokemon,
+        player_name: 'MEMORY', // TODO: Read from memory if needed
+        play_time: { hours: 0, minutes: 0, seconds: 0 }, // TODO: Read from memory if needed
+        active_slot: 0, // Memory doesn't have multiple save slots
+        warnings: validateItemLegality(this.config!, partyPokemon, []),
+        skipped_slots: this.skippedSlots,
+      }
+    }
+
+    // File mode: existing logic
+    this.determineActiveSlot()
+    this.buildSectorMap()
+
+    const saveblock1Data = this.extractSaveblock1()
+    const saveblock2Data = this.extractSaveblock2()
+
+    const playerName = this.parsePlayerName(saveblock2Data)
+    const partyPokemon = await this.parsePartyPokemon(saveblock1Data)
+    const playTime = this.parsePlayTime(saveblock2Data)
+    const trainer = this.parseTrainerInfo(saveblock2Data)
+    // Everything past party, name and play time needs the vanilla SaveBlock layout
+    const extended = this.hasExtendedSaveData()
+    const bagSlots = extended ? this.parseBagSlots(saveblock1Data, saveblock2Data) : undefined
+    const warnings = validateItemLegality(this.config!, partyPokemon, bagSlots ?? [])
+    const storageData = extended ? this.extractPokemonStorage() : undefined
+    const storage = storageData && {
+      ...this.parseBoxes(storageData),
+      ...this.parseBoxMetadata(storageData),
+    }
+
+    return {
+      party_pokemon: partyPokemon,
+      player_name: playerName,
+      play_time: playTime,
+      trainer,
+      active_slot: this.activeSlotStart,
+      sector_map: this.sectorMap,
+      rawSaveData: this.saveData,
+      warnings,
+      skipped_slots: [...this.skippedSlots, ...(storage?.skipped ?? [])],
+      bag: bagSlots && this.buildBag(bagSlots),
+      pokedex: extended ? this.parsePokedex(saveblock1Data, saveblock2Data) : undefined,
+      progress: extended ? this.parseStoryProgress(saveblock1Data, saveblock2Data) : undefined,
+      currency: extended ? this.parseCurrency(saveblock1Data, saveblock2Data) : undefined,
+      options: extended ? this.parseOptions(saveblock2Data) : undefined,
+      daycare: extended ? this.parseDaycare(saveblock1Data) : undefined,
+      mail: extended ? this.parseMail(saveblock1Data, partyPokemon) : undefined,
+      secret_bases: extended ? this.parseSecretBases(saveblock1Data) : undefined,
+      mystery_gift: extended ? this.parseMysteryGift(saveblock1Data) : undefined,
+      game_stats: extended ? this.parseGameStats(saveblock1Data, saveblock2Data) : undefined,
+      roamer: extended ? this.parseRoamer(saveblock1Data) : undefined,
+      location: extended ? this.parseLocation(saveblock1Data) : undefined,
+      misc: extended ? this.parseMisc(saveblock1Data) : undefined,
+      rival: extended ? this.parseRival(trainer) : undefined,
+      starter: extended ? this.parseStarter(saveblock1Data) : undefined,
+      optional_sections: extended ? this.parseOptionalSections(saveblock1Data) : undefined,
+      boxes: storage?.boxes,
+      box_metadata: storage?.boxMetadata,
+      current_box: storage?.currentBox,
+    }
+  }
+
+  /**
+   * Parse both save slots of a file, e.g. to recover the previous save or compare the two
+   * Uses the loaded config when there is one, otherwise each slot detects it from the file
+   */
+  async parseAllSlots(input: File | ArrayBuffer): Promise<SaveSlots> {
+    const buffer = input instanceof File ? await input.arrayBuffer() : input
+    const config = this.config ?? undefined
+
+    const readSlot = async (slot: 1 | 2): Promise<SaveSlot | null> => {
+      const parser = new PokemonSaveParser(slot, config)
+      const data = await parser.parse(buffer)
+      if (parser.sectorMap.size === 0) return null
+      const counters = [...parser.sectorMap.values()].map(i => parser.getSectorInfo(i).counter)
+      return { slot, counter: Math.max(...counters), data }
+    }
+
+    const current = await new PokemonSaveParser(undefined, config).parse(buffer)
+    return {
+      active: current.active_slot === 0 ? 1 : 2,
+      slot1: await readSlot(1),
+      slot2: await readSlot(2),
+    }
+  }
+
+  /**
+   * Decode a lightweight preview (trainer, play time, badges, money, Pokedex counts and party
+   * species/levels) for file pickers, reading only the SaveBlock1 sectors those fields live in
+   */
+  async parseSummary(input: File | ArrayBuffer | FileSystemFileHandle): Promise<SaveSummary> {
+    await this.loadInputData(input)
+    if (!this.config) throw new Error('Config not loaded')
+
+    this.determineActiveSlot()
+    this.buildSectorMap()
+
+    const { saveLayout: layout, maxPartySize, pokemonSize } = this.config
+    const extended = this.hasExtendedSaveData()
+    const ranges: [number, number][] = [
+      [layout.partyOffset, layout.partyOffset + maxPartySize * pokemonSize],
+    ]
+    if (extended) {
+      const badgeByte = layout.flags + (layout.badgeFlagStart >> 3)
+      ranges.push(
+        [layout.money, layout.money + 4],
+        [layout.coins, layout.coins + 2],
+        [badgeByte, badgeByte + 2],
+        [layout.pokedexSeen1, layout.pokedexSeen1 + DEX_FLAG_BYTES],
+        [layout.pokedexSeen2, layout.pokedexSeen2 + DEX_FLAG_BYTES]
+      )
+    }
+
+    const saveblock1Data = this.extractSaveblock1(this.saveblock1SectorsFor(ranges))
+    const saveblock2Data = this.extractSaveblock2()
+    const badges = extended ? this.parseBadges(saveblock1Data) : []
+    const currency = extended ? this.parseCurrency(saveblock1Data, saveblock2Data) : undefined
+    const pokedex = extended ? this.parsePokedex(saveblock1Data, saveblock2Data) : undefined
+    const party = await this.parsePartyPokemon(saveblock1Data)
+
+    return {
+      game: this.config.name,
+      player_name: this.parsePlayerName(saveblock2Data),
+      play_time: this.parsePlayTime(saveblock2Data),
+      badges,
+      badge_count: badges.filter(Boolean).length,
+      money: currency?.money,
+      pokedex: pokedex && { seen: pokedex.seen_count, caught: pokedex.caught_count },
+      party: party.map(pokemon => ({
+        species_id: pokemon.speciesId,
+        name_id: pokemon.nameId,
+        level: pokemon.level,
+        is_shiny: pokemon.isShiny,
+      })),
+    }
+  }
+
+  /**
+   * Get the current game configuration
+   */
+  getGameConfig(): GameConfig | null {
+    return this.config
+  }
+
+  /**
+   * Set the game configuration (useful for testing or manual override)
+   */
+  setGameConfig(config: GameConfig): void {
+    this.config = config
+  }
+
+  /**
+   * Container (128KB, 128KB + RTC footer or 64KB) of the loaded save file, for writing edits back
+   * in the same shape with convertSaveContainer
+   */
+  get saveContainer(): SaveContainer | null {
+    return this.inputContainer
+  }
+
+  /**
+   * Get the currently active game config
+   */
+  get gameConfig(): GameConfig | null {
+    return this.config
+  }
+
+  /**
+   * Reconstruct the full save file from a new party (PokemonInstance[]).
+   * Updates SaveBlock1 with the given party and returns a new Uint8Array representing the reconstructed save file.
+   *
+   * @param partyPokemon Array of PokemonInstance to update party in SaveBlock1
+   * @param boxes Optional PC boxes (saveData.boxes) to write back to PC storage as well
+   */
+  reconstructSaveFile(
+    partyPokemon: readonly PokemonBase[],
+    boxes?: readonly (readonly (PokemonBase | null)[])[]
+  ): Uint8Array {
+    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
+
+    const baseSaveblock1 = this.extractSaveblock1()
+    const updatedSaveblock1 = this.updatePartyInSaveblock1(baseSaveblock1, partyPokemon)
+    const pokemonStorage = boxes && this.updateBoxesInStorage(this.extractPokemonStorage(), boxes)
+    return this.writeSaveFile({ saveblock1: updatedSaveblock1, pokemonStorage })
+  }
+
+  /**
+   * Write PC boxes back into PC storage in the 80-byte box format
+   * Emptied slots are cleared; corrupt slots (null when parsed) are left untouched
+   */
+  private updateBoxesInStorage(
+    storage: Uint8Array,
+    boxes: readonly (readonly (PokemonBase | null)[])[]
+  ): Uint8Array {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const { boxPokemon, boxCount, boxSlotCount, boxPokemonSize } = this.config.saveLayout
+    if (boxes.length !== boxCount || boxes.some(box => box.length !== boxSlotCount)) {
+      throw new Error(`Expected ${boxCount} boxes of ${boxSlotCount} slots`)
+    }
+
+    const updated = new Uint8Array(storage)
+    boxes.forEach((slots, box) => {
+      slots.forEach((pokemon, slot) => {
+        const offset = boxPokemon + (box * boxSlotCount + slot) * boxPokemonSize
+        if (pokemon) {
+          updated.set(pokemon.toBoxData(), offset)
+          return
+        }
+        const existing = PokemonBase.fromBoxData(
+          updated.subarray(offset, offset + boxPokemonSize),
+          this.config!
+        )
+        if (existing.speciesId !== 0 && !checkPokemonSanity(existing, this.config!)) {
+          updated.fill(0, offset, offset + boxPokemonSize)
+        }
+      })
+    })
+    return updated
+  }
+
+  /**
+   * Set how many of an item (mapped ID) a bag pocket holds in SaveBlocks from getSaveBlocks;
+   * 0 removes it. Quantities are re-encrypted with the SaveBlock2 key, the pocket is compacted and
+   * the TM/HM and berry pockets are kept sorted like the game does. Write back with writeSaveFile
+   */
+  setBagItem(blocks: SaveBlocks, pocket: BagPocketName, itemId: number, quantity: number): void {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!this.hasExtendedSaveData()) {
+      throw new Error(`The bag layout of ${this.config.name} is not mapped`)
+    }
+
+    const items = this.config.mappings?.items
+    const rawItemId = toRawId(items, itemId)
+    const mapping = items?.get(rawItemId)
+    if (!mapping || rawItemId === 0) {
+      throw new Error(`Item ${itemId} does not exist in ${this.config.name}`)
+    }
+    if (!isItemAllowedInPocket(mapping.id_name, pocket)) {
+      throw new Error(`${mapping.name} cannot be stored in the "${pocket}" pocket`)
+    }
+    const capacity = getBagItemCapacity(pocket, mapping.id_name)
+    if (!Number.isInteger(quantity) || quantity < 0 || quantity > capacity) {
+      throw new Error(`${mapping.name} quantity must be between 0 and ${capacity}`)
+    }
+
+    const [, offset, count] = this.getBagPockets().find(([name]) => name === pocket)!
+    const entries = this.parseBagSlots(blocks.saveblock1, blocks.saveblock2)
+      .filter(slot => slot.pocket === pocket && slot.rawItemId !== 0)
+      .map(({ rawItemId, quantity }) => ({ rawItemId, quantity }))
+    const existing = entries.findIndex(entry => entry.rawItemId === rawItemId)
+    if (existing !== -1) {
+      entries[existing]!.quantity = quantity
+    } else if (quantity > 0) {
+      if (entries.length >= count) {
+        throw new Error(`The "${pocket}" pocket is full (${count} slots)`)
+      }
+      entries.push({ rawItemId, quantity })
+    }
+    const kept = entries.filter(entry => entry.quantity > 0)
+    if (pocket === 'tmHm' || pocket === 'berries') kept.sort((a, b) => a.rawItemId - b.rawItemId)
+
+    const key = this.getSaveEncryptionKey(blocks.saveblock2)
+    const view = new DataView(blocks.saveblock1.buffer, blocks.saveblock1.byteOffset)
+    for (let slot = 0; slot < count; slot++) {
+      const entry = kept[slot]
+      view.setUint16(offset + slot * 4, entry?.rawItemId ?? 0, true)
+      view.setUint16(offset + slot * 4 + 2, applySecurityKey(entry?.quantity ?? 0, key, 16), true)
+    }
+  }
+
+  /**
+   * Set money, coins and/or Battle Points in SaveBlocks from getSaveBlocks, clamped to the game's
+   * caps (VANILLA_CURRENCY_LIMITS unless the config overrides them). Money and coins are stored
+   * XORed with the security key. Returns the values written; write back with writeSaveFile
+   */
+  setCurrency(blocks: SaveBlocks, values: Partial<Currency>): Currency {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!this.hasExtendedSaveData()) {
+      throw new Error(`The currency layout of ${this.config.name} is not mapped`)
+    }
+
+    const limits = { ...VANILLA_CURRENCY_LIMITS, ...this.config.currencyLimits }
+    const clamp = (value: number, max: number) => Math.max(0, Math.min(max, Math.trunc(value)))
+    const current = this.parseCurrency(blocks.saveblock1, blocks.saveblock2)
+    const updated: Currency = {
+      money: clamp(values.money ?? current.money, limits.money),
+      coins: clamp(values.coins ?? current.coins, limits.coins),
+      battle_points: clamp(values.battle_points ?? current.battle_points, limits.battle_points),
+    }
+
+    const { money, coins, battlePoints } = this.config.saveLayout
+    const view1 = new DataView(blocks.saveblock1.buffer, blocks.saveblock1.byteOffset)
+    const view2 = new DataView(blocks.saveblock2.buffer, blocks.saveblock2.byteOffset)
+    const key = this.getSaveEncryptionKey(blocks.saveblock2)
+    view1.setUint32(money, applySecurityKey(updated.money, key), true)
+    view1.setUint16(coins, applySecurityKey(updated.coins, key, 16), true)
+    view2.setUint16(battlePoints, updated.battle_points, true)
+    return updated
+  }
+
+  /**
+   * Change the player's name, gender and/or trainer IDs in SaveBlocks from getSaveBlocks
+   * With cascade, party, PC and daycare Pokemon caught by the old trainer get the new OT name, ID
+   * and gender, so the game keeps treating them as the player's own (full obedience, no traded
+   * experience boost). Returns how many Pokemon were updated; write back with writeSaveFile
+   */
+  setTrainerProfile(
+    blocks: SaveBlocks,
+    profile: TrainerProfileUpdate,
+    options: { cascade?: boolean } = {}
+  ): number {
+    if (!this.config) throw new Error('Config not loaded')
+
+    const { name, gender, trainer_id, secret_id } = profile
+    if (name !== undefined) {
+      const encoded = gbaStringToBytes(name, 8)
+      if (name.length < 1 || name.length > 7 || bytesToGbaString(encoded) !== name) {
+        throw new Error(`"${name}" is not a valid player name (1-7 characters of the game charset)`)
+      }
+    }
+    for (const [field, id] of [['trainer_id', trainer_id], ['secret_id', secret_id]] as const) {
+      if (id !== undefined && (!Number.isInteger(id) || id < 0 || id > 0xffff)) {
+        throw new Error(`${field} must be between 0 and 65535, got ${id}`)
+      }
+    }
+
+    const oldName = this.parsePlayerName(blocks.saveblock2)
+    const old = this.parseTrainerInfo(blocks.saveblock2)
+    const newName = name ?? oldName
+    const newGender = gender ?? old.gender
+    const newOtId = (((secret_id ?? old.secret_id) << 16) | (trainer_id ?? old.trainer_id)) >>> 0
+
+    const { playerGender, trainerId } = this.config.saveLayout
+    const view = new DataView(blocks.saveblock2.buffer, blocks.saveblock2.byteOffset)
+    blocks.saveblock2.set(gbaStringToBytes(newName, 8), 0)
+    view.setUint8(playerGender, newGender === 'female' ? 1 : 0)
+    view.setUint32(trainerId, newOtId, true)
+
+    if (!options.cascade) return 0
+    // Move the player's Pokemon (OT name and ID match the old trainer) over to the new trainer
+    return this.retagPokemon(blocks, pokemon =>
+      pokemon.otId === old.ot_id && pokemon.otName === oldName
+        ? { name: newName, otId: newOtId, gender: newGender }
+        : undefined
+    )
+  }
+
+  /**
+   * Strip the identifying data from a save so it can be shared: the player gets the name PLAYER
+   * and random trainer IDs, every other original trainer (traded Pokemon, mail senders, secret
+   * bases from record mixing) becomes TRAINER with random IDs. Each new ID pair keeps the XOR of
+   * the old one, so no Pokemon changes its shininess, and the player's own Pokemon stay theirs.
+   * Hall of Fame, TV shows and Battle Frontier records are left as they are. Write SaveBlock1,
+   * SaveBlock2 and PC storage back with writeSaveFile
+   */
+  anonymizeSave(blocks: SaveBlocks, random: () => number = Math.random): AnonymizeResult {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!this.hasExtendedSaveData()) {
+      throw new Error(`The trainer data layout of ${this.config.name} is not mapped`)
+    }
+
+    // Same OT, same replacement, so Pokemon from one trade partner still share an OT
+    const replacements = new Map<string, number>()
+    const replaceOtId = (name: string, otId: number) => {
+      const key = `${name}:${otId}`
+      let replacement = replacements.get(key)
+      if (replacement === undefined) {
+        const trainerId = Math.floor(random() * 0x10000)
+        const secretId = trainerId ^ (otId & 0xffff) ^ (otId >>> 16)
+        replacement = ((secretId << 16) | trainerId) >>> 0
+        replacements.set(key, replacement)
+      }
+      return replacement
+    }
+
+    const oldName = this.parsePlayerName(blocks.saveblock2)
+    const old = this.parseTrainerInfo(blocks.saveblock2)
+    const otId = replaceOtId(oldName, old.ot_id)
+    const player = { name: ANONYMOUS_PLAYER_NAME, otId }
+    const anonymize = (name: string, id: number) =>
+      name === oldName && id === old.ot_id
+        ? player
+        : { name: ANONYMOUS_TRAINER_NAME, otId: replaceOtId(name, id) }
+
+    this.setTrainerProfile(blocks, {
+      name: ANONYMOUS_PLAYER_NAME,
+      trainer_id: otId & 0xffff,
+      secret_id: otId >>> 16,
+    })
+    const pokemon = this.retagPokemon(blocks, mon => ({
+      ...anonymize(mon.otName, mon.otId),
+      gender: mon.otGender,
+    }))
+
+    const layout = this.config.saveLayout
+    const { saveblock1 } = blocks
+    const view = new DataView(saveblock1.buffer, saveblock1.byteOffset)
+    let mail = 0
+    for (let slot = 0; slot < layout.mailCount; slot++) {
+      const offset = layout.mail + slot * layout.mailSize
+      if (view.getUint16(offset + 0x20, true) === 0) continue
+      const sender = decodePokemonText(saveblock1.slice(offset + 0x12, offset + 0x1a))
+      const replacement = anonymize(sender, view.getUint32(offset + 0x1a, true))
+      saveblock1.set(gbaStringToBytes(replacement.name, 8), offset + 0x12)
+      view.setUint32(offset + 0x1a, replacement.otId, true)
+      mail++
+    }
+
+    let secretBases = 0
+    for (let index = 0; index < layout.secretBaseCount; index++) {
+      const offset = layout.secretBases + index * layout.secretBaseSize
+      if (view.getUint8(offset) === 0) continue
+      const owner = decodePokemonText(saveblock1.slice(offset + 0x2, offset + 0x9))
+      const replacement = anonymize(owner, view.getUint32(offset + 0x9, true))
+      saveblock1.set(gbaStringToBytes(replacement.name, 7), offset + 0x2)
+      view.setUint32(offset + 0x9, replacement.otId, true)
+      secretBases++
+    }
+
+    return {
+      player_name: ANONYMOUS_PLAYER_NAME,
+      trainer_id: otId & 0xffff,
+      secret_id: otId >>> 16,
+      pokemon,
+      mail,
+      secret_bases: secretBases,
+    }
+  }
+
+  /**
+   * Give party, PC and daycare Pokemon a new original trainer where `retag` returns one; the
+   * substructs are re-encrypted for the new OT ID. Returns how many Pokemon changed
+   */
+  private retagPokemon(
+    blocks: SaveBlocks,
+    retag: (
+      pokemon: PokemonBase
+    ) => { name: string; otId: number; gender: 'male' | 'female' } | undefined
+  ): number {
+    if (!this.config) {
+      throw new Error('Config not loaded')
+    }
+
+    const config = this.config
+    const layout = config.saveLayout
+    // Every slot starts with the 80-byte box format, which holds all of the OT data
+    const slots: [Uint8Array, number][] = []
+    for (let slot = 0; slot < config.maxPartySize; slot++) {
+      slots.push([blocks.saveblock1, layout.partyOffset + slot * config.pokemonSize])
+    }
+    for (let slot = 0; slot < layout.boxCount * layout.boxSlotCount; slot++) {
+      slots.push([blocks.pokemonStorage, layout.boxPokemon + slot * layout.boxPokemonSize])
+    }
+    if (this.hasExtendedSaveData()) {
+      for (const slot of [0, 1]) {
+        slots.push([blocks.saveblock1, layout.daycare + slot * layout.daycareSlotSize])
+      }
+    }
+
+    let updated = 0
+    for (const [block, offset] of slots) {
+      const pokemon = PokemonBase.fromBoxData(
+        block.subarray(offset, offset + layout.boxPokemonSize),
+        config
+      )
+      if (pokemon.speciesId === 0 || checkPokemonSanity(pokemon, config)) continue
+      const trainer = retag(pokemon)
+      if (!trainer) continue
+
+      pokemon.setOriginalTrainer(trainer.name, trainer.otId, trainer.gender)
+      block.set(pokemon.toBoxData(), offset)
+      updated++
+    }
+    return updated
+  }
+
+  /**
+   * Set gym badges and story milestones in SaveBlocks from getSaveBlocks, e.g. to get past a
+   * softlock. The National Dex is switched on or off the way the game's EnableNationalPokedex does
+   * it (SaveBlock2 magic, VAR_NATIONAL_DEX, the system flag and the Pokedex mode).
+   * Write both SaveBlock1 and SaveBlock2 back with writeSaveFile
+   */
+  setStoryProgress(blocks: SaveBlocks, changes: StoryProgressUpdate): void {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!this.hasExtendedSaveData()) {
+      throw new Error(`The event flags of ${this.config.name} are not mapped`)
+    }
+
+    const layout = this.config.saveLayout
+    const { saveblock1, saveblock2 } = blocks
+    const { badges, national_dex } = changes
+    if (badges) {
+      if (badges.length !== HOENN_BADGE_NAMES.length) {
+        throw new Error(`Expected ${HOENN_BADGE_NAMES.length} badges, got ${badges.length}`)
+      }
+      badges.forEach((badge, i) => this.writeFlag(saveblock1, layout.badgeFlagStart + i, badge))
+    }
+
+    const flags = [
+      [changes.received_pokedex, layout.pokedexFlag],
+      [changes.received_pokenav, layout.pokenavFlag],
+      [changes.running_shoes, layout.runningShoesFlag],
+      [changes.game_cleared, layout.gameClearFlag],
+      [national_dex, layout.nationalDexFlag],
+    ] as const
+    for (const [value, flagId] of flags) {
+      if (value !== undefined) this.writeFlag(saveblock1, flagId, value)
+    }
+
+    if (national_dex !== undefined) {
+      saveblock2[layout.nationalDexMagic] = national_dex ? NATIONAL_DEX_MAGIC : 0
+      saveblock2[layout.pokedexMode] = national_dex ? DEX_MODE_NATIONAL : DEX_MODE_HOENN
+      this.writeVar(saveblock1, layout.nationalDexVar, national_dex ? NATIONAL_DEX_VAR_VALUE : 0)
+    }
+  }
+
+  /**
+   * Mark species (National Dex numbers) as seen and/or caught in SaveBlocks from getSaveBlocks
+   * Seen is written to SaveBlock2 and both SaveBlock1 mirrors, as the game does. Caught implies
+   * seen, and clearing seen clears caught too. Write SaveBlock1 and SaveBlock2 back with
+   * writeSaveFile
+   */
+  setPokedexFlags(
+    blocks: SaveBlocks,
+    speciesIds: readonly number[],
+    flags: { seen?: boolean; caught?: boolean }
+  ): void {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!this.hasExtendedSaveData()) {
+      throw new Error(`The Pokedex layout of ${this.config.name} is not mapped`)
+    }
+
+    const invalid = speciesIds.find(
+      id => !Number.isInteger(id) || id < 1 || id > NATIONAL_DEX_COUNT
+    )
+    if (invalid !== undefined) {
+      throw new Error(`Species ${invalid} is outside the National Dex (1-${NATIONAL_DEX_COUNT})`)
+    }
+
+    const layout = this.config.saveLayout
+    const { saveblock1, saveblock2 } = blocks
+    const seen = flags.caught ? true : flags.seen
+    const caught = flags.seen === false ? false : flags.caught
+    for (const speciesId of speciesIds) {
+      if (seen !== undefined) {
+        this.writeDexFlag(saveblock2, layout.pokedexSeen, speciesId, seen)
+        this.writeDexFlag(saveblock1, layout.pokedexSeen1, speciesId, seen)
+        this.writeDexFlag(saveblock1, layout.pokedexSeen2, speciesId, seen)
+      }
+      if (caught !== undefined) {
+        this.writeDexFlag(saveblock2, layout.pokedexOwned, speciesId, caught)
+      }
+    }
+  }
+
+  /**
+   * Repair desynced Pokedex data in SaveBlocks from getSaveBlocks: species seen in SaveBlock2 or
+   * caught are written to both SaveBlock1 seen mirrors, and stray mirror bits are cleared.
+   * Returns the National Dex numbers that changed
+   */
+  syncPokedex(blocks: SaveBlocks): number[] {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!this.hasExtendedSaveData()) {
+      throw new Error(`The Pokedex layout of ${this.config.name} is not mapped`)
+    }
+
+    const layout = this.config.saveLayout
+    const { saveblock1, saveblock2 } = blocks
+    const seen = this.readDexFlags(saveblock2, layout.pokedexSeen)
+    const caught = this.readDexFlags(saveblock2, layout.pokedexOwned)
+    const seen1 = this.readDexFlags(saveblock1, layout.pokedexSeen1)
+    const seen2 = this.readDexFlags(saveblock1, layout.pokedexSeen2)
+
+    const changed: number[] = []
+    seen.forEach((flag, i) => {
+      const value = flag || caught[i]!
+      if (value === flag && value === seen1[i] && value === seen2[i]) return
+      this.setPokedexFlags(blocks, [i + 1], { seen: value })
+      changed.push(i + 1)
+    })
+    return changed
+  }
+
+  /**
+   * Copy the active slot's SaveBlock1, SaveBlock2 and PC storage for editing with writeSaveFile
+   */
+  getSaveBlocks(): SaveBlocks {
+    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
+
+    return {
+      saveblock1: this.extractSaveblock1(),
+      saveblock2: this.extractSaveblock2(),
+      pokemonStorage: this.extractPokemonStorage(),
+    }
+  }
+
+  /**
+   * Serialize SaveBlocks back into the active slot's sectors and return the new save file
+   * Every written sector gets its footer ID, signature and checksum rewritten so the game accepts
+   * it; omitted blocks and sectors missing from the slot are left untouched
+   */
+  writeSaveFile(blocks: Partial<SaveBlocks>): Uint8Array {
+    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
+
+    const { sectorSize, sectorDataSize, pokemonStorageSectorStart } = this.config.saveLayout
+    const newSave = new Uint8Array(this.saveData)
+
+    const writeBlock = (data: Uint8Array | undefined, firstSectorId: number) => {
+      if (!data) return
+      for (let chunkOffset = 0; chunkOffset < data.length; chunkOffset += sectorDataSize) {
+        const sectorId = firstSectorId + chunkOffset / sectorDataSize
+        const sectorIdx = this.sectorMap.get(sectorId)
+        if (sectorIdx === undefined) continue
+
+        // Pad short chunks with zeros, matching how the game clears a sector before writing it
+        const chunk = new Uint8Array(sectorDataSize)
+        chunk.set(data.subarray(chunkOffset, chunkOffset + sectorDataSize))
+        const startOffset = sectorIdx * sectorSize
+        newSave.set(chunk, startOffset)
+
+        const footerOffset = startOffset + sectorSize - 12
+        const footer = new DataView(newSave.buffer, newSave.byteOffset + footerOffset, 12)
+        footer.setUint16(0, sectorId, true)
+        footer.setUint16(2, this.calculateSectorChecksum(chunk), true)
+        footer.setUint32(4, VANILLA_EMERALD_SIGNATURE, true)
+      }
+    }
+
+    writeBlock(blocks.saveblock2, 0)
+    writeBlock(blocks.saveblock1, 1)
+    writeBlock(blocks.pokemonStorage, pokemonStorageSectorStart)
+    return newSave
+  }
+
+  /**
+   * Repair the newest save slot (or the forced one) of a loaded save file whose sectors fail the
+   * checksum or signature check; the game itself would fall back to the previous save. A sector
+   * with an intact footer (ID, signature and save counter) keeps its data and gets its checksum
+   * recomputed, e.g. after a hex edit; otherwise it is copied from the backup slot, which holds
+   * the previous save. Returns the repaired file and what was done per broken sector
+   */
+  repairSave(): SaveRepairResult {
+    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
+
+    const { sectorSize, sectorDataSize } = this.config.saveLayout
+    const validSectors = (start: number) =>
+      new Map(
+        Array.from({ length: SLOT_SECTOR_COUNT }, (_, i) => start + i)
+          .map(index => [this.getSectorInfo(index), index] as const)
+          .filter(([info]) => info.valid && info.id < SLOT_SECTOR_COUNT)
+          .map(([info, index]) => [info.id, { index, counter: info.counter }] as const)
+      )
+    const slots = [0, SLOT_SECTOR_COUNT].map(start => {
+      const sectors = validSectors(start)
+      const counter = Math.max(-1, ...[...sectors.values()].map(sector => sector.counter))
+      return { start, sectors, counter }
+    })
+    const newest = slots[1]!.counter > slots[0]!.counter ? 1 : 0
+    const target = this.forcedSlot === undefined ? newest : this.forcedSlot - 1
+
+    const { start: activeStart, sectors: active, counter } = slots[target]!
+    const backup = slots[1 - target]!.sectors
+    const [first] = active
+    if (!first) throw new Error('The active save slot has no valid sector to repair from')
+    // The game rotates a slot's sectors by the save counter; recover the rotation from any sector
+    const [firstId, { index: firstIndex }] = first
+    const rotation = (firstIndex - activeStart - firstId + SLOT_SECTOR_COUNT) % SLOT_SECTOR_COUNT
+
+    const repaired = new Uint8Array(this.saveData)
+    const writeFooter = (index: number, sectorId: number) => {
+      const start = index * sectorSize
+      const chunk = repaired.subarray(start, start + sectorDataSize)
+      const footerOffset = repaired.byteOffset + start + sectorSize - 12
+      const footer = new DataView(repaired.buffer, footerOffset, 12)
+      footer.setUint16(0, sectorId, true)
+      footer.setUint16(2, this.calculateSectorChecksum(chunk), true)
+      footer.setUint32(4, VANILLA_EMERALD_SIGNATURE, true)
+      footer.setUint32(8, counter, true)
+    }
+
+    const repairs: SectorRepair[] = []
+    for (let sectorId = 0; sectorId < SLOT_SECTOR_COUNT; sectorId++) {
+      if (active.has(sectorId)) continue
+      const index = activeStart + ((sectorId + rotation) % SLOT_SECTOR_COUNT)
+      const info = this.getSectorInfo(index)
+      const view = new DataView(repaired.buffer, repaired.byteOffset + index * sectorSize)
+      const signed = view.getUint32(sectorSize - 8, true) === VANILLA_EMERALD_SIGNATURE
+      const source = backup.get(sectorId)
+
+      let action: SectorRepair['action'] = 'unrecoverable'
+      if (signed && info.id === sectorId && info.counter === counter) {
+        writeFooter(index, sectorId)
+        action = 'checksum-fixed'
+      } else if (source) {
+        const from = source.index * sectorSize
+        repaired.copyWithin(index * sectorSize, from, from + sectorDataSize)
+        writeFooter(index, sectorId)
+        action = 'restored-from-backup'
+      }
+      repairs.push({ sector_id: sectorId, sector_index: index, action })
+    }
+    return { data: repaired, repairs }
+  }
+
+  /**
+   * Copy every sector of one save slot over the other and give the copy the highest save counter,
+   * so the game loads it. Copying the older slot rolls back to the previous in-game save; copying
+   * the newer one makes the backup match the current save. Returns the new save file
+   */
+  copySaveSlot(from: 1 | 2): Uint8Array {
+    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
+
+    const { sectorSize } = this.config.saveLayout
+    const sourceStart = from === 1 ? 0 : SLOT_SECTOR_COUNT
+    const targetStart = from === 1 ? SLOT_SECTOR_COUNT : 0
+    const infos = Array.from({ length: SLOT_SECTOR_COUNT * 2 }, (_, i) => this.getSectorInfo(i))
+    const source = infos.slice(sourceStart, sourceStart + SLOT_SECTOR_COUNT)
+    const validIds = new Set(
+      source.filter(info => info.valid && info.id < SLOT_SECTOR_COUNT).map(info => info.id)
+    )
+    if (validIds.size !== SLOT_SECTOR_COUNT) {
+      throw new Error(
+        `Slot ${from} is incomplete (${validIds.size} of ${SLOT_SECTOR_COUNT} valid sectors)`
+      )
+    }
+
+    const counter = Math.max(...infos.filter(info => info.valid).map(info => info.counter)) + 1
+    const copy = new Uint8Array(this.saveData)
+    copy.copyWithin(
+      targetStart * sectorSize,
+      sourceStart * sectorSize,
+      (sourceStart + SLOT_SECTOR_COUNT) * sectorSize
+    )
+    // The counter sits outside the checksummed data, so the sector checksums stay valid
+    for (let i = 0; i < SLOT_SECTOR_COUNT; i++) {
+      const footerOffset = (targetStart + i + 1) * sectorSize - 12
+      const footer = new DataView(copy.buffer, copy.byteOffset + footerOffset)
+      footer.setUint32(8, counter >>> 0, true)
+    }
+    return copy
+  }
+
+  /**
+   * Dry run of an edit: the byte ranges an edited save file (from writeSaveFile,
+   * reconstructSaveFile, repairSave...) changes compared to the loaded save
+   */
+  previewEdit(edited: Uint8Array): ByteRangeChange[] {
+    if (!this.saveData) throw new Error('Save data not loaded')
+    return diffSaveBytes(this.saveData, edited)
+  }
+
+  /**
+   * Make an edited save file the loaded one, recording the changed bytes as an undoable
+   * transaction, and return it parsed. An edit that changes nothing is not recorded
+   */
+  async applyEdit(label: string, edited: Uint8Array): Promise<SaveData> {
+    if (!this.saveData) throw new Error('Save data not loaded')
+    const log = this.editLog ?? new EditLog(this.saveData)
+    log.record(label, edited)
+    return this.reloadEdited(log)
+  }
+
+  /**
+   * Revert the last applied edit and return the save parsed, or null when there is nothing to undo
+   */
+  async undo(): Promise<SaveData | null> {
+    const log = this.editLog
+    if (!log?.undo()) return null
+    return this.reloadEdited(log)
+  }
+
+  /**
+   * Reapply the last undone edit and return the save parsed, or null when there is nothing to redo
+   */
+  async redo(): Promise<SaveData | null> {
+    const log = this.editLog
+    if (!log?.redo()) return null
+    return this.reloadEdited(log)
+  }
+
+  /** Edits applied since the file was loaded, oldest first */
+  get editHistory(): readonly EditTransaction[] {
+    return this.editLog?.history ?? []
+  }
+
+  get canUndo(): boolean {
+    return this.editLog?.canUndo ?? false
+  }
+
+  get canRedo(): boolean {
+    return this.editLog?.canRedo ?? false
+  }
+
+  // Parse the log's current bytes, keeping the log and the container the file was loaded from
+  private async reloadEdited(log: EditLog): Promise<SaveData> {
+    const container = this.inputContainer
+    const saveData = await this.parse(log.current.buffer as ArrayBuffer)
+    this.editLog = log
+    this.inputContainer = container
+    return saveData
+  }
+
+  /**
+   * Gather the in-game trainer card (name, ID, money, Pokedex, play time, badges, back-side stats)
+   * Fields that need SaveBlock data the save doesn't have (e.g. in memory mode) are null
+   */
+  getTrainerCard(saveData: SaveData): TrainerCard {
+    if (!this.config) throw new Error('Config not loaded')
+    return createTrainerCard(this.config.name, saveData)
+  }
+
+  /**
+   * Export the party as a Pokemon Showdown paste (species, item, ability, EVs, IVs, nature, moves)
+   */
+  exportShowdown(saveData: SaveData): string {
+    if (!this.config) throw new Error('Config not loaded')
+    return exportShowdown(saveData, this.config)
+  }
+
+  /**
+   * Build Pokemon from a Showdown paste and add them to the party, owned by the save's trainer
+   * With replaceParty the paste becomes the whole party; fails when a set is invalid or illegal
+   * in Gen 3 or the party would exceed its size. Write back with reconstructSaveFile
+   */
+  importShowdown(
+    saveData: SaveData,
+    paste: string,
+    options: ShowdownImportOptions & { replaceParty?: boolean } = {}
+  ): SaveData {
+    if (!this.config) throw new Error('Config not loaded')
+    if (!saveData.trainer) throw new Error('Trainer info is needed to own imported Pokemon')
+
+    const trainer = {
+      name: saveData.player_name,
+      gender: saveData.trainer.gender,
+      otId: saveData.trainer.ot_id,
+    }
+    const team = parseShowdownPaste(paste).map(set => {
+      const pokemon = createShowdownPokemon(set, trainer, this.config!, options)
+      return this.checkImportedPokemon(pokemon, 'Showdown set')
+    })
+    if (team.length === 0) throw new Error('The paste contains no Pokemon')
+    const party = options.replaceParty ? team : [...saveData.party_pokemon, ...team]
+    if (party.length > this.config.maxPartySize) {
+      throw new Error(`Party size cannot exceed ${this.config.maxPartySize}`)
+    }
+    return { ...saveData, party_pokemon: party }
+  }
+
+  /**
+   * Build a versioned trainer card snapshot (trainer info, badges, play time and party)
+   * Only available for file-based saves since memory mode has no SaveBlock access yet
+   *
+   * @param saveData Parsed save data whose party should be captured (defaults to the loaded save)
+   */
+  async getTrainerCardSnapshot(saveData?: SaveData): Promise<TrainerCardSnapshot> {
+    if (this.isMemoryMode) {
+      throw new Error('Trainer card export is only available for save files')
+    }
+    if (!this.saveData || !this.config) throw new Error('Save data and config not loaded')
+
+    const data = saveData ?? (await this.parse(this.saveData.buffer as ArrayBuffer))
+    const trainer = data.trainer ?? this.parseTrainerInfo(this.extractSaveblock2())
+
+    return createTrainerCardSnapshot({
+      game: this.config.name,
+      playerName: data.player_name,
+      gender: trainer.gender,
+      trainerId: trainer.trainer_id,
+      secretId: trainer.secret_id,
+      badges: data.progress?.badges ?? [],
+      playTime: data.play_time,
+      party: data.party_pokemon,
+    })
+  }
+
+  /**
+   * Validate held items and bag contents against the active game's item availability
+   * Bag checks are skipped in memory mode and for configs without a mapped SaveBlock layout
+   */
+  validateItems(saveData: SaveData): SaveWarning[] {
+    if (!this.config) throw new Error('Config not loaded')
+
+    if (this.isMemoryMode || !this.saveData) {
+      return validateItemLegality(this.config, saveData.party_pokemon, [])
+    }
+    const bagSlots = this.hasExtendedSaveData()
+      ? this.parseBagSlots(this.extractSaveblock1(), this.extractSaveblock2())
+      : []
+    return validateItemLegality(this.config, saveData.party_pokemon, bagSlots)
+  }
+
+  /**
+   * Verification mode: flag party Pokemon whose stored stats differ from the recomputed values
+   * Returns no warnings for games whose base stats are not Gen 3's
+   */
+  verifyStats(saveData: SaveData): SaveWarning[] {
+    return validateStats(saveData.party_pokemon)
+  }
+
+  /**
+   * Run the Gen 3 legality checks on each party Pokemon, in party_pokemon order
+   */
+  checkLegality(saveData: SaveData): LegalityReport[] {
+    if (!this.config) throw new Error('Config not loaded')
+    const config = this.config
+    return saveData.party_pokemon.map(pokemon => checkLegality(pokemon, config))
+  }
+
+  /**
+   * Iterate every Pokemon in the parsed save (party, PC boxes, daycare) with its location
+   */
+  allPokemon(saveData: SaveData): Generator<PokemonMatch> {
+    return allPokemon(saveData)
+  }
+
+  /**
+   * Search the parsed save for Pokemon matching a query (species, level range, shiny, OT, move, IVs)
+   */
+  findPokemon(saveData: SaveData, query: PokemonQuery): PokemonMatch[] {
+    return findPokemon(saveData, query, this.config)
+  }
+
+  /**
+   * Swap two party members (0-based); write the result back with reconstructSaveFile
+   */
+  swapPartySlots(saveData: SaveData, a: number, b: number): SaveData {
+    return swapPartySlots(saveData, a, b)
+  }
+
+  /**
+   * Remove a party member (0-based); reconstructSaveFile shifts the rest up and updates the count
+   */
+  removeFromParty(saveData: SaveData, index: number): SaveData {
+    return removeFromParty(saveData, index)
+  }
+
+  /**
+   * Release the Pokemon in a party or 0-based box slot like the PC does: the party closes the gap,
+   * a box slot is cleared. Write the result back with reconstructSaveFile(party, boxes)
+   */
+  releasePokemon(saveData: SaveData, location: InjectTarget): SaveData {
+    return releasePokemon(saveData, location)
+  }
+
+  /**
+   * Restore the party's HP, status and PP like a Pokemon Center
+   * Write the result back with reconstructSaveFile
+   */
+  healParty(saveData: SaveData): SaveData {
+    return healParty(saveData)
+  }
+
+  /**
+   * Deposit a party member into a PC box (0-based; first free slot when slot is omitted)
+   * Write the result back with reconstructSaveFile(result.party_pokemon, result.boxes)
+   */
+  depositToBox(saveData: SaveData, partyIndex: number, box: number, slot?: number): SaveData {
+    return depositToBox(saveData, partyIndex, box, slot)
+  }
+
+  /**
+   * Withdraw a PC box Pokemon (0-based) to the end of the party, recomputing its battle stats
+   */
+  withdrawFromBox(saveData: SaveData, box: number, slot: number): SaveData {
+    if (!this.config) throw new Error('Config not loaded')
+    return withdrawFromBox(saveData, box, slot, this.config.maxPartySize)
+  }
+
+  /**
+   * Read a .pk3 file (80 or 100 bytes) for the loaded game, re-encrypted with the Pokemon's key
+   * Throws when the layout isn't supported or the Pokemon fails the sanity checks
+   */
+  importPK3(pk3: Uint8Array): PokemonBase {
+    if (!this.config) throw new Error('Config not loaded')
+    return this.checkImportedPokemon(PokemonBase.fromPK3(pk3, this.config), '.pk3')
+  }
+
+  /**
+   * Read an encrypted PKHeX .ek3 file (80 or 100 bytes) for the loaded game, checked like importPK3
+   */
+  importEK3(ek3: Uint8Array): PokemonBase {
+    if (!this.config) throw new Error('Config not loaded')
+    return this.checkImportedPokemon(PokemonBase.fromEK3(ek3, this.config), '.ek3')
+  }
+
+  /**
+   * Read a scanned Pokemon QR payload ("PK3:" + base64 .pk3), checked like importPK3
+   */
+  importQrPayload(payload: string): PokemonBase {
+    return this.importPK3(parsePokemonQrPayload(payload))
+  }
+
+  /**
+   * Convert a Pokemon parsed from another game (e.g. Quetzal) to the loaded game's layout,
+   * checked like importPK3
+   */
+  importFromGame(pokemon: PokemonBase): PokemonBase {
+    if (!this.config) throw new Error('Config not loaded')
+    return this.checkImportedPokemon(pokemon.convertTo(this.config), 'converted Pokemon')
+  }
+
+  private checkImportedPokemon(pokemon: PokemonBase, source: string): PokemonBase {
+    const issue = checkPokemonSanity(pokemon, this.config!)
+    if (issue) throw new Error(`Invalid ${source} (${issue.reason}): ${issue.message}`)
+    return pokemon
+  }
+
+  /**
+   * Put a Pokemon into a party slot (appending at the party size) or a 0-based box slot
+   * Write the result back with reconstructSaveFile(result.party_pokemon, result.boxes)
+   */
+  injectPokemon(saveData: SaveData, pokemon: PokemonBase, target: InjectTarget): SaveData {
+    if (!this.config) throw new Error('Config not loaded')
+    return injectPokemon(saveData, pokemon, target, this.config.maxPartySize)
+  }
+
+  /**
+   * Preview the nickname/OT renames a template rule would make across the party and PC boxes
+   */
+  planRename(saveData: SaveData, rule: RenameRule): RenameChange[] {
+    return planRename(saveData, rule)
+  }
+
+  /**
+   * Apply renames from planRename; write the result back with
+   * reconstructSaveFile(result.party_pokemon, result.boxes)
+   */
+  applyRename(saveData: SaveData, changes: readonly RenameChange[]): SaveData {
+    return applyRename(saveData, changes)
+  }
+
+  /**
+   * Check if parser is in memory mode
+   */
+  isInMemoryMode(): boolean {
+    return this.isMemoryMode
+  }
+
+  /**
+   * Get the WebSocket client (for memory mode)
+   */
+  getWebSocketClient(): MgbaWebSocketClient | null {
+    return this.webSocketClient
+  }
+
+  /**
+   * Start watching for Pokemon party changes in memory mode
+   * This will automatically set up memory watching for the party data regions
+   * and call the provided callback when changes are detected
+   */
+  async watch(
+    options: {
+      onPartyChange?: (partyPokemon: PokemonBase[]) => void
+      onError?: (error: Error) => void
+    } = {}
+  ): Promise<void> {
+    if (!this.isMemoryMode || !this.webSocketClient) {
+      throw new Error('Watch mode only available in memory mode (WebSocket connection)')
+    }
+
+    if (this.watchingChanges) {
+      throw new Error('Already watching memory. Call stopWatching() first.')
+    }
+
+    if (!this.config?.preloadRegions) {
       throw new Error('No memory addresses configured for watching')
     }
 
@@ -782,6 +2706,50 @@ export class PokemonSaveParser {
       active_slot: 0,
     }
   }
+
+  /**
+   * Push an edited party into the running game's memory (memory mode only). As with
+   * reconstructSaveFile, each Pokemon goes back to the slot it was read from unless the party grew
+   * or shrank, in which case it is written compactly with a new party count. The game only keeps
+   * the change in its save once the player saves in-game
+   */
+  async writePartyToMemory(party: readonly PokemonBase[]): Promise<void> {
+    if (!this.isMemoryMode || !this.webSocketClient) {
+      throw new Error('writePartyToMemory only available in memory mode')
+    }
+    if (!this.config) throw new Error('Config not loaded')
+
+    const { memoryAddresses, pokemonSize, maxPartySize } = this.config
+    if (!memoryAddresses) {
+      throw new Error(`Config "${this.config.name}" does not define memory addresses for writing`)
+    }
+    if (party.length > maxPartySize) {
+      throw new Error(`Party size cannot exceed ${maxPartySize}`)
+    }
+    const wrongSize = party.find(pokemon => pokemon.rawBytes.length !== pokemonSize)
+    if (wrongSize) {
+      throw new Error(
+        `${wrongSize.nickname} is ${wrongSize.rawBytes.length} bytes, ${this.config.name} party Pokemon are ${pokemonSize}`
+      )
+    }
+
+    const { partyData, partyCount } = memoryAddresses
+    if (party.length === this.partySlots.length) {
+      for (let i = 0; i < party.length; i++) {
+        const slot = this.partySlots[i] ?? i
+        await this.webSocketClient.writeBytes(partyData + slot * pokemonSize, party[i]!.rawBytes)
+      }
+      return
+    }
+
+    // Clear the slots past the new party like the game does
+    const data = new Uint8Array(maxPartySize * pokemonSize)
+    party.forEach((pokemon, i) => data.set(pokemon.rawBytes, i * pokemonSize))
+    await this.webSocketClient.writeBytes(partyData, data)
+    await this.webSocketClient.writeBytes(partyCount, Uint8Array.of(party.length))
+    this.partySlots = party.map((_, i) => i)
+    this.skippedSlots = []
+  }
 }
 
 // Export for easier usage
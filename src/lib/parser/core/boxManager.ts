@@ -0,0 +1,143 @@
+/**
+ * Bulk PC box organization: sorting, compacting and moving Pokemon between boxes
+ * Operations change a copy of the parsed boxes; write them back with write(parser), which is
+ * reconstructSaveFile(saveData.party_pokemon, saveData.boxes)
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import type { PokemonSaveParser } from './PokemonSaveParser'
+import type { SaveData } from './types'
+
+export type BoxSortKey = 'dex' | 'level' | 'name'
+
+export const BOX_SORT_KEYS: readonly BoxSortKey[] = ['dex', 'level', 'name']
+
+/** A 0-based PC box slot */
+export interface BoxSlotRef {
+  readonly box: number
+  readonly slot: number
+}
+
+const compareBy: Readonly<Record<BoxSortKey, (a: PokemonBase, b: PokemonBase) => number>> = {
+  dex: (a, b) => a.speciesId - b.speciesId,
+  level: (a, b) => a.level - b.level,
+  name: (a, b) => a.nickname.localeCompare(b.nickname),
+}
+
+const slotKey = (box: number, slot: number) => `${box}:${slot}`
+
+export class BoxManager {
+  private readonly boxes: (PokemonBase | null)[][]
+  // Corrupt slots (skipped when parsing) keep their bytes on write-back, so nothing moves into them
+  private readonly locked: ReadonlySet<string>
+
+  constructor(private readonly source: SaveData) {
+    if (!source.boxes) {
+      throw new Error('PC boxes are not available for this save')
+    }
+    this.boxes = source.boxes.map(slots => [...slots])
+    this.locked = new Set(
+      (source.skipped_slots ?? [])
+        .filter(skipped => skipped.box !== undefined)
+        .map(({ box, slot }) => slotKey(box! - 1, slot - 1))
+    )
+  }
+
+  /** The save with the organized boxes */
+  get saveData(): SaveData {
+    return { ...this.source, boxes: this.boxes.map(slots => [...slots]) }
+  }
+
+  /**
+   * Sort a box by National Dex number, level or nickname (ties by Dex number), packing the
+   * Pokemon into the first slots. Eggs go last
+   */
+  sortBox(box: number, key: BoxSortKey, options: { descending?: boolean } = {}): this {
+    const compare = compareBy[key]
+    const direction = options.descending ? -1 : 1
+    const pokemon = this.take(box).sort((a, b) => {
+      if (a.isEgg !== b.isEgg) return a.isEgg ? 1 : -1
+      return direction * (compare(a, b) || a.speciesId - b.speciesId)
+    })
+    this.place(box, pokemon)
+    return this
+  }
+
+  /**
+   * Move a box's Pokemon into its first slots, keeping their order
+   */
+  compactBox(box: number): this {
+    this.place(box, this.take(box))
+    return this
+  }
+
+  /**
+   * Move the selected Pokemon into the first free slots of another box, in selection order
+   * Nothing moves unless all of them fit; selected Pokemon already in the target box stay put
+   */
+  moveToBox(selection: readonly BoxSlotRef[], target: number): this {
+    this.assertBox(target)
+    const seen = new Set<string>()
+    for (const { box, slot } of selection) {
+      this.assertBox(box)
+      if (!this.boxes[box]![slot]) {
+        throw new Error(`Box ${box + 1} slot ${slot + 1} is empty`)
+      }
+      if (seen.has(slotKey(box, slot))) {
+        throw new Error(`Box ${box + 1} slot ${slot + 1} is selected twice`)
+      }
+      seen.add(slotKey(box, slot))
+    }
+
+    const moving = selection.filter(({ box }) => box !== target)
+    const free = this.freeSlots(target)
+    if (free.length < moving.length) {
+      throw new Error(
+        `Box ${target + 1} has ${free.length} free slots, ${moving.length} Pokemon selected`
+      )
+    }
+    moving.forEach(({ box, slot }, i) => {
+      this.boxes[target]![free[i]!] = this.boxes[box]![slot]!
+      this.boxes[box]![slot] = null
+    })
+    return this
+  }
+
+  /**
+   * Write the organized boxes (and the unchanged party) into a new save file
+   */
+  write(parser: PokemonSaveParser): Uint8Array {
+    return parser.reconstructSaveFile(this.source.party_pokemon, this.boxes)
+  }
+
+  private assertBox(box: number): void {
+    if (!Number.isInteger(box) || box < 0 || box >= this.boxes.length) {
+      throw new Error(`Box ${box + 1} does not exist`)
+    }
+  }
+
+  private freeSlots(box: number): number[] {
+    return this.boxes[box]!.flatMap((pokemon, slot) =>
+      pokemon || this.locked.has(slotKey(box, slot)) ? [] : [slot]
+    )
+  }
+
+  // Empty a box's movable slots and return their Pokemon in slot order
+  private take(box: number): PokemonBase[] {
+    this.assertBox(box)
+    const slots = this.boxes[box]!
+    const pokemon: PokemonBase[] = []
+    slots.forEach((entry, slot) => {
+      if (!entry) return
+      pokemon.push(entry)
+      slots[slot] = null
+    })
+    return pokemon
+  }
+
+  private place(box: number, pokemon: readonly PokemonBase[]): void {
+    this.freeSlots(box).forEach((slot, i) => {
+      this.boxes[box]![slot] = pokemon[i] ?? null
+    })
+  }
+}
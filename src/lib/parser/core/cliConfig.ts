@@ -0,0 +1,105 @@
+/**
+ * The CLI's config file (~/.config/pokemon-save-parser/config.yaml)
+ * Defaults for options repeat users would otherwise type every time; flags on the command line
+ * always win. The file is a flat YAML mapping:
+ *   save_dir: ~/Games/saves   # save names not found in the current directory are looked up here
+ *   format: markdown          # output when no output flag is given (json or a --format value)
+ *   game: quetzal             # game config to use instead of detecting it (--game)
+ *   port: 7102                # mGBA WebSocket server port (--ws-url)
+ */
+
+// --format= exports: the parse command's dispatch, shell completion and the config file's format
+// all use this list
+export const OUTPUT_FORMATS = [
+  'showdown',
+  'html',
+  'markdown',
+  'xlsx',
+  'hexdump',
+  'pokepaste',
+] as const
+
+export type OutputFormat = (typeof OUTPUT_FORMATS)[number]
+
+export interface CliConfig {
+  readonly save_dir?: string
+  /** json or one of OUTPUT_FORMATS */
+  readonly format?: string
+  readonly game?: string
+  readonly port?: number
+}
+
+const STRING_KEYS = ['save_dir', 'format', 'game'] as const
+const LINE = /^([A-Za-z_]\w*)[ \t]*:(?:[ \t]+(.*))?$/
+
+/**
+ * Where the config file lives: $XDG_CONFIG_HOME/pokemon-save-parser, or ~/.config/… without it
+ */
+export function cliConfigPath(env: Readonly<Record<string, string | undefined>>, home: string) {
+  const base = env.XDG_CONFIG_HOME || `${home}/.config`
+  return `${base}/pokemon-save-parser/config.yaml`
+}
+
+// A scalar: 'single' or "double" quoted, or plain text up to a # comment
+function parseScalar(text: string, line: number): string {
+  const quote = text[0]
+  if (quote !== "'" && quote !== '"') return text.replace(/(^|\s)#.*$/, '').trim()
+  // '' is a quote inside single quotes; double quotes take backslash escapes
+  let value = ''
+  let i = 1
+  for (; i < text.length; i++) {
+    const char = text[i]!
+    if (quote === "'" && char === "'" && text[i + 1] === "'") {
+      value += "'"
+      i++
+    } else if (quote === '"' && char === '\\' && i + 1 < text.length) {
+      i++
+      value += text[i]!
+    } else if (char === quote) {
+      break
+    } else {
+      value += char
+    }
+  }
+  const rest = text.slice(i + 1).trim()
+  if (i === text.length || (rest && !rest.startsWith('#'))) {
+    throw new Error(`Line ${line}: unterminated or malformed quoted value`)
+  }
+  return value
+}
+
+/**
+ * Read the settings from a config file's text; throws on lines it can't read, unknown settings,
+ * an unknown format and a port that isn't one
+ */
+export function parseCliConfig(text: string): CliConfig {
+  const config: { -readonly [K in keyof CliConfig]: CliConfig[K] } = {}
+  text.split(/\r?\n/).forEach((raw, i) => {
+    const line = i + 1
+    if (!raw.trim() || raw.trimStart().startsWith('#') || raw === '---') return
+    const match = LINE.exec(raw.trimEnd())
+    if (!match) throw new Error(`Line ${line}: expected "setting: value"`)
+    const key = match[1]!
+    const value = parseScalar((match[2] ?? '').trim(), line)
+    const stringKey = STRING_KEYS.find(name => name === key)
+    if (stringKey) {
+      const formats: readonly string[] = ['json', ...OUTPUT_FORMATS]
+      if (stringKey === 'format' && value && !formats.includes(value)) {
+        throw new Error(`Line ${line}: unknown format "${value}" (expected ${formats.join(', ')})`)
+      }
+      if (value) config[stringKey] = value
+    } else if (key === 'port') {
+      if (!value) return
+      const port = Number(value)
+      if (!Number.isInteger(port) || port < 1 || port > 65535) {
+        throw new Error(`Line ${line}: port must be a number from 1 to 65535`)
+      }
+      config.port = port
+    } else {
+      throw new Error(
+        `Line ${line}: unknown setting "${key}" (expected save_dir, format, game or port)`
+      )
+    }
+  })
+  return config
+}
@@ -0,0 +1,254 @@
+/**
+ * Shell completion scripts (the CLI's `completion` subcommand)
+ * The scripts are generated from a spec of the CLI's commands and options, so they stay in step
+ * with the help text. Save files and directories are completed from the file system when Tab is
+ * pressed; option values (`--format=`, `--sort=`, …) come from the spec
+ */
+
+export const COMPLETION_SHELLS = ['bash', 'zsh', 'fish', 'powershell'] as const
+export type CompletionShell = (typeof COMPLETION_SHELLS)[number]
+
+export interface CompletionCommand {
+  readonly name: string
+  readonly summary: string
+  /** Words completed straight after the command, e.g. `add` and `list` for `index` */
+  readonly arguments?: readonly string[]
+}
+
+export interface CompletionSpec {
+  /** The executable being completed */
+  readonly program: string
+  readonly commands: readonly CompletionCommand[]
+  /** Option names; those taking a value end in `=` (`--json`, `--format=`) */
+  readonly options: readonly string[]
+  /** Choices completed after `--option=`; other value options complete file names */
+  readonly values: Readonly<Record<string, readonly string[]>>
+}
+
+const quote = (text: string) => `'${text.replace(/'/g, "'\\''")}'`
+const quotePowerShell = (text: string) => `'${text.replace(/'/g, "''")}'`
+const functionName = (program: string) => `_${program.replace(/\W/g, '_')}`
+
+// Fish and zsh take option names without the trailing `=`
+const optionName = (option: string) => option.replace(/=$/, '')
+
+function bashScript({ program, commands, options, values }: CompletionSpec): string {
+  const fn = functionName(program)
+  const valueCases = Object.entries(values).map(
+    ([option, choices]) => `      ${option}) choices=${quote(choices.join(' '))} ;;`
+  )
+  const argumentCases = commands
+    .filter(({ arguments: words }) => words)
+    .map(({ name, arguments: words }) => `    ${name}) choices=${quote(words!.join(' '))} ;;`)
+  return `# bash completion for ${program}
+# Load it with: eval "$(${program} completion bash)"
+${fn}() {
+  local line="\${COMP_LINE:0:COMP_POINT}"
+  local cur="\${line##*[[:space:]]}"
+  local commands=${quote(commands.map(({ name }) => name).join(' '))}
+  local options=${quote(options.join(' '))}
+  local choices=""
+  COMPREPLY=()
+
+  # --option=value; bash splits the word at = unless COMP_WORDBREAKS has been changed
+  if [[ "$cur" == --*=* ]]; then
+    local option="\${cur%%=*}" prefix=""
+    [[ "$COMP_WORDBREAKS" == *=* ]] || prefix="$option="
+    case "$option" in
+${valueCases.join('\n')}
+    esac
+    if [[ -n "$choices" ]]; then
+      COMPREPLY=($(compgen -P "$prefix" -W "$choices" -- "\${cur#*=}"))
+    else
+      COMPREPLY=($(compgen -P "$prefix" -f -- "\${cur#*=}"))
+    fi
+    return
+  fi
+
+  if [[ "$cur" == -* ]]; then
+    COMPREPLY=($(compgen -W "$options" -- "$cur"))
+    [[ \${#COMPREPLY[@]} -eq 1 && "\${COMPREPLY[0]}" == *= ]] && compopt -o nospace
+    return
+  fi
+
+  case "\${COMP_WORDS[COMP_CWORD-1]}" in
+${argumentCases.join('\n')}
+  esac
+  if [[ -n "$choices" ]]; then
+    COMPREPLY=($(compgen -W "$choices" -- "$cur"))
+    return
+  fi
+
+  local word
+  for word in "\${COMP_WORDS[@]:1:COMP_CWORD-1}"; do
+    [[ " $commands " == *" $word "* ]] && commands=""
+  done
+  COMPREPLY=(
+    $(compgen -W "$commands" -- "$cur")
+    $(compgen -f -X '!*.[sS][aA][vV]' -- "$cur")
+    $(compgen -d -- "$cur")
+  )
+}
+complete -o filenames -F ${fn} ${program}
+`
+}
+
+function zshScript({ program, commands, options, values }: CompletionSpec): string {
+  const fn = `_${program}`
+  const valueCases = Object.entries(values).map(
+    ([option, choices]) => `      ${option}) choices=(${choices.map(quote).join(' ')}) ;;`
+  )
+  const argumentCases = commands
+    .filter(({ arguments: words }) => words)
+    .map(({ name, arguments: words }) => `    ${name}) compadd -- ${words!.join(' ')}; return ;;`)
+  return `#compdef ${program}
+# zsh completion for ${program}
+# Save it as ${fn} in a directory on $fpath, or load it with: source <(${program} completion zsh)
+${fn}() {
+  local -a commands options choices
+  commands=(
+${commands.map(({ name, summary }) => `    ${quote(`${name}:${summary}`)}`).join('\n')}
+  )
+  options=(${options.join(' ')})
+  local cur="\${words[CURRENT]}"
+
+  if [[ "$cur" == --*=* ]]; then
+    case "\${cur%%=*}" in
+${valueCases.join('\n')}
+    esac
+    compset -P '*='
+    if (( $#choices )); then
+      compadd -a choices
+    else
+      _files
+    fi
+    return
+  fi
+
+  if [[ "$cur" == -* ]]; then
+    compadd -S '' -- \${(M)options:#*=}
+    compadd -- \${options:#*=}
+    return
+  fi
+
+  case "\${words[CURRENT-1]}" in
+${argumentCases.join('\n')}
+  esac
+
+  local word
+  for word in \${words[2,CURRENT-1]}; do
+    (( \${commands[(I)$word:*]} )) && commands=()
+  done
+  (( $#commands )) && _describe 'command' commands
+  _files -g '*.(sav|SAV)'
+}
+
+if [[ "$funcstack[1]" == "${fn}" ]]; then
+  ${fn} "$@"
+else
+  compdef ${fn} ${program}
+fi
+`
+}
+
+function fishScript({ program, commands, options, values }: CompletionSpec): string {
+  const complete = `complete -c ${program}`
+  const commandNames = commands.map(({ name }) => name).join(' ')
+  const lines = [
+    `# fish completion for ${program}`,
+    `# Save it to ~/.config/fish/completions/${program}.fish, or load it with: ${program} completion fish | source`,
+    `${complete} -f`,
+    `${complete} -a '(__fish_complete_suffix .sav)'`,
+    ...commands.map(
+      ({ name, summary }) =>
+        `${complete} -n 'not __fish_seen_subcommand_from ${commandNames}' -a ${name} -d ${quote(summary)}`
+    ),
+    ...commands
+      .filter(({ arguments: words }) => words)
+      .map(
+        ({ name, arguments: words }) =>
+          `${complete} -n '__fish_seen_subcommand_from ${name}' -a ${quote(words!.join(' '))}`
+      ),
+    ...options.map(option => {
+      const name = optionName(option).slice(2)
+      if (!option.endsWith('=')) return `${complete} -l ${name}`
+      const choices = values[optionName(option)]
+      return choices
+        ? `${complete} -l ${name} -x -a ${quote(choices.join(' '))}`
+        : `${complete} -l ${name} -r -F`
+    }),
+  ]
+  return `${lines.join('\n')}\n`
+}
+
+function powerShellScript({ program, commands, options, values }: CompletionSpec): string {
+  const list = (items: readonly string[]) => `@(${items.map(quotePowerShell).join(', ')})`
+  const commandEntries = commands.map(
+    ({ name, summary }) => `    ${quotePowerShell(name)} = ${quotePowerShell(summary)}`
+  )
+  const valueEntries = Object.entries(values).map(
+    ([option, choices]) => `    ${quotePowerShell(option)} = ${list(choices)}`
+  )
+  const argumentEntries = commands
+    .filter(({ arguments: words }) => words)
+    .map(({ name, arguments: words }) => `    ${quotePowerShell(name)} = ${list(words!)}`)
+  return `# PowerShell completion for ${program}
+# Load it from your profile with: ${program} completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName ${quotePowerShell(program)} -ScriptBlock {
+  param($wordToComplete, $commandAst, $cursorPosition)
+  $commands = [ordered]@{
+${commandEntries.join('\n')}
+  }
+  $options = ${list(options)}
+  $values = @{
+${valueEntries.join('\n')}
+  }
+  $arguments = @{
+${argumentEntries.join('\n')}
+  }
+  $result = { param($text, $tip) [System.Management.Automation.CompletionResult]::new($text, $text, 'ParameterValue', $tip) }
+  $before = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } |
+    Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+
+  if ($wordToComplete -match '^(--[\\w-]+)=(.*)$') {
+    $option = $Matches[1]
+    $value = $Matches[2]
+    if ($values.ContainsKey($option)) {
+      $values[$option] | Where-Object { $_ -like "$value*" } | ForEach-Object { & $result "$option=$_" $_ }
+    }
+    return
+  }
+  if ($wordToComplete.StartsWith('-')) {
+    $options | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object { & $result $_ $_ }
+    return
+  }
+  if ($before.Count -gt 0 -and $arguments.ContainsKey($before[-1])) {
+    $arguments[$before[-1]] | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object { & $result $_ $_ }
+    return
+  }
+  if (-not ($before | Where-Object { $commands.Contains($_) })) {
+    $commands.Keys | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object { & $result $_ $commands[$_] }
+  }
+  $directory = $wordToComplete.Substring(0, $wordToComplete.LastIndexOfAny([char[]]'/\\') + 1)
+  Get-ChildItem -Path "$wordToComplete*" -ErrorAction SilentlyContinue |
+    Where-Object { $_.PSIsContainer -or $_.Extension -eq '.sav' } |
+    ForEach-Object { & $result "$directory$($_.Name)" $_.FullName }
+}
+`
+}
+
+/**
+ * The completion script for a shell, to be loaded as each script's header comment describes
+ */
+export function completionScript(shell: CompletionShell, spec: CompletionSpec): string {
+  switch (shell) {
+    case 'bash':
+      return bashScript(spec)
+    case 'zsh':
+      return zshScript(spec)
+    case 'fish':
+      return fishScript(spec)
+    case 'powershell':
+      return powerShellScript(spec)
+  }
+}
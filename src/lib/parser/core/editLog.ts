@@ -0,0 +1,113 @@
+/**
+ * Edit transactions for save files
+ * Each edit is stored as the byte ranges it changed (before and after), so a session of edits
+ * can be undone and redone without keeping a full copy of the save per step
+ */
+
+export interface ByteRangeChange {
+  readonly offset: number
+  readonly before: Uint8Array
+  readonly after: Uint8Array
+}
+
+export interface EditTransaction {
+  readonly label: string
+  readonly changes: readonly ByteRangeChange[]
+}
+
+// Changed runs closer than this are stored as one range (a Pokemon edit touches the data and its
+// checksum, and the sector checksum sits a few bytes past the signature)
+const MERGE_GAP = 8
+
+/**
+ * Byte ranges that differ between two saves of the same size, in file order
+ */
+export function diffSaveBytes(before: Uint8Array, after: Uint8Array): ByteRangeChange[] {
+  if (before.length !== after.length) {
+    throw new Error(`Save sizes differ: ${before.length} and ${after.length} bytes`)
+  }
+
+  const changes: ByteRangeChange[] = []
+  let i = 0
+  while (i < before.length) {
+    if (before[i] === after[i]) {
+      i++
+      continue
+    }
+    const start = i
+    let end = i + 1
+    for (let j = end; j < before.length && j < end + MERGE_GAP; j++) {
+      if (before[j] !== after[j]) end = j + 1
+    }
+    changes.push({
+      offset: start,
+      before: before.slice(start, end),
+      after: after.slice(start, end),
+    })
+    i = end
+  }
+  return changes
+}
+
+/**
+ * Undo/redo stacks of edit transactions over one save file
+ */
+export class EditLog {
+  private bytes: Uint8Array
+  private readonly done: EditTransaction[] = []
+  private undone: EditTransaction[] = []
+
+  constructor(initial: Uint8Array) {
+    this.bytes = initial.slice()
+  }
+
+  /** The save with every applied edit, as a copy */
+  get current(): Uint8Array {
+    return this.bytes.slice()
+  }
+
+  /** Applied transactions, oldest first */
+  get history(): readonly EditTransaction[] {
+    return this.done
+  }
+
+  get canUndo(): boolean {
+    return this.done.length > 0
+  }
+
+  get canRedo(): boolean {
+    return this.undone.length > 0
+  }
+
+  /**
+   * Record the edited save as a new transaction, dropping anything that could be redone
+   * Returns null (and records nothing) when the edit changed no bytes
+   */
+  record(label: string, edited: Uint8Array): EditTransaction | null {
+    const changes = diffSaveBytes(this.bytes, edited)
+    if (changes.length === 0) return null
+    const transaction = { label, changes }
+    this.bytes = edited.slice()
+    this.done.push(transaction)
+    this.undone = []
+    return transaction
+  }
+
+  /** Revert the newest transaction, or return null when there is none */
+  undo(): EditTransaction | null {
+    const transaction = this.done.pop()
+    if (!transaction) return null
+    for (const { offset, before } of transaction.changes) this.bytes.set(before, offset)
+    this.undone.push(transaction)
+    return transaction
+  }
+
+  /** Reapply the last undone transaction, or return null when there is none */
+  redo(): EditTransaction | null {
+    const transaction = this.undone.pop()
+    if (!transaction) return null
+    for (const { offset, after } of transaction.changes) this.bytes.set(after, offset)
+    this.done.push(transaction)
+    return transaction
+  }
+}
@@ -0,0 +1,74 @@
+/**
+ * Glob patterns for batch inputs the shell didn't expand (quoted, or on Windows)
+ * Supports `*`, `?`, `**` (any number of directories), `[abc]`/`[!abc]` classes and `{a,b}`
+ * alternatives; paths use `/` separators
+ */
+
+const GLOB_CHARS = /[*?[{]/
+
+const escapeRegExp = (text: string) => text.replace(/[.*+?^${}()|[\]\\]/g, '\\$&')
+
+function toSource(pattern: string): string {
+  let source = ''
+  for (let i = 0; i < pattern.length; i++) {
+    const char = pattern[i]!
+    if (char === '*' && pattern[i + 1] === '*') {
+      i++
+      // `**/` also matches no directory at all, so `saves/**/*.sav` includes saves/a.sav
+      if (pattern[i + 1] === '/') {
+        i++
+        source += '(?:[^/]*/)*'
+      } else {
+        source += '.*'
+      }
+    } else if (char === '*') {
+      source += '[^/]*'
+    } else if (char === '?') {
+      source += '[^/]'
+    } else if (char === '[') {
+      // A `]` straight after `[` (or `[!`) is part of the class; an unclosed `[` is literal
+      const negated = pattern[i + 1] === '!'
+      const start = negated ? i + 2 : i + 1
+      const end = pattern.indexOf(']', start + 1)
+      if (end === -1) {
+        source += '\\['
+      } else {
+        source += `[${negated ? '^' : ''}${pattern.slice(start, end).replace(/[\\\]^]/g, '\\$&')}]`
+        i = end
+      }
+    } else if (char === '{' && pattern.indexOf('}', i) !== -1) {
+      const end = pattern.indexOf('}', i)
+      source += `(?:${pattern
+        .slice(i + 1, end)
+        .split(',')
+        .map(toSource)
+        .join('|')})`
+      i = end
+    } else {
+      source += escapeRegExp(char)
+    }
+  }
+  return source
+}
+
+/** Whether the text contains glob syntax rather than naming a path directly */
+export const isGlob = (text: string) => GLOB_CHARS.test(text)
+
+/**
+ * A regular expression matching the whole of any path the glob pattern matches
+ */
+export function globToRegExp(pattern: string): RegExp {
+  return new RegExp(`^${toSource(pattern)}$`)
+}
+
+/**
+ * The directory to search for matches: the pattern's segments before the first one with glob
+ * syntax (`saves/2024/*.sav` → `saves/2024`)
+ */
+export function globBase(pattern: string): string {
+  const segments = pattern.split('/')
+  const first = segments.findIndex(isGlob)
+  const base = segments.slice(0, first === -1 ? segments.length : first).join('/')
+  if (base) return base
+  return pattern.startsWith('/') ? '/' : '.'
+}
@@ -0,0 +1,326 @@
+/**
+ * Annotated hexdump of a save file (the CLI's --format=hexdump)
+ * Lays the raw bytes out per flash sector with the structure the parser reads from them: sector
+ * footers, the SaveBlock and PC storage fields of the game's layout, and party and box Pokemon
+ * down to their substructures. Meant for ROM hack authors checking their own layouts
+ */
+
+import {
+  type GameConfig,
+  VANILLA_EMERALD_SIGNATURE,
+  VANILLA_POKEMON_OFFSETS,
+} from './types'
+import { bytesToGbaString } from './utils'
+
+export interface HexdumpRegion {
+  readonly offset: number
+  readonly length: number
+  readonly label: string
+}
+
+export interface HexdumpLayout {
+  /** Headings printed before the region's first line (flash sectors) */
+  readonly sections: readonly HexdumpRegion[]
+  /** Fields listed beside the line they start on */
+  readonly fields: readonly HexdumpRegion[]
+}
+
+// Sector IDs within a save slot: SaveBlock2, SaveBlock1 in four chunks, then PC storage
+const SLOT_SECTOR_COUNT = 14
+const SAVEBLOCK1_SECTORS = [1, 4] as const
+const FOOTER_SIZE = 12
+const DEX_FLAG_BYTES = 49
+const FLAG_BYTES = 300
+const VAR_COUNT = 256
+const GAME_STAT_COUNT = 64
+const PARTY_POKEMON_SIZE = 100
+
+// Substructure stored at each position for personality % 24: Growth, Attacks, EVs, Misc
+const SUBSTRUCT_ORDERS = (
+  'GAEM GAME GEAM GEMA GMAE GMEA AGEM AGME AEGM AEMG AMGE AMEG ' +
+  'EGAM EGMA EAGM EAMG EMGA EMAG MGAE MGEA MAGE MAEG MEGA MEAG'
+).split(' ')
+const SUBSTRUCT_NAMES: Readonly<Record<string, string>> = {
+  G: 'growth',
+  A: 'attacks',
+  E: 'EVs',
+  M: 'misc',
+}
+
+type Block = 'saveblock2' | 'saveblock1' | 'storage'
+
+/** Header, substructure and party-only fields of a Pokemon at `offset` in its block */
+function pokemonFields(
+  bytes: Uint8Array,
+  offset: number,
+  name: string,
+  party: boolean,
+  config: GameConfig
+): HexdumpRegion[] {
+  const o = { ...VANILLA_POKEMON_OFFSETS, ...config.offsetOverrides }
+  const size = party ? config.pokemonSize : config.saveLayout.boxPokemonSize
+  const fields: HexdumpRegion[] = [
+    { offset, length: size, label: name },
+    { offset: offset + o.personality, length: 4, label: `${name} personality` },
+    { offset: offset + o.otId, length: 4, label: `${name} OT ID` },
+    { offset: offset + o.nickname, length: o.nicknameLength, label: `${name} nickname` },
+    { offset: offset + o.otName, length: o.otNameLength, label: `${name} OT name` },
+    { offset: offset + o.checksum, length: 2, label: `${name} checksum` },
+  ]
+  // Vanilla layout: four 12-byte substructures, shuffled by personality and encrypted
+  if (!config.getSubstruct && config.pokemonSize === PARTY_POKEMON_SIZE) {
+    const personality = new DataView(bytes.buffer, bytes.byteOffset).getUint32(o.personality, true)
+    const order = SUBSTRUCT_ORDERS[personality % 24]!
+    for (let i = 0; i < 4; i++) {
+      const label = `${name} ${SUBSTRUCT_NAMES[order[i]!]!} (encrypted)`
+      fields.push({ offset: offset + 0x20 + i * 12, length: 12, label })
+    }
+  }
+  if (party) {
+    fields.push(
+      { offset: offset + o.status, length: 4, label: `${name} status` },
+      { offset: offset + o.level, length: 1, label: `${name} level` },
+      { offset: offset + o.currentHp, length: 2, label: `${name} current HP` },
+      { offset: offset + o.maxHp, length: 12, label: `${name} stats` }
+    )
+  }
+  return fields
+}
+
+/** Fields of one logical block, at offsets within that block */
+function blockFields(block: Block, data: Uint8Array, config: GameConfig): HexdumpRegion[] {
+  const layout = config.saveLayout
+  const extended = config.supportsExtendedSaveData !== false
+  const field = (offset: number, length: number, label: string) => ({ offset, length, label })
+
+  if (block === 'saveblock2') {
+    return [
+      field(0, 8, 'player name'),
+      field(layout.playerGender, 1, 'player gender'),
+      field(layout.trainerId, 4, 'trainer ID / secret ID'),
+      field(layout.playTimeHours, 2, 'play time hours'),
+      field(layout.playTimeMinutes, 1, 'play time minutes'),
+      field(layout.playTimeSeconds, 1, 'play time seconds'),
+      field(layout.playTimeMilliseconds, 1, 'play time frames'),
+      ...(extended
+        ? [
+            field(layout.pokedexMode, 1, 'Pokedex mode'),
+            field(layout.nationalDexMagic, 1, 'National Dex magic'),
+            field(layout.pokedexOwned, DEX_FLAG_BYTES, 'Pokedex caught flags'),
+            field(layout.pokedexSeen, DEX_FLAG_BYTES, 'Pokedex seen flags'),
+            field(layout.encryptionKey, 4, 'encryption key'),
+          ]
+        : []),
+    ]
+  }
+
+  if (block === 'saveblock1') {
+    const view = new DataView(data.buffer, data.byteOffset, data.byteLength)
+    const count = Math.min(view.getUint32(layout.partyCountOffset, true), config.maxPartySize)
+    const fields = [field(layout.partyCountOffset, 4, 'party count')]
+    for (let i = 0; i < count; i++) {
+      const offset = layout.partyOffset + i * config.pokemonSize
+      const pokemon = data.subarray(offset, offset + config.pokemonSize)
+      fields.push(...pokemonFields(pokemon, offset, `party ${i + 1}`, true, config))
+    }
+    if (!extended) return fields
+
+    const pockets = [
+      ['items', layout.bagItems, layout.bagItemsCount],
+      ['key items', layout.bagKeyItems, layout.bagKeyItemsCount],
+      ['Poke Balls', layout.bagPokeBalls, layout.bagPokeBallsCount],
+      ['TMs & HMs', layout.bagTmHm, layout.bagTmHmCount],
+      ['berries', layout.bagBerries, layout.bagBerriesCount],
+    ] as const
+    return [
+      ...fields,
+      field(layout.money, 4, 'money (encrypted)'),
+      field(layout.coins, 2, 'coins (encrypted)'),
+      ...pockets.map(([name, offset, slots]) => field(offset, slots * 4, `bag ${name} pocket`)),
+      field(layout.pokedexSeen1, DEX_FLAG_BYTES, 'Pokedex seen flags (copy 1)'),
+      field(layout.battlePoints, 2, 'Battle Points'),
+      field(layout.flags, FLAG_BYTES, 'event flags'),
+      field(layout.vars, VAR_COUNT * 2, 'event vars'),
+      field(layout.gameStats, GAME_STAT_COUNT * 4, 'game stats (encrypted)'),
+      field(layout.secretBases, layout.secretBaseCount * layout.secretBaseSize, 'secret bases'),
+      field(layout.mail, layout.mailCount * layout.mailSize, 'mail'),
+      field(layout.daycare, layout.daycareSlotSize * 2, 'daycare'),
+      field(layout.daycareOffspringPersonality, 4, 'daycare egg personality'),
+      field(layout.pokedexSeen2, DEX_FLAG_BYTES, 'Pokedex seen flags (copy 2)'),
+    ]
+  }
+
+  if (!extended) return []
+  const fields = [field(layout.currentBox, 4, 'current box')]
+  for (let box = 0; box < layout.boxCount; box++) {
+    for (let slot = 0; slot < layout.boxSlotCount; slot++) {
+      const index = box * layout.boxSlotCount + slot
+      const offset = layout.boxPokemon + index * layout.boxPokemonSize
+      const pokemon = data.subarray(offset, offset + layout.boxPokemonSize)
+      if (pokemon.every(byte => byte === 0)) continue
+      const name = `box ${box + 1} slot ${slot + 1}`
+      fields.push(...pokemonFields(pokemon, offset, name, false, config))
+    }
+  }
+  fields.push(
+    field(layout.boxNames, layout.boxCount * layout.boxNameLength, 'box names'),
+    field(layout.boxWallpapers, layout.boxCount, 'box wallpapers')
+  )
+  return fields
+}
+
+const sectorChecksum = (data: Uint8Array) => {
+  const view = new DataView(data.buffer, data.byteOffset, data.byteLength)
+  let sum = 0
+  for (let i = 0; i + 4 <= data.length; i += 4) sum = (sum + view.getUint32(i, true)) >>> 0
+  return ((sum >>> 16) + sum) & 0xffff
+}
+
+function blockFor(id: number): { block: Block; chunk: number } | undefined {
+  if (id === 0) return { block: 'saveblock2', chunk: 0 }
+  if (id >= SAVEBLOCK1_SECTORS[0] && id <= SAVEBLOCK1_SECTORS[1]) {
+    return { block: 'saveblock1', chunk: id - SAVEBLOCK1_SECTORS[0] }
+  }
+  if (id < SLOT_SECTOR_COUNT) return { block: 'storage', chunk: id - SAVEBLOCK1_SECTORS[1] - 1 }
+  return undefined
+}
+
+const BLOCK_NAMES: Readonly<Record<Block, string>> = {
+  saveblock2: 'SaveBlock2',
+  saveblock1: 'SaveBlock1',
+  storage: 'PC storage',
+}
+
+/**
+ * Sections (one per flash sector) and fields of a save file in the given game's layout
+ * Both save slots are annotated; sectors are matched to SaveBlock chunks by their footer IDs,
+ * so fields that span sectors are split across the physical sectors holding them
+ */
+export function annotateSave(bytes: Uint8Array, config: GameConfig): HexdumpLayout {
+  const { sectorSize, sectorDataSize, sectorCount } = config.saveLayout
+  const signature = config.signature ?? VANILLA_EMERALD_SIGNATURE
+  const view = new DataView(bytes.buffer, bytes.byteOffset, bytes.byteLength)
+  const sections: HexdumpRegion[] = []
+  const fields: HexdumpRegion[] = []
+  const extraSectors: Readonly<Record<number, string>> = {
+    [config.saveLayout.hallOfFameSector]: 'Hall of Fame (part 1)',
+    [config.saveLayout.hallOfFameSector + 1]: 'Hall of Fame (part 2)',
+    [config.saveLayout.trainerHillSector]: 'e-Reader Trainer Hill',
+    [config.saveLayout.recordedBattleSector]: 'recorded battle',
+  }
+
+  // Sector ID -> physical sector, per slot
+  const slots = [new Map<number, number>(), new Map<number, number>()]
+  const sectors = Math.min(sectorCount, Math.floor(bytes.length / sectorSize))
+  for (let i = 0; i < sectors; i++) {
+    const start = i * sectorSize
+    const footer = start + sectorSize - FOOTER_SIZE
+    const id = view.getUint16(footer, true)
+    const valid = view.getUint32(footer + 4, true) === signature
+    const slot = Math.floor(i / SLOT_SECTOR_COUNT)
+    const location = blockFor(id)
+
+    let title = `sector ${i}: ${extraSectors[i] ?? 'no valid footer'}`
+    if (valid && slot < 2 && location) {
+      slots[slot]!.set(id, i)
+      const counter = view.getUint32(footer + 8, true)
+      const checksumOk =
+        sectorChecksum(bytes.subarray(start, start + sectorDataSize)) ===
+        view.getUint16(footer + 2, true)
+      const chunk = location.block === 'saveblock2' ? '' : ` chunk ${location.chunk + 1}`
+      title =
+        `sector ${i}: slot ${slot + 1} ${BLOCK_NAMES[location.block]}${chunk} (ID ${id}, ` +
+        `save #${counter}, checksum ${checksumOk ? 'ok' : 'bad'})`
+    }
+    sections.push({ offset: start, length: sectorSize, label: title })
+    fields.push(
+      { offset: footer, length: 2, label: 'footer: sector ID' },
+      { offset: footer + 2, length: 2, label: 'footer: checksum' },
+      { offset: footer + 4, length: 4, label: 'footer: signature' },
+      { offset: footer + 8, length: 4, label: 'footer: save counter' }
+    )
+  }
+
+  for (const sectorMap of slots) {
+    for (const block of ['saveblock2', 'saveblock1', 'storage'] as const) {
+      const ids = [...sectorMap.keys()].filter(id => blockFor(id)?.block === block)
+      if (ids.length === 0) continue
+      // Reassemble the block so fields can read values like the party count and personalities
+      const chunks = Math.max(...ids.map(id => blockFor(id)!.chunk)) + 1
+      const data = new Uint8Array(chunks * sectorDataSize)
+      const physical = new Map<number, number>()
+      for (const id of ids) {
+        const { chunk } = blockFor(id)!
+        const start = sectorMap.get(id)! * sectorSize
+        data.set(bytes.subarray(start, start + sectorDataSize), chunk * sectorDataSize)
+        physical.set(chunk, start)
+      }
+
+      for (const region of blockFields(block, data, config)) {
+        // Split the field at chunk boundaries onto the sectors that hold each part
+        for (let pos = region.offset; pos < region.offset + region.length; ) {
+          const chunk = Math.floor(pos / sectorDataSize)
+          const end = Math.min(region.offset + region.length, (chunk + 1) * sectorDataSize)
+          const start = physical.get(chunk)
+          if (start !== undefined) {
+            const label = pos === region.offset ? region.label : `${region.label} (cont.)`
+            fields.push({ offset: start + (pos % sectorDataSize), length: end - pos, label })
+          }
+          pos = end
+        }
+      }
+    }
+  }
+  return { sections, fields }
+}
+
+const BYTES_PER_LINE = 16
+
+const hex = (value: number, width: number) => value.toString(16).padStart(width, '0')
+
+// The game's own character set, so names and other text show up beside the bytes; Japanese
+// characters are double width and would break the column, so they print as dots too
+const gbaChar = (byte: number) => {
+  const char = bytesToGbaString(Uint8Array.of(byte))
+  return char.length === 1 && char.charCodeAt(0) < 0x2000 ? char : '.'
+}
+
+/**
+ * `hexdump -C` style lines with section headings and, beside each line, the fields starting on
+ * it. Runs of identical lines without annotations collapse to `*`
+ */
+export function formatHexdump(bytes: Uint8Array, layout: HexdumpLayout): string {
+  const headings = new Map(layout.sections.map(section => [section.offset, section.label]))
+  const labels = new Map<number, string[]>()
+  for (const field of [...layout.fields].sort((a, b) => a.offset - b.offset)) {
+    const line = field.offset - (field.offset % BYTES_PER_LINE)
+    const size = field.length === 1 ? '1 byte' : `${field.length} bytes`
+    const text = `${field.label} @${hex(field.offset, 5)} (${size})`
+    labels.set(line, [...(labels.get(line) ?? []), text])
+  }
+
+  const lines: string[] = []
+  let previous: string | undefined
+  let collapsed = false
+  for (let offset = 0; offset < bytes.length; offset += BYTES_PER_LINE) {
+    const row = bytes.subarray(offset, offset + BYTES_PER_LINE)
+    const hexBytes = [...row].map(byte => hex(byte, 2))
+    const hexText = `${hexBytes.slice(0, 8).join(' ')}  ${hexBytes.slice(8).join(' ')}`
+    const heading = headings.get(offset)
+    const notes = labels.get(offset)
+
+    if (heading === undefined && !notes && hexText === previous) {
+      if (!collapsed) lines.push('*')
+      collapsed = true
+      continue
+    }
+    if (heading !== undefined) lines.push('', `── ${heading}`)
+    const text = [...row].map(gbaChar).join('')
+    const line = `${hex(offset, 8)}  ${hexText.padEnd(48)}  |${text.padEnd(BYTES_PER_LINE)}|`
+    lines.push(notes ? `${line}  ${notes.join(', ')}` : line)
+    previous = hexText
+    collapsed = false
+  }
+  lines.push(hex(bytes.length, 8))
+  return lines.join('\n').trimStart()
+}
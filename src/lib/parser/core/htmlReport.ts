@@ -0,0 +1,147 @@
+/**
+ * Self-contained HTML report of a save (the CLI's --format=html)
+ * One page with inline styles and no scripts or external assets: trainer summary, party cards
+ * with stat bars, and Pokedex progress, so a snapshot can be shared without the web app
+ */
+
+import { type PokemonJson, toPokemonJson } from './saveJson'
+import { type GameConfig, HOENN_BADGE_NAMES, type SaveData } from './types'
+import { formatPlayTime } from './utils'
+
+const STAT_LABELS = ['HP', 'Atk', 'Def', 'Spe', 'SpA', 'SpD'] as const
+
+const STYLES = `
+body { font-family: system-ui, sans-serif; background: #0f172a; color: #e2e8f0; margin: 0; padding: 24px; }
+main { max-width: 960px; margin: 0 auto; }
+h1, h2 { margin: 0 0 12px; }
+section { margin-bottom: 28px; }
+.muted { color: #94a3b8; }
+.summary { display: flex; flex-wrap: wrap; gap: 8px 24px; }
+.party { display: grid; grid-template-columns: repeat(auto-fill, minmax(280px, 1fr)); gap: 16px; }
+.card { background: #1e293b; border: 1px solid #334155; border-radius: 12px; padding: 16px; }
+.card h3 { margin: 0 0 4px; }
+.shiny { color: #facc15; }
+.stat { display: grid; grid-template-columns: 36px 40px 1fr; align-items: center; gap: 8px; font-size: 13px; }
+.bar { height: 8px; border-radius: 4px; background: #334155; overflow: hidden; }
+.bar > span { display: block; height: 100%; background: #38bdf8; }
+.moves { margin: 8px 0 0; padding-left: 18px; }
+.dex .bar { height: 12px; margin: 4px 0 12px; }
+.dex .bar > span { background: #4ade80; }
+`.trim()
+
+/** Escape text for HTML element content and attribute values */
+export function escapeHtml(text: string): string {
+  return text
+    .replaceAll('&', '&amp;')
+    .replaceAll('<', '&lt;')
+    .replaceAll('>', '&gt;')
+    .replaceAll('"', '&quot;')
+    .replaceAll("'", '&#39;')
+}
+
+const bar = (value: number, max: number) =>
+  `<div class="bar"><span style="width: ${max > 0 ? Math.round((value / max) * 100) : 0}%"></span></div>`
+
+function renderPokemonCard(pokemon: PokemonJson, statScale: number): string {
+  const species = pokemon.species_name ?? `#${pokemon.species_id}`
+  const title = pokemon.is_egg ? 'Egg' : escapeHtml(pokemon.nickname)
+  const shiny = pokemon.is_shiny ? ' <span class="shiny" title="Shiny">★</span>' : ''
+  const details = [
+    `Lv ${pokemon.level}`,
+    pokemon.nature,
+    pokemon.ability,
+    pokemon.types?.join('/'),
+    pokemon.item_name && `@ ${pokemon.item_name}`,
+  ].filter(Boolean)
+  const stats = pokemon.stats
+    .map(
+      (stat, i) =>
+        `<div class="stat"><span class="muted">${STAT_LABELS[i]}</span><span>${stat}</span>${bar(stat, statScale)}</div>`
+    )
+    .join('\n')
+  const moves = pokemon.move_names
+    .filter(move => move !== null)
+    .map(move => `<li>${escapeHtml(move)}</li>`)
+    .join('')
+
+  return `<article class="card">
+<h3>${title}${shiny}</h3>
+<div class="muted">${escapeHtml(species)} · ${escapeHtml(details.join(' · '))}</div>
+<p class="muted">HP ${pokemon.current_hp}/${pokemon.stats[0]} · OT ${escapeHtml(pokemon.ot_name)} (${pokemon.ot_id})</p>
+${stats}
+${moves && `<ul class="moves">${moves}</ul>`}
+</article>`
+}
+
+/**
+ * Render a parsed save as a standalone HTML page
+ */
+export function renderHtmlReport(
+  saveData: SaveData,
+  config: GameConfig,
+  generatedAt: Date = new Date()
+): string {
+  const party = saveData.party_pokemon.map(pokemon => toPokemonJson(pokemon, config))
+  // Stat bars share one scale so party members can be compared at a glance
+  const statScale = Math.max(1, ...party.flatMap(pokemon => pokemon.stats))
+  const { hours, minutes, seconds } = saveData.play_time
+  const { trainer, progress, currency, pokedex } = saveData
+
+  const summary = [
+    `Game: ${config.name}`,
+    `Play time: ${formatPlayTime(hours, minutes, seconds)}`,
+    trainer && `Trainer ID: ${trainer.trainer_id.toString().padStart(5, '0')}`,
+    currency && `Money: ₽${currency.money.toLocaleString('en-US')}`,
+    progress &&
+      `Badges: ${progress.badge_count}/${progress.badges.length}` +
+        (progress.badge_count > 0
+          ? ` (${HOENN_BADGE_NAMES.filter((_, i) => progress.badges[i]).join(', ')})`
+          : ''),
+  ]
+    .filter((item): item is string => Boolean(item))
+    .map(item => `<span>${escapeHtml(item)}</span>`)
+    .join('\n')
+
+  const dexSize = pokedex?.seen.length ?? 0
+  const dex = pokedex
+    ? `<section class="dex">
+<h2>Pokédex</h2>
+<div>Seen ${pokedex.seen_count} / ${dexSize}</div>
+${bar(pokedex.seen_count, dexSize)}
+<div>Caught ${pokedex.caught_count} / ${dexSize}</div>
+${bar(pokedex.caught_count, dexSize)}
+</section>`
+    : ''
+
+  const name = escapeHtml(saveData.player_name)
+  return `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>${name} - ${escapeHtml(config.name)}</title>
+<style>
+${STYLES}
+</style>
+</head>
+<body>
+<main>
+<section>
+<h1>${name}</h1>
+<div class="summary muted">
+${summary}
+</div>
+</section>
+<section>
+<h2>Party</h2>
+<div class="party">
+${party.map(pokemon => renderPokemonCard(pokemon, statScale)).join('\n')}
+</div>
+</section>
+${dex}
+<footer class="muted">Generated ${escapeHtml(generatedAt.toISOString())}</footer>
+</main>
+</body>
+</html>
+`
+}
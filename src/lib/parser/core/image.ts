@@ -0,0 +1,303 @@
+/**
+ * Minimal RGBA image helpers for rendering team cards without native dependencies
+ * Decodes the first frame of the GIF sprites, draws rectangles and scaled sprites,
+ * and encodes PNG (zlib compression is pluggable, uncompressed blocks otherwise)
+ * See: https://www.w3.org/Graphics/GIF/spec-gif89a.txt and https://www.w3.org/TR/png/
+ */
+
+import { crc32 } from './zip'
+
+export interface RgbaImage {
+  readonly width: number
+  readonly height: number
+  /** 4 bytes per pixel, row by row */
+  readonly data: Uint8Array
+}
+
+export type Rgba = readonly [number, number, number, number]
+
+export function createImage(width: number, height: number, fill: Rgba = [0, 0, 0, 0]): RgbaImage {
+  const data = new Uint8Array(width * height * 4)
+  for (let i = 0; i < data.length; i += 4) data.set(fill, i)
+  return { width, height, data }
+}
+
+/**
+ * Fill a rectangle, clipped to the image
+ */
+export function fillRect(
+  image: RgbaImage,
+  x: number,
+  y: number,
+  width: number,
+  height: number,
+  color: Rgba
+): void {
+  const left = Math.max(0, Math.round(x))
+  const right = Math.min(image.width, Math.round(x + width))
+  for (let row = Math.max(0, Math.round(y)); row < Math.min(image.height, y + height); row++) {
+    for (let col = left; col < right; col++) image.data.set(color, (row * image.width + col) * 4)
+  }
+}
+
+/**
+ * Draw an image at (x, y), scaled up by an integer factor with nearest-neighbor sampling
+ * and alpha blended over what is already there
+ */
+export function drawImage(target: RgbaImage, source: RgbaImage, x: number, y: number, scale = 1) {
+  for (let row = 0; row < source.height * scale; row++) {
+    const ty = y + row
+    if (ty < 0 || ty >= target.height) continue
+    for (let col = 0; col < source.width * scale; col++) {
+      const tx = x + col
+      if (tx < 0 || tx >= target.width) continue
+      const s = (Math.floor(row / scale) * source.width + Math.floor(col / scale)) * 4
+      const alpha = source.data[s + 3]! / 255
+      if (alpha === 0) continue
+      const t = (ty * target.width + tx) * 4
+      for (let c = 0; c < 3; c++) {
+        target.data[t + c] = Math.round(
+          source.data[s + c]! * alpha + target.data[t + c]! * (1 - alpha)
+        )
+      }
+      target.data[t + 3] = Math.max(target.data[t + 3]!, source.data[s + 3]!)
+    }
+  }
+}
+
+/**
+ * Crop an image to the bounding box of its visible pixels (sprites carry transparent margins)
+ */
+export function trimImage(image: RgbaImage): RgbaImage {
+  let [top, left, bottom, right] = [image.height, image.width, -1, -1]
+  for (let y = 0; y < image.height; y++) {
+    for (let x = 0; x < image.width; x++) {
+      if (image.data[(y * image.width + x) * 4 + 3] === 0) continue
+      top = Math.min(top, y)
+      bottom = Math.max(bottom, y)
+      left = Math.min(left, x)
+      right = Math.max(right, x)
+    }
+  }
+  if (bottom < 0) return image
+
+  const width = right - left + 1
+  const height = bottom - top + 1
+  const data = new Uint8Array(width * height * 4)
+  for (let y = 0; y < height; y++) {
+    const start = ((top + y) * image.width + left) * 4
+    data.set(image.data.subarray(start, start + width * 4), y * width * 4)
+  }
+  return { width, height, data }
+}
+
+// GIF image data is LZW-compressed color indices with variable-width codes (up to 12 bits)
+function decodeLzw(data: Uint8Array, minCodeSize: number, pixelCount: number): Uint8Array {
+  const pixels = new Uint8Array(pixelCount)
+  const prefix = new Int16Array(4096)
+  const suffix = new Uint8Array(4096)
+  const stack = new Uint8Array(4097)
+  const clear = 1 << minCodeSize
+  const end = clear + 1
+  for (let i = 0; i < clear; i++) suffix[i] = i
+
+  let codeSize = minCodeSize + 1
+  let next = end + 1
+  let previous = -1
+  let first = 0
+  let bitBuffer = 0
+  let bitCount = 0
+  let out = 0
+
+  for (let pos = 0; out < pixelCount; ) {
+    while (bitCount < codeSize && pos < data.length) {
+      bitBuffer |= data[pos++]! << bitCount
+      bitCount += 8
+    }
+    if (bitCount < codeSize) break
+    const code = bitBuffer & ((1 << codeSize) - 1)
+    bitBuffer >>>= codeSize
+    bitCount -= codeSize
+
+    if (code === clear) {
+      codeSize = minCodeSize + 1
+      next = end + 1
+      previous = -1
+      continue
+    }
+    if (code === end) break
+    if (previous === -1) {
+      pixels[out++] = suffix[code]!
+      previous = first = code
+      continue
+    }
+
+    let size = 0
+    let current = code
+    if (code >= next) {
+      // The code being defined right now: previous string plus its own first index
+      stack[size++] = first
+      current = previous
+    }
+    while (current >= clear) {
+      stack[size++] = suffix[current]!
+      current = prefix[current]!
+    }
+    first = current
+    stack[size++] = first
+    while (size > 0 && out < pixelCount) pixels[out++] = stack[--size]!
+
+    if (next < 4096) {
+      prefix[next] = previous
+      suffix[next] = first
+      next++
+      if (next === 1 << codeSize && codeSize < 12) codeSize++
+    }
+    previous = code
+  }
+  return pixels
+}
+
+/**
+ * Decode the first frame of a GIF (sprites are animated; the first frame is the idle pose)
+ */
+export function decodeGif(bytes: Uint8Array): RgbaImage {
+  const view = new DataView(bytes.buffer, bytes.byteOffset, bytes.byteLength)
+  const signature = String.fromCharCode(...bytes.subarray(0, 6))
+  if (signature !== 'GIF87a' && signature !== 'GIF89a') throw new Error('Not a GIF image')
+
+  const image = createImage(view.getUint16(6, true), view.getUint16(8, true))
+  let pos = 13
+  const readTable = (flags: number) => {
+    const size = 3 << ((flags & 0x07) + 1)
+    const table = bytes.subarray(pos, pos + size)
+    pos += size
+    return table
+  }
+  const readSubBlocks = () => {
+    const chunks: Uint8Array[] = []
+    for (let size = bytes[pos++]!; size > 0 && pos < bytes.length; size = bytes[pos++]!) {
+      chunks.push(bytes.subarray(pos, pos + size))
+      pos += size
+    }
+    const data = new Uint8Array(chunks.reduce((sum, chunk) => sum + chunk.length, 0))
+    let offset = 0
+    for (const chunk of chunks) {
+      data.set(chunk, offset)
+      offset += chunk.length
+    }
+    return data
+  }
+
+  const globalTable = bytes[10]! & 0x80 ? readTable(bytes[10]!) : undefined
+  let transparent = -1
+
+  while (pos < bytes.length) {
+    const block = bytes[pos++]
+    if (block === 0x21) {
+      const label = bytes[pos++]
+      // Graphic control extension: flags, delay, transparent color index
+      if (label === 0xf9 && bytes[pos + 1]! & 0x01) transparent = bytes[pos + 4]!
+      readSubBlocks()
+    } else if (block === 0x2c) {
+      const left = view.getUint16(pos, true)
+      const top = view.getUint16(pos + 2, true)
+      const width = view.getUint16(pos + 4, true)
+      const height = view.getUint16(pos + 6, true)
+      const flags = bytes[pos + 8]!
+      pos += 9
+      const table = flags & 0x80 ? readTable(flags) : globalTable
+      if (!table) throw new Error('GIF frame has no color table')
+      const minCodeSize = bytes[pos++]!
+      const indices = decodeLzw(readSubBlocks(), minCodeSize, width * height)
+
+      // Interlaced frames store rows in four passes: every 8th from 0, 8th from 4, 4th, 2nd
+      const rows = Array.from({ length: height }, (_, i) => i)
+      if (flags & 0x40) {
+        rows.length = 0
+        for (const [start, step] of [[0, 8], [4, 8], [2, 4], [1, 2]] as const) {
+          for (let row = start; row < height; row += step) rows.push(row)
+        }
+      }
+      rows.forEach((y, i) => {
+        for (let x = 0; x < width; x++) {
+          const index = indices[i * width + x]!
+          const tx = left + x
+          const ty = top + y
+          if (index === transparent || tx >= image.width || ty >= image.height) continue
+          const color = table.subarray(index * 3, index * 3 + 3)
+          image.data.set([...color, 255], (ty * image.width + tx) * 4)
+        }
+      })
+      return image
+    } else {
+      break
+    }
+  }
+  throw new Error('GIF has no image frame')
+}
+
+// A zlib stream of stored (uncompressed) deflate blocks, for when no compressor is supplied
+function zlibStored(data: Uint8Array): Uint8Array {
+  const blocks = Math.max(1, Math.ceil(data.length / 0xffff))
+  const out = new Uint8Array(2 + data.length + blocks * 5 + 4)
+  const view = new DataView(out.buffer)
+  out.set([0x78, 0x01])
+  let pos = 2
+  for (let i = 0; i < blocks; i++) {
+    const chunk = data.subarray(i * 0xffff, (i + 1) * 0xffff)
+    out[pos] = i === blocks - 1 ? 1 : 0
+    view.setUint16(pos + 1, chunk.length, true)
+    view.setUint16(pos + 3, ~chunk.length & 0xffff, true)
+    out.set(chunk, pos + 5)
+    pos += 5 + chunk.length
+  }
+  let a = 1
+  let b = 0
+  for (const byte of data) {
+    a = (a + byte) % 65521
+    b = (b + a) % 65521
+  }
+  view.setUint32(pos, ((b << 16) | a) >>> 0)
+  return out
+}
+
+/**
+ * Encode an image as an 8-bit RGBA PNG. `deflate` must produce a zlib stream
+ * (e.g. node:zlib's deflateSync); without it the pixels are stored uncompressed
+ */
+export function encodePng(
+  image: RgbaImage,
+  deflate: (data: Uint8Array) => Uint8Array = zlibStored
+): Uint8Array {
+  const { width, height, data } = image
+  // Each scanline starts with its filter type; 0 leaves the row as is
+  const raw = new Uint8Array(height * (width * 4 + 1))
+  for (let y = 0; y < height; y++) {
+    raw.set(data.subarray(y * width * 4, (y + 1) * width * 4), y * (width * 4 + 1) + 1)
+  }
+
+  const header = new Uint8Array(13)
+  const headerView = new DataView(header.buffer)
+  headerView.setUint32(0, width)
+  headerView.setUint32(4, height)
+  header.set([8, 6, 0, 0, 0], 8) // bit depth 8, RGBA, deflate, adaptive filtering, no interlace
+
+  const chunks = [
+    ['IHDR', header],
+    ['IDAT', deflate(raw)],
+    ['IEND', new Uint8Array(0)],
+  ] as const
+  const png = new Uint8Array(8 + chunks.reduce((sum, [, body]) => sum + body.length + 12, 0))
+  const view = new DataView(png.buffer)
+  png.set([0x89, 0x50, 0x4e, 0x47, 0x0d, 0x0a, 0x1a, 0x0a])
+  let pos = 8
+  for (const [type, body] of chunks) {
+    view.setUint32(pos, body.length)
+    png.set([...type].map(char => char.charCodeAt(0)), pos + 4)
+    png.set(body, pos + 8)
+    view.setUint32(pos + 8 + body.length, crc32(png.subarray(pos + 4, pos + 8 + body.length)))
+    pos += body.length + 12
+  }
+  return png
+}
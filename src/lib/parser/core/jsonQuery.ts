@@ -0,0 +1,185 @@
+/**
+ * Path and filter expressions over JSON documents (the CLI's --query)
+ * Enough for the extractions scripts would otherwise need jq for:
+ *   player_name                          one value
+ *   party_pokemon[0].moves               array index (negative counts from the end)
+ *   party_pokemon[*].nickname            every element
+ *   boxes[*][?is_shiny]                  elements passing a filter
+ *   party_pokemon[?level >= 50].nickname comparisons: == != < <= > >= against numbers,
+ *                                        'strings', true, false and null; [?!field] negates
+ * A query with [*] or a filter returns a flat array of everything it reached; otherwise it
+ * returns the single value, or null when the path doesn't exist
+ */
+
+type Literal = string | number | boolean | null
+
+const OPERATORS = ['==', '!=', '<=', '>=', '<', '>'] as const
+type Operator = (typeof OPERATORS)[number]
+
+interface Filter {
+  readonly path: readonly string[]
+  readonly negate: boolean
+  readonly comparison?: { readonly operator: Operator; readonly value: Literal }
+}
+
+type Step =
+  | { readonly kind: 'field'; readonly name: string }
+  | { readonly kind: 'index'; readonly index: number }
+  | { readonly kind: 'wildcard' }
+  | { readonly kind: 'filter'; readonly filter: Filter }
+
+const NAME = /[A-Za-z_][A-Za-z0-9_]*/y
+const NUMBER = /-?\d+(\.\d+)?/y
+const SPACE = /\s*/y
+
+/**
+ * Split a query into steps; throws with the position of the first thing it can't read
+ */
+function parseQuery(expression: string): Step[] {
+  let pos = 0
+  function fail(expected: string): never {
+    throw new Error(`Invalid query at character ${pos + 1}: expected ${expected}`)
+  }
+  const match = (pattern: RegExp) => {
+    pattern.lastIndex = pos
+    const found = pattern.exec(expression)?.[0]
+    if (found !== undefined) pos += found.length
+    return found
+  }
+  const skipSpace = () => match(SPACE)
+  const take = (text: string) => {
+    if (!expression.startsWith(text, pos)) return false
+    pos += text.length
+    return true
+  }
+  const name = () => match(NAME) || fail('a field name')
+
+  const literal = (): Literal => {
+    const quote = expression[pos]
+    if (quote === "'" || quote === '"') {
+      const end = expression.indexOf(quote, pos + 1)
+      if (end === -1) fail(`a closing ${quote}`)
+      const text = expression.slice(pos + 1, end)
+      pos = end + 1
+      return text
+    }
+    const number = match(NUMBER)
+    if (number !== undefined) return Number(number)
+    const word = match(NAME)
+    if (word === 'true' || word === 'false') return word === 'true'
+    if (word === 'null') return null
+    return fail('a number, a quoted string, true, false or null')
+  }
+
+  const filter = (): Filter => {
+    skipSpace()
+    const negate = take('!')
+    const path = [name()]
+    while (take('.')) path.push(name())
+    skipSpace()
+    const operator = OPERATORS.find(take)
+    if (!operator) return { path, negate }
+    skipSpace()
+    return { path, negate, comparison: { operator, value: literal() } }
+  }
+
+  const steps: Step[] = []
+  if (expression.trim() === '') return steps
+  if (expression[0] !== '[') steps.push({ kind: 'field', name: name() })
+  while (pos < expression.length) {
+    if (take('.')) {
+      steps.push({ kind: 'field', name: name() })
+    } else if (take('[*]')) {
+      steps.push({ kind: 'wildcard' })
+    } else if (take('[?')) {
+      steps.push({ kind: 'filter', filter: filter() })
+      skipSpace()
+      if (!take(']')) fail('] after the filter')
+    } else if (take('[')) {
+      const index = match(NUMBER)
+      if (index === undefined || index.includes('.')) fail('an array index, * or ?')
+      if (!take(']')) fail(']')
+      steps.push({ kind: 'index', index: Number(index) })
+    } else {
+      fail('., [index], [*] or [?filter]')
+    }
+  }
+  return steps
+}
+
+const isObject = (value: unknown): value is Readonly<Record<string, unknown>> =>
+  typeof value === 'object' && value !== null && !Array.isArray(value)
+
+const field = (value: unknown, name: string) =>
+  isObject(value) && Object.hasOwn(value, name) ? value[name] : undefined
+
+// The elements of an array, or the values of an object
+function children(value: unknown): readonly unknown[] {
+  if (Array.isArray(value)) return value
+  if (isObject(value)) return Object.values(value)
+  return []
+}
+
+// Negative, zero or positive; ordering only applies between two numbers or two strings
+function order(left: unknown, right: Literal): number | null {
+  if (typeof left === 'number' && typeof right === 'number') return left - right
+  if (typeof left === 'string' && typeof right === 'string') {
+    return Number(left > right) - Number(left < right)
+  }
+  return null
+}
+
+function compare(left: unknown, operator: Operator, right: Literal): boolean {
+  if (operator === '==') return left === right
+  if (operator === '!=') return left !== right
+  const sign = order(left, right)
+  if (sign === null) return false
+  switch (operator) {
+    case '<':
+      return sign < 0
+    case '<=':
+      return sign <= 0
+    case '>':
+      return sign > 0
+    case '>=':
+      return sign >= 0
+  }
+}
+
+function matches(value: unknown, { path, negate, comparison }: Filter): boolean {
+  const target = path.reduce<unknown>(field, value)
+  const result = comparison
+    ? compare(target, comparison.operator, comparison.value)
+    : Boolean(target)
+  return result !== negate
+}
+
+/**
+ * Evaluate a query against a JSON document (such as the --json output of toSaveJson)
+ * Throws on a query it can't parse
+ */
+export function evaluateQuery(expression: string, document: unknown): unknown {
+  let nodes: unknown[] = [document]
+  let projected = false
+  for (const step of parseQuery(expression)) {
+    switch (step.kind) {
+      case 'field':
+        nodes = nodes.map(node => field(node, step.name)).filter(node => node !== undefined)
+        break
+      case 'index':
+        nodes = nodes
+          .map(node => (Array.isArray(node) ? node.at(step.index) : undefined))
+          .filter(node => node !== undefined)
+        break
+      case 'wildcard':
+        projected = true
+        nodes = nodes.flatMap(children)
+        break
+      case 'filter':
+        projected = true
+        nodes = nodes.flatMap(children).filter(node => matches(node, step.filter))
+        break
+    }
+  }
+  return projected ? nodes : (nodes[0] ?? null)
+}
@@ -0,0 +1,189 @@
+/**
+ * Gen 3 legality checks for a single Pokemon
+ * Covers data the game itself can't produce: origin data, move slots and PP, event-only species
+ * and the PID/IV correlation of wild encounters. Learnsets are not embedded, so whether a
+ * species can learn its moves is not checked
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import { getBaseMovePP, getMaxMovePP } from './moves'
+import type { GameConfig, LegalityReport, SaveWarning } from './types'
+import { originGames } from './utils'
+
+// Origin games whose Pokemon were generated by the GBA games' PID/IV routine
+const GBA_ORIGIN_GAMES = new Set([1, 2, 3, 4, 5])
+// Colosseum Bonus Disc and Colosseum/XD
+const GAMECUBE_ORIGIN_GAMES = new Set([0, 15])
+
+// Mythicals only distributed through events; legitimate ones carry the fateful encounter flag
+const EVENT_ONLY_SPECIES: Readonly<Record<number, string>> = {
+  151: 'Mew',
+  251: 'Celebi',
+  385: 'Jirachi',
+  386: 'Deoxys',
+}
+
+// Roaming Latias and Latios keep only the low byte of their IVs (a Ruby/Sapphire/Emerald bug)
+const ROAMER_SPECIES = new Set([380, 381])
+
+const formatPid = (personality: number) => personality.toString(16).padStart(8, '0')
+
+const nextSeed = (seed: number) => (Math.imul(seed, 0x41c64e6d) + 0x6073) >>> 0
+
+/**
+ * Which of the GBA wild encounter methods (1, 2 or 4) generated this PID and IV pair, if any.
+ * Each method draws the PID low half, PID high half and two IV halves from the same LCG; methods
+ * 2 and 4 skip one call before the first or second IV half
+ */
+export function findPidIvMethod(personality: number, ivs: readonly number[]): 1 | 2 | 4 | null {
+  const pidLow = personality & 0xffff
+  const pidHigh = personality >>> 16
+  const iv1 = ivs[0]! | (ivs[1]! << 5) | (ivs[2]! << 10)
+  const iv2 = ivs[3]! | (ivs[4]! << 5) | (ivs[5]! << 10)
+
+  for (let low = 0; low < 0x10000; low++) {
+    const seed1 = ((pidLow << 16) | low) >>> 0
+    const seed2 = nextSeed(seed1)
+    if (seed2 >>> 16 !== pidHigh) continue
+    const seed3 = nextSeed(seed2)
+    const seed4 = nextSeed(seed3)
+    const seed5 = nextSeed(seed4)
+    // The top bit of each IV half is unused
+    const [out3, out4, out5] = [seed3, seed4, seed5].map(seed => (seed >>> 16) & 0x7fff)
+    if (out3 === iv1 && out4 === iv2) return 1
+    if (out4 === iv1 && out5 === iv2) return 2
+    if (out3 === iv1 && out5 === iv2) return 4
+  }
+  return null
+}
+
+/**
+ * Check a Pokemon for data the game can't produce. Games without Gen 3 species data (hacks with
+ * newer moves and species) are not checked and get a single info entry
+ */
+export function checkLegality(pokemon: PokemonBase, config: GameConfig): LegalityReport {
+  if (config.usesGen3SpeciesData === false) {
+    return {
+      legal: true,
+      issues: [
+        {
+          code: 'legality-unsupported',
+          severity: 'info',
+          message: `Legality checks need Gen 3 species and move data, which ${config.name} does not use`,
+        },
+      ],
+    }
+  }
+
+  // Eggs have no met data, moves are only checked once they hatch
+  if (pokemon.isEgg) return { legal: true, issues: [] }
+
+  const issues: SaveWarning[] = []
+  const issue = (
+    code: string,
+    severity: SaveWarning['severity'],
+    message: string,
+    context?: SaveWarning['context']
+  ) => {
+    issues.push({ code, severity, message, context })
+  }
+
+  // Origin data
+  const { metLevel, metLocation, originGame } = pokemon
+  if (originGames[originGame] === undefined) {
+    issue('origin-game-unknown', 'error', `Origin game ${originGame} does not exist`, {
+      origin_game: originGame,
+    })
+  }
+  if (metLevel > pokemon.level) {
+    issue(
+      'met-level-above-level',
+      'error',
+      `Met at level ${metLevel} but is level ${pokemon.level}`,
+      { met_level: metLevel, level: pokemon.level }
+    )
+  }
+  const locations = config.mappings?.locations
+  if (locations && GBA_ORIGIN_GAMES.has(originGame) && !locations.has(metLocation)) {
+    issue('met-location-unknown', 'error', `Met location ${metLocation} does not exist`, {
+      met_location: metLocation,
+    })
+  }
+
+  // Moves and PP
+  const { moveIds, ppValues, ppUps } = pokemon
+  if (moveIds.every(moveId => moveId === 0)) {
+    issue('no-moves', 'error', 'Knows no moves')
+  }
+  moveIds.forEach((moveId, i) => {
+    const slot = i + 1
+    if (moveId === 0) {
+      if (moveIds.slice(i + 1).some(later => later !== 0)) {
+        issue('move-gap', 'error', `Move slot ${slot} is empty before a filled slot`, { slot })
+      }
+      return
+    }
+    if (getBaseMovePP(moveId) === undefined) {
+      issue('move-unknown', 'error', `Move ${moveId} does not exist in Gen 3`, {
+        slot,
+        move: moveId,
+      })
+      return
+    }
+    if (moveIds.indexOf(moveId) < i) {
+      issue('move-duplicate', 'error', `Move ${moveId} is known twice`, { slot, move: moveId })
+    }
+    const maxPP = getMaxMovePP(moveId, ppUps[i])!
+    if (ppValues[i]! > maxPP) {
+      issue(
+        'pp-above-max',
+        'error',
+        `Move slot ${slot} has ${ppValues[i]} PP, more than ${maxPP}`,
+        { slot, pp: ppValues[i]!, max_pp: maxPP }
+      )
+    }
+  })
+
+  // Event-only species
+  const eventSpecies = EVENT_ONLY_SPECIES[pokemon.speciesId]
+  if (eventSpecies && !pokemon.fatefulEncounter && !GAMECUBE_ORIGIN_GAMES.has(originGame)) {
+    issue(
+      'event-species-without-event',
+      'error',
+      `${eventSpecies} is only distributed through events but lacks the fateful encounter flag`,
+      { species: pokemon.speciesId }
+    )
+  }
+
+  // Wild and gift Pokemon (not hatched: met level 0) come from the correlated PID/IV routine
+  if (
+    GBA_ORIGIN_GAMES.has(originGame) &&
+    metLevel > 0 &&
+    !ROAMER_SPECIES.has(pokemon.speciesId) &&
+    findPidIvMethod(pokemon.personality, pokemon.ivs) === null
+  ) {
+    issue(
+      'pid-iv-mismatch',
+      'warning',
+      `IVs ${pokemon.ivs.join('/')} can't come from PID ${formatPid(pokemon.personality)} (Method 1, 2 or 4)`,
+      { personality: pokemon.personality, ivs: pokemon.ivs.join('/') }
+    )
+  }
+
+  return { legal: !issues.some(({ severity }) => severity !== 'info'), issues }
+}
+
+/** Turn per-slot legality reports into warnings that name the Pokémon. */
+export const legalityWarnings = (
+  party: readonly PokemonBase[],
+  reports: readonly LegalityReport[]
+): SaveWarning[] =>
+  reports.flatMap((report, i) =>
+    report.issues
+      .filter(({ severity }) => severity !== 'info')
+      .map(issue => ({
+        ...issue,
+        message: `Party slot ${i + 1} (${party[i]!.nickname}): ${issue.message}`,
+        context: { ...issue.context, party_slot: i + 1 },
+      }))
+  )
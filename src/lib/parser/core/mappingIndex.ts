@@ -0,0 +1,90 @@
+/**
+ * Reverse lookup indices for ID mappings
+ * Built once per mapping table and cached so lookups don't scan the tables per query
+ */
+
+import type { BaseMappingItem } from './utils'
+
+export interface MappingIndex {
+  /** External (mapped) ID -> raw internal ID */
+  readonly rawByExternalId: ReadonlyMap<number, number>
+  /** Normalized display name or id_name -> raw internal ID */
+  readonly rawByName: ReadonlyMap<string, number>
+}
+
+const indexCache = new WeakMap<ReadonlyMap<number, BaseMappingItem>, MappingIndex>()
+const normalizedNameCache = new Map<string, string>()
+
+/**
+ * Normalize a name for lookups: case, spacing and punctuation insensitive
+ * ("Mr. Mime", "mr-mime" and "MR MIME" all normalize to "mrmime")
+ */
+export function normalizeMappingName(name: string): string {
+  let normalized = normalizedNameCache.get(name)
+  if (normalized === undefined) {
+    normalized = name
+      .normalize('NFD')
+      .replace(/[\u0300-\u036f]/g, '')
+      .toLowerCase()
+      .replace(/♀/g, 'f')
+      .replace(/♂/g, 'm')
+      .replace(/[^a-z0-9]/g, '')
+    normalizedNameCache.set(name, normalized)
+  }
+  return normalized
+}
+
+/**
+ * Get (building on first use) the reverse index for a mapping table
+ */
+export function getMappingIndex(mapping: ReadonlyMap<number, BaseMappingItem>): MappingIndex {
+  let index = indexCache.get(mapping)
+  if (!index) {
+    const rawByExternalId = new Map<number, number>()
+    const rawByName = new Map<string, number>()
+    for (const [raw, entry] of mapping) {
+      // First raw ID wins, matching the previous linear-scan behavior
+      if (entry.id !== null && !rawByExternalId.has(entry.id)) rawByExternalId.set(entry.id, raw)
+      for (const name of [entry.name, entry.id_name]) {
+        const key = normalizeMappingName(name)
+        if (!rawByName.has(key)) rawByName.set(key, raw)
+      }
+    }
+    index = { rawByExternalId, rawByName }
+    indexCache.set(mapping, index)
+  }
+  return index
+}
+
+/**
+ * Convert an external (mapped) ID back to the raw internal ID, or return it unchanged if unmapped
+ */
+export function toRawId(
+  mapping: ReadonlyMap<number, BaseMappingItem> | undefined,
+  externalId: number
+): number {
+  if (!mapping) return externalId
+  return getMappingIndex(mapping).rawByExternalId.get(externalId) ?? externalId
+}
+
+/**
+ * Look up a raw internal ID by display name or id_name
+ */
+export function findRawIdByName(
+  mapping: ReadonlyMap<number, BaseMappingItem> | undefined,
+  name: string
+): number | undefined {
+  if (!mapping) return undefined
+  return getMappingIndex(mapping).rawByName.get(normalizeMappingName(name))
+}
+
+/**
+ * Look up a mapping entry by display name or id_name
+ */
+export function findMappingByName<T extends BaseMappingItem>(
+  mapping: ReadonlyMap<number, T> | undefined,
+  name: string
+): T | undefined {
+  const raw = findRawIdByName(mapping, name)
+  return raw === undefined ? undefined : mapping?.get(raw)
+}
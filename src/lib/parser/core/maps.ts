@@ -0,0 +1,78 @@
+/**
+ * Vanilla Emerald map names
+ * Resolves the (map group, map number) pairs stored in warps and the player's position
+ */
+
+const TOWNS_AND_CITIES = [
+  'Petalburg City',
+  'Slateport City',
+  'Mauville City',
+  'Rustboro City',
+  'Fortree City',
+  'Lilycove City',
+  'Mossdeep City',
+  'Sootopolis City',
+  'Ever Grande City',
+  'Littleroot Town',
+  'Oldale Town',
+  'Dewford Town',
+  'Lavaridge Town',
+  'Fallarbor Town',
+  'Verdanturf Town',
+  'Pacifidlog Town',
+] as const
+
+/**
+ * Map group 0: towns and cities, then Routes 101-134, then the underwater maps
+ */
+const TOWNS_AND_ROUTES: readonly string[] = [
+  ...TOWNS_AND_CITIES,
+  ...Array.from({ length: 34 }, (_, i) => `Route ${101 + i}`),
+  ...[124, 126, 127, 128, 129, 105, 125].map(route => `Route ${route} (Underwater)`),
+]
+
+/**
+ * Place whose buildings make up each indoor map group; groups 24-26 (dungeons, dynamic and special
+ * areas) hold unrelated maps and have no single name
+ */
+const INDOOR_GROUPS: Readonly<Record<number, string>> = {
+  1: 'Littleroot Town',
+  2: 'Oldale Town',
+  3: 'Dewford Town',
+  4: 'Lavaridge Town',
+  5: 'Fallarbor Town',
+  6: 'Verdanturf Town',
+  7: 'Pacifidlog Town',
+  8: 'Petalburg City',
+  9: 'Slateport City',
+  10: 'Mauville City',
+  11: 'Rustboro City',
+  12: 'Fortree City',
+  13: 'Lilycove City',
+  14: 'Mossdeep City',
+  15: 'Sootopolis City',
+  16: 'Ever Grande City',
+  17: 'Route 104',
+  18: 'Route 111',
+  19: 'Route 112',
+  20: 'Route 114',
+  21: 'Route 116',
+  22: 'Route 117',
+  23: 'Route 121',
+  27: 'Route 104',
+  28: 'Route 109',
+  29: 'Route 110',
+  30: 'Route 113',
+  31: 'Route 123',
+  32: 'Route 119',
+  33: 'Route 124',
+}
+
+/**
+ * Human-readable name for a map, or null when the map can't be named
+ */
+export function getMapName(mapGroup: number, mapNum: number): string | null {
+  if (mapGroup === 0) return TOWNS_AND_ROUTES[mapNum] ?? null
+  const place = INDOOR_GROUPS[mapGroup]
+  return place ? `${place} (Indoors)` : null
+}
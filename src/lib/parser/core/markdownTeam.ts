@@ -0,0 +1,57 @@
+/**
+ * Markdown team summary of a save (the CLI's --format=markdown)
+ * A GitHub-flavored table of the party that also renders on Discord and Reddit: one row per
+ * Pokemon with a shiny marker, its set and its Hidden Power
+ */
+
+import { type PokemonJson, toPokemonJson } from './saveJson'
+import type { GameConfig, SaveData } from './types'
+import { getHiddenPower } from './utils'
+
+const HEADER = ['#', 'Pokémon', 'Lv', 'Nature', 'Ability', 'Item', 'Moves', 'Hidden Power']
+
+/** Escape text for a markdown table cell: pipes split cells and newlines end the row */
+export function escapeMarkdownCell(text: string): string {
+  return text.replace(/([\\|*_`~[\]<>])/g, '\\$1').replace(/\s*\n\s*/g, ' ')
+}
+
+const row = (cells: readonly string[]) => `| ${cells.join(' | ')} |`
+
+const capitalize = (text: string) => text.charAt(0).toUpperCase() + text.slice(1)
+
+function pokemonRow(pokemon: PokemonJson, slot: number): string[] {
+  if (pokemon.is_egg) return [String(slot), 'Egg', '', '', '', '', '', '']
+
+  const species = pokemon.species_name ?? `#${pokemon.species_id}`
+  const name =
+    pokemon.nickname && pokemon.nickname.toLowerCase() !== species.toLowerCase()
+      ? `${escapeMarkdownCell(pokemon.nickname)} (${escapeMarkdownCell(species)})`
+      : escapeMarkdownCell(species)
+  const hiddenPower = getHiddenPower(pokemon.ivs)
+  return [
+    String(slot),
+    pokemon.is_shiny ? `★ ${name}` : name,
+    String(pokemon.level),
+    pokemon.nature,
+    escapeMarkdownCell(pokemon.ability ?? ''),
+    escapeMarkdownCell(pokemon.item_name ?? ''),
+    escapeMarkdownCell(pokemon.move_names.filter(move => move !== null).join(', ')),
+    `${capitalize(hiddenPower.type)} ${hiddenPower.power}`,
+  ]
+}
+
+/**
+ * Format the party of a parsed save as a markdown table, headed by the trainer and game
+ */
+export function formatMarkdownTeam(saveData: SaveData, config: GameConfig): string {
+  const party = saveData.party_pokemon.map(pokemon => toPokemonJson(pokemon, config))
+  const lines = [
+    `**${escapeMarkdownCell(saveData.player_name)}**'s team (${escapeMarkdownCell(config.name)})`,
+    '',
+    row(HEADER),
+    row(HEADER.map(cell => (cell === 'Lv' || cell === '#' ? '---:' : '---'))),
+    ...party.map((pokemon, i) => row(pokemonRow(pokemon, i + 1))),
+  ]
+  if (party.some(pokemon => pokemon.is_shiny)) lines.push('', '★ Shiny')
+  return lines.join('\n')
+}
@@ -0,0 +1,172 @@
+/**
+ * Gen 3 move data: base PP and types by move ID (pokeemerald's gBattleMoves)
+ * Later generations changed some of these, so hacks with newer move data may differ
+ */
+
+import type { PokemonType } from './species'
+
+// Base PP of moves 1-354 (Pound to Psycho Boost), ten per row
+const BASE_PP: readonly number[] = [
+  // 1-10
+  35, 25, 10, 15, 20, 20, 15, 15, 15, 35,
+  // 11-20
+  30, 5, 10, 30, 30, 35, 35, 20, 15, 20,
+  // 21-30
+  20, 10, 20, 30, 5, 25, 15, 15, 15, 25,
+  // 31-40
+  20, 5, 35, 15, 20, 20, 20, 15, 30, 35,
+  // 41-50
+  20, 20, 30, 25, 40, 20, 15, 20, 20, 20,
+  // 51-60
+  30, 25, 15, 30, 25, 5, 15, 10, 5, 20,
+  // 61-70
+  20, 20, 5, 35, 20, 25, 20, 20, 20, 15,
+  // 71-80
+  20, 10, 10, 40, 25, 10, 35, 30, 15, 20,
+  // 81-90
+  40, 10, 15, 30, 15, 20, 10, 15, 10, 5,
+  // 91-100
+  10, 10, 25, 10, 20, 40, 30, 30, 20, 20,
+  // 101-110
+  15, 10, 40, 15, 20, 30, 20, 20, 10, 40,
+  // 111-120
+  40, 30, 30, 30, 20, 30, 10, 10, 20, 5,
+  // 121-130
+  10, 30, 20, 20, 20, 5, 15, 10, 20, 15,
+  // 131-140
+  15, 35, 20, 15, 10, 20, 30, 15, 40, 20,
+  // 141-150
+  15, 10, 5, 10, 30, 10, 15, 20, 15, 40,
+  // 151-160
+  40, 10, 5, 15, 10, 10, 10, 15, 30, 30,
+  // 161-170
+  10, 10, 20, 10, 1, 1, 10, 10, 10, 5,
+  // 171-180
+  15, 25, 15, 10, 15, 30, 5, 40, 15, 10,
+  // 181-190
+  25, 10, 30, 10, 20, 10, 10, 10, 10, 10,
+  // 191-200
+  20, 5, 40, 5, 5, 15, 5, 10, 5, 15,
+  // 201-210
+  10, 5, 10, 20, 20, 40, 15, 10, 20, 20,
+  // 211-220
+  25, 5, 15, 10, 5, 20, 15, 20, 25, 20,
+  // 221-230
+  5, 30, 5, 10, 20, 40, 5, 20, 40, 20,
+  // 231-240
+  15, 35, 10, 5, 5, 5, 15, 5, 20, 5,
+  // 241-250
+  5, 15, 20, 10, 5, 5, 15, 15, 15, 15,
+  // 251-260
+  10, 10, 10, 20, 10, 10, 10, 10, 15, 15,
+  // 261-270
+  15, 10, 20, 20, 10, 20, 20, 20, 20, 20,
+  // 271-280
+  10, 10, 10, 20, 20, 5, 15, 10, 10, 15,
+  // 281-290
+  10, 20, 5, 5, 10, 10, 20, 5, 10, 20,
+  // 291-300
+  10, 20, 20, 20, 5, 5, 15, 20, 10, 15,
+  // 301-310
+  20, 15, 10, 10, 15, 10, 5, 5, 10, 15,
+  // 311-320
+  10, 5, 20, 25, 5, 40, 10, 5, 40, 15,
+  // 321-330
+  20, 20, 5, 15, 20, 30, 15, 15, 5, 10,
+  // 331-340
+  30, 20, 30, 15, 5, 40, 15, 5, 20, 5,
+  // 341-350
+  15, 25, 40, 15, 20, 15, 20, 15, 20, 10,
+  // 351-354
+  20, 20, 5, 5,
+]
+
+/**
+ * Base PP of a Gen 3 move; undefined for 0 (no move) and IDs past Psycho Boost
+ */
+export function getBaseMovePP(moveId: number): number | undefined {
+  return BASE_PP[moveId - 1]
+}
+
+/**
+ * Maximum PP of a move with 0-3 PP Ups applied (each adds a fifth of the base PP)
+ */
+export function getMaxMovePP(moveId: number, ppUps = 0): number | undefined {
+  const base = getBaseMovePP(moveId)
+  return base === undefined ? undefined : base + Math.floor((base * ppUps) / 5)
+}
+
+// Gen 3 move IDs by type; Curse has the ??? type and is in none of them
+const MOVES_BY_TYPE: Readonly<Record<PokemonType, readonly number[]>> = {
+  normal: [
+    1, 3, 4, 5, 6, 10, 11, 12, 13, 14, 15, 18, 20, 21, 23, 25, 29, 30, 31, 32, 33, 34, 35, 36, 37,
+    38, 39, 43, 45, 46, 47, 48, 49, 50, 63, 70, 74, 98, 99, 102, 103, 104, 105, 106, 107, 108, 111,
+    116, 117, 118, 120, 121, 129, 130, 131, 132, 135, 137, 140, 142, 144, 146, 148, 150, 153, 154,
+    158, 159, 160, 161, 162, 163, 164, 165, 166, 170, 173, 175, 176, 182, 184, 186, 187, 193, 195,
+    199, 203, 204, 206, 207, 208, 212, 213, 214, 215, 216, 217, 218, 219, 220, 226, 227, 229, 230,
+    234, 236, 237, 244, 245, 252, 253, 254, 255, 256, 263, 265, 266, 267, 270, 273, 274, 278, 281,
+    283, 287, 290, 293, 298, 303, 304, 306, 311, 316, 321, 335, 336, 343,
+  ],
+  fighting: [
+    2, 24, 26, 27, 66, 67, 68, 69, 136, 167, 179, 183, 197, 223, 233, 238, 249, 264, 276, 279, 280,
+    292, 327, 339,
+  ],
+  flying: [16, 17, 19, 64, 65, 119, 143, 177, 297, 314, 332, 340],
+  poison: [40, 51, 77, 92, 123, 124, 139, 151, 188, 305, 342],
+  ground: [28, 89, 90, 91, 125, 155, 189, 191, 198, 222, 300, 328, 341],
+  rock: [88, 157, 201, 205, 246, 317, 350],
+  bug: [41, 42, 81, 141, 169, 210, 224, 294, 318, 324],
+  ghost: [101, 109, 122, 171, 180, 194, 247, 288, 310, 325],
+  steel: [211, 231, 232, 309, 319, 334, 353],
+  fire: [7, 52, 53, 83, 126, 172, 221, 241, 257, 261, 284, 299, 307, 315],
+  water: [55, 56, 57, 61, 110, 127, 128, 145, 152, 190, 240, 250, 291, 308, 323, 330, 346, 352],
+  grass: [
+    22, 71, 72, 73, 75, 76, 78, 79, 80, 147, 178, 202, 235, 275, 302, 312, 320, 331, 338, 345, 348,
+  ],
+  electric: [9, 84, 85, 86, 87, 192, 209, 268, 344, 351],
+  psychic: [
+    60, 93, 94, 95, 96, 97, 100, 112, 113, 115, 133, 134, 138, 149, 156, 243, 248, 271, 272, 277,
+    285, 286, 295, 296, 322, 326, 347, 354,
+  ],
+  ice: [8, 54, 58, 59, 62, 114, 181, 196, 258, 301, 329, 333],
+  dragon: [82, 200, 225, 239, 337, 349],
+  dark: [44, 168, 185, 228, 242, 251, 259, 260, 262, 269, 282, 289, 313],
+}
+
+const MOVE_TYPES = new Map(
+  Object.entries(MOVES_BY_TYPE).flatMap(([type, ids]) =>
+    ids.map(id => [id, type as PokemonType] as const)
+  )
+)
+
+// Moves without base power
+const STATUS_MOVES: ReadonlySet<number> = new Set([
+  14, 18, 28, 39, 43, 45, 46, 47, 48, 50, 54, 73, 74, 77, 78, 79, 81, 86, 92, 95, 96, 97, 100, 102,
+  103, 104, 105, 106, 107, 108, 109, 110, 111, 112, 113, 114, 115, 116, 118, 119, 133, 134, 135,
+  137, 139, 142, 144, 147, 148, 150, 151, 156, 159, 160, 164, 166, 169, 170, 171, 174, 176, 178,
+  180, 182, 184, 186, 187, 191, 193, 194, 195, 197, 199, 201, 203, 204, 207, 208, 212, 213, 214,
+  215, 219, 220, 226, 227, 230, 234, 235, 236, 240, 241, 244, 254, 256, 258, 259, 260, 261, 262,
+  266, 267, 268, 269, 270, 271, 272, 273, 274, 275, 277, 278, 281, 285, 286, 287, 288, 289, 293,
+  294, 297, 298, 300, 303, 312, 313, 316, 319, 320, 321, 322, 334, 335, 336, 339, 346, 347, 349,
+])
+
+// Damaging moves whose damage ignores type matchups apart from immunities: fixed damage, OHKO,
+// Counter/Bide/Mirror Coat, and the typeless Gen 3 Future Sight, Beat Up and Doom Desire
+const UNTYPED_DAMAGE_MOVES: ReadonlySet<number> = new Set([
+  12, 32, 49, 68, 69, 82, 90, 101, 117, 149, 162, 243, 248, 251, 283, 329, 353,
+])
+
+/**
+ * Type of a Gen 3 move; undefined for 0, Curse (???) and IDs past Psycho Boost
+ */
+export function getMoveType(moveId: number): PokemonType | undefined {
+  return MOVE_TYPES.get(moveId)
+}
+
+/**
+ * Whether a move deals damage scaled by type effectiveness, i.e. counts toward offensive coverage.
+ * Hidden Power is included; its real type comes from the user's IVs (getHiddenPower)
+ */
+export function isTypedAttack(moveId: number): boolean {
+  return MOVE_TYPES.has(moveId) && !STATUS_MOVES.has(moveId) && !UNTYPED_DAMAGE_MOVES.has(moveId)
+}
@@ -0,0 +1,317 @@
+/**
+ * MessagePack encoding for moving save data between threads and processes
+ * A binary alternative to JSON strings for the same documents (e.g. toSaveJson output): no
+ * stringify/parse round trip and save bytes travel as bin instead of number arrays.
+ * Values follow JSON.stringify semantics (toJSON is honored, undefined keys are dropped)
+ * See: https://github.com/msgpack/msgpack/blob/master/spec.md
+ */
+
+export type MsgPackValue =
+  | null
+  | boolean
+  | number
+  | string
+  | Uint8Array
+  | readonly MsgPackValue[]
+  | { readonly [key: string]: MsgPackValue }
+
+const textEncoder = new TextEncoder()
+const textDecoder = new TextDecoder()
+
+class ByteWriter {
+  private bytes = new Uint8Array(1024)
+  private view = new DataView(this.bytes.buffer)
+  private length = 0
+
+  private reserve(size: number): number {
+    if (this.length + size > this.bytes.length) {
+      const grown = new Uint8Array(Math.max(this.bytes.length * 2, this.length + size))
+      grown.set(this.bytes)
+      this.bytes = grown
+      this.view = new DataView(grown.buffer)
+    }
+    const offset = this.length
+    this.length += size
+    return offset
+  }
+
+  u8(value: number) {
+    this.bytes[this.reserve(1)] = value
+  }
+
+  u16(value: number) {
+    this.view.setUint16(this.reserve(2), value)
+  }
+
+  u32(value: number) {
+    this.view.setUint32(this.reserve(4), value)
+  }
+
+  i8(value: number) {
+    this.view.setInt8(this.reserve(1), value)
+  }
+
+  i16(value: number) {
+    this.view.setInt16(this.reserve(2), value)
+  }
+
+  i32(value: number) {
+    this.view.setInt32(this.reserve(4), value)
+  }
+
+  u64(value: number) {
+    this.view.setBigUint64(this.reserve(8), BigInt(value))
+  }
+
+  i64(value: number) {
+    this.view.setBigInt64(this.reserve(8), BigInt(value))
+  }
+
+  f64(value: number) {
+    this.view.setFloat64(this.reserve(8), value)
+  }
+
+  raw(data: Uint8Array) {
+    this.bytes.set(data, this.reserve(data.length))
+  }
+
+  result(): Uint8Array {
+    return this.bytes.slice(0, this.length)
+  }
+}
+
+// Header for a str/bin/array/map: the fix form when one exists, then 8/16/32-bit lengths
+function writeLength(
+  writer: ByteWriter,
+  length: number,
+  fix: { prefix: number; max: number } | null,
+  [l8, l16, l32]: readonly [number | null, number, number]
+) {
+  if (fix && length <= fix.max) return writer.u8(fix.prefix | length)
+  if (l8 !== null && length <= 0xff) {
+    writer.u8(l8)
+    writer.u8(length)
+  } else if (length <= 0xffff) {
+    writer.u8(l16)
+    writer.u16(length)
+  } else {
+    writer.u8(l32)
+    writer.u32(length)
+  }
+}
+
+function writeNumber(writer: ByteWriter, value: number) {
+  if (!Number.isSafeInteger(value)) {
+    // NaN and Infinity become null like in JSON
+    if (!Number.isFinite(value)) return writer.u8(0xc0)
+    writer.u8(0xcb)
+    return writer.f64(value)
+  }
+  if (value >= 0 && value <= 0x7f) return writer.u8(value)
+  if (value < 0 && value >= -32) return writer.i8(value)
+
+  if (value > 0xffffffff) {
+    writer.u8(0xcf)
+    writer.u64(value)
+  } else if (value > 0xffff) {
+    writer.u8(0xce)
+    writer.u32(value)
+  } else if (value > 0xff) {
+    writer.u8(0xcd)
+    writer.u16(value)
+  } else if (value >= 0) {
+    writer.u8(0xcc)
+    writer.u8(value)
+  } else if (value >= -0x80) {
+    writer.u8(0xd0)
+    writer.i8(value)
+  } else if (value >= -0x8000) {
+    writer.u8(0xd1)
+    writer.i16(value)
+  } else if (value >= -0x80000000) {
+    writer.u8(0xd2)
+    writer.i32(value)
+  } else {
+    writer.u8(0xd3)
+    writer.i64(value)
+  }
+}
+
+function writeValue(writer: ByteWriter, value: unknown): void {
+  if (value === null || value === undefined || typeof value === 'function') {
+    return writer.u8(0xc0)
+  }
+  if (typeof value === 'boolean') return writer.u8(value ? 0xc3 : 0xc2)
+  if (typeof value === 'number') return writeNumber(writer, value)
+  if (typeof value === 'string') {
+    const utf8 = textEncoder.encode(value)
+    writeLength(writer, utf8.length, { prefix: 0xa0, max: 31 }, [0xd9, 0xda, 0xdb])
+    return writer.raw(utf8)
+  }
+  if (value instanceof Uint8Array) {
+    writeLength(writer, value.length, null, [0xc4, 0xc5, 0xc6])
+    return writer.raw(value)
+  }
+  if (Array.isArray(value)) {
+    writeLength(writer, value.length, { prefix: 0x90, max: 15 }, [null, 0xdc, 0xdd])
+    for (const item of value) writeValue(writer, item)
+    return
+  }
+  if (typeof value === 'object') {
+    const json = (value as { toJSON?: () => unknown }).toJSON
+    if (typeof json === 'function') return writeValue(writer, json.call(value))
+
+    const entries = Object.entries(value).filter(
+      ([, item]) => item !== undefined && typeof item !== 'function'
+    )
+    writeLength(writer, entries.length, { prefix: 0x80, max: 15 }, [null, 0xde, 0xdf])
+    for (const [key, item] of entries) {
+      writeValue(writer, key)
+      writeValue(writer, item)
+    }
+    return
+  }
+  throw new Error(`Cannot encode a ${typeof value} as MessagePack`)
+}
+
+/**
+ * Encode a value as MessagePack
+ */
+export function encodeMsgPack(value: unknown): Uint8Array {
+  const writer = new ByteWriter()
+  writeValue(writer, value)
+  return writer.result()
+}
+
+class ByteReader {
+  private readonly view: DataView
+  offset = 0
+
+  constructor(private readonly bytes: Uint8Array) {
+    this.view = new DataView(bytes.buffer, bytes.byteOffset, bytes.byteLength)
+  }
+
+  get remaining(): number {
+    return this.bytes.length - this.offset
+  }
+
+  private advance(size: number): number {
+    if (size > this.remaining) {
+      throw new Error(`MessagePack data ends early at byte ${this.bytes.length}`)
+    }
+    const offset = this.offset
+    this.offset += size
+    return offset
+  }
+
+  u8 = () => this.view.getUint8(this.advance(1))
+  u16 = () => this.view.getUint16(this.advance(2))
+  u32 = () => this.view.getUint32(this.advance(4))
+  i8 = () => this.view.getInt8(this.advance(1))
+  i16 = () => this.view.getInt16(this.advance(2))
+  i32 = () => this.view.getInt32(this.advance(4))
+  u64 = () => Number(this.view.getBigUint64(this.advance(8)))
+  i64 = () => Number(this.view.getBigInt64(this.advance(8)))
+  f32 = () => this.view.getFloat32(this.advance(4))
+  f64 = () => this.view.getFloat64(this.advance(8))
+
+  raw(length: number): Uint8Array {
+    const offset = this.advance(length)
+    return this.bytes.slice(offset, offset + length)
+  }
+}
+
+function readValue(reader: ByteReader): MsgPackValue {
+  const type = reader.u8()
+  if (type <= 0x7f) return type
+  if (type >= 0xe0) return type - 0x100
+  if ((type & 0xe0) === 0xa0) return textDecoder.decode(reader.raw(type & 0x1f))
+  if ((type & 0xf0) === 0x90) return readArray(reader, type & 0x0f)
+  if ((type & 0xf0) === 0x80) return readMap(reader, type & 0x0f)
+
+  switch (type) {
+    case 0xc0:
+      return null
+    case 0xc2:
+      return false
+    case 0xc3:
+      return true
+    case 0xc4:
+      return reader.raw(reader.u8())
+    case 0xc5:
+      return reader.raw(reader.u16())
+    case 0xc6:
+      return reader.raw(reader.u32())
+    case 0xca:
+      return reader.f32()
+    case 0xcb:
+      return reader.f64()
+    case 0xcc:
+      return reader.u8()
+    case 0xcd:
+      return reader.u16()
+    case 0xce:
+      return reader.u32()
+    case 0xcf:
+      return reader.u64()
+    case 0xd0:
+      return reader.i8()
+    case 0xd1:
+      return reader.i16()
+    case 0xd2:
+      return reader.i32()
+    case 0xd3:
+      return reader.i64()
+    case 0xd9:
+      return textDecoder.decode(reader.raw(reader.u8()))
+    case 0xda:
+      return textDecoder.decode(reader.raw(reader.u16()))
+    case 0xdb:
+      return textDecoder.decode(reader.raw(reader.u32()))
+    case 0xdc:
+      return readArray(reader, reader.u16())
+    case 0xdd:
+      return readArray(reader, reader.u32())
+    case 0xde:
+      return readMap(reader, reader.u16())
+    case 0xdf:
+      return readMap(reader, reader.u32())
+    default:
+      // Extension types (0xc7-0xc9, 0xd4-0xd8) are never written by encodeMsgPack
+      throw new Error(`Unsupported MessagePack type 0x${type.toString(16)}`)
+  }
+}
+
+function readArray(reader: ByteReader, length: number): MsgPackValue[] {
+  return Array.from({ length }, () => readValue(reader))
+}
+
+function readMap(reader: ByteReader, size: number): Record<string, MsgPackValue> {
+  const map: Record<string, MsgPackValue> = {}
+  for (let i = 0; i < size; i++) {
+    const key = readValue(reader)
+    if (typeof key !== 'string' && typeof key !== 'number') {
+      throw new Error('MessagePack map keys must be strings or numbers')
+    }
+    // defineProperty so a "__proto__" key stays a plain key
+    Object.defineProperty(map, key, {
+      value: readValue(reader),
+      enumerable: true,
+      writable: true,
+      configurable: true,
+    })
+  }
+  return map
+}
+
+/**
+ * Decode one MessagePack value; trailing bytes are an error
+ */
+export function decodeMsgPack(bytes: Uint8Array): MsgPackValue {
+  const reader = new ByteReader(bytes)
+  const value = readValue(reader)
+  if (reader.remaining > 0) {
+    throw new Error(`${reader.remaining} trailing bytes after the MessagePack value`)
+  }
+  return value
+}
@@ -0,0 +1,262 @@
+/**
+ * Minimal Parquet file writer
+ * One row group with one uncompressed, PLAIN-encoded data page per column: enough for DuckDB,
+ * pandas/pyarrow and Spark to load exports without a Parquet library here
+ * See: https://github.com/apache/parquet-format (parquet.thrift)
+ */
+
+export type ParquetValue = string | number | boolean | null
+
+export interface ParquetColumn {
+  readonly name: string
+  /** int64 for whole numbers, string for UTF-8 text, boolean for flags */
+  readonly type: 'int64' | 'string' | 'boolean'
+  /** Whether the column accepts nulls */
+  readonly optional?: boolean
+}
+
+export type ParquetRow = Readonly<Record<string, ParquetValue>>
+
+const MAGIC = new TextEncoder().encode('PAR1')
+
+const IS_TYPE: Readonly<Record<ParquetColumn['type'], (value: ParquetValue) => boolean>> = {
+  int64: value => Number.isInteger(value),
+  string: value => typeof value === 'string',
+  boolean: value => typeof value === 'boolean',
+}
+
+// parquet.thrift enums
+const PHYSICAL_TYPES = { boolean: 0, int64: 2, string: 6 } as const
+const REPETITION_REQUIRED = 0
+const REPETITION_OPTIONAL = 1
+const CONVERTED_TYPE_UTF8 = 0
+const ENCODING_PLAIN = 0
+const ENCODING_RLE = 3
+const CODEC_UNCOMPRESSED = 0
+const PAGE_TYPE_DATA = 0
+
+// The footer and page headers use Thrift's compact protocol; these are the value kinds they need
+type ThriftValue =
+  | { readonly kind: 'i32' | 'i64'; readonly value: number }
+  | { readonly kind: 'binary'; readonly value: string }
+  | {
+      readonly kind: 'list'
+      readonly element: 'i32' | 'binary' | 'struct'
+      readonly items: readonly ThriftValue[]
+    }
+  | { readonly kind: 'struct'; readonly fields: Readonly<Record<number, ThriftValue | undefined>> }
+
+const COMPACT_TYPES = { i32: 5, i64: 6, binary: 8, list: 9, struct: 12 } as const
+
+const i32 = (value: number): ThriftValue => ({ kind: 'i32', value })
+const i64 = (value: number): ThriftValue => ({ kind: 'i64', value })
+const binary = (value: string): ThriftValue => ({ kind: 'binary', value })
+const list = (
+  element: 'i32' | 'binary' | 'struct',
+  items: readonly ThriftValue[]
+): ThriftValue => ({ kind: 'list', element, items })
+const struct = (fields: Readonly<Record<number, ThriftValue | undefined>>): ThriftValue => ({
+  kind: 'struct',
+  fields,
+})
+
+function writeVarint(out: number[], value: number) {
+  // Division instead of shifts so 64-bit sizes above 2^31 survive
+  while (value >= 0x80) {
+    out.push((value % 0x80) | 0x80)
+    value = Math.floor(value / 0x80)
+  }
+  out.push(value)
+}
+
+const zigzag = (value: number) => (value >= 0 ? value * 2 : -value * 2 - 1)
+
+function writeThrift(out: number[], value: ThriftValue) {
+  switch (value.kind) {
+    case 'i32':
+    case 'i64':
+      writeVarint(out, zigzag(value.value))
+      return
+    case 'binary': {
+      const bytes = new TextEncoder().encode(value.value)
+      writeVarint(out, bytes.length)
+      append(out, bytes)
+      return
+    }
+    case 'list': {
+      const type = COMPACT_TYPES[value.element]
+      if (value.items.length < 15) {
+        out.push((value.items.length << 4) | type)
+      } else {
+        out.push(0xf0 | type)
+        writeVarint(out, value.items.length)
+      }
+      for (const item of value.items) writeThrift(out, item)
+      return
+    }
+    case 'struct': {
+      let lastId = 0
+      // Integer keys enumerate in ascending order, as the compact protocol's field deltas need
+      for (const [key, field] of Object.entries(value.fields)) {
+        if (!field) continue
+        const id = Number(key)
+        const type = COMPACT_TYPES[field.kind]
+        if (id - lastId > 0 && id - lastId <= 15) {
+          out.push(((id - lastId) << 4) | type)
+        } else {
+          out.push(type)
+          writeVarint(out, zigzag(id))
+        }
+        writeThrift(out, field)
+        lastId = id
+      }
+      out.push(0) // stop field
+    }
+  }
+}
+
+// Pushed one by one: spreading a whole column's bytes into push() can overflow the call stack
+function append(out: number[], bytes: ArrayLike<number>) {
+  for (let i = 0; i < bytes.length; i++) out.push(bytes[i]!)
+}
+
+const writeUint32 = (out: number[], value: number) =>
+  out.push(value & 0xff, (value >>> 8) & 0xff, (value >>> 16) & 0xff, value >>> 24)
+
+// Booleans (values and definition levels) are bit-packed least significant bit first
+function packBits(out: number[], bits: readonly boolean[]) {
+  for (let i = 0; i < bits.length; i += 8) {
+    let byte = 0
+    for (let bit = 0; bit < 8 && i + bit < bits.length; bit++) {
+      if (bits[i + bit]) byte |= 1 << bit
+    }
+    out.push(byte)
+  }
+}
+
+function pageData(column: ParquetColumn, values: readonly ParquetValue[]): number[] {
+  const out: number[] = []
+  if (column.optional) {
+    // Definition levels: one bit-packed run of bit width 1 in the RLE/bit-packing hybrid,
+    // behind its byte length
+    const levels: number[] = []
+    writeVarint(levels, (Math.ceil(values.length / 8) << 1) | 1)
+    packBits(levels, values.map(value => value !== null))
+    writeUint32(out, levels.length)
+    append(out, levels)
+  }
+
+  const present = values.filter(value => value !== null)
+  if (column.type === 'boolean') {
+    packBits(out, present.map(Boolean))
+    return out
+  }
+  const encoder = new TextEncoder()
+  for (const value of present) {
+    if (column.type === 'string') {
+      const bytes = encoder.encode(String(value))
+      writeUint32(out, bytes.length)
+      append(out, bytes)
+    } else {
+      const bytes = new Uint8Array(8)
+      new DataView(bytes.buffer).setBigInt64(0, BigInt(value as number), true)
+      append(out, bytes)
+    }
+  }
+  return out
+}
+
+/**
+ * Build a Parquet file with the given columns; rows are keyed by column name
+ * Throws when a required column gets a null or a value of the wrong type
+ */
+export function createParquet(
+  columns: readonly ParquetColumn[],
+  rows: readonly ParquetRow[]
+): Uint8Array {
+  const out: number[] = [...MAGIC]
+  const chunks = columns.map(column => {
+    const values = rows.map(row => row[column.name] ?? null)
+    values.forEach((value, i) => {
+      if (value === null) {
+        if (!column.optional) throw new Error(`Row ${i + 1} has no ${column.name}`)
+        return
+      }
+      if (!IS_TYPE[column.type](value)) {
+        throw new Error(`Row ${i + 1} has a non-${column.type} ${column.name}`)
+      }
+    })
+
+    const data = pageData(column, values)
+    const header: number[] = []
+    writeThrift(
+      header,
+      struct({
+        1: i32(PAGE_TYPE_DATA),
+        2: i32(data.length),
+        3: i32(data.length),
+        5: struct({
+          1: i32(values.length),
+          2: i32(ENCODING_PLAIN),
+          3: i32(ENCODING_RLE),
+          4: i32(ENCODING_RLE),
+        }),
+      })
+    )
+    const offset = out.length
+    append(out, header)
+    append(out, data)
+    return { column, offset, size: header.length + data.length, count: values.length }
+  })
+
+  const physicalType = (column: ParquetColumn) => i32(PHYSICAL_TYPES[column.type])
+  const totalSize = chunks.reduce((sum, chunk) => sum + chunk.size, 0)
+  const metadata: number[] = []
+  writeThrift(
+    metadata,
+    struct({
+      1: i32(1),
+      2: list('struct', [
+        struct({ 4: binary('schema'), 5: i32(columns.length) }),
+        ...columns.map(column =>
+          struct({
+            1: physicalType(column),
+            3: i32(column.optional ? REPETITION_OPTIONAL : REPETITION_REQUIRED),
+            4: binary(column.name),
+            6: column.type === 'string' ? i32(CONVERTED_TYPE_UTF8) : undefined,
+          })
+        ),
+      ]),
+      3: i64(rows.length),
+      4: list('struct', [
+        struct({
+          1: list(
+            'struct',
+            chunks.map(chunk =>
+              struct({
+                2: i64(chunk.offset),
+                3: struct({
+                  1: physicalType(chunk.column),
+                  2: list('i32', [i32(ENCODING_PLAIN), i32(ENCODING_RLE)]),
+                  3: list('binary', [binary(chunk.column.name)]),
+                  4: i32(CODEC_UNCOMPRESSED),
+                  5: i64(chunk.count),
+                  6: i64(chunk.size),
+                  7: i64(chunk.size),
+                  9: i64(chunk.offset),
+                }),
+              })
+            )
+          ),
+          2: i64(totalSize),
+          3: i64(rows.length),
+        }),
+      ]),
+      6: binary('pokemon-save-web'),
+    })
+  )
+  append(out, metadata)
+  writeUint32(out, metadata.length)
+  out.push(...MAGIC)
+  return Uint8Array.from(out)
+}
@@ -0,0 +1,89 @@
+/**
+ * Parquet export of Pokemon across many saves
+ * One row per party, box and daycare Pokemon, with the save's fingerprint, path, game and trainer
+ * on every row, so a whole collection loads into DuckDB or pandas as a single table
+ */
+
+import { createParquet, type ParquetColumn, type ParquetRow } from './parquet'
+import { allPokemon } from './query'
+import { toPokemonJson } from './saveJson'
+import type { GameConfig, SaveData } from './types'
+
+export interface ParquetExportSource {
+  readonly saveData: SaveData
+  readonly config: GameConfig
+  /** Content fingerprint (fingerprintSave) identifying the save */
+  readonly fingerprint: string
+  readonly path: string
+}
+
+// Same stat order and column names as the SQLite export's pokemon table
+const STAT_COLUMNS = ['hp', 'atk', 'def', 'spe', 'spa', 'spd'] as const
+
+export const PARQUET_POKEMON_COLUMNS: readonly ParquetColumn[] = [
+  { name: 'save', type: 'string' },
+  { name: 'path', type: 'string' },
+  { name: 'game', type: 'string' },
+  { name: 'player_name', type: 'string' },
+  { name: 'area', type: 'string' },
+  { name: 'box', type: 'int64', optional: true },
+  { name: 'slot', type: 'int64' },
+  { name: 'species_id', type: 'int64' },
+  { name: 'species_name', type: 'string', optional: true },
+  { name: 'nickname', type: 'string' },
+  { name: 'level', type: 'int64' },
+  { name: 'nature', type: 'string' },
+  { name: 'is_shiny', type: 'boolean' },
+  { name: 'is_egg', type: 'boolean' },
+  { name: 'personality', type: 'int64' },
+  { name: 'ot_name', type: 'string' },
+  { name: 'ot_id', type: 'int64' },
+  { name: 'item_name', type: 'string', optional: true },
+  ...STAT_COLUMNS.map(stat => ({ name: `iv_${stat}`, type: 'int64' as const })),
+  ...STAT_COLUMNS.map(stat => ({ name: `ev_${stat}`, type: 'int64' as const })),
+  ...[1, 2, 3, 4].map(n => ({ name: `move${n}`, type: 'string' as const, optional: true })),
+]
+
+/**
+ * Rows for every Pokemon in one save, in party, box, daycare order
+ */
+export function saveToParquetRows(source: ParquetExportSource): ParquetRow[] {
+  const { saveData, config } = source
+  return [...allPokemon(saveData)].map(({ pokemon, location }) => {
+    const json = toPokemonJson(pokemon, config)
+    const [move1 = null, move2 = null, move3 = null, move4 = null] = json.move_names
+    return {
+      save: source.fingerprint,
+      path: source.path,
+      game: config.name,
+      player_name: saveData.player_name,
+      area: location.area,
+      box: location.area === 'box' ? location.box + 1 : null,
+      slot: location.slot + 1,
+      species_id: json.species_id,
+      species_name: json.species_name,
+      nickname: json.nickname,
+      level: json.level,
+      nature: json.nature,
+      is_shiny: json.is_shiny,
+      is_egg: json.is_egg,
+      personality: pokemon.personality,
+      ot_name: json.ot_name,
+      ot_id: pokemon.otId,
+      item_name: json.item_name,
+      ...Object.fromEntries(STAT_COLUMNS.map((stat, i) => [`iv_${stat}`, json.ivs[i]!])),
+      ...Object.fromEntries(STAT_COLUMNS.map((stat, i) => [`ev_${stat}`, json.evs[i]!])),
+      move1,
+      move2,
+      move3,
+      move4,
+    }
+  })
+}
+
+/**
+ * A Parquet file of every Pokemon in the given saves
+ */
+export function savesToParquet(sources: readonly ParquetExportSource[]): Uint8Array {
+  return createParquet(PARQUET_POKEMON_COLUMNS, sources.flatMap(saveToParquetRows))
+}
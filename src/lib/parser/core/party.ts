@@ -0,0 +1,204 @@
+/**
+ * Party and PC box editing
+ * Helpers return updated SaveData; write it back with
+ * reconstructSaveFile(saveData.party_pokemon, saveData.boxes)
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import type { PokemonLocation } from './query'
+import type { SaveData } from './types'
+
+/** Where a Pokemon can be injected: a party slot (the next free one appends) or a box slot */
+export type InjectTarget = Exclude<PokemonLocation, { area: 'daycare' }>
+
+function assertPartyIndex(saveData: SaveData, index: number): void {
+  const size = saveData.party_pokemon.length
+  if (!Number.isInteger(index) || index < 0 || index >= size) {
+    throw new Error(`Party index ${index} is out of range for a party of ${size}`)
+  }
+}
+
+/**
+ * Swap two party members by 0-based index
+ */
+export function swapPartySlots(saveData: SaveData, a: number, b: number): SaveData {
+  assertPartyIndex(saveData, a)
+  assertPartyIndex(saveData, b)
+
+  const party = [...saveData.party_pokemon]
+  ;[party[a], party[b]] = [party[b]!, party[a]!]
+  return { ...saveData, party_pokemon: party }
+}
+
+/**
+ * Remove a party member by 0-based index; the party can't be emptied
+ * On write-back the remaining members move up and the party count shrinks
+ */
+export function removeFromParty(saveData: SaveData, index: number): SaveData {
+  assertPartyIndex(saveData, index)
+  if (saveData.party_pokemon.length === 1) {
+    throw new Error('Cannot remove the last Pokemon in the party')
+  }
+
+  return { ...saveData, party_pokemon: saveData.party_pokemon.filter((_, i) => i !== index) }
+}
+
+/**
+ * Heal every party member like a Pokemon Center (HP, status and PP); the Pokemon are healed in place
+ */
+export function healParty(saveData: SaveData): SaveData {
+  for (const pokemon of saveData.party_pokemon) pokemon.heal()
+  return { ...saveData, party_pokemon: [...saveData.party_pokemon] }
+}
+
+function getBoxes(saveData: SaveData) {
+  if (!saveData.boxes) {
+    throw new Error('PC boxes are not available for this save')
+  }
+  return saveData.boxes
+}
+
+function assertBoxSlot(saveData: SaveData, box: number, slot: number): void {
+  const boxes = getBoxes(saveData)
+  if (!Number.isInteger(box) || box < 0 || box >= boxes.length) {
+    throw new Error(`Box ${box + 1} does not exist`)
+  }
+  const slotCount = boxes[box]!.length
+  if (!Number.isInteger(slot) || slot < 0 || slot >= slotCount) {
+    throw new Error(`Box slot ${slot + 1} is out of range for a box of ${slotCount}`)
+  }
+}
+
+/**
+ * Deposit a party member into a PC box (0-based box and slot; first free slot when omitted)
+ * The Pokemon is converted to the box format, so it is healed and loses party-only data
+ */
+export function depositToBox(
+  saveData: SaveData,
+  partyIndex: number,
+  box: number,
+  slot?: number
+): SaveData {
+  assertPartyIndex(saveData, partyIndex)
+  if (saveData.party_pokemon.length === 1) {
+    throw new Error('Cannot deposit the last Pokemon in the party')
+  }
+  const pokemon = saveData.party_pokemon[partyIndex]!
+  if (pokemon.mailId !== undefined) {
+    throw new Error('Take the mail from the Pokemon before depositing it')
+  }
+
+  const boxes = getBoxes(saveData)
+  const target = slot ?? boxes[box]?.indexOf(null) ?? 0
+  if (target === -1) {
+    throw new Error(`Box ${box + 1} is full`)
+  }
+  assertBoxSlot(saveData, box, target)
+  if (boxes[box]![target]) {
+    throw new Error(`Box ${box + 1} slot ${target + 1} is not empty`)
+  }
+
+  const updatedBox = [...boxes[box]!]
+  updatedBox[target] = pokemon.toBoxPokemon()
+  return {
+    ...saveData,
+    party_pokemon: saveData.party_pokemon.filter((_, i) => i !== partyIndex),
+    boxes: boxes.map((slots, i) => (i === box ? updatedBox : slots)),
+  }
+}
+
+/**
+ * Withdraw a Pokemon from a PC box (0-based) to the end of the party
+ * Battle stats are recomputed from the box data; fails when the party is already full
+ */
+export function withdrawFromBox(
+  saveData: SaveData,
+  box: number,
+  slot: number,
+  maxPartySize: number
+): SaveData {
+  assertBoxSlot(saveData, box, slot)
+  const boxes = getBoxes(saveData)
+  const pokemon = boxes[box]![slot]
+  if (!pokemon) {
+    throw new Error(`Box ${box + 1} slot ${slot + 1} is empty`)
+  }
+  if (saveData.party_pokemon.length >= maxPartySize) {
+    throw new Error(`Party is full (${maxPartySize} Pokemon)`)
+  }
+
+  const updatedBox = [...boxes[box]!]
+  updatedBox[slot] = null
+  return {
+    ...saveData,
+    party_pokemon: [...saveData.party_pokemon, pokemon.toBoxPokemon()],
+    boxes: boxes.map((slots, i) => (i === box ? updatedBox : slots)),
+  }
+}
+
+/**
+ * Release the Pokemon in a party or box slot (0-based) like the PC's Release option
+ * A party keeps its order with the gap closed and a smaller count; a box slot is left empty and
+ * zeroed on write-back. Like the game, a Pokemon holding mail or the last party member that can
+ * still battle (not an egg, HP above 0) can't be released
+ */
+export function releasePokemon(saveData: SaveData, location: InjectTarget): SaveData {
+  if (location.area === 'box') {
+    assertBoxSlot(saveData, location.box, location.slot)
+    const boxes = getBoxes(saveData)
+    const pokemon = boxes[location.box]![location.slot]
+    if (!pokemon) {
+      throw new Error(`Box ${location.box + 1} slot ${location.slot + 1} is empty`)
+    }
+    const updatedBox = [...boxes[location.box]!]
+    updatedBox[location.slot] = null
+    return {
+      ...saveData,
+      boxes: boxes.map((slots, i) => (i === location.box ? updatedBox : slots)),
+    }
+  }
+
+  assertPartyIndex(saveData, location.slot)
+  const party = saveData.party_pokemon
+  if (party[location.slot]!.mailId !== undefined) {
+    throw new Error('Take the mail from the Pokemon before releasing it')
+  }
+  const canBattle = party.some(
+    (pokemon, i) => i !== location.slot && !pokemon.isEgg && pokemon.currentHp > 0
+  )
+  if (!canBattle) {
+    throw new Error('Cannot release the last Pokemon in the party that can battle')
+  }
+  return { ...saveData, party_pokemon: party.filter((_, i) => i !== location.slot) }
+}
+
+/**
+ * Put a Pokemon into a party or box slot, replacing whatever is there
+ * A party slot equal to the party size appends; box Pokemon are stored in the box format
+ */
+export function injectPokemon(
+  saveData: SaveData,
+  pokemon: PokemonBase,
+  target: InjectTarget,
+  maxPartySize: number
+): SaveData {
+  if (target.area === 'box') {
+    assertBoxSlot(saveData, target.box, target.slot)
+    const boxes = getBoxes(saveData)
+    const updatedBox = [...boxes[target.box]!]
+    updatedBox[target.slot] = pokemon.toBoxPokemon()
+    return { ...saveData, boxes: boxes.map((slots, i) => (i === target.box ? updatedBox : slots)) }
+  }
+
+  const party = [...saveData.party_pokemon]
+  if (target.slot === party.length) {
+    if (party.length >= maxPartySize) {
+      throw new Error(`Party is full (${maxPartySize} Pokemon)`)
+    }
+    party.push(pokemon)
+  } else {
+    assertPartyIndex(saveData, target.slot)
+    party[target.slot] = pokemon
+  }
+  return { ...saveData, party_pokemon: party }
+}
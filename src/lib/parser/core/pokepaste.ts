@@ -0,0 +1,80 @@
+/**
+ * Pokepaste export and upload (the CLI's --format=pokepaste)
+ * pokepast.es renders Showdown sets with sprites and type colors; a paste is the Showdown text of
+ * the team plus a title, author and notes, and is shared by URL instead of as a local file
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import { formatShowdownSet } from './showdown'
+import type { GameConfig, SaveData } from './types'
+
+export const POKEPASTE_URL = 'https://pokepast.es'
+
+export interface Pokepaste {
+  readonly title: string
+  readonly author: string
+  readonly notes: string
+  /** Showdown sets of the party, eggs left out */
+  readonly paste: string
+}
+
+export type PokepasteOptions = Partial<Omit<Pokepaste, 'paste'>>
+
+const capitalize = (text: string) => text.charAt(0).toUpperCase() + text.slice(1)
+
+// Showdown writes Hidden Power with its type, which pokepast.es uses for the move's color
+function pokepasteSet(pokemon: PokemonBase, config: GameConfig): string {
+  const type = capitalize(pokemon.hiddenPower.type)
+  return formatShowdownSet(pokemon, config).replace(
+    /^- Hidden Power$/m,
+    `- Hidden Power [${type}]`
+  )
+}
+
+/**
+ * The party as a pokepast.es paste; title, author and notes default to the trainer, game and
+ * play time
+ */
+export function createPokepaste(
+  saveData: SaveData,
+  config: GameConfig,
+  options: PokepasteOptions = {}
+): Pokepaste {
+  const { player_name: player, play_time: playTime } = saveData
+  const clock = `${playTime.hours}:${String(playTime.minutes).padStart(2, '0')}`
+  return {
+    title: options.title ?? `${player}'s ${config.name} team`,
+    author: options.author ?? player,
+    notes: options.notes ?? `Exported from a ${config.name} save at ${clock} play time`,
+    paste: saveData.party_pokemon
+      .filter(pokemon => !pokemon.isEgg)
+      .map(pokemon => pokepasteSet(pokemon, config))
+      .join('\n\n'),
+  }
+}
+
+/**
+ * Upload a paste to pokepast.es and return its share URL
+ * The site answers the form post with a redirect to the new paste, so the URL is where the
+ * request ends up
+ */
+export async function uploadPokepaste(
+  pokepaste: Pokepaste,
+  fetchImpl: typeof fetch = fetch
+): Promise<string> {
+  const createUrl = `${POKEPASTE_URL}/create`
+  const response = await fetchImpl(createUrl, {
+    method: 'POST',
+    body: new URLSearchParams({
+      title: pokepaste.title,
+      author: pokepaste.author,
+      notes: pokepaste.notes,
+      paste: pokepaste.paste,
+    }),
+  })
+  if (!response.ok) throw new Error(`pokepast.es upload failed (HTTP ${response.status})`)
+  if (!response.url || response.url === createUrl) {
+    throw new Error('pokepast.es did not return a paste URL')
+  }
+  return response.url
+}
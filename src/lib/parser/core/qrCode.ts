@@ -0,0 +1,553 @@
+/**
+ * QR codes for moving single Pokemon between devices
+ * A byte-mode QR encoder (versions 1-40, all error correction levels) and a decoder for clean
+ * module grids, plus the payload format: "PK3:" followed by the base64 of a .pk3.
+ * Cameras and image scanning are left to the platform (e.g. the browser's BarcodeDetector), which
+ * hands back the payload text
+ * See: ISO/IEC 18004 and https://www.nayuki.io/page/qr-code-generator-library
+ */
+
+import type { PokemonBase } from './PokemonBase'
+
+export type QrErrorCorrection = 'L' | 'M' | 'Q' | 'H'
+
+export interface QrCode {
+  readonly version: number
+  readonly errorCorrection: QrErrorCorrection
+  /** Side length in modules (17 + 4 * version) */
+  readonly size: number
+  /** modules[y][x], true for dark */
+  readonly modules: readonly (readonly boolean[])[]
+}
+
+// The level's 2-bit code in the format information (not in L, M, Q, H order)
+const FORMAT_BITS: Record<QrErrorCorrection, number> = { L: 1, M: 0, Q: 3, H: 2 }
+
+// Per version (index 0 unused): error correction codewords per block and number of blocks
+const ECC_CODEWORDS_PER_BLOCK: Record<QrErrorCorrection, readonly number[]> = {
+  L: [
+    0, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30,
+    30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+  ],
+  M: [
+    0, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28,
+    28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28,
+  ],
+  Q: [
+    0, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30,
+    30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+  ],
+  H: [
+    0, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30,
+    30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30,
+  ],
+}
+const ERROR_CORRECTION_BLOCKS: Record<QrErrorCorrection, readonly number[]> = {
+  L: [
+    0, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14,
+    15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25,
+  ],
+  M: [
+    0, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23,
+    25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49,
+  ],
+  Q: [
+    0, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29,
+    34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68,
+  ],
+  H: [
+    0, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35,
+    37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81,
+  ],
+}
+
+const MIN_VERSION = 1
+const MAX_VERSION = 40
+const BYTE_MODE = 0b0100
+
+/* Reed-Solomon arithmetic over GF(2^8) with the QR polynomial x^8 + x^4 + x^3 + x^2 + 1 */
+
+function gfMultiply(x: number, y: number): number {
+  let z = 0
+  for (let i = 7; i >= 0; i--) {
+    z = (z << 1) ^ ((z >>> 7) * 0x11d)
+    z ^= ((y >>> i) & 1) * x
+  }
+  return z
+}
+
+function reedSolomonDivisor(degree: number): number[] {
+  // Coefficients of (x - a^0)(x - a^1)...(x - a^(degree-1)), highest power first, leading 1 dropped
+  const result = new Array<number>(degree).fill(0)
+  result[degree - 1] = 1
+  let root = 1
+  for (let i = 0; i < degree; i++) {
+    for (let j = 0; j < degree; j++) {
+      result[j] = gfMultiply(result[j]!, root)
+      if (j + 1 < degree) result[j] = result[j]! ^ result[j + 1]!
+    }
+    root = gfMultiply(root, 0x02)
+  }
+  return result
+}
+
+function reedSolomonRemainder(data: readonly number[], divisor: readonly number[]): number[] {
+  const result = new Array<number>(divisor.length).fill(0)
+  for (const byte of data) {
+    const factor = byte ^ result.shift()!
+    result.push(0)
+    for (let i = 0; i < divisor.length; i++) {
+      result[i] = result[i]! ^ gfMultiply(divisor[i]!, factor)
+    }
+  }
+  return result
+}
+
+// A block is intact when the codeword polynomial vanishes at every generator root
+function hasValidSyndromes(block: readonly number[], eccLength: number): boolean {
+  let root = 1
+  for (let i = 0; i < eccLength; i++) {
+    if (block.reduce((sum, byte) => gfMultiply(sum, root) ^ byte, 0) !== 0) return false
+    root = gfMultiply(root, 0x02)
+  }
+  return true
+}
+
+/* Layout shared by the encoder and decoder */
+
+const getBit = (value: number, bit: number) => ((value >>> bit) & 1) !== 0
+
+function rawDataModules(version: number): number {
+  let result = (16 * version + 128) * version + 64
+  if (version >= 2) {
+    const alignments = Math.floor(version / 7) + 2
+    result -= (25 * alignments - 10) * alignments - 55
+    if (version >= 7) result -= 36
+  }
+  return result
+}
+
+function dataCodewords(version: number, level: QrErrorCorrection): number {
+  return (
+    Math.floor(rawDataModules(version) / 8) -
+    ECC_CODEWORDS_PER_BLOCK[level][version]! * ERROR_CORRECTION_BLOCKS[level][version]!
+  )
+}
+
+function alignmentPositions(version: number): number[] {
+  if (version === 1) return []
+  const count = Math.floor(version / 7) + 2
+  const step = version === 32 ? 26 : Math.ceil((version * 4 + 4) / (count * 2 - 2)) * 2
+  const result = [6]
+  for (let pos = version * 4 + 10; result.length < count; pos -= step) result.splice(1, 0, pos)
+  return result
+}
+
+function formatBits(level: QrErrorCorrection, mask: number): number {
+  const data = (FORMAT_BITS[level] << 3) | mask
+  let remainder = data
+  for (let i = 0; i < 10; i++) remainder = (remainder << 1) ^ ((remainder >>> 9) * 0x537)
+  return ((data << 10) | remainder) ^ 0x5412
+}
+
+// The two copies of the 15 format bits as [x, y] module positions, bit 0 first
+function formatPositions(size: number): [number, number][][] {
+  const first: [number, number][] = []
+  const second: [number, number][] = []
+  for (let i = 0; i < 15; i++) {
+    first.push(i < 6 ? [8, i] : i < 8 ? [8, i + 1] : i === 8 ? [7, 8] : [14 - i, 8])
+    second.push(i < 8 ? [size - 1 - i, 8] : [8, size - 15 + i])
+  }
+  return [first, second]
+}
+
+class QrGrid {
+  readonly modules: boolean[][]
+  readonly isFunction: boolean[][]
+
+  constructor(
+    readonly version: number,
+    readonly size = version * 4 + 17
+  ) {
+    this.modules = Array.from({ length: size }, () => new Array<boolean>(size).fill(false))
+    this.isFunction = Array.from({ length: size }, () => new Array<boolean>(size).fill(false))
+    this.drawFunctionPatterns()
+  }
+
+  setFunction(x: number, y: number, dark: boolean) {
+    this.modules[y]![x] = dark
+    this.isFunction[y]![x] = true
+  }
+
+  private drawFunctionPatterns() {
+    const { size, version } = this
+    for (let i = 0; i < size; i++) {
+      this.setFunction(6, i, i % 2 === 0)
+      this.setFunction(i, 6, i % 2 === 0)
+    }
+    for (const [x, y] of [
+      [3, 3],
+      [size - 4, 3],
+      [3, size - 4],
+    ] as const) {
+      // Finder pattern with its light separator
+      for (let dy = -4; dy <= 4; dy++) {
+        for (let dx = -4; dx <= 4; dx++) {
+          const distance = Math.max(Math.abs(dx), Math.abs(dy))
+          const [xx, yy] = [x + dx, y + dy]
+          if (xx >= 0 && xx < size && yy >= 0 && yy < size) {
+            this.setFunction(xx, yy, distance !== 2 && distance !== 4)
+          }
+        }
+      }
+    }
+
+    const positions = alignmentPositions(version)
+    const last = positions.length - 1
+    positions.forEach((x, i) =>
+      positions.forEach((y, j) => {
+        // The corners under the finder patterns have no alignment pattern
+        if ((i === 0 && j === 0) || (i === 0 && j === last) || (i === last && j === 0)) return
+        for (let dy = -2; dy <= 2; dy++) {
+          for (let dx = -2; dx <= 2; dx++) {
+            this.setFunction(x + dx, y + dy, Math.max(Math.abs(dx), Math.abs(dy)) !== 1)
+          }
+        }
+      })
+    )
+
+    // Reserve the format areas (filled in once the mask is chosen) and the always-dark module
+    for (const copy of formatPositions(size)) {
+      for (const [x, y] of copy) this.setFunction(x, y, false)
+    }
+    this.setFunction(8, size - 8, true)
+
+    if (version >= 7) {
+      let remainder = version
+      for (let i = 0; i < 12; i++) remainder = (remainder << 1) ^ ((remainder >>> 11) * 0x1f25)
+      const bits = (version << 12) | remainder
+      for (let i = 0; i < 18; i++) {
+        const [a, b] = [size - 11 + (i % 3), Math.floor(i / 3)]
+        this.setFunction(a, b, getBit(bits, i))
+        this.setFunction(b, a, getBit(bits, i))
+      }
+    }
+  }
+
+  drawFormatBits(level: QrErrorCorrection, mask: number) {
+    const bits = formatBits(level, mask)
+    for (const copy of formatPositions(this.size)) {
+      copy.forEach(([x, y], i) => this.setFunction(x, y, getBit(bits, i)))
+    }
+  }
+
+  /** Data module positions in placement order: two-column zigzag from the bottom right */
+  *dataPositions(): Generator<[number, number]> {
+    const { size } = this
+    for (let right = size - 1; right >= 1; right -= 2) {
+      if (right === 6) right = 5
+      const upward = ((right + 1) & 2) === 0
+      for (let vert = 0; vert < size; vert++) {
+        const y = upward ? size - 1 - vert : vert
+        for (const x of [right, right - 1]) {
+          if (!this.isFunction[y]![x]) yield [x, y]
+        }
+      }
+    }
+  }
+
+  applyMask(mask: number) {
+    for (let y = 0; y < this.size; y++) {
+      for (let x = 0; x < this.size; x++) {
+        if (!this.isFunction[y]![x] && maskInverts(mask, x, y)) {
+          this.modules[y]![x] = !this.modules[y]![x]
+        }
+      }
+    }
+  }
+}
+
+function maskInverts(mask: number, x: number, y: number): boolean {
+  switch (mask) {
+    case 0:
+      return (x + y) % 2 === 0
+    case 1:
+      return y % 2 === 0
+    case 2:
+      return x % 3 === 0
+    case 3:
+      return (x + y) % 3 === 0
+    case 4:
+      return (Math.floor(x / 3) + Math.floor(y / 2)) % 2 === 0
+    case 5:
+      return ((x * y) % 2) + ((x * y) % 3) === 0
+    case 6:
+      return (((x * y) % 2) + ((x * y) % 3)) % 2 === 0
+    default:
+      return (((x + y) % 2) + ((x * y) % 3)) % 2 === 0
+  }
+}
+
+// Scanner-friendliness score of a masked symbol (lower is better), per the spec's four rules
+function maskPenalty(modules: readonly (readonly boolean[])[]): number {
+  const size = modules.length
+  let penalty = 0
+  const toLine = (line: readonly boolean[]) => line.map(dark => (dark ? '1' : '0')).join('')
+  const lines = [
+    ...modules.map(toLine),
+    ...modules.map((_, x) => toLine(modules.map(row => row[x]!))),
+  ]
+  for (const line of lines) {
+    // Runs of five or more same-colored modules
+    for (const run of line.match(/0{5,}|1{5,}/g) ?? []) penalty += run.length - 2
+    // Patterns that look like a finder (1:1:3:1:1 with four light modules on one side)
+    penalty += (line.match(/(?=10111010000|00001011101)/g) ?? []).length * 40
+  }
+  for (let y = 0; y < size - 1; y++) {
+    for (let x = 0; x < size - 1; x++) {
+      const color = modules[y]![x]
+      if (
+        color === modules[y]![x + 1] &&
+        color === modules[y + 1]![x] &&
+        color === modules[y + 1]![x + 1]
+      ) {
+        penalty += 3
+      }
+    }
+  }
+  const dark = modules.reduce((sum, row) => sum + row.filter(Boolean).length, 0)
+  penalty += Math.floor(Math.abs((dark * 20) / (size * size) - 10)) * 10
+  return penalty
+}
+
+/**
+ * Encode bytes as a byte-mode QR code, using the smallest version that fits
+ */
+export function encodeQrCode(data: Uint8Array, errorCorrection: QrErrorCorrection = 'M'): QrCode {
+  let version = MIN_VERSION
+  const bitsFor = (v: number) => 4 + (v < 10 ? 8 : 16) + data.length * 8
+  while (bitsFor(version) > dataCodewords(version, errorCorrection) * 8) {
+    if (++version > MAX_VERSION) throw new Error(`${data.length} bytes don't fit in a QR code`)
+  }
+
+  // Mode, length and data, then a terminator and padding up to the version's capacity
+  const capacity = dataCodewords(version, errorCorrection)
+  const bits: number[] = []
+  const push = (value: number, length: number) => {
+    for (let i = length - 1; i >= 0; i--) bits.push((value >>> i) & 1)
+  }
+  push(BYTE_MODE, 4)
+  push(data.length, version < 10 ? 8 : 16)
+  for (const byte of data) push(byte, 8)
+  push(0, Math.min(4, capacity * 8 - bits.length))
+  push(0, (8 - (bits.length % 8)) % 8)
+  const codewords: number[] = []
+  for (let i = 0; i < bits.length; i += 8) {
+    codewords.push(bits.slice(i, i + 8).reduce((byte, bit) => (byte << 1) | bit, 0))
+  }
+  for (let pad = 0xec; codewords.length < capacity; pad ^= 0xec ^ 0x11) codewords.push(pad)
+
+  // Split into blocks, append each block's error correction and interleave
+  const blockCount = ERROR_CORRECTION_BLOCKS[errorCorrection][version]!
+  const eccLength = ECC_CODEWORDS_PER_BLOCK[errorCorrection][version]!
+  const rawCodewords = Math.floor(rawDataModules(version) / 8)
+  const shortBlocks = blockCount - (rawCodewords % blockCount)
+  const shortLength = Math.floor(rawCodewords / blockCount)
+  const divisor = reedSolomonDivisor(eccLength)
+  const blocks: number[][] = []
+  for (let i = 0, offset = 0; i < blockCount; i++) {
+    const length = shortLength - eccLength + (i < shortBlocks ? 0 : 1)
+    const block = codewords.slice(offset, offset + length)
+    offset += length
+    const ecc = reedSolomonRemainder(block, divisor)
+    // Short blocks get a placeholder so all blocks line up; it is skipped when interleaving
+    if (i < shortBlocks) block.push(0)
+    blocks.push([...block, ...ecc])
+  }
+  const interleaved: number[] = []
+  for (let i = 0; i <= shortLength; i++) {
+    blocks.forEach((block, j) => {
+      if (i !== shortLength - eccLength || j >= shortBlocks) interleaved.push(block[i]!)
+    })
+  }
+
+  const grid = new QrGrid(version)
+  let bit = 0
+  for (const [x, y] of grid.dataPositions()) {
+    // Remainder bits past the last codeword stay light
+    const byte = interleaved[bit >>> 3]
+    grid.modules[y]![x] = byte !== undefined && getBit(byte, 7 - (bit & 7))
+    bit++
+  }
+
+  let best = { mask: 0, penalty: Infinity }
+  for (let mask = 0; mask < 8; mask++) {
+    grid.applyMask(mask)
+    grid.drawFormatBits(errorCorrection, mask)
+    const penalty = maskPenalty(grid.modules)
+    if (penalty < best.penalty) best = { mask, penalty }
+    grid.applyMask(mask)
+  }
+  grid.applyMask(best.mask)
+  grid.drawFormatBits(errorCorrection, best.mask)
+
+  return { version, errorCorrection, size: grid.size, modules: grid.modules }
+}
+
+/**
+ * Decode the bytes of a byte-mode QR code from its module grid (modules[y][x], true for dark),
+ * e.g. one sampled from a camera image. Throws when the error correction detects damage
+ */
+export function decodeQrCode(modules: readonly (readonly boolean[])[]): Uint8Array {
+  const size = modules.length
+  const version = (size - 17) / 4
+  if (!Number.isInteger(version) || version < MIN_VERSION || version > MAX_VERSION) {
+    throw new Error(`A QR code is 21 to 177 modules wide, got ${size}`)
+  }
+  if (modules.some(row => row.length !== size)) throw new Error('QR module grid is not square')
+
+  // Both format copies are tried against every level and mask, allowing up to 3 flipped bits
+  const grid = new QrGrid(version)
+  let format: { level: QrErrorCorrection; mask: number; distance: number } | undefined
+  for (const copy of formatPositions(size)) {
+    const read = copy.reduce((bits, [x, y], i) => bits | (Number(modules[y]![x]) << i), 0)
+    for (const level of ['L', 'M', 'Q', 'H'] as const) {
+      for (let mask = 0; mask < 8; mask++) {
+        let diff = read ^ formatBits(level, mask)
+        let distance = 0
+        for (; diff; diff &= diff - 1) distance++
+        if (distance <= 3 && (!format || distance < format.distance)) {
+          format = { level, mask, distance }
+        }
+      }
+    }
+  }
+  if (!format) throw new Error('QR format information is unreadable')
+  const { level, mask } = format
+
+  for (let y = 0; y < size; y++) {
+    for (let x = 0; x < size; x++) grid.modules[y]![x] = modules[y]![x]!
+  }
+  grid.applyMask(mask)
+  const rawCodewords = Math.floor(rawDataModules(version) / 8)
+  const interleaved = new Array<number>(rawCodewords).fill(0)
+  let bit = 0
+  for (const [x, y] of grid.dataPositions()) {
+    if (bit >= rawCodewords * 8) break
+    if (grid.modules[y]![x]) interleaved[bit >>> 3] = interleaved[bit >>> 3]! | (0x80 >>> (bit & 7))
+    bit++
+  }
+
+  // Undo the interleaving, checking each block before taking its data codewords
+  const blockCount = ERROR_CORRECTION_BLOCKS[level][version]!
+  const eccLength = ECC_CODEWORDS_PER_BLOCK[level][version]!
+  const shortBlocks = blockCount - (rawCodewords % blockCount)
+  const shortLength = Math.floor(rawCodewords / blockCount)
+  const blocks = Array.from({ length: blockCount }, () => new Array<number>(shortLength + 1))
+  let next = 0
+  for (let i = 0; i <= shortLength; i++) {
+    blocks.forEach((block, j) => {
+      if (i !== shortLength - eccLength || j >= shortBlocks) block[i] = interleaved[next++]!
+    })
+  }
+  const codewords: number[] = []
+  blocks.forEach((block, j) => {
+    if (j < shortBlocks) block.splice(shortLength - eccLength, 1)
+    if (!hasValidSyndromes(block, eccLength)) throw new Error('QR code data is damaged')
+    codewords.push(...block.slice(0, block.length - eccLength))
+  })
+
+  let position = 0
+  const read = (length: number) => {
+    let value = 0
+    for (let i = 0; i < length; i++, position++) {
+      value = (value << 1) | Number(getBit(codewords[position >>> 3] ?? 0, 7 - (position & 7)))
+    }
+    return value
+  }
+  const bytes: number[] = []
+  while (position + 4 <= codewords.length * 8) {
+    const mode = read(4)
+    if (mode === 0) break
+    if (mode !== BYTE_MODE) throw new Error(`Unsupported QR segment mode ${mode}`)
+    const length = read(version < 10 ? 8 : 16)
+    for (let i = 0; i < length; i++) bytes.push(read(8))
+  }
+  return new Uint8Array(bytes)
+}
+
+/**
+ * Render a QR code as an SVG image with a light quiet zone (4 modules, as the spec asks)
+ */
+export function qrCodeToSvg(qr: QrCode, margin = 4): string {
+  const dimension = qr.size + margin * 2
+  const path = qr.modules
+    .flatMap((row, y) =>
+      row.map((dark, x) => (dark ? `M${x + margin} ${y + margin}h1v1h-1z` : ''))
+    )
+    .join('')
+  return `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 ${dimension} ${dimension}" shape-rendering="crispEdges"><rect width="100%" height="100%" fill="#fff"/><path d="${path}" fill="#000"/></svg>`
+}
+
+/**
+ * Render a QR code for a terminal, two module rows per line using half blocks. Light modules are
+ * drawn as blocks, so it scans on dark terminal backgrounds
+ */
+export function qrCodeToText(qr: QrCode, margin = 2): string {
+  const dimension = qr.size + margin * 2
+  const light = (x: number, y: number) => !qr.modules[y - margin]?.[x - margin]
+  const lines: string[] = []
+  for (let y = 0; y < dimension; y += 2) {
+    let line = ''
+    for (let x = 0; x < dimension; x++) {
+      const top = light(x, y)
+      const bottom = y + 1 < dimension && light(x, y + 1)
+      line += top && bottom ? '█' : top ? '▀' : bottom ? '▄' : ' '
+    }
+    lines.push(line)
+  }
+  return lines.join('\n')
+}
+
+/* Pokemon payloads */
+
+export const POKEMON_QR_PREFIX = 'PK3:'
+
+/**
+ * The QR payload for a Pokemon: "PK3:" and the base64 of its .pk3. The 80-byte box format keeps
+ * the code small (version 7 at level M); the receiver recomputes the battle stats on import
+ */
+export function toPokemonQrPayload(
+  pokemon: PokemonBase,
+  format: 'party' | 'box' = 'box'
+): string {
+  return POKEMON_QR_PREFIX + btoa(String.fromCharCode(...pokemon.exportPK3(format)))
+}
+
+/**
+ * Read the .pk3 bytes from a scanned payload, for importPK3
+ */
+export function parsePokemonQrPayload(payload: string): Uint8Array {
+  const text = payload.trim()
+  if (!text.startsWith(POKEMON_QR_PREFIX)) {
+    throw new Error(`Not a Pokemon QR code (expected a "${POKEMON_QR_PREFIX}" payload)`)
+  }
+  let binary: string
+  try {
+    binary = atob(text.slice(POKEMON_QR_PREFIX.length))
+  } catch {
+    throw new Error('Pokemon QR payload is not valid base64')
+  }
+  if (binary.length !== 80 && binary.length !== 100) {
+    throw new Error(`Pokemon QR payload holds ${binary.length} bytes, expected 80 or 100`)
+  }
+  return Uint8Array.from(binary, char => char.charCodeAt(0))
+}
+
+export function encodePokemonQr(pokemon: PokemonBase): QrCode {
+  return encodeQrCode(new TextEncoder().encode(toPokemonQrPayload(pokemon)))
+}
+
+export function decodePokemonQr(modules: readonly (readonly boolean[])[]): Uint8Array {
+  return parsePokemonQrPayload(new TextDecoder().decode(decodeQrCode(modules)))
+}
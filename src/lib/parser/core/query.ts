@@ -0,0 +1,117 @@
+/**
+ * Pokemon search across a parsed save
+ * Filters are combined with AND; omitted filters match everything
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import { findMappingByName, normalizeMappingName } from './mappingIndex'
+import type { GameConfig, SaveData } from './types'
+
+export interface PokemonQuery {
+  /** National Dex number or species name ("Treecko", "mr-mime") */
+  readonly species?: number | string
+  readonly minLevel?: number
+  readonly maxLevel?: number
+  readonly shiny?: boolean
+  /** Original trainer name (case-insensitive) or 5-digit public trainer ID */
+  readonly ot?: string
+  /** Move ID or move name the Pokemon must know */
+  readonly move?: number | string
+  /** Every IV must be at least this value */
+  readonly minIv?: number
+}
+
+// Slots and boxes are 0-based
+export type PokemonLocation =
+  | { readonly area: 'party'; readonly slot: number }
+  | { readonly area: 'box'; readonly box: number; readonly slot: number }
+  | { readonly area: 'daycare'; readonly slot: number }
+
+export interface PokemonMatch {
+  readonly pokemon: PokemonBase
+  readonly location: PokemonLocation
+}
+
+/**
+ * Walk every Pokemon in the save (party, then PC boxes, then daycare) with where it is stored
+ * Boxes and daycare are only visited when the save data includes them
+ */
+export function* allPokemon(saveData: SaveData): Generator<PokemonMatch> {
+  for (const [slot, pokemon] of saveData.party_pokemon.entries()) {
+    yield { pokemon, location: { area: 'party', slot } }
+  }
+  for (const [box, slots] of (saveData.boxes ?? []).entries()) {
+    for (const [slot, pokemon] of slots.entries()) {
+      if (pokemon) yield { pokemon, location: { area: 'box', box, slot } }
+    }
+  }
+  for (const [slot, deposit] of (saveData.daycare?.slots ?? []).entries()) {
+    if (deposit) yield { pokemon: deposit.pokemon, location: { area: 'daycare', slot } }
+  }
+}
+
+/**
+ * Every Pokemon in the save tagged with where it is stored
+ */
+export function listPokemon(saveData: SaveData): PokemonMatch[] {
+  return [...allPokemon(saveData)]
+}
+
+/**
+ * Describe a storage location for display ("Party slot 1", "Box 3 slot 12", "Daycare slot 2")
+ */
+export function formatPokemonLocation(location: PokemonLocation): string {
+  switch (location.area) {
+    case 'party':
+      return `Party slot ${location.slot + 1}`
+    case 'box':
+      return `Box ${location.box + 1} slot ${location.slot + 1}`
+    case 'daycare':
+      return `Daycare slot ${location.slot + 1}`
+  }
+}
+
+function matchesSpecies(pokemon: PokemonBase, species: number | string): boolean {
+  if (typeof species === 'number') return pokemon.speciesId === species
+  return (
+    pokemon.nameId !== undefined &&
+    normalizeMappingName(pokemon.nameId) === normalizeMappingName(species)
+  )
+}
+
+function matchesOt(pokemon: PokemonBase, ot: string): boolean {
+  return /^\d+$/.test(ot)
+    ? Number(ot) === (pokemon.otId & 0xffff)
+    : pokemon.otName.toLowerCase() === ot.toLowerCase()
+}
+
+/**
+ * Find Pokemon matching every given filter
+ * Move names are resolved through the config's move mapping when one is provided
+ */
+export function findPokemon(
+  saveData: SaveData,
+  query: PokemonQuery,
+  config?: GameConfig | null
+): PokemonMatch[] {
+  let moveId: number | undefined
+  if (query.move !== undefined) {
+    moveId =
+      typeof query.move === 'number'
+        ? query.move
+        : (findMappingByName(config?.mappings?.moves, query.move)?.id ?? undefined)
+    // An unknown move name cannot be known by anything
+    if (moveId === undefined) return []
+  }
+
+  return listPokemon(saveData).filter(({ pokemon }) => {
+    if (query.species !== undefined && !matchesSpecies(pokemon, query.species)) return false
+    if (query.minLevel !== undefined && pokemon.level < query.minLevel) return false
+    if (query.maxLevel !== undefined && pokemon.level > query.maxLevel) return false
+    if (query.shiny !== undefined && pokemon.isShiny !== query.shiny) return false
+    if (query.ot !== undefined && !matchesOt(pokemon, query.ot)) return false
+    if (moveId !== undefined && !pokemon.moveIds.includes(moveId)) return false
+    if (query.minIv !== undefined && pokemon.ivs.some(iv => iv < query.minIv!)) return false
+    return true
+  })
+}
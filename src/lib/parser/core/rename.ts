@@ -0,0 +1,111 @@
+/**
+ * Batch nickname and OT name rewriting across the party and PC boxes
+ * planRename previews the renames as a list of before/after names; applyRename applies them in
+ * place. Write the result back with reconstructSaveFile(saveData.party_pokemon, saveData.boxes)
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import { allPokemon, formatPokemonLocation, type PokemonLocation } from './query'
+import { getSpeciesName } from './species'
+import { type SaveData, VANILLA_POKEMON_OFFSETS } from './types'
+import { bytesToGbaString, gbaStringToBytes } from './utils'
+
+/**
+ * Name templates; `{species}` is the game's default (uppercase) species name, `{nickname}` and
+ * `{ot}` the current names and `{level}` the level. Omitted templates keep the names as they are
+ */
+export interface RenameRule {
+  readonly nickname?: string
+  readonly otName?: string
+  /** Only the player's own Pokemon, only traded ones, or all (default) */
+  readonly owner?: 'all' | 'own' | 'traded'
+}
+
+export type RenamePreset = 'species-names' | 'strip-trade-nicknames'
+
+export const RENAME_PRESETS: Readonly<Record<RenamePreset, RenameRule>> = {
+  /** Every nickname back to the species name */
+  'species-names': { nickname: '{species}' },
+  /** Nicknames given by other trainers back to the species name */
+  'strip-trade-nicknames': { nickname: '{species}', owner: 'traded' },
+}
+
+export interface RenameChange {
+  readonly location: Exclude<PokemonLocation, { area: 'daycare' }>
+  readonly field: 'nickname' | 'ot_name'
+  readonly before: string
+  readonly after: string
+}
+
+const TEMPLATE_TOKEN = /\{(species|nickname|ot|level)\}/g
+
+function renderName(template: string, pokemon: PokemonBase, length: number): string {
+  const rendered = template.replace(TEMPLATE_TOKEN, (_, token: string) => {
+    switch (token) {
+      case 'species':
+        return (getSpeciesName(pokemon.speciesId) ?? pokemon.nameId ?? '').toUpperCase()
+      case 'nickname':
+        return pokemon.nickname
+      case 'ot':
+        return pokemon.otName
+      default:
+        return String(pokemon.level)
+    }
+  })
+  // Round-trip through the game charset so the preview shows exactly what gets stored
+  return bytesToGbaString(gbaStringToBytes(rendered, length))
+}
+
+/**
+ * List the name changes a rule would make, in party-then-box order; eggs are skipped and
+ * Pokemon whose names wouldn't change are left out
+ */
+export function planRename(saveData: SaveData, rule: RenameRule): RenameChange[] {
+  const owner = rule.owner ?? 'all'
+  const { trainer } = saveData
+  if (owner !== 'all' && !trainer) {
+    throw new Error('Trainer IDs are not available for this save')
+  }
+
+  const changes: RenameChange[] = []
+  for (const { pokemon, location } of allPokemon(saveData)) {
+    if (location.area === 'daycare' || pokemon.isEgg) continue
+    if (owner !== 'all') {
+      const own = pokemon.otId === trainer!.ot_id && pokemon.otName === saveData.player_name
+      if (own !== (owner === 'own')) continue
+    }
+
+    const renames = [
+      ['nickname', rule.nickname, pokemon.nickname, VANILLA_POKEMON_OFFSETS.nicknameLength],
+      ['ot_name', rule.otName, pokemon.otName, VANILLA_POKEMON_OFFSETS.otNameLength],
+    ] as const
+    for (const [field, template, before, length] of renames) {
+      if (template === undefined) continue
+      const after = renderName(template, pokemon, length)
+      if (after !== before) changes.push({ location, field, before, after })
+    }
+  }
+  return changes
+}
+
+/**
+ * Apply planned renames; the Pokemon are renamed in place
+ */
+export function applyRename(saveData: SaveData, changes: readonly RenameChange[]): SaveData {
+  for (const { location, field, after } of changes) {
+    const pokemon =
+      location.area === 'party'
+        ? saveData.party_pokemon[location.slot]
+        : saveData.boxes?.[location.box]?.[location.slot]
+    if (!pokemon) {
+      throw new Error(`${formatPokemonLocation(location)} is empty`)
+    }
+    if (field === 'nickname') pokemon.nickname = after
+    else pokemon.otName = after
+  }
+  return {
+    ...saveData,
+    party_pokemon: [...saveData.party_pokemon],
+    boxes: saveData.boxes?.map(slots => [...slots]),
+  }
+}
@@ -0,0 +1,255 @@
+/**
+ * Interactive save browser (the CLI's `tui` subcommand)
+ * Tabs for the party and each PC box, a detail view for one Pokemon and a search across the
+ * whole save. Key handling and rendering are plain functions of the state, so the terminal
+ * side only has to feed key presses in and print the screen
+ */
+
+import { allPokemon, formatPokemonLocation, type PokemonLocation } from './query'
+import { type PokemonJson, toPokemonJson } from './saveJson'
+import type { GameConfig, SaveData, StatName } from './types'
+import { statAbbreviations, statNames } from './utils'
+
+export interface BrowserEntry {
+  readonly location: PokemonLocation
+  readonly pokemon: PokemonJson
+}
+
+export interface BrowserTab {
+  readonly label: string
+  readonly entries: readonly BrowserEntry[]
+}
+
+export interface SaveBrowser {
+  readonly title: string
+  /** The party, then one tab per PC box */
+  readonly tabs: readonly BrowserTab[]
+  /** Every Pokemon in the save, for search */
+  readonly all: readonly BrowserEntry[]
+}
+
+export interface SaveBrowserState {
+  readonly tab: number
+  readonly cursor: number
+  /** Inspecting the Pokemon under the cursor */
+  readonly detail: boolean
+  /** Search text being typed, or null when not typing */
+  readonly query: string | null
+  /** Results of the last search, shown instead of the tabs until Escape */
+  readonly results: BrowserTab | null
+  readonly quit: boolean
+}
+
+/** A key press: a named key (up, down, left, right, tab, return, escape, backspace) or text */
+export interface BrowserKey {
+  readonly name?: string
+  readonly text?: string
+}
+
+export const INITIAL_BROWSER_STATE: SaveBrowserState = {
+  tab: 0,
+  cursor: 0,
+  detail: false,
+  query: null,
+  results: null,
+  quit: false,
+}
+
+const HELP = '←/→ tab  ↑/↓ select  Enter inspect  / search  Esc back  q quit'
+
+/**
+ * Gather the party and PC boxes of a parsed save into browser tabs
+ */
+export function createSaveBrowser(saveData: SaveData, config: GameConfig): SaveBrowser {
+  const all = [...allPokemon(saveData)].map(({ pokemon, location }) => ({
+    location,
+    pokemon: toPokemonJson(pokemon, config),
+  }))
+  const boxes = (saveData.boxes ?? []).map((_, box) => {
+    const name = saveData.box_metadata?.[box]?.name
+    return {
+      label: name ? `Box ${box + 1}: ${name}` : `Box ${box + 1}`,
+      entries: all.filter(({ location }) => location.area === 'box' && location.box === box),
+    }
+  })
+  const party = all.filter(({ location }) => location.area === 'party')
+  return {
+    title: `${saveData.player_name} · ${config.name}`,
+    tabs: [{ label: 'Party', entries: party }, ...boxes],
+    all,
+  }
+}
+
+/**
+ * Pokemon whose nickname, species, item, moves, nature, ability or OT contain the text
+ * (case-insensitive)
+ */
+export function searchBrowser(browser: SaveBrowser, text: string): BrowserTab {
+  const needle = text.trim().toLowerCase()
+  const entries = browser.all.filter(({ pokemon }) =>
+    [
+      pokemon.nickname,
+      pokemon.species_name,
+      pokemon.item_name,
+      pokemon.nature,
+      pokemon.ability,
+      pokemon.ot_name,
+      ...pokemon.move_names,
+    ].some(field => field?.toLowerCase().includes(needle))
+  )
+  return { label: `Search "${text.trim()}"`, entries }
+}
+
+const currentTab = (browser: SaveBrowser, state: SaveBrowserState) =>
+  state.results ?? browser.tabs[state.tab]!
+
+const clamp = (value: number, max: number) => Math.max(0, Math.min(value, max))
+
+/**
+ * The state after a key press
+ */
+export function handleBrowserKey(
+  browser: SaveBrowser,
+  state: SaveBrowserState,
+  key: BrowserKey
+): SaveBrowserState {
+  // Typing a search: text goes into the query instead of acting as a command
+  if (state.query !== null) {
+    switch (key.name) {
+      case 'return':
+        if (!state.query.trim()) return { ...state, query: null }
+        return { ...state, query: null, results: searchBrowser(browser, state.query), cursor: 0 }
+      case 'escape':
+        return { ...state, query: null }
+      case 'backspace':
+        return { ...state, query: state.query.slice(0, -1) }
+    }
+    return key.text ? { ...state, query: state.query + key.text } : state
+  }
+
+  const last = currentTab(browser, state).entries.length - 1
+  if (key.text === 'q') return { ...state, quit: true }
+  if (key.text === '/') return { ...state, query: '', detail: false }
+  switch (key.name) {
+    // In the detail view up and down step through the Pokemon of the list
+    case 'up':
+      return { ...state, cursor: clamp(state.cursor - 1, last) }
+    case 'down':
+      return { ...state, cursor: clamp(state.cursor + 1, last) }
+    case 'left':
+    case 'right':
+    case 'tab': {
+      if (state.results || state.detail) return state
+      const step = key.name === 'left' ? -1 : 1
+      const count = browser.tabs.length
+      return { ...state, tab: (state.tab + step + count) % count, cursor: 0 }
+    }
+    case 'return':
+      return { ...state, detail: last >= 0 }
+    case 'escape':
+    case 'backspace':
+      if (state.detail) return { ...state, detail: false }
+      if (state.results) return { ...state, results: null, cursor: 0 }
+      return state
+  }
+  return state
+}
+
+const displayName = (pokemon: PokemonJson) => {
+  if (pokemon.is_egg) return 'Egg'
+  const species = pokemon.species_name ?? `#${pokemon.species_id}`
+  return pokemon.nickname && pokemon.nickname.toLowerCase() !== species.toLowerCase()
+    ? `${pokemon.nickname} (${species})`
+    : species
+}
+
+const GENDER_SYMBOLS = { male: '♂', female: '♀', genderless: '' } as const
+
+function listLine(entry: BrowserEntry, selected: boolean, showLocation: boolean): string {
+  const { pokemon, location } = entry
+  const where = showLocation
+    ? formatPokemonLocation(location).padEnd(16)
+    : String(location.slot + 1).padStart(2)
+  const cells = [
+    selected ? '›' : ' ',
+    where,
+    displayName(pokemon).padEnd(24),
+    `Lv ${pokemon.level}`.padEnd(6),
+    (pokemon.gender ? GENDER_SYMBOLS[pokemon.gender] : '').padEnd(1),
+    pokemon.is_shiny ? '★' : ' ',
+    pokemon.item_name ? `@ ${pokemon.item_name}` : '',
+  ]
+  return cells.join(' ').trimEnd()
+}
+
+const statAbbreviation = (stat: StatName) => statAbbreviations[statNames.indexOf(stat)]!
+
+const statRow = (label: string, values: readonly number[]) =>
+  `${label.padEnd(7)}${values.map(value => String(value).padStart(5)).join('')}`
+
+/**
+ * Everything the save stores about one Pokemon, one fact per line
+ */
+export function formatBrowserDetail({ pokemon, location }: BrowserEntry): string[] {
+  const { origin, nature_effect: effect } = pokemon
+  const natureEffect =
+    effect.plus && effect.minus
+      ? ` (+${statAbbreviation(effect.plus)} -${statAbbreviation(effect.minus)})`
+      : ''
+  const gender = pokemon.gender ? ` ${GENDER_SYMBOLS[pokemon.gender]}` : ''
+  const moves = pokemon.move_names.map((name, i) =>
+    name ? `${name} (${pokemon.pp[i] ?? 0} PP)` : '—'
+  )
+  return [
+    `${displayName(pokemon)}  Lv ${pokemon.level}${gender}${pokemon.is_shiny ? '  ★ Shiny' : ''}`,
+    formatPokemonLocation(location),
+    '',
+    `Types: ${pokemon.types?.join(' / ') ?? '?'}   Ability: ${pokemon.ability ?? '?'}`,
+    `Nature: ${pokemon.nature}${natureEffect}   ${pokemon.characteristic}`,
+    `Item: ${pokemon.item_name ?? '—'}`,
+    `HP: ${pokemon.current_hp}/${pokemon.stats[0]}   EXP: ${pokemon.experience}` +
+      (pokemon.exp_to_next_level === null ? '' : ` (${pokemon.exp_to_next_level} to next level)`),
+    '',
+    `       ${statAbbreviations.map(label => label.padStart(5)).join('')}`,
+    statRow('Stats', pokemon.stats),
+    statRow('IVs', pokemon.ivs),
+    statRow('EVs', pokemon.evs),
+    '',
+    `Moves: ${moves.join(', ')}`,
+    '',
+    `OT: ${pokemon.ot_name} (${pokemon.ot_id}) ${GENDER_SYMBOLS[origin.ot_gender]}` +
+      `   Ball: ${origin.pokeball_name}`,
+    `Met: ${origin.met_location_name} at Lv ${origin.met_level} in ${origin.origin_game_name}`,
+  ]
+}
+
+/**
+ * The screen for the current state as lines no taller than `height`; the list scrolls to keep
+ * the cursor in view
+ */
+export function renderSaveBrowser(
+  browser: SaveBrowser,
+  state: SaveBrowserState,
+  height: number
+): string[] {
+  const tab = currentTab(browser, state)
+  const position = state.results ? '' : ` (${state.tab + 1}/${browser.tabs.length})`
+  const header = [browser.title, `${tab.label}${position}`, '']
+  const prompt = state.query === null ? HELP : `Search: ${state.query}▏  Enter search  Esc cancel`
+  const footer = ['', prompt]
+  const rows = Math.max(1, height - header.length - footer.length)
+
+  const entry = tab.entries[state.cursor]
+  if (state.detail && entry) {
+    return [...header, ...formatBrowserDetail(entry).slice(0, rows), ...footer]
+  }
+
+  if (tab.entries.length === 0) {
+    return [...header, state.results ? 'No matching Pokémon' : 'Empty', ...footer]
+  }
+  const top = clamp(state.cursor - rows + 1, Math.max(0, tab.entries.length - rows))
+  const list = tab.entries
+    .slice(top, top + rows)
+    .map((item, i) => listLine(item, top + i === state.cursor, state.results !== null))
+  return [...header, ...list, ...footer]
+}
@@ -0,0 +1,100 @@
+/**
+ * Save file containers
+ * Emulators store the same Flash data with different padding: a raw 128KB dump, 128KB followed
+ * by a real-time clock footer (mGBA), or a 64KB file from emulators set to the smaller Flash chip
+ */
+
+import { VANILLA_EMERALD_SIGNATURE, VANILLA_SAVE_LAYOUT } from './types'
+
+export type SaveContainer = '128k' | '128k-rtc' | '64k'
+
+export const SAVE_CONTAINERS: readonly SaveContainer[] = ['128k', '128k-rtc', '64k']
+
+export const FLASH_128K_SIZE = 131072
+export const FLASH_64K_SIZE = 65536
+// mGBA's RTC footer: 7 BCD time bytes, the control register and the last latch as a u64 timestamp
+export const RTC_FOOTER_SIZE = 16
+// Largest trailer accepted after the 128KB of Flash data
+const MAX_FOOTER_SIZE = 128
+
+// Each save slot holds sectors 0-13; the 64KB container only has room for the first slot
+const SLOT_SECTOR_COUNT = 14
+const ERASED_BYTE = 0xff
+
+/**
+ * Identify a save's container from its size, or undefined when it is none of the known ones
+ */
+export function detectSaveContainer(save: Uint8Array): SaveContainer | undefined {
+  if (save.length === FLASH_64K_SIZE) return '64k'
+  if (save.length === FLASH_128K_SIZE) return '128k'
+  if (save.length > FLASH_128K_SIZE && save.length <= FLASH_128K_SIZE + MAX_FOOTER_SIZE) {
+    return '128k-rtc'
+  }
+  return undefined
+}
+
+/**
+ * Build an RTC footer for a clock set to 2000-01-01 00:00 in 24-hour mode, latched at `now`
+ */
+function createRtcFooter(now: Date): Uint8Array {
+  const footer = new Uint8Array(RTC_FOOTER_SIZE)
+  // Year, month, day, weekday (Saturday), hour, minute, second, all BCD
+  footer.set([0x00, 0x01, 0x01, 0x06, 0x00, 0x00, 0x00])
+  footer[7] = 0x40
+  new DataView(footer.buffer).setBigUint64(8, BigInt(Math.floor(now.getTime() / 1000)), true)
+  return footer
+}
+
+/**
+ * Move the newest slot into slot 1 so it survives truncation to 64KB
+ */
+function moveNewestSlotFirst(flash: Uint8Array): void {
+  const { sectorSize } = VANILLA_SAVE_LAYOUT
+  const view = new DataView(flash.buffer, flash.byteOffset, flash.byteLength)
+  const newestCounter = (firstSector: number) => {
+    let counter = -1
+    for (let i = firstSector; i < firstSector + SLOT_SECTOR_COUNT; i++) {
+      const footer = (i + 1) * sectorSize - 12
+      if (view.getUint32(footer + 4, true) !== VANILLA_EMERALD_SIGNATURE) continue
+      counter = Math.max(counter, view.getUint32(footer + 8, true))
+    }
+    return counter
+  }
+
+  if (newestCounter(SLOT_SECTOR_COUNT) > newestCounter(0)) {
+    flash.copyWithin(0, SLOT_SECTOR_COUNT * sectorSize, SLOT_SECTOR_COUNT * 2 * sectorSize)
+  }
+}
+
+/**
+ * Convert a save to another container. 64KB saves are padded with erased Flash (0xFF) and
+ * footers are dropped or added (a new RTC footer starts the clock at 2000-01-01, an existing one
+ * is kept). Going to 64KB keeps only the newest slot, moved to slot 1: the backup slot and the
+ * Hall of Fame don't fit, so that direction is lossy
+ */
+export function convertSaveContainer(
+  save: Uint8Array,
+  target: SaveContainer,
+  now: Date = new Date()
+): Uint8Array {
+  const source = detectSaveContainer(save)
+  if (!source) throw new Error(`Unrecognized save size: ${save.length} bytes`)
+
+  const flash = new Uint8Array(FLASH_128K_SIZE).fill(ERASED_BYTE)
+  flash.set(save.subarray(0, Math.min(save.length, FLASH_128K_SIZE)))
+
+  if (target === '128k') return flash
+  if (target === '64k') {
+    moveNewestSlotFirst(flash)
+    const small = flash.slice(0, FLASH_64K_SIZE)
+    // Sectors 14-15 begin the second slot; erase them so the game doesn't see a partial save
+    small.fill(ERASED_BYTE, SLOT_SECTOR_COUNT * VANILLA_SAVE_LAYOUT.sectorSize)
+    return small
+  }
+
+  const footer = source === '128k-rtc' ? save.subarray(FLASH_128K_SIZE) : createRtcFooter(now)
+  const withRtc = new Uint8Array(FLASH_128K_SIZE + footer.length)
+  withRtc.set(flash)
+  withRtc.set(footer, FLASH_128K_SIZE)
+  return withRtc
+}
@@ -0,0 +1,293 @@
+/**
+ * Structured diff between two parsed saves (the CLI's `diff` subcommand)
+ * A versioned, JSON-ready document for sync and backup tools: Pokemon added, removed, moved or
+ * edited, bag quantities gained or lost, story and Pokedex flags toggled, and counters changed
+ */
+
+import { allPokemon, formatPokemonLocation, type PokemonLocation } from './query'
+import { type PokemonJson, toPokemonJson } from './saveJson'
+import {
+  type BagPocketName,
+  type GameConfig,
+  HOENN_BADGE_NAMES,
+  type PlayTimeData,
+  type SaveData,
+  type StoryProgress,
+} from './types'
+
+export const SAVE_DIFF_VERSION = 1
+
+export interface FieldChange<T = unknown> {
+  readonly field: string
+  readonly from: T
+  readonly to: T
+}
+
+export interface PokemonRef {
+  /** Personality value; together with the OT ID it identifies a Pokemon across saves */
+  readonly personality: number
+  readonly ot_id: number
+  readonly species_id: number
+  readonly species_name: string | null
+  readonly nickname: string
+  /** Location in the later save (the earlier one for removed Pokemon) */
+  readonly location: PokemonLocation
+}
+
+export interface PokemonChange extends PokemonRef {
+  /** Location in the earlier save when the Pokemon was moved, otherwise null */
+  readonly moved_from: PokemonLocation | null
+  readonly changes: readonly FieldChange[]
+}
+
+export interface ItemChange {
+  readonly pocket: BagPocketName
+  readonly item_id: number
+  readonly name: string
+  readonly from: number
+  readonly to: number
+}
+
+export interface FlagChange {
+  /** "badge.stone", "progress.running_shoes", "pokedex.caught.252"... */
+  readonly flag: string
+  readonly from: boolean
+  readonly to: boolean
+}
+
+export interface SaveDiff {
+  readonly version: typeof SAVE_DIFF_VERSION
+  /** Play time between the two saves; negative when `after` is older */
+  readonly play_time_seconds: number
+  readonly pokemon: {
+    readonly added: readonly PokemonRef[]
+    readonly removed: readonly PokemonRef[]
+    /** Pokemon in both saves that were edited or moved */
+    readonly changed: readonly PokemonChange[]
+  }
+  readonly items: {
+    readonly gained: readonly ItemChange[]
+    readonly lost: readonly ItemChange[]
+  }
+  readonly flags: readonly FlagChange[]
+  /** Money, coins, Battle Points and game stats ("currency.money", "game_stats.steps") */
+  readonly counters: readonly FieldChange<number>[]
+}
+
+// Fields compared for Pokemon found in both saves
+const POKEMON_FIELDS: readonly (keyof PokemonJson)[] = [
+  'species_id',
+  'nickname',
+  'level',
+  'experience',
+  'item',
+  'moves',
+  'pp',
+  'current_hp',
+  'stats',
+  'evs',
+  'ivs',
+  'is_egg',
+  'ot_name',
+  'markings',
+  'contest_stats',
+]
+
+const PROGRESS_FLAGS: readonly (keyof StoryProgress)[] = [
+  'received_starter',
+  'received_pokedex',
+  'received_pokenav',
+  'running_shoes',
+  'national_dex',
+  'game_cleared',
+]
+
+const same = (a: unknown, b: unknown) => JSON.stringify(a) === JSON.stringify(b)
+
+const playSeconds = ({ hours, minutes, seconds }: PlayTimeData) =>
+  hours * 3600 + minutes * 60 + seconds
+
+interface PokemonEntry {
+  readonly ref: PokemonRef
+  readonly json: PokemonJson
+}
+
+function pokemonEntries(saveData: SaveData, config: GameConfig): PokemonEntry[] {
+  return [...allPokemon(saveData)].map(({ pokemon, location }) => {
+    const json = toPokemonJson(pokemon, config)
+    return {
+      json,
+      ref: {
+        personality: pokemon.personality,
+        ot_id: pokemon.otId,
+        species_id: json.species_id,
+        species_name: json.species_name,
+        nickname: json.nickname,
+        location,
+      },
+    }
+  })
+}
+
+function diffPokemon(before: PokemonEntry[], after: PokemonEntry[]): SaveDiff['pokemon'] {
+  // Clones share a personality and OT, so each key holds a queue matched in save order
+  const key = ({ ref }: PokemonEntry) => `${ref.personality}:${ref.ot_id}`
+  const remaining = new Map<string, PokemonEntry[]>()
+  for (const entry of before) {
+    remaining.set(key(entry), [...(remaining.get(key(entry)) ?? []), entry])
+  }
+
+  const added: PokemonRef[] = []
+  const changed: PokemonChange[] = []
+  for (const entry of after) {
+    const previous = remaining.get(key(entry))?.shift()
+    if (!previous) {
+      added.push(entry.ref)
+      continue
+    }
+    const changes = POKEMON_FIELDS.filter(
+      field => !same(previous.json[field], entry.json[field])
+    ).map(field => ({ field, from: previous.json[field], to: entry.json[field] }))
+    const moved = !same(previous.ref.location, entry.ref.location)
+    if (changes.length > 0 || moved) {
+      changed.push({ ...entry.ref, moved_from: moved ? previous.ref.location : null, changes })
+    }
+  }
+  const removed = [...remaining.values()].flat().map(entry => entry.ref)
+  return { added, removed, changed }
+}
+
+function diffItems(before: SaveData, after: SaveData): SaveDiff['items'] {
+  const totals = (saveData: SaveData) => {
+    const counts = new Map<string, ItemChange>()
+    for (const [pocket, items] of Object.entries(saveData.bag ?? {})) {
+      for (const item of items) {
+        const id = `${pocket}:${item.item_id}`
+        const quantity = (counts.get(id)?.to ?? 0) + item.quantity
+        counts.set(id, {
+          pocket: pocket as BagPocketName,
+          item_id: item.item_id,
+          name: item.name,
+          from: 0,
+          to: quantity,
+        })
+      }
+    }
+    return counts
+  }
+  if (!before.bag || !after.bag) return { gained: [], lost: [] }
+
+  const previous = totals(before)
+  const next = totals(after)
+  const changes: ItemChange[] = [...next.values()].map(item => ({
+    ...item,
+    from: previous.get(`${item.pocket}:${item.item_id}`)?.to ?? 0,
+  }))
+  for (const [id, item] of previous) {
+    if (!next.has(id)) changes.push({ ...item, from: item.to, to: 0 })
+  }
+  return {
+    gained: changes.filter(item => item.to > item.from),
+    lost: changes.filter(item => item.to < item.from),
+  }
+}
+
+function diffFlags(before: SaveData, after: SaveData): FlagChange[] {
+  const flags: FlagChange[] = []
+  const compare = (flag: string, from: boolean, to: boolean) => {
+    if (from !== to) flags.push({ flag, from, to })
+  }
+
+  if (before.progress && after.progress) {
+    const { progress: previous } = before
+    const { progress: next } = after
+    HOENN_BADGE_NAMES.forEach((badge, i) => {
+      compare(`badge.${badge.toLowerCase()}`, previous.badges[i] ?? false, next.badges[i] ?? false)
+    })
+    for (const flag of PROGRESS_FLAGS) {
+      compare(`progress.${flag}`, previous[flag] === true, next[flag] === true)
+    }
+  }
+  if (before.pokedex && after.pokedex) {
+    for (const kind of ['seen', 'caught'] as const) {
+      const previous = before.pokedex[kind]
+      after.pokedex[kind].forEach((to, i) => {
+        compare(`pokedex.${kind}.${i + 1}`, previous[i] ?? false, to)
+      })
+    }
+  }
+  return flags
+}
+
+function diffCounters(before: SaveData, after: SaveData): FieldChange<number>[] {
+  const counters: FieldChange<number>[] = []
+  const compare = (prefix: string, from?: object, to?: object) => {
+    if (!from || !to) return
+    const previous = from as Readonly<Record<string, number>>
+    for (const [name, value] of Object.entries(to as Readonly<Record<string, number>>)) {
+      const old = previous[name] ?? 0
+      if (old !== value) counters.push({ field: `${prefix}.${name}`, from: old, to: value })
+    }
+  }
+  compare('currency', before.currency, after.currency)
+  compare('game_stats', before.game_stats, after.game_stats)
+  return counters
+}
+
+/**
+ * Diff two parsed saves of the same game, `before` usually being the older one
+ * Pokemon are matched by personality value and OT ID, so they are followed between party, boxes
+ * and daycare; fields use the same JSON shape as --json. Sections either save lacks are skipped
+ */
+export function diffSaves(
+  before: SaveData,
+  after: SaveData,
+  config: GameConfig,
+  beforeConfig: GameConfig = config
+): SaveDiff {
+  return {
+    version: SAVE_DIFF_VERSION,
+    play_time_seconds: playSeconds(after.play_time) - playSeconds(before.play_time),
+    pokemon: diffPokemon(pokemonEntries(before, beforeConfig), pokemonEntries(after, config)),
+    items: diffItems(before, after),
+    flags: diffFlags(before, after),
+    counters: diffCounters(before, after),
+  }
+}
+
+const describePokemon = (ref: PokemonRef) => {
+  const species = ref.species_name ?? `#${ref.species_id}`
+  return `${ref.nickname} (${species}) in ${formatPokemonLocation(ref.location)}`
+}
+
+const formatValue = (value: unknown) => (typeof value === 'string' ? value : JSON.stringify(value))
+
+/**
+ * Human-readable summary of a diff, one change per line
+ */
+export function formatSaveDiff(diff: SaveDiff): string {
+  const seconds = Math.abs(diff.play_time_seconds)
+  const minutes = String(Math.floor(seconds / 60) % 60).padStart(2, '0')
+  const clock = `${Math.floor(seconds / 3600)}:${minutes}`
+  const lines = [`Play time: ${diff.play_time_seconds < 0 ? '-' : '+'}${clock}`]
+
+  for (const ref of diff.pokemon.added) lines.push(`+ ${describePokemon(ref)}`)
+  for (const ref of diff.pokemon.removed) lines.push(`- ${describePokemon(ref)}`)
+  for (const change of diff.pokemon.changed) {
+    lines.push(`~ ${describePokemon(change)}`)
+    if (change.moved_from) lines.push(`    moved from ${formatPokemonLocation(change.moved_from)}`)
+    for (const { field, from, to } of change.changes) {
+      lines.push(`    ${field}: ${formatValue(from)} → ${formatValue(to)}`)
+    }
+  }
+  for (const item of [...diff.items.gained, ...diff.items.lost]) {
+    const delta = item.to - item.from
+    const sign = delta > 0 ? '+' : '-'
+    lines.push(`${sign} ${item.name} x${Math.abs(delta)} (${item.from} → ${item.to})`)
+  }
+  for (const { flag, to } of diff.flags) lines.push(`${to ? '+' : '-'} ${flag}`)
+  for (const { field, from, to } of diff.counters) lines.push(`  ${field}: ${from} → ${to}`)
+
+  if (lines.length === 1) lines.push('No changes')
+  return lines.join('\n')
+}
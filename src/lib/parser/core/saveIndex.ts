@@ -0,0 +1,386 @@
+/**
+ * Save library index
+ * Catalogs summary metadata for many saves in a SQLite database, deduplicated by content
+ * fingerprint, so tools can work across a whole library without re-parsing every file
+ * (Node-only: used by the CLI)
+ */
+
+import { createHash } from 'crypto'
+import { normalizeMappingName } from './mappingIndex'
+import { PokemonSaveParser } from './PokemonSaveParser'
+import type { PokemonQuery } from './query'
+import type { PlayTimeData, SaveSummaryPokemon } from './types'
+
+/**
+ * Bump whenever the catalog's tables change so stale catalogs are rebuilt instead of misread;
+ * stored as the database's user_version
+ */
+export const SAVE_INDEX_VERSION = 1
+
+// One row per unique save keyed by its fingerprint, and one per file path pointing at its save.
+// Party summaries are stored as JSON text
+export const SAVE_INDEX_SCHEMA: readonly string[] = [
+  `CREATE TABLE IF NOT EXISTS saves (
+  fingerprint TEXT PRIMARY KEY,
+  file_size INTEGER NOT NULL,
+  game TEXT NOT NULL,
+  player_name TEXT NOT NULL,
+  play_hours INTEGER NOT NULL,
+  play_minutes INTEGER NOT NULL,
+  play_seconds INTEGER NOT NULL,
+  badge_count INTEGER NOT NULL,
+  money INTEGER,
+  dex_seen INTEGER,
+  dex_caught INTEGER,
+  party TEXT NOT NULL,
+  indexed_at TEXT NOT NULL
+)`,
+  `CREATE TABLE IF NOT EXISTS paths (
+  path TEXT PRIMARY KEY,
+  fingerprint TEXT NOT NULL REFERENCES saves(fingerprint)
+)`,
+]
+
+type SqlParam = string | number | null
+
+/** The part of node:sqlite's DatabaseSync the catalog uses */
+export interface SaveIndexDatabase {
+  exec(sql: string): void
+  prepare(sql: string): {
+    run(...params: SqlParam[]): unknown
+    all(...params: SqlParam[]): unknown[]
+  }
+}
+
+export interface SaveIndexEntry {
+  readonly fingerprint: string
+  readonly paths: readonly string[]
+  readonly file_size: number
+  readonly game: string
+  readonly player_name: string
+  readonly play_time: PlayTimeData
+  readonly badge_count: number
+  readonly money?: number
+  readonly pokedex?: { readonly seen: number; readonly caught: number }
+  readonly party: readonly SaveSummaryPokemon[]
+  readonly indexed_at: string
+}
+
+export interface SaveIndex {
+  readonly version: number
+  readonly entries: Readonly<Record<string, SaveIndexEntry>>
+}
+
+export type SaveIndexResult = 'added' | 'duplicate' | 'unchanged'
+
+export function createSaveIndex(): SaveIndex {
+  return { version: SAVE_INDEX_VERSION, entries: {} }
+}
+
+/**
+ * SHA-256 of the raw save bytes, hex encoded
+ */
+export function fingerprintSave(bytes: Uint8Array): string {
+  return createHash('sha256').update(bytes).digest('hex')
+}
+
+/**
+ * Remove a path from every entry but one, dropping entries left without any path
+ */
+function releasePath(
+  entries: Readonly<Record<string, SaveIndexEntry>>,
+  path: string,
+  keep: string
+): Record<string, SaveIndexEntry> {
+  const released: Record<string, SaveIndexEntry> = {}
+  for (const [fingerprint, entry] of Object.entries(entries)) {
+    if (fingerprint === keep || !entry.paths.includes(path)) {
+      released[fingerprint] = entry
+      continue
+    }
+    const paths = entry.paths.filter(existing => existing !== path)
+    if (paths.length > 0) released[fingerprint] = { ...entry, paths }
+  }
+  return released
+}
+
+/**
+ * Add a save to the index, returning the updated index and what happened
+ * Identical content seen under another path is recorded as a duplicate instead of re-parsed, and
+ * a path whose content changed moves from its old entry to the new one
+ */
+export async function addSaveToIndex(
+  index: SaveIndex,
+  bytes: Uint8Array,
+  path: string,
+  indexedAt: Date = new Date()
+): Promise<{ index: SaveIndex; result: SaveIndexResult; entry: SaveIndexEntry }> {
+  const fingerprint = fingerprintSave(bytes)
+  const existing = index.entries[fingerprint]
+  const entries = releasePath(index.entries, path, fingerprint)
+
+  if (existing) {
+    if (existing.paths.includes(path)) return { index, result: 'unchanged', entry: existing }
+    const entry = { ...existing, paths: [...existing.paths, path] }
+    return {
+      index: { ...index, entries: { ...entries, [fingerprint]: entry } },
+      result: 'duplicate',
+      entry,
+    }
+  }
+
+  const summary = await new PokemonSaveParser().parseSummary(bytes.slice().buffer)
+  const entry: SaveIndexEntry = {
+    fingerprint,
+    paths: [path],
+    file_size: bytes.length,
+    game: summary.game,
+    player_name: summary.player_name,
+    play_time: summary.play_time,
+    badge_count: summary.badge_count,
+    money: summary.money,
+    pokedex: summary.pokedex,
+    party: summary.party,
+    indexed_at: indexedAt.toISOString(),
+  }
+  return {
+    index: { ...index, entries: { ...entries, [fingerprint]: entry } },
+    result: 'added',
+    entry,
+  }
+}
+
+/**
+ * Drop paths that no longer exist, removing entries left without any path
+ */
+export function pruneSaveIndex(index: SaveIndex, exists: (path: string) => boolean): SaveIndex {
+  const entries: Record<string, SaveIndexEntry> = {}
+  for (const [fingerprint, entry] of Object.entries(index.entries)) {
+    const paths = entry.paths.filter(exists)
+    if (paths.length > 0) entries[fingerprint] = { ...entry, paths }
+  }
+  return { ...index, entries }
+}
+
+interface SaveRow {
+  readonly fingerprint: string
+  readonly file_size: number
+  readonly game: string
+  readonly player_name: string
+  readonly play_hours: number
+  readonly play_minutes: number
+  readonly play_seconds: number
+  readonly badge_count: number
+  readonly money: number | null
+  readonly dex_seen: number | null
+  readonly dex_caught: number | null
+  readonly party: string
+  readonly indexed_at: string
+}
+
+/**
+ * Read the catalog from its database; an empty database is a new catalog, and one written for
+ * another version is rejected so it is rebuilt rather than overwritten
+ */
+export function readSaveIndex(db: SaveIndexDatabase): SaveIndex {
+  const [{ user_version: version }] = db.prepare('PRAGMA user_version').all() as [
+    { user_version: number },
+  ]
+  if (version === 0) return createSaveIndex()
+  if (version !== SAVE_INDEX_VERSION) {
+    throw new Error(`Unsupported save index version: ${version} (expected ${SAVE_INDEX_VERSION})`)
+  }
+
+  const paths = new Map<string, string[]>()
+  const pathRows = db.prepare('SELECT path, fingerprint FROM paths ORDER BY rowid').all()
+  for (const { path, fingerprint } of pathRows as { path: string; fingerprint: string }[]) {
+    paths.set(fingerprint, [...(paths.get(fingerprint) ?? []), path])
+  }
+  const entries: Record<string, SaveIndexEntry> = {}
+  for (const row of db.prepare('SELECT * FROM saves ORDER BY rowid').all() as SaveRow[]) {
+    entries[row.fingerprint] = {
+      fingerprint: row.fingerprint,
+      paths: paths.get(row.fingerprint) ?? [],
+      file_size: row.file_size,
+      game: row.game,
+      player_name: row.player_name,
+      play_time: { hours: row.play_hours, minutes: row.play_minutes, seconds: row.play_seconds },
+      badge_count: row.badge_count,
+      money: row.money ?? undefined,
+      pokedex:
+        row.dex_seen === null || row.dex_caught === null
+          ? undefined
+          : { seen: row.dex_seen, caught: row.dex_caught },
+      party: JSON.parse(row.party) as SaveSummaryPokemon[],
+      indexed_at: row.indexed_at,
+    }
+  }
+  return { version, entries }
+}
+
+/**
+ * Store the catalog in its database, creating the tables on first use. The rows are replaced in
+ * one transaction: a library's catalog is small, and a failed write leaves the old one intact
+ */
+export function writeSaveIndex(db: SaveIndexDatabase, index: SaveIndex): void {
+  for (const sql of SAVE_INDEX_SCHEMA) db.exec(sql)
+  db.exec(`PRAGMA user_version = ${SAVE_INDEX_VERSION}`)
+  db.exec('BEGIN')
+  try {
+    db.exec('DELETE FROM paths')
+    db.exec('DELETE FROM saves')
+    const insertSave = db.prepare(
+      'INSERT INTO saves VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)'
+    )
+    const insertPath = db.prepare('INSERT INTO paths (path, fingerprint) VALUES (?, ?)')
+    for (const entry of Object.values(index.entries)) {
+      const { hours, minutes, seconds } = entry.play_time
+      insertSave.run(
+        entry.fingerprint,
+        entry.file_size,
+        entry.game,
+        entry.player_name,
+        hours,
+        minutes,
+        seconds,
+        entry.badge_count,
+        entry.money ?? null,
+        entry.pokedex?.seen ?? null,
+        entry.pokedex?.caught ?? null,
+        JSON.stringify(entry.party),
+        entry.indexed_at
+      )
+      for (const path of entry.paths) insertPath.run(path, entry.fingerprint)
+    }
+    db.exec('COMMIT')
+  } catch (error) {
+    db.exec('ROLLBACK')
+    throw error
+  }
+}
+
+export interface SaveIndexMatch {
+  readonly entry: SaveIndexEntry
+  /** 0-based party slot */
+  readonly slot: number
+  readonly pokemon: SaveSummaryPokemon
+}
+
+/**
+ * Find party Pokemon across every indexed save from the catalog alone, without reading the saves
+ * Only the filters the catalog can answer are supported: species, level range and shininess
+ */
+export function findInSaveIndex(index: SaveIndex, query: PokemonQuery): SaveIndexMatch[] {
+  const { species } = query
+  const unsupported = (['ot', 'move', 'minIv'] as const).filter(key => query[key] !== undefined)
+  if (unsupported.length > 0) {
+    throw new Error(`The save index can't filter by ${unsupported.join(', ')}; use find on a save`)
+  }
+
+  return Object.values(index.entries).flatMap(entry =>
+    entry.party.flatMap((pokemon, slot) => {
+      if (typeof species === 'number' && pokemon.species_id !== species) return []
+      if (
+        typeof species === 'string' &&
+        (pokemon.name_id === undefined ||
+          normalizeMappingName(pokemon.name_id) !== normalizeMappingName(species))
+      ) {
+        return []
+      }
+      if (query.minLevel !== undefined && pokemon.level < query.minLevel) return []
+      if (query.maxLevel !== undefined && pokemon.level > query.maxLevel) return []
+      if (query.shiny !== undefined && pokemon.is_shiny !== query.shiny) return []
+      return [{ entry, slot, pokemon }]
+    })
+  )
+}
+
+const playSeconds = ({ play_time: t }: SaveIndexEntry) =>
+  t.hours * 3600 + t.minutes * 60 + t.seconds
+
+export interface SaveIndexStats {
+  readonly saves: number
+  readonly files: number
+  readonly games: Readonly<Record<string, number>>
+  readonly play_time_seconds: number
+  readonly party_pokemon: number
+  readonly shiny_pokemon: number
+  readonly species: number
+}
+
+/**
+ * Totals over the whole catalog: saves, files, saves per game, play time and party Pokemon
+ */
+export function summarizeSaveIndex(index: SaveIndex): SaveIndexStats {
+  const entries = Object.values(index.entries)
+  const games: Record<string, number> = {}
+  for (const { game } of entries) games[game] = (games[game] ?? 0) + 1
+  const party = entries.flatMap(entry => entry.party)
+  return {
+    saves: entries.length,
+    files: entries.reduce((sum, entry) => sum + entry.paths.length, 0),
+    games,
+    play_time_seconds: entries.reduce((sum, entry) => sum + playSeconds(entry), 0),
+    party_pokemon: party.length,
+    shiny_pokemon: party.filter(pokemon => pokemon.is_shiny).length,
+    species: new Set(party.map(pokemon => pokemon.species_id)).size,
+  }
+}
+
+export interface SaveIndexTimelineSave {
+  readonly entry: SaveIndexEntry
+  /** Progress since the run's previous save; absent on its first save */
+  readonly since_previous?: {
+    readonly play_time_seconds: number
+    readonly badges: number
+    readonly caught?: number
+    /** Party species the previous save didn't have */
+    readonly new_species: readonly number[]
+  }
+}
+
+export interface SaveIndexRun {
+  readonly game: string
+  readonly player_name: string
+  readonly saves: readonly SaveIndexTimelineSave[]
+}
+
+/**
+ * The catalog as playthroughs: saves of the same game and player name ordered by play time, each
+ * with the play time, badges, Pokédex catches and party species gained since the one before
+ */
+export function saveIndexTimeline(index: SaveIndex): SaveIndexRun[] {
+  const runs = new Map<string, SaveIndexEntry[]>()
+  for (const entry of Object.values(index.entries)) {
+    const key = JSON.stringify([entry.game, entry.player_name])
+    runs.set(key, [...(runs.get(key) ?? []), entry])
+  }
+  return [...runs.values()].map(entries => {
+    const sorted = entries.sort((a, b) => playSeconds(a) - playSeconds(b))
+    return {
+      game: sorted[0]!.game,
+      player_name: sorted[0]!.player_name,
+      saves: sorted.map((entry, i) => {
+        const previous = sorted[i - 1]
+        if (!previous) return { entry }
+        const species = new Set(previous.party.map(pokemon => pokemon.species_id))
+        return {
+          entry,
+          since_previous: {
+            play_time_seconds: playSeconds(entry) - playSeconds(previous),
+            badges: entry.badge_count - previous.badge_count,
+            caught:
+              entry.pokedex && previous.pokedex
+                ? entry.pokedex.caught - previous.pokedex.caught
+                : undefined,
+            new_species: [
+              ...new Set(
+                entry.party.map(pokemon => pokemon.species_id).filter(id => !species.has(id))
+              ),
+            ],
+          },
+        }
+      }),
+    }
+  })
+}
@@ -0,0 +1,319 @@
+/**
+ * Versioned JSON output for parsed saves (the CLI's --json)
+ * The shape is described by schema/save-data.schema.json; every key is snake_case, optional data
+ * is null rather than missing, and IDs come with their resolved names
+ */
+
+import { toRawId } from './mappingIndex'
+import type { PokemonBase } from './PokemonBase'
+import { getSpeciesName } from './species'
+import type {
+  BagItem,
+  BoxMetadata,
+  CurrentLocation,
+  Currency,
+  GameConfig,
+  GameOptions,
+  GameStats,
+  LegalityReport,
+  MailMessage,
+  Misc,
+  MysteryGift,
+  OptionalSections,
+  PlayTimeData,
+  Rival,
+  Roamer,
+  SaveData,
+  SaveSlot,
+  SaveWarning,
+  SecretBases,
+  SkippedSlot,
+  Starter,
+  StoryProgress,
+  TrainerInfo,
+} from './types'
+
+/**
+ * Bump whenever a key is renamed, removed or changes type; adding keys keeps the version
+ */
+export const SAVE_JSON_SCHEMA_VERSION = 1
+
+type PokemonBaseJson = ReturnType<PokemonBase['toJSON']>
+
+// Fields toJSON leaves undefined for some games are null here, so every Pokemon has the same keys
+export type PokemonJson = Omit<
+  PokemonBaseJson,
+  'name_id' | 'egg_cycles' | 'form' | 'types' | 'ability' | 'gender' | 'exp_to_next_level'
+> & {
+  readonly species_name: string | null
+  readonly name_id: string | null
+  readonly item_name: string | null
+  /** Same order as moves; null for empty move slots */
+  readonly move_names: readonly (string | null)[]
+  readonly egg_cycles: number | null
+  readonly form: string | null
+  readonly types: PokemonBaseJson['types'] | null
+  readonly ability: string | null
+  readonly gender: PokemonBaseJson['gender'] | null
+  readonly exp_to_next_level: number | null
+}
+
+export interface SaveSlotSummary {
+  readonly slot: 1 | 2
+  readonly counter: number
+  readonly player_name: string
+  readonly play_time: PlayTimeData
+  /** "NICKNAME LvN" per party member */
+  readonly party: readonly string[]
+}
+
+export interface SaveJson {
+  readonly schema_version: typeof SAVE_JSON_SCHEMA_VERSION
+  readonly game: string
+  readonly player_name: string
+  readonly play_time: PlayTimeData
+  readonly trainer: TrainerInfo | null
+  readonly active_slot: number
+  readonly party_pokemon: readonly PokemonJson[]
+  readonly currency: Currency | null
+  readonly progress: StoryProgress | null
+  readonly options: GameOptions | null
+  readonly daycare: {
+    readonly slots: readonly ({
+      readonly pokemon: PokemonJson
+      readonly steps: number
+      readonly level_on_withdraw: number
+    } | null)[]
+    readonly egg_pending: boolean
+    readonly offspring_personality: number
+    readonly step_counter: number
+  } | null
+  readonly mail: readonly MailMessage[] | null
+  readonly secret_bases: SecretBases | null
+  readonly mystery_gift: MysteryGift | null
+  readonly game_stats: GameStats | null
+  readonly roamer: Roamer | null
+  readonly location: CurrentLocation | null
+  readonly misc: Misc | null
+  readonly rival: Rival | null
+  readonly starter: Starter | null
+  readonly optional_sections: OptionalSections | null
+  /** Flags listed as National Dex numbers */
+  readonly pokedex: {
+    readonly seen_count: number
+    readonly caught_count: number
+    readonly seen: readonly number[]
+    readonly caught: readonly number[]
+  } | null
+  readonly bag: Readonly<Record<BagPocketKey, readonly BagItem[]>> | null
+  readonly boxes?: readonly (readonly (PokemonJson | null)[])[] | null
+  readonly box_metadata?: readonly BoxMetadata[] | null
+  readonly current_box?: number | null
+  readonly slots?: readonly (SaveSlotSummary | null)[]
+  readonly legality?: readonly LegalityReport[]
+  readonly skipped_slots: readonly SkippedSlot[]
+  readonly warnings: readonly SaveWarning[]
+}
+
+// Bag pockets are keyed by their snake_case names in the output
+type BagPocketKey = 'items' | 'key_items' | 'poke_balls' | 'tm_hm' | 'berries'
+
+export interface SaveJsonOptions {
+  /** Include the PC boxes, box names/wallpapers and the current box (large) */
+  readonly boxes?: boolean
+  /** Summaries of both save slots (parseAllSlots) */
+  readonly slots?: readonly (SaveSlot | null)[]
+  readonly legality?: readonly LegalityReport[]
+  /** Warnings to report instead of saveData.warnings (e.g. with stat checks added) */
+  readonly warnings?: readonly SaveWarning[]
+}
+
+/** List the National Dex numbers whose flag is set */
+export const dexNumbers = (flags: readonly boolean[]) =>
+  flags.flatMap((flag, i) => (flag ? [i + 1] : []))
+
+/**
+ * Condense a save slot to what tells the two slots apart
+ */
+export function summarizeSaveSlot(slot: SaveSlot | null): SaveSlotSummary | null {
+  return (
+    slot && {
+      slot: slot.slot,
+      counter: slot.counter,
+      player_name: slot.data.player_name,
+      play_time: slot.data.play_time,
+      party: slot.data.party_pokemon.map(p => `${p.nickname} Lv${p.level}`),
+    }
+  )
+}
+
+/**
+ * A Pokemon's JSON with its species, item and move names resolved through the game's mappings
+ */
+export function toPokemonJson(pokemon: PokemonBase, config: GameConfig): PokemonJson {
+  const json = pokemon.toJSON()
+  const { items, moves, pokemon: species } = config.mappings ?? {}
+  const mappedName = (mapping: typeof items, id: number) =>
+    id === 0 ? null : (mapping?.get(toRawId(mapping, id))?.name ?? null)
+
+  return {
+    ...json,
+    species_name:
+      species?.get(pokemon.internalSpeciesId)?.name ?? getSpeciesName(pokemon.speciesId) ?? null,
+    name_id: json.name_id ?? null,
+    item_name: mappedName(items, json.item),
+    move_names: json.moves.map(id => mappedName(moves, id)),
+    egg_cycles: json.egg_cycles ?? null,
+    form: json.form ?? null,
+    types: json.types ?? null,
+    ability: json.ability ?? null,
+    gender: json.gender ?? null,
+    exp_to_next_level: json.exp_to_next_level ?? null,
+  }
+}
+
+/**
+ * Build the versioned JSON document for a parsed save
+ */
+export function toSaveJson(
+  saveData: SaveData,
+  config: GameConfig,
+  options: SaveJsonOptions = {}
+): SaveJson {
+  const toJson = (pokemon: PokemonBase) => toPokemonJson(pokemon, config)
+  const { pokedex, bag, daycare } = saveData
+
+  return {
+    schema_version: SAVE_JSON_SCHEMA_VERSION,
+    game: config.name,
+    player_name: saveData.player_name,
+    play_time: saveData.play_time,
+    trainer: saveData.trainer ?? null,
+    active_slot: saveData.active_slot,
+    party_pokemon: saveData.party_pokemon.map(toJson),
+    currency: saveData.currency ?? null,
+    progress: saveData.progress ?? null,
+    options: saveData.options ?? null,
+    daycare: daycare
+      ? {
+          ...daycare,
+          slots: daycare.slots.map(slot => slot && { ...slot, pokemon: toJson(slot.pokemon) }),
+        }
+      : null,
+    mail: saveData.mail ?? null,
+    secret_bases: saveData.secret_bases ?? null,
+    mystery_gift: saveData.mystery_gift ?? null,
+    game_stats: saveData.game_stats ?? null,
+    roamer: saveData.roamer ?? null,
+    location: saveData.location ?? null,
+    misc: saveData.misc ?? null,
+    rival: saveData.rival ?? null,
+    starter: saveData.starter ?? null,
+    optional_sections: saveData.optional_sections ?? null,
+    pokedex: pokedex
+      ? {
+          seen_count: pokedex.seen_count,
+          caught_count: pokedex.caught_count,
+          seen: dexNumbers(pokedex.seen),
+          caught: dexNumbers(pokedex.caught),
+        }
+      : null,
+    bag: bag
+      ? {
+          items: bag.items,
+          key_items: bag.keyItems,
+          poke_balls: bag.pokeBalls,
+          tm_hm: bag.tmHm,
+          berries: bag.berries,
+        }
+      : null,
+    // Boxes are large, so they are only included when asked for
+    ...(options.boxes && {
+      boxes: saveData.boxes?.map(box => box.map(p => p && toJson(p))) ?? null,
+      box_metadata: saveData.box_metadata ?? null,
+      current_box: saveData.current_box ?? null,
+    }),
+    ...(options.slots && { slots: options.slots.map(summarizeSaveSlot) }),
+    ...(options.legality && { legality: options.legality }),
+    skipped_slots: saveData.skipped_slots ?? [],
+    warnings: options.warnings ?? saveData.warnings ?? [],
+  }
+}
+
+// Keys are unique within an object, so the comparison never sees two equal keys
+const sortKeys = (_key: string, value: unknown) =>
+  value && typeof value === 'object' && !Array.isArray(value)
+    ? Object.fromEntries(Object.entries(value).sort(([a], [b]) => (a < b ? -1 : 1)))
+    : value
+
+/**
+ * Canonical JSON text: keys sorted at every level (after toJSON), so the same data always gives
+ * the same bytes to diff or cache. One line by default; `pretty` indents by two spaces
+ */
+export function toCanonicalJson(value: unknown, { pretty = false } = {}): string {
+  return JSON.stringify(value, sortKeys, pretty ? 2 : undefined)
+}
+
+/** A batch output record: a save's document or the error that stopped it, tagged with its file */
+export type SaveJsonRecord = { readonly path: string } & (SaveJson | { readonly error: string })
+
+/**
+ * One line of NDJSON (newline-delimited JSON) for batch output, so consumers can process each
+ * save as soon as it is written
+ */
+export function toNdjsonLine(path: string, result: SaveJson | Error): string {
+  const record: SaveJsonRecord =
+    result instanceof Error ? { path, error: result.message } : { path, ...result }
+  return `${JSON.stringify(record)}\n`
+}
+
+/** Columns of batch CSV output: one row per save, with only path and error for failed ones */
+export const BATCH_CSV_COLUMNS = [
+  'path',
+  'error',
+  'game',
+  'player_name',
+  'trainer_id',
+  'play_hours',
+  'play_minutes',
+  'money',
+  'dex_seen',
+  'dex_caught',
+  'party_size',
+  'party',
+  'warnings',
+] as const
+
+// RFC 4180 quoting: fields with commas, quotes or line breaks are quoted, quotes doubled
+const csvField = (value: string | number | undefined) => {
+  const text = value === undefined ? '' : String(value)
+  return /[",\r\n]/.test(text) ? `"${text.replaceAll('"', '""')}"` : text
+}
+
+// Missing columns are left empty
+const csvLine = (row: Partial<Record<(typeof BATCH_CSV_COLUMNS)[number], string | number>>) =>
+  `${BATCH_CSV_COLUMNS.map(column => csvField(row[column])).join(',')}\n`
+
+/**
+ * One line of CSV for batch output (columns in BATCH_CSV_COLUMNS order), summarizing a save or
+ * recording the error that stopped it
+ */
+export function toCsvLine(path: string, result: SaveJson | Error): string {
+  if (result instanceof Error) return csvLine({ path, error: result.message })
+  return csvLine({
+    path,
+    game: result.game,
+    player_name: result.player_name,
+    trainer_id: result.trainer?.trainer_id,
+    play_hours: result.play_time.hours,
+    play_minutes: result.play_time.minutes,
+    money: result.currency?.money,
+    dex_seen: result.pokedex?.seen_count,
+    dex_caught: result.pokedex?.caught_count,
+    party_size: result.party_pokemon.length,
+    party: result.party_pokemon
+      .map(p => `${p.species_name ?? `#${p.species_id}`} Lv${p.level}`)
+      .join(' / '),
+    warnings: result.warnings.length,
+  })
+}
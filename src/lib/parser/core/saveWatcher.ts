@@ -0,0 +1,137 @@
+/**
+ * Save file watching
+ * Re-parses a save whenever the emulator flushes it and reports each changed party as its --json
+ * document, optionally POSTing it to a tracker or overlay (Node-only: used by the CLI)
+ */
+
+import fs from 'fs'
+import path from 'path'
+import { PokemonSaveParser } from './PokemonSaveParser'
+import { type SaveJson, toCanonicalJson, toSaveJson } from './saveJson'
+import type { SaveData } from './types'
+
+// An emulator flush can land as several writes, or as a temporary file renamed over the save,
+// so changes are parsed once the file has been quiet this long
+export const WATCH_SETTLE_MS = 200
+
+export interface SaveUpdate {
+  readonly result: SaveData
+  readonly json: SaveJson
+}
+
+export interface SaveWatchOptions {
+  /** Milliseconds between the stat polls behind the file system's change events */
+  readonly interval: number
+  /** Called with the first parse and every later one whose party changed */
+  readonly onUpdate: (update: SaveUpdate) => void | Promise<void>
+  /** Parse and POST failures; watching carries on */
+  readonly onError: (message: string) => void
+  /** URL each updated document is POSTed to */
+  readonly post?: string
+  readonly parser?: PokemonSaveParser
+  readonly fetch?: typeof fetch
+  readonly settleMs?: number
+}
+
+export interface SaveWatcher {
+  /** Resolves once the first parse has been reported */
+  readonly ready: Promise<void>
+  /** Stop watching, waiting for a parse in progress to finish */
+  close(): Promise<void>
+}
+
+/**
+ * POST a --json document as canonical JSON, throwing when the server doesn't accept it
+ */
+export async function postSaveJson(
+  url: string,
+  json: unknown,
+  fetchImpl: typeof fetch = fetch
+): Promise<void> {
+  let response: Response
+  try {
+    response = await fetchImpl(url, {
+      method: 'POST',
+      headers: { 'Content-Type': 'application/json' },
+      body: toCanonicalJson(json),
+    })
+  } catch (error) {
+    const reason = error instanceof Error ? error.message : 'Unknown error'
+    throw new Error(`POST ${url} failed: ${reason}`)
+  }
+  if (!response.ok) throw new Error(`POST ${url} failed: ${response.status}`)
+}
+
+// What counts as a change: species, level, HP and nickname of each party member
+const partyKey = ({ party_pokemon: party }: SaveData) =>
+  JSON.stringify(
+    party.map(p => ({
+      species: p.speciesId,
+      level: p.level,
+      hp: p.currentHp,
+      nickname: p.nickname,
+    }))
+  )
+
+/**
+ * Watch a save file. fs.watch reports writes as they happen; polling the file's stats every
+ * interval ms covers file systems that don't deliver change events (network shares, some
+ * container mounts). The directory is watched rather than the file, since a save replaced by a
+ * rename would leave a file watcher on the old inode
+ */
+export function watchSaveFile(filePath: string, options: SaveWatchOptions): SaveWatcher {
+  const absPath = path.resolve(filePath)
+  const parser = options.parser ?? new PokemonSaveParser()
+  const report = (error: unknown) =>
+    options.onError(error instanceof Error ? error.message : 'Unknown error')
+  let lastKey: string | undefined
+  let lastBuffer: Buffer | undefined
+
+  const refresh = async () => {
+    try {
+      // The stat poll also reports writes fs.watch already delivered
+      const buffer = fs.readFileSync(absPath)
+      if (lastBuffer?.equals(buffer)) return
+      const result = await parser.parse(buffer)
+      lastBuffer = buffer
+
+      const key = partyKey(result)
+      if (key === lastKey) return
+      lastKey = key
+
+      // One document for the output and the POST
+      const json = toSaveJson(result, parser.gameConfig!)
+      await options.onUpdate({ result, json })
+      if (options.post) await postSaveJson(options.post, json, options.fetch).catch(report)
+    } catch (error) {
+      // A half-written save fails its checksums; the rest of the write triggers another parse
+      report(error)
+    }
+  }
+
+  // Parses run one at a time, in the order changes settled
+  const ready = refresh()
+  let queue = ready
+  let timer: ReturnType<typeof setTimeout> | undefined
+  const schedule = () => {
+    clearTimeout(timer)
+    timer = setTimeout(() => {
+      queue = queue.then(refresh)
+    }, options.settleMs ?? WATCH_SETTLE_MS)
+  }
+
+  const watcher = fs.watch(path.dirname(absPath), (_event, name) => {
+    if (name === path.basename(absPath)) schedule()
+  })
+  fs.watchFile(absPath, { interval: options.interval }, schedule)
+
+  return {
+    ready,
+    close: async () => {
+      clearTimeout(timer)
+      watcher.close()
+      fs.unwatchFile(absPath, schedule)
+      await queue
+    },
+  }
+}
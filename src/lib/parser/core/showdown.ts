@@ -0,0 +1,378 @@
+/**
+ * Pokemon Showdown team export and import
+ * Formats party Pokemon as a Showdown paste, and builds Gen 3 Pokemon back from one
+ */
+
+import { PokemonBase } from './PokemonBase'
+import { findRawIdByName, normalizeMappingName, toRawId } from './mappingIndex'
+import {
+  GENDER_RATIO_GENDERLESS,
+  MAX_LEVEL,
+  SPECIES_UNOWN,
+  experienceForLevel,
+  findSpeciesIdByName,
+  getGenderFromPersonality,
+  getSpeciesInfo,
+  getSpeciesName,
+  getUnownForm,
+} from './species'
+import {
+  VANILLA_SHINY_THRESHOLD,
+  type GameConfig,
+  type PokemonGender,
+  type SaveData,
+} from './types'
+import { gbaStringToBytes, natures, type BaseMappingItem } from './utils'
+
+// Showdown lists stats as HP/Atk/Def/SpA/SpD/Spe; the parser stores Speed fourth
+const SHOWDOWN_STATS = [
+  ['HP', 0],
+  ['Atk', 1],
+  ['Def', 2],
+  ['SpA', 4],
+  ['SpD', 5],
+  ['Spe', 3],
+] as const
+
+const MAX_IV = 31
+
+/** Display name for a mapped (external) ID, e.g. an item or move */
+function mappedName(
+  mapping: ReadonlyMap<number, BaseMappingItem> | undefined,
+  id: number
+): string | undefined {
+  return mapping?.get(toRawId(mapping, id))?.name
+}
+
+/** "swift-swim" -> "Swift Swim" */
+const displayName = (idName: string) =>
+  idName
+    .split('-')
+    .map(word => word.charAt(0).toUpperCase() + word.slice(1))
+    .join(' ')
+
+/**
+ * Showdown species name including the form ("Deoxys-Speed", "Unown-B")
+ */
+function showdownSpecies(pokemon: PokemonBase, config: GameConfig): string {
+  // The species table has proper names ("Mr. Mime"); hack-only species fall back to the mapping
+  const species =
+    getSpeciesName(pokemon.speciesId) ??
+    config.mappings?.pokemon?.get(pokemon.internalSpeciesId)?.name ??
+    `#${pokemon.speciesId}`
+  const { form } = pokemon
+  // Megas only exist in battle, so a stored Pokemon is exported in its base form
+  if (!form || form === 'mega') return species
+  return `${species}-${displayName(form)}`
+}
+
+/**
+ * Format one Pokemon as a Showdown set
+ */
+export function formatShowdownSet(pokemon: PokemonBase, config: GameConfig): string {
+  const species = showdownSpecies(pokemon, config)
+  const { nickname, gender } = pokemon
+  const named =
+    nickname && normalizeMappingName(nickname) !== normalizeMappingName(species)
+      ? `${nickname} (${species})`
+      : species
+  const genderTag = gender === 'male' ? ' (M)' : gender === 'female' ? ' (F)' : ''
+  const item = pokemon.item ? mappedName(config.mappings?.items, pokemon.item) : undefined
+
+  const lines = [`${named}${genderTag}${item ? ` @ ${item}` : ''}`]
+  if (pokemon.ability) lines.push(`Ability: ${displayName(pokemon.ability)}`)
+  if (pokemon.level !== 100) lines.push(`Level: ${pokemon.level}`)
+  if (pokemon.isShiny) lines.push('Shiny: Yes')
+
+  const { evs, ivs } = pokemon
+  const evParts = SHOWDOWN_STATS.filter(([, i]) => evs[i]).map(([name, i]) => `${evs[i]} ${name}`)
+  if (evParts.length > 0) lines.push(`EVs: ${evParts.join(' / ')}`)
+  lines.push(`${pokemon.nature} Nature`)
+  const ivParts = SHOWDOWN_STATS.filter(([, i]) => ivs[i] !== MAX_IV).map(
+    ([name, i]) => `${ivs[i]} ${name}`
+  )
+  if (ivParts.length > 0) lines.push(`IVs: ${ivParts.join(' / ')}`)
+
+  for (const moveId of pokemon.moveIds) {
+    if (moveId) lines.push(`- ${mappedName(config.mappings?.moves, moveId) ?? `Move #${moveId}`}`)
+  }
+  return lines.join('\n')
+}
+
+/**
+ * Export the party as a Showdown paste, one set per Pokemon separated by blank lines
+ * Eggs are left out since Showdown can't represent them
+ */
+export function exportShowdown(saveData: SaveData, config: GameConfig): string {
+  return saveData.party_pokemon
+    .filter(pokemon => !pokemon.isEgg)
+    .map(pokemon => formatShowdownSet(pokemon, config))
+    .join('\n\n')
+}
+
+/** One Pokemon parsed from a Showdown paste; stats are in save order (HP/Atk/Def/Spe/SpA/SpD) */
+export interface ShowdownSet {
+  readonly nickname?: string
+  readonly species: string
+  readonly gender?: 'male' | 'female'
+  readonly item?: string
+  readonly ability?: string
+  readonly level: number
+  readonly shiny: boolean
+  readonly friendship?: number
+  readonly evs: readonly number[]
+  readonly ivs: readonly number[]
+  readonly nature: string
+  readonly moves: readonly string[]
+}
+
+/** The trainer that receives the imported Pokemon */
+export interface ShowdownTrainer {
+  readonly name: string
+  readonly gender: 'male' | 'female'
+  /** Full 32-bit OT ID (secret ID in the high half) */
+  readonly otId: number
+}
+
+export interface ShowdownImportOptions {
+  /**
+   * Current PP for a move; there is no move table offline, so this defaults to 5 and the game
+   * refills every move to its real maximum at the next Pokemon Center
+   */
+  readonly movePP?: (moveId: number) => number
+  /** Random source for personality generation, for reproducible results */
+  readonly random?: () => number
+}
+
+const MAX_EV = 255
+const MAX_TOTAL_EVS = 510
+const DEFAULT_FRIENDSHIP = 70
+const DEFAULT_MOVE_PP = 5
+const LANGUAGE_ENGLISH = 2
+const FLAG_HAS_SPECIES = 0x02
+const MET_LOCATION_TRADE = 0xfe // Shown as met in a trade, like in-game trade Pokemon
+const ORIGIN_EMERALD = 3
+const BALL_POKE_BALL = 4
+
+/** Parse "252 HP / 4 Atk" into save-order stat values on top of the defaults */
+function parseStatLine(line: string, defaults: number): number[] {
+  const values = Array<number>(6).fill(defaults)
+  for (const part of line.split('/')) {
+    const match = /^\s*(\d+)\s+(\w+)\s*$/.exec(part)
+    const key = match?.[2]?.toLowerCase()
+    const stat = SHOWDOWN_STATS.find(([name]) => name.toLowerCase() === key)
+    if (!match || !stat) throw new Error(`Invalid stat entry "${part.trim()}"`)
+    values[stat[1]] = parseInt(match[1]!, 10)
+  }
+  return values
+}
+
+/**
+ * Parse a Showdown paste into sets; sets are separated by blank lines
+ */
+export function parseShowdownPaste(paste: string): ShowdownSet[] {
+  const blocks = paste
+    .split(/\r?\n\s*\r?\n/)
+    .map(block =>
+      block
+        .split(/\r?\n/)
+        .map(line => line.trim())
+        .filter(Boolean)
+    )
+    .filter(lines => lines.length > 0)
+
+  return blocks.map(([header, ...lines]) => {
+    // "Nickname (Species) (M) @ Item", with nickname, gender and item optional
+    const [nameAndGender = '', item] = header!.split(' @ ').map(part => part.trim())
+    const genderMatch = /\s*\(([MF])\)$/.exec(nameAndGender)
+    const name = genderMatch ? nameAndGender.slice(0, genderMatch.index) : nameAndGender
+    const nicknameMatch = /^(.*\S)\s*\(([^()]+)\)$/.exec(name)
+    const field = (key: string) =>
+      lines.find(line => line.startsWith(`${key}:`))?.slice(key.length + 1).trim()
+
+    const level = field('Level')
+    const happiness = field('Happiness')
+    const evs = field('EVs')
+    const ivs = field('IVs')
+    // Other lines (Tera Type, Dynamax Level, ...) don't exist in Gen 3 and are ignored
+    return {
+      nickname: nicknameMatch?.[1],
+      species: nicknameMatch?.[2] ?? name,
+      gender: genderMatch ? (genderMatch[1] === 'M' ? 'male' : 'female') : undefined,
+      item: item || undefined,
+      ability: field('Ability'),
+      level: level ? parseInt(level, 10) : MAX_LEVEL,
+      shiny: field('Shiny')?.toLowerCase() === 'yes',
+      friendship: happiness ? parseInt(happiness, 10) : undefined,
+      evs: evs ? parseStatLine(evs, 0) : Array<number>(6).fill(0),
+      ivs: ivs ? parseStatLine(ivs, MAX_IV) : Array<number>(6).fill(MAX_IV),
+      nature: lines.find(line => / Nature$/.test(line))?.replace(/ Nature$/, '') ?? 'Hardy',
+      moves: lines.filter(line => line.startsWith('-')).map(line => line.slice(1).trim()),
+    }
+  })
+}
+
+/**
+ * Resolve "Treecko", "Ho-Oh" or a form like "Unown-B" to a National Dex number and form suffix
+ */
+function resolveSpecies(name: string): { speciesId: number; form?: string } | undefined {
+  const direct = findSpeciesIdByName(name)
+  if (direct !== undefined) return { speciesId: direct }
+  const dash = name.lastIndexOf('-')
+  if (dash === -1) return undefined
+  const speciesId = findSpeciesIdByName(name.slice(0, dash))
+  if (speciesId === undefined) return undefined
+  return { speciesId, form: name.slice(dash + 1).toLowerCase() }
+}
+
+/**
+ * Find a personality value matching the nature, gender, ability slot, shininess and Unown letter
+ * Shiny values are built directly from the OT ID so the search stays short
+ */
+function generatePersonality(
+  constraints: {
+    nature: number
+    genderRatio: number
+    gender?: PokemonGender
+    abilitySlot?: number
+    shiny: boolean
+    unownForm?: string
+  },
+  otId: number,
+  random: () => number
+): number {
+  const rand16 = () => Math.floor(random() * 0x10000)
+  const xorId = (otId & 0xffff) ^ (otId >>> 16)
+  const isShiny = (pid: number) =>
+    (xorId ^ (pid >>> 16) ^ (pid & 0xffff)) < VANILLA_SHINY_THRESHOLD
+
+  for (let attempt = 0; attempt < 1_000_000; attempt++) {
+    const low = rand16()
+    const high = constraints.shiny
+      ? xorId ^ low ^ Math.floor(random() * VANILLA_SHINY_THRESHOLD)
+      : rand16()
+    const pid = ((high << 16) | low) >>> 0
+    if (pid % 25 !== constraints.nature) continue
+    if (!constraints.shiny && isShiny(pid)) continue
+    const gender = getGenderFromPersonality(constraints.genderRatio, pid)
+    if (constraints.gender && gender !== constraints.gender) continue
+    if (constraints.abilitySlot !== undefined && (pid & 1) !== constraints.abilitySlot) continue
+    if (constraints.unownForm && getUnownForm(pid) !== constraints.unownForm) continue
+    return pid
+  }
+  throw new Error('Could not generate a personality value for this set')
+}
+
+/**
+ * Build a Gen 3 Pokemon from a Showdown set, owned by the given trainer
+ * The personality value is generated to match the nature, gender, ability and shininess; the
+ * Pokemon is recorded as met at its current level in a trade and caught in a Poke Ball
+ */
+export function createShowdownPokemon(
+  set: ShowdownSet,
+  trainer: ShowdownTrainer,
+  config: GameConfig,
+  options: ShowdownImportOptions = {}
+): PokemonBase {
+  const { mappings } = config
+  const resolved = resolveSpecies(set.species)
+  const info = resolved && getSpeciesInfo(resolved.speciesId)
+  if (!resolved || !info) throw new Error(`Unknown species "${set.species}"`)
+  const { speciesId, form } = resolved
+
+  if (!Number.isInteger(set.level) || set.level < 1 || set.level > MAX_LEVEL) {
+    throw new Error(`Level ${set.level} is outside 1-${MAX_LEVEL}`)
+  }
+  const totalEvs = set.evs.reduce((sum, ev) => sum + ev, 0)
+  if (set.evs.some(ev => ev < 0 || ev > MAX_EV) || totalEvs > MAX_TOTAL_EVS) {
+    throw new Error(`EVs must be 0-${MAX_EV} each and at most ${MAX_TOTAL_EVS} in total`)
+  }
+  if (set.ivs.some(iv => iv < 0 || iv > MAX_IV)) {
+    throw new Error(`IVs must be 0-${MAX_IV}`)
+  }
+  const friendship = set.friendship ?? DEFAULT_FRIENDSHIP
+  if (!Number.isInteger(friendship) || friendship < 0 || friendship > 255) {
+    throw new Error(`Happiness ${friendship} is outside 0-255`)
+  }
+  const nature = natures.findIndex(name => name.toLowerCase() === set.nature.toLowerCase())
+  if (nature === -1) throw new Error(`Unknown nature "${set.nature}"`)
+  // The lowest and highest gender bytes cover every gender the species can have
+  const genders = [0, 0xff].map(pid => getGenderFromPersonality(info.gender_ratio, pid))
+  if (set.gender && !genders.includes(set.gender)) {
+    throw new Error(`${info.name} can't be ${set.gender}`)
+  }
+
+  let abilitySlot: number | undefined
+  if (set.ability) {
+    abilitySlot = info.abilities.findIndex(
+      ability => normalizeMappingName(ability) === normalizeMappingName(set.ability!)
+    )
+    if (abilitySlot === -1) throw new Error(`${info.name} can't have ${set.ability}`)
+    // Single-ability species accept either personality parity
+    if (info.abilities.length === 1) abilitySlot = undefined
+  }
+
+  const rawItem = set.item ? findRawIdByName(mappings?.items, set.item) : 0
+  if (rawItem === undefined) throw new Error(`Unknown item "${set.item}"`)
+  if (set.moves.length === 0 || set.moves.length > 4) {
+    throw new Error(`${info.name} needs 1-4 moves, got ${set.moves.length}`)
+  }
+  const rawMoves = set.moves.map(move => {
+    // "Hidden Power [Fire]": the type follows from the IVs
+    const raw = findRawIdByName(mappings?.moves, move.replace(/\s*\[.*\]$/, ''))
+    if (raw === undefined) throw new Error(`Unknown move "${move}"`)
+    return raw
+  })
+
+  const personality = generatePersonality(
+    {
+      nature,
+      genderRatio: info.gender_ratio,
+      gender: info.gender_ratio === GENDER_RATIO_GENDERLESS ? undefined : set.gender,
+      abilitySlot,
+      shiny: set.shiny,
+      unownForm: speciesId === SPECIES_UNOWN ? form : undefined,
+    },
+    trainer.otId,
+    options.random ?? Math.random
+  )
+
+  // Decrypted box format with the substructures in Growth/Attacks/EVs/Misc order (.pk3)
+  const pk3 = new Uint8Array(config.saveLayout.boxPokemonSize)
+  const view = new DataView(pk3.buffer)
+  view.setUint32(0x00, personality, true)
+  view.setUint32(0x04, trainer.otId >>> 0, true)
+  // Pokemon without a nickname carry the species name in capitals, as the game names them
+  pk3.set(gbaStringToBytes(set.nickname ?? info.name.toUpperCase(), 10), 0x08)
+  pk3[0x12] = LANGUAGE_ENGLISH
+  pk3[0x13] = FLAG_HAS_SPECIES
+  pk3.set(gbaStringToBytes(trainer.name, 7), 0x14)
+
+  view.setUint16(0x20, toRawId(mappings?.pokemon, speciesId), true)
+  view.setUint16(0x22, rawItem, true)
+  view.setUint32(0x24, experienceForLevel(info.growth_rate, set.level), true)
+  pk3[0x29] = friendship
+  rawMoves.forEach((raw, i) => {
+    const moveId = mappings?.moves?.get(raw)?.id ?? raw
+    view.setUint16(0x2c + i * 2, raw, true)
+    pk3[0x34 + i] = options.movePP?.(moveId) ?? DEFAULT_MOVE_PP
+  })
+  set.evs.forEach((ev, i) => (pk3[0x38 + i] = ev))
+
+  pk3[0x45] = MET_LOCATION_TRADE
+  const otGender = trainer.gender === 'female' ? 1 : 0
+  view.setUint16(
+    0x46,
+    set.level | (ORIGIN_EMERALD << 7) | (BALL_POKE_BALL << 11) | (otGender << 15),
+    true
+  )
+  const ivWord = set.ivs.reduce((word, iv, i) => word | (iv << (i * 5)), 0)
+  // Bit 31 is the ability slot, which the game derives from the personality's low bit
+  view.setUint32(0x48, (ivWord | ((personality & 1) << 31)) >>> 0, true)
+
+  let checksum = 0
+  for (let i = 0x20; i < 0x50; i += 2) checksum += view.getUint16(i, true)
+  view.setUint16(0x1c, checksum & 0xffff, true)
+
+  return PokemonBase.fromPK3(pk3, config)
+}
@@ -0,0 +1,212 @@
+/**
+ * Embedded per-species data keyed by National Dex number
+ */
+
+import speciesData from '../data/species_info.json'
+import { normalizeMappingName } from './mappingIndex'
+import type { PokemonGender } from './types'
+import { calculateTotalStatsDirect } from './utils'
+
+export type GrowthRate =
+  | 'medium_fast'
+  | 'erratic'
+  | 'fluctuating'
+  | 'medium_slow'
+  | 'fast'
+  | 'slow'
+
+export type PokemonType =
+  | 'normal'
+  | 'fighting'
+  | 'flying'
+  | 'poison'
+  | 'ground'
+  | 'rock'
+  | 'bug'
+  | 'ghost'
+  | 'steel'
+  | 'fire'
+  | 'water'
+  | 'grass'
+  | 'electric'
+  | 'psychic'
+  | 'ice'
+  | 'dragon'
+  | 'dark'
+
+export interface SpeciesInfo {
+  readonly name: string
+  /** Gen 3 gender threshold: 0 = always male, 254 = always female, 255 = genderless */
+  readonly gender_ratio: number
+  readonly growth_rate: GrowthRate
+  /** Gen 3 base stats in save order: HP, Attack, Defense, Speed, Sp. Atk, Sp. Def */
+  readonly base_stats: readonly number[]
+  /** One or two Gen 3 types (no Fairy) */
+  readonly types: readonly PokemonType[]
+  /** Gen 3 abilities in slot order (kebab-case); single-ability species have one entry */
+  readonly abilities: readonly string[]
+}
+
+const speciesTable = speciesData as Record<string, SpeciesInfo>
+let speciesIdByName: Map<string, number> | undefined
+
+export const GENDER_RATIO_MALE_ONLY = 0
+export const GENDER_RATIO_FEMALE_ONLY = 254
+export const GENDER_RATIO_GENDERLESS = 255
+
+/**
+ * Look up species data by National Dex number
+ */
+export function getSpeciesInfo(nationalDexId: number): SpeciesInfo | undefined {
+  return speciesTable[nationalDexId.toString()]
+}
+
+/**
+ * Species display name by National Dex number
+ */
+export function getSpeciesName(nationalDexId: number): string | undefined {
+  return getSpeciesInfo(nationalDexId)?.name
+}
+
+/**
+ * Look up a National Dex number by species name ("Mr. Mime", "mr-mime" and "MR MIME" all match)
+ */
+export function findSpeciesIdByName(name: string): number | undefined {
+  if (!speciesIdByName) {
+    speciesIdByName = new Map()
+    for (const [id, info] of Object.entries(speciesTable)) {
+      speciesIdByName.set(normalizeMappingName(info.name), Number(id))
+    }
+  }
+  return speciesIdByName.get(normalizeMappingName(name))
+}
+
+/**
+ * Ability for a species' ability slot (0 or 1), falling back to the first slot
+ * as the game does for species with a single ability
+ */
+export function getSpeciesAbility(
+  nationalDexId: number,
+  abilityNumber: number
+): string | undefined {
+  const abilities = getSpeciesInfo(nationalDexId)?.abilities
+  if (!abilities) return undefined
+  return abilities[abilityNumber] ?? abilities[0]
+}
+
+/**
+ * Derive gender from the species gender ratio and the personality value's low byte
+ */
+export function getGenderFromPersonality(
+  genderRatio: number,
+  personality: number
+): PokemonGender {
+  if (genderRatio === GENDER_RATIO_GENDERLESS) return 'genderless'
+  if (genderRatio === GENDER_RATIO_FEMALE_ONLY) return 'female'
+  if (genderRatio === GENDER_RATIO_MALE_ONLY) return 'male'
+  return (personality & 0xff) < genderRatio ? 'female' : 'male'
+}
+
+export const MAX_LEVEL = 100
+
+/**
+ * Total experience needed to reach a level, using the Gen 3 growth formulas (integer math)
+ */
+export function experienceForLevel(growthRate: GrowthRate, level: number): number {
+  const n = Math.max(1, Math.min(MAX_LEVEL, Math.trunc(level)))
+  if (n === 1) return 0
+  const cube = n ** 3
+
+  switch (growthRate) {
+    case 'fast':
+      return Math.floor((4 * cube) / 5)
+    case 'medium_fast':
+      return cube
+    case 'medium_slow':
+      return Math.floor((6 * cube) / 5) - 15 * n * n + 100 * n - 140
+    case 'slow':
+      return Math.floor((5 * cube) / 4)
+    case 'erratic':
+      if (n < 50) return Math.floor((cube * (100 - n)) / 50)
+      if (n < 68) return Math.floor((cube * (150 - n)) / 100)
+      if (n < 98) return Math.floor((cube * Math.floor((1911 - 10 * n) / 3)) / 500)
+      return Math.floor((cube * (160 - n)) / 100)
+    case 'fluctuating':
+      if (n < 15) return Math.floor((cube * (Math.floor((n + 1) / 3) + 24)) / 50)
+      if (n < 36) return Math.floor((cube * (n + 14)) / 50)
+      return Math.floor((cube * (Math.floor(n / 2) + 32)) / 50)
+  }
+}
+
+/**
+ * Level reached with the given total experience (PC box Pokemon store experience but no level)
+ */
+export function levelForExperience(growthRate: GrowthRate, experience: number): number {
+  let level = 1
+  while (level < MAX_LEVEL && experienceForLevel(growthRate, level + 1) <= experience) level++
+  return level
+}
+
+export const SPECIES_UNOWN = 201
+export const SPECIES_CASTFORM = 351
+export const SPECIES_DEOXYS = 386
+
+/**
+ * Unown letters in Gen 3 order, using the sprite form suffixes ('a' is the default form)
+ */
+export const UNOWN_FORMS = [
+  ...'abcdefghijklmnopqrstuvwxyz'.split(''),
+  'exclamation',
+  'question',
+] as const
+
+export type UnownForm = (typeof UNOWN_FORMS)[number]
+
+/**
+ * Unown letter from the low two bits of each personality byte, modulo the 28 letters
+ */
+export function getUnownForm(personality: number): UnownForm {
+  const value =
+    (((personality >>> 24) & 3) << 6) |
+    (((personality >>> 16) & 3) << 4) |
+    (((personality >>> 8) & 3) << 2) |
+    (personality & 3)
+  return UNOWN_FORMS[value % UNOWN_FORMS.length]!
+}
+
+export const SPECIES_SHEDINJA = 292
+
+// Deoxys forms change every base stat except HP (same order as SpeciesInfo.base_stats)
+const DEOXYS_FORM_BASE_STATS: Readonly<Record<string, readonly number[]>> = {
+  attack: [50, 180, 20, 150, 180, 20],
+  defense: [50, 70, 160, 90, 70, 160],
+  speed: [50, 95, 90, 180, 95, 90],
+}
+
+/**
+ * Gen 3 base stats for a species, accounting for forms that change them
+ */
+export function getBaseStats(nationalDexId: number, form?: string): readonly number[] | undefined {
+  if (nationalDexId === SPECIES_DEOXYS && form) return DEOXYS_FORM_BASE_STATS[form]
+  return getSpeciesInfo(nationalDexId)?.base_stats
+}
+
+/**
+ * Recompute a Pokemon's stats from the embedded base stats, IVs, EVs, nature and level
+ * Returns undefined for species outside the embedded table
+ */
+export function calculateStats(
+  nationalDexId: number,
+  level: number,
+  ivs: readonly number[],
+  evs: readonly number[],
+  nature: string,
+  form?: string
+): number[] | undefined {
+  const baseStats = getBaseStats(nationalDexId, form)
+  if (!baseStats) return undefined
+  const stats = calculateTotalStatsDirect(baseStats, ivs, evs, level, nature)
+  // Shedinja's HP is always 1 regardless of level, IVs and EVs
+  if (nationalDexId === SPECIES_SHEDINJA) stats[0] = 1
+  return stats
+}
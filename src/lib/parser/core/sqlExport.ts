@@ -0,0 +1,199 @@
+/**
+ * SQLite export of save contents
+ * Turns parsed saves into SQL statements over five tables (meta, pokemon, items, dex, stats), keyed
+ * by the save's content fingerprint so one database can hold a whole collection's history
+ * Statements carry bound parameters for a SQLite driver; toSqlScript inlines them for sqlite3
+ */
+
+import { allPokemon } from './query'
+import { toPokemonJson } from './saveJson'
+import type { GameConfig, SaveData } from './types'
+
+export type SqlValue = string | number | null
+
+export interface SqlStatement {
+  readonly sql: string
+  readonly params: readonly SqlValue[]
+}
+
+export interface SqlExportSource {
+  readonly saveData: SaveData
+  readonly config: GameConfig
+  /** Content fingerprint (fingerprintSave) used as the save's key in every table */
+  readonly fingerprint: string
+  readonly path: string
+  readonly exportedAt?: Date
+}
+
+const STAT_COLUMNS = ['hp', 'atk', 'def', 'spe', 'spa', 'spd'] as const
+
+export const SQL_EXPORT_SCHEMA: readonly string[] = [
+  `CREATE TABLE IF NOT EXISTS meta (
+  save TEXT PRIMARY KEY,
+  path TEXT NOT NULL,
+  game TEXT NOT NULL,
+  player_name TEXT NOT NULL,
+  trainer_id INTEGER,
+  secret_id INTEGER,
+  play_seconds INTEGER NOT NULL,
+  money INTEGER,
+  badge_count INTEGER,
+  exported_at TEXT NOT NULL
+)`,
+  `CREATE TABLE IF NOT EXISTS pokemon (
+  save TEXT NOT NULL REFERENCES meta(save),
+  area TEXT NOT NULL,
+  box INTEGER,
+  slot INTEGER NOT NULL,
+  species_id INTEGER NOT NULL,
+  species_name TEXT,
+  nickname TEXT NOT NULL,
+  level INTEGER NOT NULL,
+  nature TEXT NOT NULL,
+  is_shiny INTEGER NOT NULL,
+  is_egg INTEGER NOT NULL,
+  personality INTEGER NOT NULL,
+  ot_name TEXT NOT NULL,
+  ot_id INTEGER NOT NULL,
+  item_name TEXT,
+  ${STAT_COLUMNS.map(stat => `iv_${stat} INTEGER NOT NULL`).join(',\n  ')},
+  ${STAT_COLUMNS.map(stat => `ev_${stat} INTEGER NOT NULL`).join(',\n  ')},
+  move1 TEXT,
+  move2 TEXT,
+  move3 TEXT,
+  move4 TEXT
+)`,
+  `CREATE TABLE IF NOT EXISTS items (
+  save TEXT NOT NULL REFERENCES meta(save),
+  pocket TEXT NOT NULL,
+  slot INTEGER NOT NULL,
+  item_id INTEGER NOT NULL,
+  name TEXT NOT NULL,
+  quantity INTEGER NOT NULL
+)`,
+  `CREATE TABLE IF NOT EXISTS dex (
+  save TEXT NOT NULL REFERENCES meta(save),
+  species_id INTEGER NOT NULL,
+  seen INTEGER NOT NULL,
+  caught INTEGER NOT NULL
+)`,
+  `CREATE TABLE IF NOT EXISTS stats (
+  save TEXT NOT NULL REFERENCES meta(save),
+  name TEXT NOT NULL,
+  value INTEGER NOT NULL
+)`,
+]
+
+const insert = (table: string, row: Readonly<Record<string, SqlValue>>): SqlStatement => {
+  const columns = Object.keys(row)
+  const placeholders = columns.map(() => '?').join(', ')
+  return {
+    sql: `INSERT INTO ${table} (${columns.join(', ')}) VALUES (${placeholders})`,
+    params: Object.values(row),
+  }
+}
+
+/**
+ * Statements that (re)write one save; rows from an earlier export of the same save are replaced
+ */
+export function saveToSqlStatements(source: SqlExportSource): SqlStatement[] {
+  const { saveData, config, fingerprint } = source
+  const { trainer, play_time: time, pokedex, bag, game_stats: stats } = saveData
+  const save = fingerprint
+
+  const statements: SqlStatement[] = [
+    ...['pokemon', 'items', 'dex', 'stats', 'meta'].map(table => ({
+      sql: `DELETE FROM ${table} WHERE save = ?`,
+      params: [save],
+    })),
+    insert('meta', {
+      save,
+      path: source.path,
+      game: config.name,
+      player_name: saveData.player_name,
+      trainer_id: trainer?.trainer_id ?? null,
+      secret_id: trainer?.secret_id ?? null,
+      play_seconds: time.hours * 3600 + time.minutes * 60 + time.seconds,
+      money: saveData.currency?.money ?? null,
+      badge_count: saveData.progress?.badges.filter(Boolean).length ?? null,
+      exported_at: (source.exportedAt ?? new Date()).toISOString(),
+    }),
+  ]
+
+  for (const { pokemon, location } of allPokemon(saveData)) {
+    const json = toPokemonJson(pokemon, config)
+    const [move1 = null, move2 = null, move3 = null, move4 = null] = json.move_names
+    statements.push(
+      insert('pokemon', {
+        save,
+        area: location.area,
+        box: location.area === 'box' ? location.box + 1 : null,
+        slot: location.slot + 1,
+        species_id: json.species_id,
+        species_name: json.species_name,
+        nickname: json.nickname,
+        level: json.level,
+        nature: json.nature,
+        is_shiny: Number(json.is_shiny),
+        is_egg: Number(json.is_egg),
+        personality: pokemon.personality,
+        ot_name: json.ot_name,
+        ot_id: pokemon.otId,
+        item_name: json.item_name,
+        ...Object.fromEntries(STAT_COLUMNS.map((stat, i) => [`iv_${stat}`, json.ivs[i]!])),
+        ...Object.fromEntries(STAT_COLUMNS.map((stat, i) => [`ev_${stat}`, json.evs[i]!])),
+        move1,
+        move2,
+        move3,
+        move4,
+      })
+    )
+  }
+
+  for (const [pocket, items] of Object.entries(bag ?? {})) {
+    for (const item of items) {
+      statements.push(
+        insert('items', {
+          save,
+          pocket: pocket.replace(/[A-Z]/g, letter => `_${letter.toLowerCase()}`),
+          slot: item.slot + 1,
+          item_id: item.item_id,
+          name: item.name,
+          quantity: item.quantity,
+        })
+      )
+    }
+  }
+
+  // Only species with a flag set, to keep the table small
+  pokedex?.seen.forEach((seen, i) => {
+    const caught = pokedex.caught[i] ?? false
+    if (!seen && !caught) return
+    statements.push(
+      insert('dex', { save, species_id: i + 1, seen: Number(seen), caught: Number(caught) })
+    )
+  })
+
+  for (const [name, value] of Object.entries(stats ?? {})) {
+    statements.push(insert('stats', { save, name, value }))
+  }
+  return statements
+}
+
+const sqlLiteral = (value: SqlValue) => {
+  if (value === null) return 'NULL'
+  if (typeof value === 'number') return String(value)
+  return `'${value.replaceAll("'", "''")}'`
+}
+
+/**
+ * A script for the sqlite3 shell (`sqlite3 saves.db < export.sql`): the schema, then every
+ * statement with its parameters inlined, in one transaction
+ */
+export function toSqlScript(statements: readonly SqlStatement[]): string {
+  const lines = statements.map(({ sql, params }) => {
+    let i = 0
+    return `${sql.replace(/\?/g, () => sqlLiteral(params[i++] ?? null))};`
+  })
+  return [...SQL_EXPORT_SCHEMA.map(sql => `${sql};`), 'BEGIN;', ...lines, 'COMMIT;', ''].join('\n')
+}
@@ -0,0 +1,222 @@
+/**
+ * Shareable team card image (the CLI's `render` subcommand)
+ * Composes party sprites, names, levels and HP bars into one RGBA image. Sprites are resolved
+ * through the embedded sprite index (the GIFs in public/sprites) and loaded by the caller, so the
+ * same renderer works from the filesystem or from fetched assets
+ */
+
+import spriteIndex from '../data/sprite_index.json'
+import { createImage, drawImage, fillRect, type Rgba, type RgbaImage, trimImage } from './image'
+import type { PokemonBase } from './PokemonBase'
+import type { SaveData } from './types'
+
+const SPRITES = new Set(spriteIndex.sprites)
+const SHINY_SPRITES = new Set(spriteIndex.shiny)
+
+/**
+ * Path of a Pokemon's sprite relative to the sprites directory ("treecko.gif",
+ * "shiny/unown-b.gif"), falling back from the form to the base species. Undefined for eggs and
+ * species without a sprite
+ */
+export function getSpritePath(pokemon: PokemonBase): string | undefined {
+  if (pokemon.isEgg) return undefined
+  const sprites = pokemon.isShiny ? SHINY_SPRITES : SPRITES
+  const name = [pokemon.formNameId, pokemon.nameId].find(id => id && sprites.has(id))
+  if (name) return `${pokemon.isShiny ? 'shiny/' : ''}${name}.gif`
+  // Shiny sprites are missing for a few species; the regular one is better than none
+  return pokemon.isShiny && pokemon.nameId && SPRITES.has(pokemon.nameId)
+    ? `${pokemon.nameId}.gif`
+    : undefined
+}
+
+// 5x7 pixel font, one hex byte per row with the leftmost pixel in bit 4; text is drawn uppercase
+const FONT: Record<string, string> = {
+  A: '0e11111f111111',
+  B: '1e11111e11111e',
+  C: '0e11101010110e',
+  D: '1c12111111121c',
+  E: '1f10101e10101f',
+  F: '1f10101e101010',
+  G: '0e11101711110f',
+  H: '1111111f111111',
+  I: '0e04040404040e',
+  J: '0702020202120c',
+  K: '11121418141211',
+  L: '1010101010101f',
+  M: '111b1515111111',
+  N: '11111915131111',
+  O: '0e11111111110e',
+  P: '1e11111e101010',
+  Q: '0e11111115120d',
+  R: '1e11111e141211',
+  S: '0f10100e01011e',
+  T: '1f040404040404',
+  U: '1111111111110e',
+  V: '11111111110a04',
+  W: '1111111515150a',
+  X: '11110a040a1111',
+  Y: '1111110a040404',
+  Z: '1f01020408101f',
+  '0': '0e11131519110e',
+  '1': '040c040404040e',
+  '2': '0e11010204081f',
+  '3': '1f02040201110e',
+  '4': '02060a121f0202',
+  '5': '1f101e0101110e',
+  '6': '0608101e11110e',
+  '7': '1f010204080808',
+  '8': '0e11110e11110e',
+  '9': '0e11110f01020c',
+  ' ': '00000000000000',
+  '.': '00000000000c0c',
+  '-': '0000001f000000',
+  '/': '00010204081000',
+  ':': '000c0c000c0c00',
+  "'": '0c040800000000',
+  '!': '04040404040004',
+  '?': '0e110102040004',
+  '♂': '0703050e12120c',
+  '♀': '0e11110e040e04',
+  '★': '04041f0e0e1b11',
+}
+
+const GLYPH_WIDTH = 6 // 5 pixels and a gap
+
+const COLORS = {
+  background: [24, 32, 48, 255],
+  panel: [40, 52, 74, 255],
+  text: [240, 244, 248, 255],
+  muted: [148, 163, 184, 255],
+  shiny: [250, 204, 21, 255],
+  hpTrack: [15, 23, 42, 255],
+  // The in-game HP bar turns yellow at half and red at a fifth
+  hpHigh: [74, 222, 128, 255],
+  hpMid: [250, 204, 21, 255],
+  hpLow: [248, 113, 113, 255],
+  placeholder: [71, 85, 105, 255],
+} as const
+
+/**
+ * Draw text with the built-in pixel font; characters it lacks are drawn as '?'
+ */
+export function drawText(
+  image: RgbaImage,
+  text: string,
+  x: number,
+  y: number,
+  color: Rgba,
+  scale = 1
+): void {
+  const chars = [...text.toUpperCase()]
+  for (let i = 0; i < chars.length; i++) {
+    const glyph = FONT[chars[i]!] ?? FONT['?']!
+    for (let row = 0; row < 7; row++) {
+      const bits = parseInt(glyph.slice(row * 2, row * 2 + 2), 16)
+      for (let col = 0; col < 5; col++) {
+        if (!(bits & (0x10 >> col))) continue
+        fillRect(image, x + (i * GLYPH_WIDTH + col) * scale, y + row * scale, scale, scale, color)
+      }
+    }
+  }
+}
+
+const PADDING = 12
+const COLUMNS = 3
+const CELL_WIDTH = 160
+const CELL_HEIGHT = 156
+const SPRITE_BOX = 96
+const HEADER_HEIGHT = 28
+
+function drawPokemonCell(
+  image: RgbaImage,
+  pokemon: PokemonBase,
+  sprite: RgbaImage | undefined,
+  x: number,
+  y: number
+) {
+  fillRect(image, x, y, CELL_WIDTH, CELL_HEIGHT, COLORS.panel)
+
+  const spriteX = x + (CELL_WIDTH - SPRITE_BOX) / 2
+  const spriteY = y + 6
+  if (sprite) {
+    // Integer scaling keeps the pixel art sharp; sprites sit on the bottom of their box
+    const trimmed = trimImage(sprite)
+    const longest = Math.max(trimmed.width, trimmed.height)
+    const fit = Math.min(2, Math.max(1, Math.floor(SPRITE_BOX / longest)))
+    drawImage(
+      image,
+      trimmed,
+      Math.round(spriteX + (SPRITE_BOX - trimmed.width * fit) / 2),
+      spriteY + SPRITE_BOX - Math.min(SPRITE_BOX, trimmed.height * fit),
+      fit
+    )
+  } else {
+    fillRect(image, spriteX + 32, spriteY + 32, 32, 32, COLORS.placeholder)
+    drawText(image, '?', spriteX + 43, spriteY + 41, COLORS.muted, 2)
+  }
+
+  const name = pokemon.isEgg ? 'Egg' : pokemon.nickname
+  const nameX = x + 8
+  drawText(image, name, nameX, y + SPRITE_BOX + 14, COLORS.text, 2)
+  if (pokemon.isShiny) {
+    drawText(image, '★', x + CELL_WIDTH - 8 - 5 * 2, y + SPRITE_BOX + 14, COLORS.shiny, 2)
+  }
+  if (pokemon.isEgg) return
+
+  const maxHp = pokemon.maxHp
+  drawText(
+    image,
+    `Lv${pokemon.level}  HP ${pokemon.currentHp}/${maxHp}`,
+    nameX,
+    y + SPRITE_BOX + 32,
+    COLORS.muted
+  )
+  const ratio = maxHp > 0 ? Math.min(1, pokemon.currentHp / maxHp) : 0
+  const barWidth = CELL_WIDTH - 16
+  const barY = y + SPRITE_BOX + 44
+  fillRect(image, nameX, barY, barWidth, 6, COLORS.hpTrack)
+  const hpColor = ratio > 0.5 ? COLORS.hpHigh : ratio > 0.2 ? COLORS.hpMid : COLORS.hpLow
+  fillRect(image, nameX, barY, Math.ceil(barWidth * ratio), 6, hpColor)
+}
+
+/**
+ * Render the party as a team card: a header with the trainer, then one cell per party member
+ * (up to three per row). `loadSprite` receives getSpritePath results and may return undefined,
+ * which draws a placeholder
+ */
+export function renderTeamCard(
+  saveData: SaveData,
+  loadSprite: (spritePath: string) => RgbaImage | undefined
+): RgbaImage {
+  const party = saveData.party_pokemon
+  const rows = Math.max(1, Math.ceil(party.length / COLUMNS))
+  const image = createImage(
+    PADDING + COLUMNS * (CELL_WIDTH + PADDING),
+    PADDING + HEADER_HEIGHT + rows * (CELL_HEIGHT + PADDING),
+    COLORS.background
+  )
+
+  drawText(image, saveData.player_name, PADDING, PADDING, COLORS.text, 2)
+  const { hours, minutes } = saveData.play_time
+  const playTime = `${hours}:${minutes.toString().padStart(2, '0')}`
+  drawText(
+    image,
+    playTime,
+    image.width - PADDING - playTime.length * GLYPH_WIDTH * 2,
+    PADDING,
+    COLORS.muted,
+    2
+  )
+
+  party.forEach((pokemon, i) => {
+    const spritePath = getSpritePath(pokemon)
+    drawPokemonCell(
+      image,
+      pokemon,
+      spritePath ? loadSprite(spritePath) : undefined,
+      PADDING + (i % COLUMNS) * (CELL_WIDTH + PADDING),
+      PADDING + HEADER_HEIGHT + Math.floor(i / COLUMNS) * (CELL_HEIGHT + PADDING)
+    )
+  })
+  return image
+}
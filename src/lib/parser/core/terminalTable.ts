@@ -0,0 +1,152 @@
+/**
+ * Terminal tables (the CLI's party and PC box summaries)
+ * Columns are as wide as their longest value. When that is wider than the terminal, the least
+ * important columns are left out and then text columns are cut short with …, so rows don't wrap.
+ * Widths count terminal cells, so emoji and full-width characters don't push columns out of line
+ */
+
+export interface TableColumn<T> {
+  readonly label: string
+  readonly value: (row: T, index: number) => string
+  readonly align?: 'left' | 'right'
+  /** Values may be cut down to this many cells (or the label's width) when the table doesn't fit */
+  readonly minWidth?: number
+  /**
+   * Columns are left out lowest priority first when the table doesn't fit; columns without a
+   * priority are always shown
+   */
+  readonly priority?: number
+  /** Also shown on rows with a note */
+  readonly key?: boolean
+  /** ANSI SGR parameters for the cell, e.g. '32' for green */
+  readonly color?: (row: T, index: number) => string | undefined
+}
+
+export interface TableOptions<T> {
+  /** Terminal width in cells (default: unlimited) */
+  readonly width?: number
+  /** Whether to use ANSI colors */
+  readonly color?: boolean
+  /** ANSI SGR parameters for a whole row */
+  readonly rowColor?: (row: T, index: number) => string | undefined
+  /** Text shown after the key columns instead of the other cells, e.g. for eggs */
+  readonly note?: (row: T, index: number) => string | undefined
+}
+
+const GAP = '  '
+// eslint-disable-next-line no-control-regex
+const ANSI = /\x1b\[[0-9;]*m/g
+const ZERO_WIDTH = /^[\p{M}\u200b-\u200f\ufe00-\ufe0f]$/u
+const EMOJI = /^\p{Emoji_Presentation}$/u
+
+// East Asian wide and full-width ranges; terminals give these two cells
+const WIDE_RANGES: readonly (readonly [number, number])[] = [
+  [0x1100, 0x115f],
+  [0x2e80, 0xa4cf],
+  [0xac00, 0xd7a3],
+  [0xf900, 0xfaff],
+  [0xfe30, 0xfe4f],
+  [0xff00, 0xff60],
+  [0xffe0, 0xffe6],
+  [0x20000, 0x3fffd],
+]
+
+function charWidth(char: string): number {
+  if (ZERO_WIDTH.test(char)) return 0
+  const code = char.codePointAt(0)!
+  if (EMOJI.test(char) || WIDE_RANGES.some(([start, end]) => code >= start && code <= end)) {
+    return 2
+  }
+  return 1
+}
+
+/** The number of terminal cells text takes up, ignoring ANSI color codes */
+export function displayWidth(text: string): number {
+  let width = 0
+  for (const char of text.replace(ANSI, '')) width += charWidth(char)
+  return width
+}
+
+/** Cut text to at most `width` cells, ending in … when anything was cut */
+export function truncateText(text: string, width: number): string {
+  if (displayWidth(text) <= width) return text
+  let result = ''
+  let used = 0
+  for (const char of text) {
+    const next = charWidth(char)
+    if (used + next > width - 1) break
+    result += char
+    used += next
+  }
+  return `${result}…`
+}
+
+function padText(text: string, width: number, align: 'left' | 'right' = 'left'): string {
+  const padding = ' '.repeat(Math.max(0, width - displayWidth(text)))
+  return align === 'right' ? padding + text : text + padding
+}
+
+const paint = (codes: readonly (string | undefined)[], text: string) => {
+  const sgr = codes.filter(Boolean).join(';')
+  return sgr ? `\x1b[${sgr}m${text}\x1b[0m` : text
+}
+
+const tableWidth = (widths: readonly number[]) =>
+  widths.reduce((sum, width) => sum + width, 0) + GAP.length * Math.max(0, widths.length - 1)
+
+/**
+ * The table as lines: a header, a rule and one line per row
+ */
+export function renderTable<T>(
+  rows: readonly T[],
+  columns: readonly TableColumn<T>[],
+  { width = Infinity, color = false, rowColor, note }: TableOptions<T> = {}
+): string[] {
+  const notes = rows.map((row, i) => note?.(row, i))
+  const cells = columns.map(column =>
+    rows.map((row, i) => (notes[i] === undefined || column.key ? column.value(row, i) : ''))
+  )
+  const natural = columns.map((column, c) =>
+    Math.max(displayWidth(column.label), ...cells[c]!.map(displayWidth))
+  )
+
+  // Leave out columns by priority until the table fits, then cut down the text columns
+  const widths = [...natural]
+  const shown = columns.map((_, c) => c)
+  const shownWidth = () => tableWidth(shown.map(c => widths[c]!))
+  const droppable = shown
+    .filter(c => columns[c]!.priority !== undefined)
+    .sort((a, b) => columns[a]!.priority! - columns[b]!.priority!)
+  while (shownWidth() > width && droppable.length > 0) {
+    shown.splice(shown.indexOf(droppable.shift()!), 1)
+  }
+  for (const c of shown) {
+    const { label, minWidth } = columns[c]!
+    const excess = shownWidth() - width
+    if (minWidth === undefined || excess <= 0) continue
+    widths[c] = Math.max(minWidth, displayWidth(label), widths[c]! - excess)
+  }
+
+  const header = shown
+    .map(c => padText(columns[c]!.label, widths[c]!, columns[c]!.align))
+    .join(GAP)
+    .trimEnd()
+  const lines = [color ? paint(['1'], header) : header, '-'.repeat(shownWidth())]
+  rows.forEach((row, i) => {
+    const visible = notes[i] === undefined ? shown : shown.filter(c => columns[c]!.key)
+    const rowCodes = color ? rowColor?.(row, i) : undefined
+    const parts = visible.map(c => {
+      const column = columns[c]!
+      const text = padText(truncateText(cells[c]![i]!, widths[c]!), widths[c]!, column.align)
+      return color ? paint([rowCodes, column.color?.(row, i)], text) : text
+    })
+    const rowNote = notes[i]
+    if (rowNote !== undefined) {
+      const used = tableWidth(visible.map(c => widths[c]!)) + GAP.length
+      const text = truncateText(rowNote, Math.max(1, width - used))
+      parts.push(color ? paint([rowCodes], text) : text)
+    }
+    lines.push(parts.join(GAP).trimEnd())
+  })
+  return lines
+}
@@ -0,0 +1,156 @@
+/**
+ * Trainer card snapshot export
+ * Combines trainer info, badges, play time and the current party into a single versioned artifact
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import type {
+  PlayTimeData,
+  SaveData,
+  TrainerCard,
+  TrainerCardPokemon,
+  TrainerCardSnapshot,
+} from './types'
+
+/**
+ * Bump whenever the snapshot shape changes so older exports can still be told apart
+ */
+export const TRAINER_CARD_SNAPSHOT_VERSION = 1
+
+export interface TrainerCardInput {
+  readonly game: string
+  readonly playerName: string
+  readonly gender: 'male' | 'female'
+  readonly trainerId: number
+  readonly secretId: number
+  readonly badges: readonly boolean[]
+  readonly playTime: PlayTimeData
+  readonly party: readonly PokemonBase[]
+  readonly exportedAt?: Date
+}
+
+/**
+ * Convert a party Pokemon into the plain snapshot representation
+ */
+function toTrainerCardPokemon(pokemon: PokemonBase): TrainerCardPokemon {
+  return {
+    species_id: pokemon.speciesId,
+    name_id: pokemon.nameId,
+    nickname: pokemon.nickname,
+    level: pokemon.level,
+    nature: pokemon.nature,
+    item: pokemon.item,
+    is_shiny: pokemon.isShiny,
+    moves: [...pokemon.moveIds],
+  }
+}
+
+/**
+ * Build a trainer card snapshot from parsed save data
+ */
+export function createTrainerCardSnapshot(input: TrainerCardInput): TrainerCardSnapshot {
+  return {
+    version: TRAINER_CARD_SNAPSHOT_VERSION,
+    exported_at: (input.exportedAt ?? new Date()).toISOString(),
+    game: input.game,
+    trainer: {
+      name: input.playerName,
+      gender: input.gender,
+      trainer_id: input.trainerId,
+      secret_id: input.secretId,
+    },
+    badges: [...input.badges],
+    badge_count: input.badges.filter(Boolean).length,
+    play_time: { ...input.playTime },
+    party: input.party.map(toTrainerCardPokemon),
+  }
+}
+
+/**
+ * Collect the fields shown on the in-game trainer card from parsed save data
+ */
+export function createTrainerCard(game: string, saveData: SaveData): TrainerCard {
+  const { trainer, currency, pokedex, progress, game_stats: stats } = saveData
+  const badges = progress?.badges ?? []
+  // first_hof_play_time is packed as (hours << 16) | (minutes << 8) | seconds
+  const debut = stats?.first_hof_play_time ?? 0
+
+  return {
+    game,
+    name: saveData.player_name,
+    gender: trainer?.gender ?? null,
+    trainer_id: trainer?.trainer_id ?? null,
+    money: currency?.money ?? null,
+    pokedex: pokedex ? { seen: pokedex.seen_count, caught: pokedex.caught_count } : null,
+    play_time: { ...saveData.play_time },
+    badges: [...badges],
+    badge_count: badges.filter(Boolean).length,
+    hall_of_fame_debut:
+      debut === 0
+        ? null
+        : { hours: debut >>> 16, minutes: (debut >> 8) & 0xff, seconds: debut & 0xff },
+    link_battles: stats
+      ? { wins: stats.link_battle_wins, losses: stats.link_battle_losses }
+      : null,
+    trades: stats?.pokemon_trades ?? null,
+  }
+}
+
+/**
+ * Serialize a snapshot to pretty-printed JSON for download or file output
+ */
+export function serializeTrainerCardSnapshot(snapshot: TrainerCardSnapshot): string {
+  return JSON.stringify(snapshot, null, 2)
+}
+
+/**
+ * Parse a previously exported snapshot, rejecting unknown versions
+ */
+export function parseTrainerCardSnapshot(json: string): TrainerCardSnapshot {
+  const snapshot = JSON.parse(json) as TrainerCardSnapshot
+  if (typeof snapshot.version !== 'number' || snapshot.version > TRAINER_CARD_SNAPSHOT_VERSION) {
+    throw new Error(`Unsupported trainer card snapshot version: ${String(snapshot.version)}`)
+  }
+  return snapshot
+}
+
+export interface TrainerCardComparison {
+  readonly play_time_seconds: number
+  readonly badges_gained: readonly number[]
+  readonly party_added: readonly TrainerCardPokemon[]
+  readonly party_removed: readonly TrainerCardPokemon[]
+  readonly level_ups: readonly { species_id: number; from: number; to: number }[]
+}
+
+function playTimeToSeconds({ hours, minutes, seconds }: PlayTimeData): number {
+  return hours * 3600 + minutes * 60 + seconds
+}
+
+/**
+ * Compare two snapshots taken over the course of a run
+ * Party members are matched by species and nickname since snapshots carry no personality value
+ */
+export function compareTrainerCardSnapshots(
+  before: TrainerCardSnapshot,
+  after: TrainerCardSnapshot
+): TrainerCardComparison {
+  const key = (p: TrainerCardPokemon) => `${p.species_id}:${p.nickname}`
+  const beforeParty = new Map(before.party.map(p => [key(p), p]))
+  const afterParty = new Map(after.party.map(p => [key(p), p]))
+
+  const levelUps: { species_id: number; from: number; to: number }[] = []
+  for (const [k, next] of afterParty) {
+    const prev = beforeParty.get(k)
+    if (prev && next.level > prev.level) {
+      levelUps.push({ species_id: next.species_id, from: prev.level, to: next.level })
+    }
+  }
+
+  return {
+    play_time_seconds: playTimeToSeconds(after.play_time) - playTimeToSeconds(before.play_time),
+    badges_gained: after.badges.flatMap((has, i) => (has && !before.badges[i] ? [i + 1] : [])),
+    party_added: after.party.filter(p => !beforeParty.has(key(p))),
+    party_removed: before.party.filter(p => !afterParty.has(key(p))),
+    level_ups: levelUps,
+  }
+}
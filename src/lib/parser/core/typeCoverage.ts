@@ -0,0 +1,246 @@
+/**
+ * Party type coverage (the CLI's `coverage` subcommand)
+ * Offense: how hard the party's damaging moves can hit each type. Defense: how much damage each
+ * attacking type deals to every member. Uses the Gen 3 type chart (no Fairy; Steel still resists
+ * Ghost and Dark)
+ */
+
+import { getMoveType, isTypedAttack } from './moves'
+import type { PokemonBase } from './PokemonBase'
+import { getSpeciesName, type PokemonType } from './species'
+import type { GameConfig, SaveData } from './types'
+
+// In the games' type order
+export const POKEMON_TYPES: readonly PokemonType[] = [
+  'normal',
+  'fighting',
+  'flying',
+  'poison',
+  'ground',
+  'rock',
+  'bug',
+  'ghost',
+  'steel',
+  'fire',
+  'water',
+  'grass',
+  'electric',
+  'psychic',
+  'ice',
+  'dragon',
+  'dark',
+]
+
+// Attacking type -> defending types it doesn't hit for neutral damage
+const TYPE_CHART: Readonly<Record<PokemonType, Partial<Record<PokemonType, number>>>> = {
+  normal: { rock: 0.5, ghost: 0, steel: 0.5 },
+  fighting: {
+    normal: 2,
+    flying: 0.5,
+    poison: 0.5,
+    rock: 2,
+    bug: 0.5,
+    ghost: 0,
+    steel: 2,
+    psychic: 0.5,
+    ice: 2,
+    dark: 2,
+  },
+  flying: { fighting: 2, rock: 0.5, bug: 2, steel: 0.5, grass: 2, electric: 0.5 },
+  poison: { poison: 0.5, ground: 0.5, rock: 0.5, ghost: 0.5, steel: 0, grass: 2 },
+  ground: {
+    flying: 0,
+    poison: 2,
+    rock: 2,
+    bug: 0.5,
+    steel: 2,
+    fire: 2,
+    grass: 0.5,
+    electric: 2,
+  },
+  rock: { fighting: 0.5, flying: 2, ground: 0.5, bug: 2, steel: 0.5, fire: 2, ice: 2 },
+  bug: {
+    fighting: 0.5,
+    flying: 0.5,
+    poison: 0.5,
+    ghost: 0.5,
+    steel: 0.5,
+    fire: 0.5,
+    grass: 2,
+    psychic: 2,
+    dark: 2,
+  },
+  ghost: { normal: 0, ghost: 2, steel: 0.5, psychic: 2, dark: 0.5 },
+  steel: { rock: 2, steel: 0.5, fire: 0.5, water: 0.5, electric: 0.5, ice: 2 },
+  fire: { rock: 0.5, bug: 2, steel: 2, fire: 0.5, water: 0.5, grass: 2, ice: 2, dragon: 0.5 },
+  water: { ground: 2, rock: 2, fire: 2, water: 0.5, grass: 0.5, dragon: 0.5 },
+  grass: {
+    flying: 0.5,
+    poison: 0.5,
+    ground: 2,
+    rock: 2,
+    bug: 0.5,
+    steel: 0.5,
+    fire: 0.5,
+    water: 2,
+    grass: 0.5,
+    dragon: 0.5,
+  },
+  electric: { flying: 2, ground: 0, water: 2, grass: 0.5, electric: 0.5, dragon: 0.5 },
+  psychic: { fighting: 2, poison: 2, steel: 0.5, psychic: 0.5, dark: 0 },
+  ice: { flying: 2, ground: 2, steel: 0.5, fire: 0.5, water: 0.5, grass: 2, ice: 0.5, dragon: 2 },
+  dragon: { steel: 0.5, dragon: 2 },
+  dark: { fighting: 0.5, ghost: 2, steel: 0.5, psychic: 2, dark: 0.5 },
+}
+
+/**
+ * Damage multiplier of an attacking type against a Pokemon with the given types (0 to 4)
+ */
+export function getTypeEffectiveness(
+  attack: PokemonType,
+  defender: readonly PokemonType[]
+): number {
+  return defender.reduce((multiplier, type) => multiplier * (TYPE_CHART[attack][type] ?? 1), 1)
+}
+
+export interface CoverageMember {
+  readonly nickname: string
+  readonly species_name: string | null
+  readonly types: readonly PokemonType[]
+  /** Types of its damaging moves, with Hidden Power's type worked out from the IVs */
+  readonly attack_types: readonly PokemonType[]
+}
+
+export interface OffensiveCoverage {
+  /** Defending type */
+  readonly type: PokemonType
+  /** Best multiplier each member's moves reach against it; 0 without a damaging move */
+  readonly members: readonly number[]
+  readonly best: number
+}
+
+export interface DefensiveCoverage {
+  /** Attacking type */
+  readonly type: PokemonType
+  /** Multiplier of the attack against each member */
+  readonly members: readonly number[]
+  readonly weak: number
+  readonly resist: number
+  readonly immune: number
+}
+
+export interface TypeCoverage {
+  /** Party members in order, eggs left out */
+  readonly members: readonly CoverageMember[]
+  readonly offense: readonly OffensiveCoverage[]
+  readonly defense: readonly DefensiveCoverage[]
+  /** Types no damaging move in the party hits super effectively */
+  readonly uncovered: readonly PokemonType[]
+  /** Attacking types more members are weak to than resist or are immune to */
+  readonly shared_weaknesses: readonly PokemonType[]
+}
+
+const HIDDEN_POWER = 237
+
+function attackTypes(pokemon: PokemonBase): PokemonType[] {
+  const types = pokemon.moveIds
+    .filter(isTypedAttack)
+    .map(moveId => (moveId === HIDDEN_POWER ? pokemon.hiddenPower.type : getMoveType(moveId)!))
+  return [...new Set(types)]
+}
+
+/**
+ * Offensive and defensive type coverage of the party. Needs Gen 3 species and move data, so
+ * hacks with newer moves and species are rejected
+ */
+export function analyzeTypeCoverage(saveData: SaveData, config: GameConfig): TypeCoverage {
+  if (config.usesGen3SpeciesData === false) {
+    throw new Error(
+      `Type coverage needs Gen 3 species and move data, which ${config.name} does not use`
+    )
+  }
+
+  const members: CoverageMember[] = saveData.party_pokemon
+    .filter(pokemon => !pokemon.isEgg && pokemon.types)
+    .map(pokemon => ({
+      nickname: pokemon.nickname,
+      species_name: getSpeciesName(pokemon.speciesId) ?? null,
+      types: pokemon.types!,
+      attack_types: attackTypes(pokemon),
+    }))
+
+  const offense = POKEMON_TYPES.map(type => {
+    const perMember = members.map(member =>
+      Math.max(0, ...member.attack_types.map(attack => getTypeEffectiveness(attack, [type])))
+    )
+    return { type, members: perMember, best: Math.max(0, ...perMember) }
+  })
+  const defense = POKEMON_TYPES.map(type => {
+    const perMember = members.map(member => getTypeEffectiveness(type, member.types))
+    return {
+      type,
+      members: perMember,
+      weak: perMember.filter(multiplier => multiplier > 1).length,
+      resist: perMember.filter(multiplier => multiplier > 0 && multiplier < 1).length,
+      immune: perMember.filter(multiplier => multiplier === 0).length,
+    }
+  })
+
+  return {
+    members,
+    offense,
+    defense,
+    uncovered: offense.filter(entry => entry.best < 2).map(entry => entry.type),
+    shared_weaknesses: defense
+      .filter(entry => entry.weak > entry.resist + entry.immune)
+      .map(entry => entry.type),
+  }
+}
+
+const MULTIPLIER_LABELS: Record<number, string> = {
+  0: '0',
+  0.25: '¼',
+  0.5: '½',
+  1: '·',
+  2: '2x',
+  4: '4x',
+}
+
+/**
+ * Coverage as two text matrices: damage each type takes from the party's best moves, and damage
+ * each attacking type deals to every member with weak/resist totals
+ */
+export function formatCoverageMatrix(coverage: TypeCoverage): string {
+  const names = coverage.members.map(member => member.nickname.slice(0, 10))
+  const widths = names.map(name => Math.max(name.length, 3) + 2)
+  const label = (multiplier: number) => MULTIPLIER_LABELS[multiplier] ?? `${multiplier}x`
+  const row = (first: string, cells: readonly string[], extra: readonly string[]) =>
+    [
+      first.padEnd(10),
+      ...cells.map((cell, i) => cell.padStart(widths[i]!)),
+      ...extra.map(cell => cell.padStart(8)),
+    ]
+      .join('')
+      .trimEnd()
+
+  const lines = [
+    'Offense (best multiplier against each type)',
+    row('', names, ['Best']),
+    ...coverage.offense.map(entry =>
+      row(entry.type, entry.members.map(label), [label(entry.best)])
+    ),
+    '',
+    'Defense (damage taken from each attacking type)',
+    row('', names, ['Weak', 'Resist']),
+    ...coverage.defense.map(entry =>
+      row(entry.type, entry.members.map(label), [
+        String(entry.weak),
+        String(entry.resist + entry.immune),
+      ])
+    ),
+    '',
+    `Not hit super effectively: ${coverage.uncovered.join(', ') || 'none'}`,
+    `Shared weaknesses: ${coverage.shared_weaknesses.join(', ') || 'none'}`,
+  ]
+  return lines.join('\n')
+}
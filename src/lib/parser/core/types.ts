@@ -21,6 +21,14 @@ export interface PokemonStats {
   readonly sp_defense: number
 }
 
+export type StatName = keyof PokemonStats
+
+// Stats a nature raises and lowers; both null for neutral natures
+export interface NatureEffect {
+  readonly plus: StatName | null
+  readonly minus: StatName | null
+}
+
 export interface MoveData {
   readonly id: number
   readonly pp: number
@@ -51,6 +59,323 @@ export interface PokemonIVs {
   readonly sp_defense: number
 }
 
+export interface PokemonMarkings {
+  readonly circle: boolean
+  readonly square: boolean
+  readonly triangle: boolean
+  readonly heart: boolean
+}
+
+export type PokemonGender = 'male' | 'female' | 'genderless'
+
+export interface PokemonContestStats {
+  readonly cool: number
+  readonly beauty: number
+  readonly cute: number
+  readonly smart: number
+  readonly tough: number
+  readonly sheen: number
+}
+
+// Bag pockets in SaveBlock1 order
+export type BagPocketName = 'items' | 'keyItems' | 'pokeBalls' | 'tmHm' | 'berries'
+
+export interface BagSlot {
+  readonly pocket: BagPocketName
+  readonly slot: number
+  readonly rawItemId: number
+  readonly quantity: number
+}
+
+// Gym badges and key story milestones read from the event flags
+export interface StoryProgress {
+  /** Gym badges in order (see HOENN_BADGE_NAMES) */
+  readonly badges: readonly boolean[]
+  readonly badge_count: number
+  readonly received_starter: boolean
+  readonly received_pokedex: boolean
+  readonly received_pokenav: boolean
+  readonly running_shoes: boolean
+  readonly national_dex: boolean
+  /** Entered the Hall of Fame after beating the Elite Four */
+  readonly game_cleared: boolean
+}
+
+// Milestones setStoryProgress can change; the starter flag is left to the game
+export type StoryProgressUpdate = Partial<
+  Omit<StoryProgress, 'badge_count' | 'received_starter'>
+>
+
+// Emerald's rival is the neighbour of the opposite gender; the name is fixed rather than saved
+export interface Rival {
+  readonly name: 'BRENDAN' | 'MAY'
+  readonly gender: 'male' | 'female'
+}
+
+// Starter picked from Birch's bag (VAR_STARTER_MON)
+export interface Starter {
+  readonly species_id: number // National Dex number
+  readonly name: string
+}
+
+// Minor SaveBlock1 state kept in event flags and variables
+export interface Misc {
+  /** Lottery Corner ticket number compared against the daily draw (0-65535) */
+  readonly lottery_number: number
+  /** Saved inside the Safari Zone; steps and Safari Balls left are not kept in the save */
+  readonly safari_mode: boolean
+  /** Steps left on the active Repel */
+  readonly repel_steps: number
+  /** Volcanic ash in the Soot Sack, in steps */
+  readonly ash_collected: number
+}
+
+// A map by its group and number as the game stores it in warps
+export interface MapLocation {
+  readonly map_group: number
+  readonly map_num: number
+  /** Town, route or "<place> (Indoors)"; null for dungeons and other unnamed maps */
+  readonly map_name: string | null
+}
+
+// Where the player saved: the current map, tile coordinates on it and the last Pokemon Center used
+export interface CurrentLocation extends MapLocation {
+  readonly x: number
+  readonly y: number
+  readonly last_heal_location: MapLocation
+}
+
+// Roaming Latios/Latias; its route is kept in RAM only and re-rolled whenever the save is loaded
+export interface Roamer {
+  readonly species_id: number // National Dex number
+  /** False until the Elite Four is beaten, and again once it is caught or defeated */
+  readonly active: boolean
+  readonly level: number
+  readonly hp: number
+  readonly status: number
+  readonly personality: number
+  /** HP, Attack, Defense, Speed, Sp. Attack, Sp. Defense */
+  readonly ivs: readonly number[]
+}
+
+// An optional save area that is only written once the related feature has been used
+export interface OptionalSectionStatus {
+  readonly present: boolean
+  /** Checksum result; undefined where the game's own check isn't reproduced */
+  readonly valid?: boolean
+}
+
+// Sectors 28-31 outside the two save slots, plus the Mystery Gift areas of SaveBlock1
+export interface OptionalSections {
+  readonly hall_of_fame: OptionalSectionStatus
+  /** e-Reader Trainer Hill data */
+  readonly trainer_hill: OptionalSectionStatus
+  readonly recorded_battle: OptionalSectionStatus
+  readonly wonder_news: OptionalSectionStatus
+  readonly wonder_card: OptionalSectionStatus
+}
+
+// Wonder Card received through Mystery Gift
+export interface WonderCard {
+  readonly card_id: number
+  /** Event flag the card's gift sets once claimed */
+  readonly flag_id: number
+  readonly icon_species_id: number // National Dex number
+  readonly title: string
+  readonly subtitle: string
+  readonly body: readonly string[]
+  readonly footer: readonly string[]
+}
+
+// Mystery Gift / Mystery Event state: menu unlocks, stored card and news, event tickets
+export interface MysteryGift {
+  readonly mystery_gift_enabled: boolean
+  readonly mystery_event_enabled: boolean
+  readonly wonder_card: WonderCard | null
+  readonly wonder_news_title: string | null
+  /** Ferry destinations unlocked by event tickets */
+  readonly event_tickets: {
+    readonly eon_ticket: boolean
+    readonly aurora_ticket: boolean
+    readonly old_sea_map: boolean
+    readonly mystic_ticket: boolean
+  }
+  /** e-Reader data: the Enigma Berry's name (null if none) and whether an event script is stored */
+  readonly enigma_berry: string | null
+  readonly ram_script: boolean
+}
+
+// Decoration placed in a secret base; x/y are tile coordinates inside the base
+export interface SecretBaseDecoration {
+  readonly decoration_id: number
+  readonly x: number
+  readonly y: number
+}
+
+// A Pokemon from the owner's party, recorded for the secret base battle
+export interface SecretBaseMember {
+  readonly species_id: number // National Dex number
+  readonly level: number
+  readonly item_id: number
+  readonly moves: readonly number[]
+}
+
+export interface SecretBase {
+  /** Entrance the base was built at (the game's SECRET_BASE_* location ID) */
+  readonly location_id: number
+  readonly owner_name: string
+  readonly owner_gender: 'male' | 'female'
+  /** Owner's full 32-bit trainer ID */
+  readonly owner_ot_id: number
+  /** Registered in the PC so it survives record mixing */
+  readonly registered: boolean
+  readonly times_entered: number
+  readonly decorations: readonly SecretBaseDecoration[]
+  readonly party: readonly SecretBaseMember[]
+}
+
+// The player's own base (null if none is built) and bases received through record mixing
+export interface SecretBases {
+  readonly own: SecretBase | null
+  readonly friends: readonly SecretBase[]
+}
+
+// Mail message; Easy Chat words are kept as raw IDs ((group << 9) | index)
+export interface MailMessage {
+  /** 0-based mail slot: 0-5 are reserved for party Pokemon, 6-15 are the PC mailbox */
+  readonly slot: number
+  /** 1-based party slot of the Pokemon holding this mail */
+  readonly held_by?: number
+  /** Nine words in message order; null for blank words */
+  readonly words: readonly (number | null)[]
+  readonly sender: string
+  /** Sender's full 32-bit trainer ID */
+  readonly sender_ot_id: number
+  /** National Dex number of the Pokemon shown on the mail */
+  readonly species_id: number
+  readonly item_id: number
+  readonly item_name: string
+}
+
+// A Pokemon left at the daycare; it gains one experience point per step
+export interface DaycareSlot {
+  readonly pokemon: PokemonBase
+  readonly steps: number
+  /** Level the Pokemon will be when withdrawn */
+  readonly level_on_withdraw: number
+}
+
+export interface Daycare {
+  /** Both daycare slots, null when empty */
+  readonly slots: readonly (DaycareSlot | null)[]
+  /** The daycare man is holding an egg (its personality is already decided) */
+  readonly egg_pending: boolean
+  readonly offspring_personality: number
+  /** Steps toward the next egg check (one every 256 steps) */
+  readonly step_counter: number
+}
+
+// Options menu settings from SaveBlock2; out-of-range values decode as 'unknown'
+export interface GameOptions {
+  readonly text_speed: 'slow' | 'mid' | 'fast' | 'unknown'
+  /** Battle animations on */
+  readonly battle_scene: boolean
+  readonly battle_style: 'shift' | 'set'
+  readonly sound: 'mono' | 'stereo'
+  readonly button_mode: 'normal' | 'lr' | 'l=a' | 'unknown'
+  /** 0-based window frame style (the menu shows TYPE 1-20) */
+  readonly frame_type: number
+}
+
+// Money and coins are decrypted with the SaveBlock2 security key
+export interface Currency {
+  readonly money: number
+  readonly coins: number
+  readonly battle_points: number
+}
+
+// Emerald's caps (MAX_MONEY, MAX_COINS, MAX_BATTLE_FRONTIER_POINTS)
+export const VANILLA_CURRENCY_LIMITS: Currency = {
+  money: 999999,
+  coins: 9999,
+  battle_points: 9999,
+}
+
+// Pokedex progress: flags are indexed by National Dex number - 1
+export interface PokedexData {
+  readonly seen: readonly boolean[]
+  readonly caught: readonly boolean[]
+  readonly seen_count: number
+  readonly caught_count: number
+}
+
+// Bag entry with the item resolved through the game's item mapping
+export interface BagItem {
+  /** 0-based slot within the pocket */
+  readonly slot: number
+  /** Mapped item ID (the raw ID when the item is unmapped) */
+  readonly item_id: number
+  readonly raw_item_id: number
+  readonly id_name?: string
+  readonly name: string
+  readonly quantity: number
+}
+
+// Non-empty bag slots per pocket, in pocket order
+export type Bag = Readonly<Record<BagPocketName, readonly BagItem[]>>
+
+// Shiny classification with the rule the active game used to decide it
+export interface ShinyInfo {
+  /** Value the game checks: the personality/OT XOR, or the stored shiny byte for hacks */
+  readonly value: number
+  /** XOR threshold (shiny when value is below it); undefined when shininess is stored directly */
+  readonly threshold?: number
+  /** Wild encounter odds as "1 in N" for threshold-based games */
+  readonly odds?: number
+  readonly is_shiny: boolean
+  readonly is_radiant: boolean
+}
+
+// Structured validation warnings
+export type WarningSeverity = 'info' | 'warning' | 'error'
+
+export interface SaveWarning {
+  readonly code: string
+  readonly severity: WarningSeverity
+  readonly message: string
+  readonly context?: Readonly<Record<string, string | number>>
+}
+
+// Findings of the Gen 3 legality checks for one Pokemon
+export interface LegalityReport {
+  /** False when any issue has error or warning severity */
+  readonly legal: boolean
+  readonly issues: readonly SaveWarning[]
+}
+
+// Why a non-empty party slot was left out of party_pokemon
+export type SkippedSlotReason =
+  | 'bad-egg'
+  | 'checksum-mismatch'
+  | 'species-out-of-range'
+  | 'level-out-of-range'
+
+export interface SkippedSlot {
+  /** 1-based party slot, or box slot when `box` is set */
+  readonly slot: number
+  /** 1-based PC box; absent for party slots */
+  readonly box?: number
+  readonly reason: SkippedSlotReason
+  readonly message: string
+}
+
+// PC box name and wallpaper as shown in the storage system
+export interface BoxMetadata {
+  readonly name: string
+  readonly wallpaper: number
+  readonly wallpaper_name: string
+}
+
 // Sector information
 export interface SectorInfo {
   readonly id: number
@@ -59,18 +384,176 @@ export interface SectorInfo {
   readonly valid: boolean
 }
 
+// What repairSave did with a broken sector of the active slot
+export interface SectorRepair {
+  readonly sector_id: number
+  /** Physical sector index in the file */
+  readonly sector_index: number
+  /**
+   * checksum-fixed: the footer was intact, so the data was kept and its checksum recomputed;
+   * restored-from-backup: copied from the other slot's (older) save;
+   * unrecoverable: the backup slot has no valid copy either
+   */
+  readonly action: 'checksum-fixed' | 'restored-from-backup' | 'unrecoverable'
+}
+
+export interface SaveRepairResult {
+  readonly data: Uint8Array
+  readonly repairs: readonly SectorRepair[]
+}
+
+// Raw buffers of the active slot: SaveBlock1 (sectors 1-4), SaveBlock2 (sector 0), PC storage
+export interface SaveBlocks {
+  readonly saveblock1: Uint8Array
+  readonly saveblock2: Uint8Array
+  readonly pokemonStorage: Uint8Array
+}
+
+// One of the two alternating save slots; the game writes to the older one each time it saves
+export interface SaveSlot {
+  readonly slot: 1 | 2
+  /** Save counter from the sector footers; the higher one is the newer save */
+  readonly counter: number
+  readonly data: SaveData
+}
+
+export interface SaveSlots {
+  readonly active: 1 | 2
+  /** Null when the slot holds no valid sectors (e.g. a game saved only once) */
+  readonly slot1: SaveSlot | null
+  readonly slot2: SaveSlot | null
+}
+
 // Complete save data structure
 export interface SaveData {
   readonly party_pokemon: readonly PokemonBase[]
   readonly player_name: string
   readonly play_time: PlayTimeData
+  readonly trainer?: TrainerInfo // Undefined for memory mode
   readonly active_slot: number
   readonly sector_map?: ReadonlyMap<number, number> // Undefined for memory mode
   readonly rawSaveData?: Uint8Array | null // Undefined for memory mode
+  readonly warnings?: readonly SaveWarning[] // Validation findings collected while parsing
+  readonly skipped_slots?: readonly SkippedSlot[] // Party/box slots dropped by the sanity checks
+  readonly bag?: Bag // File mode with a mapped SaveBlock layout
+  readonly pokedex?: PokedexData // File mode with a mapped SaveBlock layout
+  readonly progress?: StoryProgress // File mode with a mapped SaveBlock layout
+  readonly currency?: Currency // File mode with a mapped SaveBlock layout
+  readonly options?: GameOptions // File mode with a mapped SaveBlock layout
+  readonly daycare?: Daycare // File mode with a mapped SaveBlock layout
+  readonly mail?: readonly MailMessage[] // Non-empty mail slots (held and PC mailbox)
+  readonly secret_bases?: SecretBases // File mode with a mapped SaveBlock layout
+  readonly mystery_gift?: MysteryGift // File mode with a mapped SaveBlock layout
+  readonly game_stats?: GameStats // File mode with a mapped SaveBlock layout
+  readonly roamer?: Roamer // File mode with a mapped SaveBlock layout
+  readonly location?: CurrentLocation // File mode with a mapped SaveBlock layout
+  readonly misc?: Misc // File mode with a mapped SaveBlock layout
+  readonly rival?: Rival // File mode with a mapped SaveBlock layout
+  readonly starter?: Starter | null // Null until a starter has been chosen
+  readonly optional_sections?: OptionalSections // File mode with a mapped SaveBlock layout
+  // PC boxes in box order, 30 slots each with null for empty slots (file mode, vanilla layout)
+  readonly boxes?: readonly (readonly (PokemonBase | null)[])[]
+  readonly box_metadata?: readonly BoxMetadata[] // Same order as boxes
+  readonly current_box?: number // 0-based index of the box the PC opens to
   // Optional marker so UI can avoid heavy refetches on transient updates (undo/redo/reset)
   readonly __transient__?: boolean
 }
 
+// Lightweight save preview (file pickers, recent files) decoded without touching the PC boxes
+export interface SaveSummaryPokemon {
+  readonly species_id: number
+  readonly name_id?: string
+  readonly level: number
+  readonly is_shiny: boolean
+}
+
+export interface SaveSummary {
+  readonly game: string
+  readonly player_name: string
+  readonly play_time: PlayTimeData
+  readonly badges: readonly boolean[]
+  readonly badge_count: number
+  readonly money?: number // Undefined when the config has no mapped SaveBlock layout
+  readonly pokedex?: { readonly seen: number; readonly caught: number }
+  readonly party: readonly SaveSummaryPokemon[]
+}
+
+// Trainer card snapshot (versioned export artifact)
+export interface TrainerCardPokemon {
+  readonly species_id: number
+  readonly name_id?: string
+  readonly nickname: string
+  readonly level: number
+  readonly nature: string
+  readonly item: number
+  readonly is_shiny: boolean
+  readonly moves: readonly number[]
+}
+
+// Front and back of the in-game trainer card; null where the save data lacks the SaveBlock field
+export interface TrainerCard {
+  readonly game: string
+  readonly name: string
+  readonly gender: 'male' | 'female' | null
+  readonly trainer_id: number | null
+  readonly money: number | null
+  readonly pokedex: { readonly seen: number; readonly caught: number } | null
+  readonly play_time: PlayTimeData
+  readonly badges: readonly boolean[]
+  readonly badge_count: number
+  /** Play time when the Hall of Fame was first entered; null before then */
+  readonly hall_of_fame_debut: PlayTimeData | null
+  readonly link_battles: { readonly wins: number; readonly losses: number } | null
+  readonly trades: number | null
+}
+
+// Player gender and IDs from SaveBlock2
+export interface TrainerInfo {
+  readonly gender: 'male' | 'female'
+  /** Visible 16-bit trainer ID */
+  readonly trainer_id: number
+  readonly secret_id: number
+  /** Full 32-bit OT ID (secret ID in the high half), as stored on the player's Pokemon */
+  readonly ot_id: number
+}
+
+// Trainer fields setTrainerProfile can change; omitted ones are kept
+export interface TrainerProfileUpdate {
+  /** 1-7 characters the game's charset can encode */
+  readonly name?: string
+  readonly gender?: 'male' | 'female'
+  readonly trainer_id?: number
+  readonly secret_id?: number
+}
+
+// What anonymizeSave replaced; the new player identity and how many records were rewritten
+export interface AnonymizeResult {
+  readonly player_name: string
+  readonly trainer_id: number
+  readonly secret_id: number
+  /** Party, PC and daycare Pokemon given a new OT name and ID */
+  readonly pokemon: number
+  readonly mail: number
+  /** Secret bases (own and record-mixed) given a new owner name and ID */
+  readonly secret_bases: number
+}
+
+export interface TrainerCardSnapshot {
+  readonly version: number
+  readonly exported_at: string
+  readonly game: string
+  readonly trainer: {
+    readonly name: string
+    readonly gender: 'male' | 'female'
+    readonly trainer_id: number
+    readonly secret_id: number
+  }
+  readonly badges: readonly boolean[]
+  readonly badge_count: number
+  readonly play_time: PlayTimeData
+  readonly party: readonly TrainerCardPokemon[]
+}
+
 // Mapping interfaces for ID translation
 interface BaseMapping {
   readonly name: string
@@ -89,6 +572,22 @@ export interface MoveMapping extends BaseMapping {
   readonly id: number | null // Allow null for unmapped moves
 }
 
+export interface LocationMapping extends BaseMapping {
+  readonly id: number
+}
+
+// Origin data from the misc substructure (met location/level, game of origin, ball)
+export interface PokemonOrigin {
+  readonly met_location: number
+  readonly met_location_name: string
+  readonly met_level: number
+  readonly origin_game: number
+  readonly origin_game_name: string
+  readonly pokeball: number
+  readonly pokeball_name: string
+  readonly ot_gender: 'male' | 'female'
+}
+
 /**
  * Vanilla Pokemon Emerald configuration (baseline)
  * All offsets and layouts defined here represent the vanilla game structure
@@ -101,6 +600,9 @@ export const VANILLA_POKEMON_OFFSETS = {
   nicknameLength: 10,
   otName: 0x14,
   otNameLength: 7,
+  flags: 0x13,
+  markings: 0x1b,
+  checksum: 0x1c,
   currentHp: 0x56,
   maxHp: 0x58,
   attack: 0x5a,
@@ -110,6 +612,7 @@ export const VANILLA_POKEMON_OFFSETS = {
   spDefense: 0x62,
   status: 0x50,
   level: 0x54,
+  mail: 0x55,
 }
 
 export const VANILLA_SAVE_LAYOUT = {
@@ -118,19 +621,195 @@ export const VANILLA_SAVE_LAYOUT = {
   sectorCount: 32,
   slotsPerSave: 18,
   saveBlockSize: 3968 * 4,
+  // Sectors after both slots: Hall of Fame (2 sectors), e-Reader Trainer Hill, recorded battle
+  hallOfFameSector: 28,
+  trainerHillSector: 30,
+  recordedBattleSector: 31,
   partyOffset: 0x238,
   partyCountOffset: 0x234,
+  // SaveBlock1 starts with the player's tile position, then warps (s8 group, s8 num, ...)
+  playerPosition: 0x0,
+  location: 0x4,
+  lastHealLocation: 0x1c,
   playTimeHours: 0x0e,
   playTimeMinutes: 0x10,
   playTimeSeconds: 0x11,
   playTimeMilliseconds: 0x12,
+  playerGender: 0x08,
+  trainerId: 0x0a,
+  flags: 0x1270,
+  vars: 0x139c,
+  badgeFlagStart: 0x867,
+  // Secret bases: 20 160-byte records, the player's own first
+  secretBases: 0x1a9c,
+  secretBaseCount: 20,
+  secretBaseSize: 0xa0,
+  // Mail: 6 slots for party Pokemon followed by the 10-slot PC mailbox, 36 bytes each
+  mail: 0x2be0,
+  mailCount: 16,
+  mailSize: 36,
+  // Daycare: two 0x8c-byte slots (box Pokemon, mail, u32 steps), offspring personality, counter
+  daycare: 0x3030,
+  daycareSlotSize: 0x8c,
+  daycareSlotSteps: 0x88,
+  daycareOffspringPersonality: 0x3148,
+  daycareStepCounter: 0x314c,
+  starterFlag: 0x860,
+  pokedexFlag: 0x861,
+  pokenavFlag: 0x862,
+  gameClearFlag: 0x864,
+  runningShoesFlag: 0x890,
+  // The National Dex counts as unlocked when SaveBlock2's magic byte and VAR_NATIONAL_DEX agree
+  nationalDexFlag: 0x896,
+  nationalDexVar: 0x404e,
+  pokedexMode: 0x19,
+  nationalDexMagic: 0x1a,
+  // Mystery Gift menu unlocks and the ferry flags set by event tickets
+  mysteryEventFlag: 0x8bc,
+  mysteryGiftFlag: 0x8db,
+  eonTicketFlag: 0x8c3,
+  auroraTicketFlag: 0x8d5,
+  oldSeaMapFlag: 0x8d6,
+  mysticTicketFlag: 0x8e0,
+  roamer: 0x31dc,
+  enigmaBerry: 0x31f8,
+  // Mystery Gift save: news CRC + Wonder News, card CRC + Wonder Card, metadata
+  mysteryGift: 0x322c,
+  ramScript: 0x3728,
+  safariModeFlag: 0x88c,
+  // Event variable IDs (0x4000 is the first var)
+  repelStepsVar: 0x4021,
+  starterVar: 0x4023,
+  ashGatherCountVar: 0x4048,
+  lotteryNumberVar: 0x404c,
+  encryptionKey: 0xac,
+  optionsButtonMode: 0x13,
+  options: 0x14,
+  pokedexOwned: 0x28,
+  pokedexSeen: 0x5c,
+  // SaveBlock1 mirrors of the seen flags; a species only counts as seen when all three agree
+  pokedexSeen1: 0x988,
+  pokedexSeen2: 0x3b24,
+  money: 0x490,
+  coins: 0x494,
+  battlePoints: 0xeb8,
+  // 64 u32 counters encrypted with the security key (see GAME_STAT_NAMES)
+  gameStats: 0x159c,
+  bagItems: 0x560,
+  bagItemsCount: 30,
+  bagKeyItems: 0x5d8,
+  bagKeyItemsCount: 30,
+  bagPokeBalls: 0x650,
+  bagPokeBallsCount: 16,
+  bagTmHm: 0x690,
+  bagTmHmCount: 64,
+  bagBerries: 0x790,
+  bagBerriesCount: 46,
+  // PC storage spans sectors 5-13: current box, then 14 boxes of 30 80-byte box Pokemon
+  pokemonStorageSectorStart: 5,
+  pokemonStorageSectorCount: 9,
+  currentBox: 0x0,
+  boxPokemon: 0x4,
+  boxCount: 14,
+  boxSlotCount: 30,
+  boxPokemonSize: 80,
+  boxNames: 0x8344,
+  boxNameLength: 9,
+  boxWallpapers: 0x83c2,
 }
 
+/**
+ * Hoenn gym badges in flag order
+ */
+export const HOENN_BADGE_NAMES = [
+  'Stone',
+  'Knuckle',
+  'Dynamo',
+  'Heat',
+  'Balance',
+  'Feather',
+  'Mind',
+  'Rain',
+] as const
+
+/**
+ * Starters in VAR_STARTER_MON order (National Dex numbers)
+ */
+export const HOENN_STARTERS = [252, 255, 258] as const
+
+/**
+ * Game stat counters in SaveBlock1 order (the remaining slots up to 64 are unused)
+ */
+export const GAME_STAT_NAMES = [
+  'saved_game',
+  'first_hof_play_time',
+  'started_trends',
+  'planted_berries',
+  'traded_bikes',
+  'steps',
+  'got_interviewed',
+  'total_battles',
+  'wild_battles',
+  'trainer_battles',
+  'entered_hof',
+  'pokemon_captures',
+  'fishing_encounters',
+  'hatched_eggs',
+  'evolved_pokemon',
+  'used_pokecenter',
+  'rested_at_home',
+  'entered_safari_zone',
+  'used_cut',
+  'used_rock_smash',
+  'moved_secret_base',
+  'pokemon_trades',
+  'unknown_22',
+  'link_battle_wins',
+  'link_battle_losses',
+  'link_battle_draws',
+  'used_splash',
+  'used_struggle',
+  'slot_jackpots',
+  'consecutive_roulette_wins',
+  'entered_battle_tower',
+  'unknown_31',
+  'battle_tower_best_streak',
+  'pokeblocks',
+  'pokeblocks_with_friends',
+  'won_link_contest',
+  'entered_contest',
+  'won_contest',
+  'shopped',
+  'used_itemfinder',
+  'got_rained_on',
+  'checked_pokedex',
+  'received_ribbons',
+  'jumped_down_ledges',
+  'watched_tv',
+  'checked_clock',
+  'won_pokemon_lottery',
+  'used_daycare',
+  'rode_cable_car',
+  'entered_hot_springs',
+  'union_room_battles',
+  'played_berry_crush',
+] as const
+
+export type GameStatName = (typeof GAME_STAT_NAMES)[number]
+
+// first_hof_play_time is packed as (hours << 16) | (minutes << 8) | seconds
+export type GameStats = Readonly<Record<GameStatName, number>>
+
 /**
  * Vanilla Pokemon Emerald game signature
  */
 export const VANILLA_EMERALD_SIGNATURE = 0x08012025
 
+/**
+ * Gen 3 shiny threshold: shiny when the personality/OT XOR value is below it (1 in 8192)
+ */
+export const VANILLA_SHINY_THRESHOLD = 8
+
 /**
  * Type definitions for overridable configurations
  */
@@ -162,6 +841,27 @@ export interface GameConfig {
   /** Maximum party size (defaults to 6 for vanilla) */
   readonly maxPartySize: number
 
+  /** XOR value below which a Pokemon is shiny (defaults to 8; hacks may raise the odds) */
+  readonly shinyThreshold?: number
+
+  /**
+   * Whether species use Gen 3 base stats, types and abilities from the embedded species table,
+   * so stored stats can be verified (defaults to true)
+   */
+  readonly usesGen3SpeciesData?: boolean
+
+  /** Deoxys form for this game (Gen 3 ties it to the cartridge; defaults to normal) */
+  readonly deoxysForm?: 'normal' | 'attack' | 'defense' | 'speed'
+
+  /**
+   * Whether SaveBlock data beyond party, player name and play time (bag, flags, money...)
+   * follows the vanilla layout. Defaults to true; set false until a hack's layout is mapped
+   */
+  readonly supportsExtendedSaveData?: boolean
+
+  /** Currency caps for games that raise them; defaults to VANILLA_CURRENCY_LIMITS */
+  readonly currencyLimits?: Partial<Currency>
+
   /** Offset overrides for games with different data layouts */
   readonly offsetOverrides?: PokemonOffsetsOverride
 
@@ -176,6 +876,7 @@ export interface GameConfig {
     readonly pokemon?: ReadonlyMap<number, PokemonMapping>
     readonly items?: ReadonlyMap<number, ItemMapping>
     readonly moves?: ReadonlyMap<number, MoveMapping>
+    readonly locations?: ReadonlyMap<number, LocationMapping>
   }
 
   /** Check if this config can handle the given save data */
@@ -203,17 +904,30 @@ export interface GameConfig {
   isShiny?(personality: number, otId: number): boolean
   getShinyValue?(personality: number, otId: number): number
   isRadiant?(personality: number, otId: number): boolean
+  /** Mark a Pokemon shiny in games that store shininess instead of deriving it from the PID */
+  makeShiny?(data: Uint8Array, view: DataView): void
 
   // Optional data structure overrides (for games with completely different layouts)
+  /** Internal (unmapped) species ID */
   getSpeciesId?(data: Uint8Array, view: DataView): number
   getPokemonName?(data: Uint8Array, view: DataView): string | undefined
+  /** Form suffix for hacks that store forms as separate species (undefined = default form) */
+  getForm?(data: Uint8Array, view: DataView): string | undefined
+  /** Internal (unmapped) item ID */
   getItem?(data: Uint8Array, view: DataView): number
   getItemName?(data: Uint8Array, view: DataView): string | undefined
   setItem?(data: Uint8Array, view: DataView, value: number): void
+  /** Internal (unmapped) move ID */
   getMove?(data: Uint8Array, view: DataView, index: number): number
   getPP?(data: Uint8Array, view: DataView, index: number): number
+  setMove?(data: Uint8Array, view: DataView, index: number, value: number): void
+  setPP?(data: Uint8Array, view: DataView, index: number, value: number): void
   getEV?(data: Uint8Array, view: DataView, index: number): number
   setEV?(data: Uint8Array, view: DataView, index: number, value: number): void
   getIVs?(data: Uint8Array, view: DataView): readonly number[]
   setIVs?(data: Uint8Array, view: DataView, values: readonly number[]): void
+
+  // Raw substruct access for games that store the 4 substructures unencrypted/unshuffled
+  getSubstruct?(data: Uint8Array, view: DataView, index: number): Uint8Array
+  setSubstruct?(data: Uint8Array, view: DataView, index: number, value: Uint8Array): void
 }
@@ -4,6 +4,8 @@
  */
 
 import type { PokemonBase } from './PokemonBase'
+import type { PokemonType } from './species'
+import type { StatName } from './types'
 import charmapData from '../data/pokemon_charmap.json'
 
 // Convert charmap keys from strings to numbers for faster lookup
@@ -108,6 +110,14 @@ export function formatPlayTime(hours: number, minutes: number, seconds: number):
   return `${hours}:${minutes.toString().padStart(2, '0')}:${seconds.toString().padStart(2, '0')}`
 }
 
+/**
+ * XOR a stored value with the SaveBlock2 security key. Money uses the whole key, 16-bit values
+ * (coins, bag quantities) use its low half; applying it again re-encrypts the value
+ */
+export function applySecurityKey(value: number, key: number, bits: 16 | 32 = 32): number {
+  return bits === 16 ? (value ^ key) & 0xffff : (value ^ key) >>> 0
+}
+
 export const statStrings: string[] = [
   'HP',
   'Attack',
@@ -117,6 +127,16 @@ export const statStrings: string[] = [
   'Special Defense',
 ]
 
+// Snake_case stat keys in save order, matching PokemonStats
+export const statNames: readonly StatName[] = [
+  'hp',
+  'attack',
+  'defense',
+  'speed',
+  'sp_attack',
+  'sp_defense',
+]
+
 // Shared stat abbreviations used across UI components
 export const statAbbreviations: readonly string[] = [
   'HP',
@@ -163,6 +183,34 @@ export const natures = [
   'Careful',
   'Quirky',
 ]
+// Game of origin IDs stored in the origins word of the misc substructure
+export const originGames: Record<number, string> = {
+  0: 'Colosseum Bonus Disc',
+  1: 'Sapphire',
+  2: 'Ruby',
+  3: 'Emerald',
+  4: 'FireRed',
+  5: 'LeafGreen',
+  15: 'Colosseum/XD',
+}
+
+// Poke Ball IDs (index = ball ID stored in the origins word)
+export const pokeballNames: readonly string[] = [
+  'None',
+  'Master Ball',
+  'Ultra Ball',
+  'Great Ball',
+  'Poke Ball',
+  'Safari Ball',
+  'Net Ball',
+  'Dive Ball',
+  'Nest Ball',
+  'Repeat Ball',
+  'Timer Ball',
+  'Luxury Ball',
+  'Premier Ball',
+]
+
 /**
  * Get Pokemon nature from the first byte of the personality value
  * Pokemon nature is determined by (personality & 0xFF) % 25
@@ -217,6 +265,94 @@ export function findNatureForEffects(increased: number, decreased: number): stri
   }
   return 'Serious'
 }
+// Summary-screen characteristics per stat (HP, Atk, Def, Spe, SpA, SpD), indexed by IV % 5
+export const characteristics: readonly (readonly string[])[] = [
+  [
+    'Loves to eat',
+    'Takes plenty of siestas',
+    'Nods off a lot',
+    'Scatters things often',
+    'Likes to relax',
+  ],
+  [
+    'Proud of its power',
+    'Likes to thrash about',
+    'A little quick tempered',
+    'Likes to fight',
+    'Quick tempered',
+  ],
+  [
+    'Sturdy body',
+    'Capable of taking hits',
+    'Highly persistent',
+    'Good endurance',
+    'Good perseverance',
+  ],
+  [
+    'Likes to run',
+    'Alert to sounds',
+    'Impetuous and silly',
+    'Somewhat of a clown',
+    'Quick to flee',
+  ],
+  ['Highly curious', 'Mischievous', 'Thoroughly cunning', 'Often lost in thought', 'Very finicky'],
+  ['Strong willed', 'Somewhat vain', 'Strongly defiant', 'Hates to lose', 'Somewhat stubborn'],
+]
+
+/**
+ * Get the characteristic for a Pokemon from its highest IV
+ * Ties go to the first highest stat counting from personality % 6, wrapping around
+ * @param ivs Array of IVs [HP, Atk, Def, Spe, SpA, SpD]
+ * @param personality The Pokemon's personality value
+ */
+export function getCharacteristic(ivs: readonly number[], personality: number): string {
+  const maxIv = Math.max(...ivs)
+  const start = (personality >>> 0) % 6
+  let statIndex = start
+  for (let i = 0; i < 6; i++) {
+    const index = (start + i) % 6
+    if (ivs[index] === maxIv) {
+      statIndex = index
+      break
+    }
+  }
+  return characteristics[statIndex]![maxIv % 5]!
+}
+
+// Hidden Power types in the order the IV formula indexes them (never Normal)
+const HIDDEN_POWER_TYPES: readonly PokemonType[] = [
+  'fighting',
+  'flying',
+  'poison',
+  'ground',
+  'rock',
+  'bug',
+  'ghost',
+  'steel',
+  'fire',
+  'water',
+  'grass',
+  'electric',
+  'psychic',
+  'ice',
+  'dragon',
+  'dark',
+]
+
+/**
+ * Get Hidden Power's type and base power (30-70) from the IVs
+ * The type comes from each IV's lowest bit and the power from the second lowest
+ * @param ivs Array of IVs [HP, Atk, Def, Spe, SpA, SpD]
+ */
+export function getHiddenPower(ivs: readonly number[]): { type: PokemonType; power: number } {
+  const bits = (shift: number) =>
+    ivs.reduce((sum, iv, i) => sum + (((iv >> shift) & 1) << i), 0)
+  return {
+    type: HIDDEN_POWER_TYPES[Math.floor((bits(0) * 15) / 63)]!,
+    power: Math.floor((bits(1) * 40) / 63) + 30,
+  }
+}
+
 /**
  * Get nature modifier for a given stat
  * @param nature The Pokemon's nature
@@ -347,17 +483,26 @@ export interface BaseMappingItem {
 
 /**
  * Creates a Map from JSON mapping data, filtering out invalid entries
+ * Results are cached per source object, so repeated calls return the same Map
  * @param mapData - Raw JSON mapping data
  * @returns Map with numeric keys and validated mapping objects
  */
+// Mappings are built once per JSON source; configs are instantiated repeatedly during detection
+const mappingCache = new WeakMap<Record<string, unknown>, Map<number, BaseMappingItem>>()
+
 export function createMapping<T extends BaseMappingItem>(
   mapData: Record<string, unknown>
 ): Map<number, T> {
-  return new Map<number, T>(
+  const cached = mappingCache.get(mapData)
+  if (cached) return cached as Map<number, T>
+
+  const mapping = new Map<number, T>(
     Object.entries(mapData)
       .filter(([_, v]) => typeof v === 'object' && v !== null && 'id' in v && v.id !== null)
       .map(([k, v]) => [parseInt(k, 10), v as T])
   )
+  mappingCache.set(mapData, mapping)
+  return mapping
 }
 
 /**
@@ -0,0 +1,179 @@
+/**
+ * Save data validation producing structured warnings
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import { MAX_LEVEL, getSpeciesInfo } from './species'
+import type {
+  BagPocketName,
+  BagSlot,
+  GameConfig,
+  SaveWarning,
+  SkippedSlot,
+  SkippedSlotReason,
+} from './types'
+
+/**
+ * Share of impossible items above which the detected config is probably wrong
+ */
+const CONFIG_MISMATCH_RATIO = 0.25
+const CONFIG_MISMATCH_MIN_ITEMS = 3
+
+// Which item id_names belong in which pocket (items pocket accepts anything not claimed here)
+const POCKET_PATTERNS: Partial<Record<BagPocketName, RegExp>> = {
+  pokeBalls: /-ball$/,
+  tmHm: /^(tm|hm)\d+$/,
+  berries: /-berry$/,
+}
+
+/**
+ * The pocket an item belongs in by its id_name, or null when only the items pocket can tell
+ */
+export function pocketForItem(idName: string): BagPocketName | null {
+  for (const [pocket, pattern] of Object.entries(POCKET_PATTERNS)) {
+    if (pattern.test(idName)) return pocket as BagPocketName
+  }
+  return null
+}
+
+/**
+ * Whether an item may sit in a pocket; only pockets with a recognizable naming scheme are checked
+ */
+export function isItemAllowedInPocket(idName: string, pocket: BagPocketName): boolean {
+  const expected = pocketForItem(idName)
+  return pocket in POCKET_PATTERNS ? expected === pocket : pocket !== 'items' || expected === null
+}
+
+/**
+ * Check held items and bag contents against the active game's item list
+ * Items missing from the config's mapping or sitting in the wrong pocket are flagged;
+ * many such items at once usually mean the wrong config was detected
+ */
+export function validateItemLegality(
+  config: GameConfig,
+  party: readonly PokemonBase[],
+  bag: readonly BagSlot[]
+): SaveWarning[] {
+  const items = config.mappings?.items
+  if (!items) return []
+
+  const warnings: SaveWarning[] = []
+  let checked = 0
+  let impossible = 0
+
+  party.forEach((pokemon, slot) => {
+    if (pokemon.item === 0) return
+    checked++
+    if (pokemon.itemIdName === undefined) {
+      impossible++
+      warnings.push({
+        code: 'held-item-unknown',
+        severity: 'warning',
+        message: `Party slot ${slot + 1} holds item ${pokemon.item}, which does not exist in ${config.name}`,
+        context: { slot: slot + 1, item: pokemon.item },
+      })
+    }
+  })
+
+  for (const entry of bag) {
+    if (entry.rawItemId === 0) continue
+    checked++
+    const mapping = items.get(entry.rawItemId)
+    if (!mapping) {
+      impossible++
+      warnings.push({
+        code: 'bag-item-unknown',
+        severity: 'warning',
+        message: `Bag pocket "${entry.pocket}" slot ${entry.slot + 1} contains item ${entry.rawItemId}, which does not exist in ${config.name}`,
+        context: { pocket: entry.pocket, slot: entry.slot + 1, item: entry.rawItemId },
+      })
+      continue
+    }
+
+    if (!isItemAllowedInPocket(mapping.id_name, entry.pocket)) {
+      impossible++
+      warnings.push({
+        code: 'bag-item-wrong-pocket',
+        severity: 'warning',
+        message: `${mapping.name} cannot be stored in the "${entry.pocket}" pocket`,
+        context: { pocket: entry.pocket, slot: entry.slot + 1, item: entry.rawItemId },
+      })
+    }
+  }
+
+  if (impossible >= CONFIG_MISMATCH_MIN_ITEMS && impossible / checked > CONFIG_MISMATCH_RATIO) {
+    warnings.push({
+      code: 'config-mismatch',
+      severity: 'error',
+      message: `${impossible} of ${checked} items are impossible in ${config.name}; the save may belong to a different game`,
+      context: { impossible, checked, config: config.name },
+    })
+  }
+
+  return warnings
+}
+
+const STAT_LABELS = ['HP', 'Attack', 'Defense', 'Speed', 'Sp. Atk', 'Sp. Def'] as const
+
+/**
+ * Compare each party member's stored stats with the values recomputed from base stats, IVs,
+ * EVs, nature and level; mismatches point to edited (cheated) or corrupted Pokemon
+ */
+export function validateStats(party: readonly PokemonBase[]): SaveWarning[] {
+  const warnings: SaveWarning[] = []
+
+  party.forEach((pokemon, slot) => {
+    const expected = pokemon.expectedStats
+    if (!expected) return
+    const stored = pokemon.stats
+    const mismatched = STAT_LABELS.filter((_, i) => stored[i] !== expected[i])
+    if (mismatched.length === 0) return
+
+    warnings.push({
+      code: 'stats-mismatch',
+      severity: 'warning',
+      message: `Party slot ${slot + 1} (${pokemon.nameId ?? `species ${pokemon.speciesId}`}) has stats ${stored.join('/')} but ${expected.join('/')} were expected (${mismatched.join(', ')})`,
+      context: { slot: slot + 1, stored: stored.join('/'), expected: expected.join('/') },
+    })
+  })
+
+  return warnings
+}
+
+/**
+ * Structured validity check for a non-empty Pokemon slot: Bad Egg flag or checksum failure,
+ * species outside the config's species list, and impossible levels
+ * Returns why the Pokemon should be skipped, or null when it looks sane
+ */
+export function checkPokemonSanity(
+  pokemon: PokemonBase,
+  config: GameConfig
+): Omit<SkippedSlot, 'slot'> | null {
+  const issue = (reason: SkippedSlotReason, message: string) => ({ reason, message })
+
+  if (pokemon.isBadEgg) {
+    return pokemon.isChecksumValid === false
+      ? issue(
+          'checksum-mismatch',
+          `Data checksum ${pokemon.checksum} does not match ${pokemon.computedChecksum}; the game shows this Pokemon as a Bad Egg`
+        )
+      : issue('bad-egg', 'The game has flagged this Pokemon as a Bad Egg')
+  }
+
+  // Configs with a species mapping define their own species range; otherwise use the Gen 3 dex
+  const knownSpecies = config.mappings?.pokemon
+    ? pokemon.nameId !== undefined
+    : getSpeciesInfo(pokemon.speciesId) !== undefined
+  if (!knownSpecies) {
+    return issue(
+      'species-out-of-range',
+      `Species ${pokemon.speciesId} does not exist in ${config.name}`
+    )
+  }
+
+  if (pokemon.level < 1 || pokemon.level > MAX_LEVEL) {
+    return issue('level-out-of-range', `Level ${pokemon.level} is outside 1-${MAX_LEVEL}`)
+  }
+
+  return null
+}
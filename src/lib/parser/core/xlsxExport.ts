@@ -0,0 +1,250 @@
+/**
+ * Excel workbook export of save contents
+ * One sheet each for the party, the PC boxes, the bag and the Pokédex, written as a minimal
+ * SpreadsheetML package (a ZIP of XML parts) that Excel, LibreOffice and Google Sheets open
+ * See: ECMA-376 Part 1, §18 (SpreadsheetML)
+ */
+
+import type { PokemonBase } from './PokemonBase'
+import { toPokemonJson } from './saveJson'
+import { getSpeciesName } from './species'
+import type { BagPocketName, GameConfig, SaveData } from './types'
+import { createZip } from './zip'
+
+export type XlsxCell = string | number | boolean | null
+
+export interface XlsxSheet {
+  /** Tab name; at most 31 characters and none of : \ / ? * [ ] */
+  readonly name: string
+  /** The first row is the header: bold, and frozen while scrolling */
+  readonly rows: readonly (readonly XlsxCell[])[]
+}
+
+const MAIN_NS = 'http://schemas.openxmlformats.org/spreadsheetml/2006/main'
+const REL_NS = 'http://schemas.openxmlformats.org/officeDocument/2006/relationships'
+const PACKAGE_REL_NS = 'http://schemas.openxmlformats.org/package/2006/relationships'
+const CONTENT_TYPE = 'application/vnd.openxmlformats-officedocument.spreadsheetml'
+const XML_DECLARATION = '<?xml version="1.0" encoding="UTF-8" standalone="yes"?>\n'
+
+// Characters XML 1.0 doesn't allow at all, even escaped
+// eslint-disable-next-line no-control-regex
+const INVALID_XML_CHARS = /[\u0000-\u0008\u000b\u000c\u000e-\u001f\ufffe\uffff]/g
+
+const escapeXml = (text: string) =>
+  text
+    .replace(INVALID_XML_CHARS, '')
+    .replaceAll('&', '&amp;')
+    .replaceAll('<', '&lt;')
+    .replaceAll('>', '&gt;')
+    .replaceAll('"', '&quot;')
+
+/** Column letters for a 0-based index: A…Z, AA, AB… */
+const columnName = (index: number): string =>
+  (index >= 26 ? columnName(Math.floor(index / 26) - 1) : '') +
+  String.fromCharCode(65 + (index % 26))
+
+function cellXml(value: XlsxCell, ref: string, header: boolean): string {
+  if (value === null) return ''
+  const style = header ? ' s="1"' : ''
+  if (typeof value === 'number') return `<c r="${ref}"${style}><v>${value}</v></c>`
+  if (typeof value === 'boolean') {
+    return `<c r="${ref}"${style} t="b"><v>${Number(value)}</v></c>`
+  }
+  // Inline strings avoid a shared string table; spaces at either end need xml:space
+  const space = value.trim() === value ? '' : ' xml:space="preserve"'
+  return `<c r="${ref}"${style} t="inlineStr"><is><t${space}>${escapeXml(value)}</t></is></c>`
+}
+
+function sheetXml({ rows }: XlsxSheet): string {
+  // Excel has no auto-fit on open, so size columns to their longest value
+  const widths: number[] = []
+  for (const row of rows) {
+    row.forEach((value, i) => {
+      const length = value === null ? 0 : String(value).length
+      widths[i] = Math.max(widths[i] ?? 6, Math.min(40, length + 2))
+    })
+  }
+  const cols = widths
+    .map((width, i) => `<col min="${i + 1}" max="${i + 1}" width="${width}" customWidth="1"/>`)
+    .join('')
+  const rowsXml = rows
+    .map((row, r) => {
+      const cells = row.map((value, c) => cellXml(value, `${columnName(c)}${r + 1}`, r === 0))
+      return `<row r="${r + 1}">${cells.join('')}</row>`
+    })
+    .join('')
+
+  return (
+    `${XML_DECLARATION}<worksheet xmlns="${MAIN_NS}"><sheetViews><sheetView workbookViewId="0">` +
+    '<pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/>' +
+    `</sheetView></sheetViews>${cols ? `<cols>${cols}</cols>` : ''}` +
+    `<sheetData>${rowsXml}</sheetData></worksheet>`
+  )
+}
+
+// Style 0 is the default, style 1 the bold header; a fill list must start with none and gray125
+const STYLES_XML =
+  `${XML_DECLARATION}<styleSheet xmlns="${MAIN_NS}">` +
+  '<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font>' +
+  '<font><b/><sz val="11"/><name val="Calibri"/></font></fonts>' +
+  '<fills count="2"><fill><patternFill patternType="none"/></fill>' +
+  '<fill><patternFill patternType="gray125"/></fill></fills>' +
+  '<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>' +
+  '<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>' +
+  '<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>' +
+  '<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>' +
+  '</styleSheet>'
+
+/**
+ * Package sheets as an .xlsx workbook, in tab order
+ */
+export function createXlsx(sheets: readonly XlsxSheet[]): Uint8Array {
+  const encoder = new TextEncoder()
+  const sheetPath = (i: number) => `worksheets/sheet${i + 1}.xml`
+
+  const contentTypes =
+    `${XML_DECLARATION}<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+    '<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>' +
+    '<Default Extension="xml" ContentType="application/xml"/>' +
+    `<Override PartName="/xl/workbook.xml" ContentType="${CONTENT_TYPE}.sheet.main+xml"/>` +
+    `<Override PartName="/xl/styles.xml" ContentType="${CONTENT_TYPE}.styles+xml"/>` +
+    sheets
+      .map(
+        (_, i) =>
+          `<Override PartName="/xl/${sheetPath(i)}" ContentType="${CONTENT_TYPE}.worksheet+xml"/>`
+      )
+      .join('') +
+    '</Types>'
+  const rootRels =
+    `${XML_DECLARATION}<Relationships xmlns="${PACKAGE_REL_NS}">` +
+    `<Relationship Id="rId1" Type="${REL_NS}/officeDocument" Target="xl/workbook.xml"/>` +
+    '</Relationships>'
+  const workbook =
+    `${XML_DECLARATION}<workbook xmlns="${MAIN_NS}" xmlns:r="${REL_NS}"><sheets>` +
+    sheets
+      .map((sheet, i) => {
+        const name = escapeXml(sheet.name)
+        return `<sheet name="${name}" sheetId="${i + 1}" r:id="rId${i + 1}"/>`
+      })
+      .join('') +
+    '</sheets></workbook>'
+  const workbookRels =
+    `${XML_DECLARATION}<Relationships xmlns="${PACKAGE_REL_NS}">` +
+    sheets
+      .map(
+        (_, i) =>
+          `<Relationship Id="rId${i + 1}" Type="${REL_NS}/worksheet" Target="${sheetPath(i)}"/>`
+      )
+      .join('') +
+    `<Relationship Id="rId${sheets.length + 1}" Type="${REL_NS}/styles" Target="styles.xml"/>` +
+    '</Relationships>'
+
+  return createZip(
+    [
+      ['[Content_Types].xml', contentTypes],
+      ['_rels/.rels', rootRels],
+      ['xl/workbook.xml', workbook],
+      ['xl/_rels/workbook.xml.rels', workbookRels],
+      ['xl/styles.xml', STYLES_XML],
+      ...sheets.map((sheet, i) => [`xl/${sheetPath(i)}`, sheetXml(sheet)] as const),
+    ].map(([name, xml]) => ({ name, data: encoder.encode(xml) }))
+  )
+}
+
+// Stats are stored HP, Atk, Def, Spe, SpA, SpD
+const STAT_LABELS = ['HP', 'Atk', 'Def', 'Spe', 'SpA', 'SpD']
+
+const POKEMON_HEADER: readonly string[] = [
+  'Species',
+  'Nickname',
+  'Level',
+  'Nature',
+  'Ability',
+  'Item',
+  'Shiny',
+  'Egg',
+  'Move 1',
+  'Move 2',
+  'Move 3',
+  'Move 4',
+  'Current HP',
+  ...STAT_LABELS,
+  ...STAT_LABELS.map(stat => `IV ${stat}`),
+  ...STAT_LABELS.map(stat => `EV ${stat}`),
+  'OT',
+  'OT ID',
+]
+
+function pokemonRow(pokemon: PokemonBase, config: GameConfig): XlsxCell[] {
+  const json = toPokemonJson(pokemon, config)
+  return [
+    json.species_name ?? `#${json.species_id}`,
+    json.nickname,
+    json.level,
+    json.nature,
+    json.ability,
+    json.item_name,
+    json.is_shiny,
+    json.is_egg,
+    ...Array.from({ length: 4 }, (_, i) => json.move_names[i] ?? null),
+    json.current_hp,
+    ...json.stats,
+    ...json.ivs,
+    ...json.evs,
+    json.ot_name,
+    json.ot_id,
+  ]
+}
+
+const BAG_POCKET_LABELS: Record<BagPocketName, string> = {
+  items: 'Items',
+  pokeBalls: 'Poké Balls',
+  tmHm: 'TMs & HMs',
+  berries: 'Berries',
+  keyItems: 'Key Items',
+}
+
+/**
+ * The Party, Boxes, Items and Pokédex sheets for a save. Sections the game's layout doesn't
+ * map (boxes, bag or Pokédex) keep their header row so the workbook always has the same shape
+ */
+export function saveToXlsxSheets(saveData: SaveData, config: GameConfig): XlsxSheet[] {
+  const { boxes, box_metadata: boxMetadata, bag, pokedex } = saveData
+
+  const party = saveData.party_pokemon.map((pokemon, slot) => [
+    slot + 1,
+    ...pokemonRow(pokemon, config),
+  ])
+  const boxRows = (boxes ?? []).flatMap((slots, box) => {
+    const boxName = boxMetadata?.[box]?.name ?? `Box ${box + 1}`
+    return slots.flatMap((pokemon, slot) =>
+      pokemon ? [[boxName, slot + 1, ...pokemonRow(pokemon, config)]] : []
+    )
+  })
+  const pockets = Object.entries(BAG_POCKET_LABELS) as [BagPocketName, string][]
+  const items = bag
+    ? pockets.flatMap(([pocket, label]) =>
+        bag[pocket].map(item => [label, item.name, item.quantity])
+      )
+    : []
+  const dex = (pokedex?.seen ?? []).map((seen, i) => [
+    i + 1,
+    getSpeciesName(i + 1) ?? null,
+    seen,
+    pokedex?.caught[i] ?? false,
+  ])
+
+  return [
+    { name: 'Party', rows: [['Slot', ...POKEMON_HEADER], ...party] },
+    { name: 'Boxes', rows: [['Box', 'Slot', ...POKEMON_HEADER], ...boxRows] },
+    { name: 'Items', rows: [['Pocket', 'Item', 'Quantity'], ...items] },
+    { name: 'Pokédex', rows: [['No.', 'Species', 'Seen', 'Caught'], ...dex] },
+  ]
+}
+
+/**
+ * Excel workbook (.xlsx) of a save's party, boxes, bag and Pokédex
+ */
+export function saveToXlsx(saveData: SaveData, config: GameConfig): Uint8Array {
+  return createXlsx(saveToXlsxSheets(saveData, config))
+}
@@ -0,0 +1,91 @@
+/**
+ * Minimal ZIP archive writer
+ * Entries are stored uncompressed, which every unzip tool and Office package reader accepts; it
+ * keeps exports like XLSX dependency-free
+ */
+
+export interface ZipEntry {
+  /** Path inside the archive, with forward slashes */
+  readonly name: string
+  readonly data: Uint8Array
+}
+
+const CRC_TABLE = Array.from({ length: 256 }, (_, n) => {
+  let c = n
+  for (let k = 0; k < 8; k++) c = c & 1 ? 0xedb88320 ^ (c >>> 1) : c >>> 1
+  return c >>> 0
+})
+
+/**
+ * CRC-32 as used by ZIP and PNG
+ */
+export function crc32(bytes: Uint8Array): number {
+  let crc = 0xffffffff
+  for (const byte of bytes) crc = CRC_TABLE[(crc ^ byte) & 0xff]! ^ (crc >>> 8)
+  return (crc ^ 0xffffffff) >>> 0
+}
+
+const LOCAL_HEADER_SIZE = 30
+const CENTRAL_HEADER_SIZE = 46
+const END_RECORD_SIZE = 22
+// MS-DOS date for 1980-01-01, so the same entries always produce the same bytes
+const DOS_DATE = (0 << 9) | (1 << 5) | 1
+
+/**
+ * Build a ZIP archive of stored entries, in the given order
+ */
+export function createZip(entries: readonly ZipEntry[]): Uint8Array {
+  const encoder = new TextEncoder()
+  const files = entries.map(entry => ({
+    name: encoder.encode(entry.name),
+    data: entry.data,
+    crc: crc32(entry.data),
+  }))
+  const localSize = files.reduce(
+    (sum, file) => sum + LOCAL_HEADER_SIZE + file.name.length + file.data.length,
+    0
+  )
+  const centralSize = files.reduce((sum, file) => sum + CENTRAL_HEADER_SIZE + file.name.length, 0)
+  const zip = new Uint8Array(localSize + centralSize + END_RECORD_SIZE)
+  const view = new DataView(zip.buffer)
+
+  // Fields shared by the local and central headers, from "version needed" to the name length
+  const writeCommon = (pos: number, file: (typeof files)[number]) => {
+    view.setUint16(pos, 20, true) // version needed: 2.0
+    view.setUint16(pos + 2, 0x0800, true) // names are UTF-8
+    view.setUint16(pos + 4, 0, true) // stored
+    view.setUint16(pos + 6, 0, true)
+    view.setUint16(pos + 8, DOS_DATE, true)
+    view.setUint32(pos + 10, file.crc, true)
+    view.setUint32(pos + 14, file.data.length, true)
+    view.setUint32(pos + 18, file.data.length, true)
+    view.setUint16(pos + 22, file.name.length, true)
+  }
+
+  const offsets: number[] = []
+  let pos = 0
+  for (const file of files) {
+    offsets.push(pos)
+    view.setUint32(pos, 0x04034b50, true)
+    writeCommon(pos + 4, file)
+    zip.set(file.name, pos + LOCAL_HEADER_SIZE)
+    zip.set(file.data, pos + LOCAL_HEADER_SIZE + file.name.length)
+    pos += LOCAL_HEADER_SIZE + file.name.length + file.data.length
+  }
+
+  files.forEach((file, i) => {
+    view.setUint32(pos, 0x02014b50, true)
+    view.setUint16(pos + 4, 20, true) // version made by
+    writeCommon(pos + 6, file)
+    view.setUint32(pos + 42, offsets[i]!, true)
+    zip.set(file.name, pos + CENTRAL_HEADER_SIZE)
+    pos += CENTRAL_HEADER_SIZE + file.name.length
+  })
+
+  view.setUint32(pos, 0x06054b50, true)
+  view.setUint16(pos + 8, files.length, true)
+  view.setUint16(pos + 10, files.length, true)
+  view.setUint32(pos + 12, centralSize, true)
+  view.setUint32(pos + 16, localSize, true)
+  return zip
+}
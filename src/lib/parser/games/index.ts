@@ -3,7 +3,7 @@
  * Automatically registers all available game configs
  */
 
-import { gameConfigRegistry } from '../core/GameConfigRegistry'
+import { type GameConfigConstructor, gameConfigRegistry } from '../core/GameConfigRegistry'
 import { QuetzalConfig } from './quetzal/config'
 import { VanillaConfig } from './vanilla/config'
 
@@ -16,3 +16,13 @@ export { gameConfigRegistry as GameConfigRegistry }
 
 // Export individual configs for direct usage if needed
 export { QuetzalConfig, VanillaConfig }
+
+/** Registered game configs by their --game name: the last word of the config's name */
+export function gameConfigsByName(): Map<string, GameConfigConstructor> {
+  return new Map(
+    gameConfigRegistry.getRegisteredConfigs().map(Config => {
+      const name = /(\w+)\W*$/.exec(new Config().name)?.[1] ?? ''
+      return [name.toLowerCase(), Config]
+    })
+  )
+}
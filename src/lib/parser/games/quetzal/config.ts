@@ -14,10 +14,36 @@ import {
 } from '../../core/types'
 import { createMapping, natures } from '../../core/utils'
 import { GameConfigBase } from '../../core/GameConfigBase'
+import { toRawId } from '../../core/mappingIndex'
+import { UNOWN_FORMS } from '../../core/species'
 import itemMapData from './data/item_map.json'
 import moveMapData from './data/move_map.json'
 import pokemonMapData from './data/pokemon_map.json'
 
+// Raw species ID ranges holding regional and Mega forms (unlisted IDs are default forms)
+const QUETZAL_FORM_RANGES: readonly { first: number; last: number; form: string }[] = [
+  { first: 899, last: 946, form: 'mega' },
+  { first: 947, last: 948, form: 'primal' },
+  { first: 949, last: 966, form: 'alola' },
+  { first: 967, last: 985, form: 'galar' },
+]
+
+// Raw species IDs whose form does not follow a range
+const QUETZAL_FORM_OVERRIDES: Readonly<Record<number, string>> = {
+  900: 'megax',
+  901: 'megay',
+  912: 'megax',
+  913: 'megay',
+  // Unown B-Z, ! and ? follow the base Unown (A)
+  ...Object.fromEntries(UNOWN_FORMS.slice(1).map((letter, i) => [1001 + i, letter])),
+  1028: 'sunny',
+  1029: 'rainy',
+  1030: 'snowy',
+  1031: 'attack',
+  1032: 'defense',
+  1033: 'speed',
+}
+
 export class QuetzalConfig extends GameConfigBase implements GameConfig {
   readonly name = 'Pokemon Quetzal'
 
@@ -28,6 +54,12 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
   // Quetzal includes Mega Evolution feature
   readonly supportsMega = true
 
+  // Species use modern base stats, types and abilities, so the Gen 3 species table does not apply
+  readonly usesGen3SpeciesData = false
+
+  // SaveBlock layout beyond party/name/play time (bag, flags, money) is not mapped yet
+  readonly supportsExtendedSaveData = false
+
   // Override offsets for Quetzal's unencrypted structure
   readonly offsetOverrides: PokemonOffsetsOverride = {
     currentHp: 0x23,
@@ -39,6 +71,7 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     spDefense: 0x64,
     status: 0x57,
     level: 0x58,
+    mail: 0x59,
   }
 
   // Override save layout for Quetzal
@@ -107,13 +140,14 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     spaEV: 0x44,
     spdEV: 0x45,
     ivData: 0x50,
+    // Substructs are stored unencrypted in fixed Growth/Attacks/EVs/Misc order
+    substructs: 0x28,
   } as const
 
   // Override data access methods for Quetzal's unencrypted structure
   getSpeciesId(_data: Uint8Array, view: DataView): number {
-    const rawSpecies = view.getUint16(this.quetzalOffsets.species, true)
-    // Apply ID mapping using the base mapping system
-    return this.mappings.pokemon.get(rawSpecies)?.id ?? rawSpecies
+    // Internal species ID; PokemonBase maps it to the National Dex number
+    return view.getUint16(this.quetzalOffsets.species, true)
   }
 
   getPokemonName(_data: Uint8Array, view: DataView): string | undefined {
@@ -122,10 +156,18 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     return this.mappings.pokemon.get(rawSpecies)?.id_name
   }
 
+  getForm(_data: Uint8Array, view: DataView): string | undefined {
+    // Quetzal stores forms as separate species IDs that share the base species mapping
+    const rawSpecies = view.getUint16(this.quetzalOffsets.species, true)
+    const explicit = QUETZAL_FORM_OVERRIDES[rawSpecies]
+    if (explicit) return explicit
+    return QUETZAL_FORM_RANGES.find(({ first, last }) => rawSpecies >= first && rawSpecies <= last)
+      ?.form
+  }
+
   getItem(_data: Uint8Array, view: DataView): number {
-    const rawItem = view.getUint16(this.quetzalOffsets.item, true)
-    // Apply ID mapping using the base mapping system
-    return this.mappings.items.get(rawItem)?.id ?? rawItem
+    // Internal item ID; PokemonBase maps it to the external ID
+    return view.getUint16(this.quetzalOffsets.item, true)
   }
 
   getItemName(_data: Uint8Array, view: DataView): string | undefined {
@@ -136,14 +178,7 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
 
   setItem(_data: Uint8Array, view: DataView, value: number): void {
     // Convert mapped (external) ID back to internal raw ID if a mapping exists
-    let raw = value
-    for (const [rawKey, entry] of this.mappings.items.entries()) {
-      if (entry.id === value) {
-        raw = rawKey
-        break
-      }
-    }
-    view.setUint16(this.quetzalOffsets.item, raw, true)
+    view.setUint16(this.quetzalOffsets.item, toRawId(this.mappings.items, value), true)
   }
 
   getMove(_data: Uint8Array, view: DataView, index: number): number {
@@ -153,9 +188,8 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
       this.quetzalOffsets.move3,
       this.quetzalOffsets.move4,
     ]
-    const rawMove = view.getUint16(moveOffsets[index]!, true)
-    // Apply ID mapping using the base mapping system
-    return this.mappings.moves.get(rawMove)?.id ?? rawMove
+    // Internal move ID; PokemonBase maps it to the external ID
+    return view.getUint16(moveOffsets[index]!, true)
   }
 
   getPP(_data: Uint8Array, view: DataView, index: number): number {
@@ -168,6 +202,26 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     return view.getUint8(ppOffsets[index]!)
   }
 
+  setMove(_data: Uint8Array, view: DataView, index: number, value: number): void {
+    const moveOffsets = [
+      this.quetzalOffsets.move1,
+      this.quetzalOffsets.move2,
+      this.quetzalOffsets.move3,
+      this.quetzalOffsets.move4,
+    ]
+    view.setUint16(moveOffsets[index]!, toRawId(this.mappings.moves, value), true)
+  }
+
+  setPP(_data: Uint8Array, view: DataView, index: number, value: number): void {
+    const ppOffsets = [
+      this.quetzalOffsets.pp1,
+      this.quetzalOffsets.pp2,
+      this.quetzalOffsets.pp3,
+      this.quetzalOffsets.pp4,
+    ]
+    view.setUint8(ppOffsets[index]!, Math.max(0, Math.min(255, value)))
+  }
+
   getEV(_data: Uint8Array, view: DataView, index: number): number {
     const evOffsets = [
       this.quetzalOffsets.hpEV,
@@ -208,6 +262,15 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     view.setUint32(this.quetzalOffsets.ivData, packed, true)
   }
 
+  getSubstruct(data: Uint8Array, _view: DataView, index: number): Uint8Array {
+    const offset = this.quetzalOffsets.substructs + index * 12
+    return data.slice(offset, offset + 12)
+  }
+
+  setSubstruct(data: Uint8Array, _view: DataView, index: number, value: Uint8Array): void {
+    data.set(value, this.quetzalOffsets.substructs + index * 12)
+  }
+
   /**
    * Override nature calculation for Quetzal-specific formula
    */
@@ -228,10 +291,12 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     if (currentNature === value) return
 
     // Calculate new first byte: preserve quotient, set remainder to desired nature
+    // (stepping down a cycle when that would overflow the byte, e.g. 250 + 10)
     const newFirstByte = currentFirstByte - currentNature + value
+    const firstByte = newFirstByte > 0xff ? newFirstByte - 25 : newFirstByte
 
     // Update personality with new first byte
-    const newPersonality = (currentPersonality & 0xffffff00) | (newFirstByte & 0xff)
+    const newPersonality = (currentPersonality & 0xffffff00) | firstByte
     view.setUint32(0x00, newPersonality >>> 0, true)
   }
 
@@ -262,6 +327,11 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
     return this.getShinyValue(personality, _otId) === 2
   }
 
+  makeShiny(_data: Uint8Array, view: DataView): void {
+    // The shiny byte sits above the nature byte, so the nature is unaffected
+    view.setUint8(0x01, 1)
+  }
+
   /**
    * Check if this config can handle the given save file
    * Use parsing success as detection criteria with base class helpers
@@ -282,7 +352,8 @@ export class QuetzalConfig extends GameConfigBase implements GameConfig {
       const pokemonFound = this.parsePokemonForDetection(
         saveblock1Data,
         this.pokemonSize,
-        (data, view) => this.getSpeciesId(data, view)
+        (_data, view) =>
+          this.mappings.pokemon.has(view.getUint16(this.quetzalOffsets.species, true))
       )
 
       // Return true if we found valid Pokemon data
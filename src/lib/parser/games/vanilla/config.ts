@@ -7,11 +7,13 @@ import {
   VANILLA_SAVE_LAYOUT,
   type GameConfig,
   type ItemMapping,
+  type LocationMapping,
   type MoveMapping,
   type PokemonMapping,
 } from '../../core/types'
 import { GameConfigBase } from '../../core/GameConfigBase'
 import itemMapData from './data/item_map.json'
+import locationMapData from './data/location_map.json'
 import moveMapData from './data/move_map.json'
 import pokemonMapData from './data/pokemon_map.json'
 import { createMapping } from '../../core/utils'
@@ -29,6 +31,9 @@ export class VanillaConfig extends GameConfigBase implements GameConfig {
   // Vanilla Emerald does not support Mega Evolution
   readonly supportsMega = false
 
+  // Emerald cartridges always load Deoxys in its Speed Forme
+  readonly deoxysForm = 'speed'
+
   // Use default save layout with no overrides
   readonly saveLayout = VANILLA_SAVE_LAYOUT
 
@@ -37,6 +42,7 @@ export class VanillaConfig extends GameConfigBase implements GameConfig {
     pokemon: createMapping<PokemonMapping>(pokemonMapData as Record<string, unknown>),
     moves: createMapping<MoveMapping>(moveMapData as Record<string, unknown>),
     items: createMapping<ItemMapping>(itemMapData as Record<string, unknown>),
+    locations: createMapping<LocationMapping>(locationMapData as Record<string, unknown>),
   } as const
 
   // Memory addresses for Pokémon Emerald (USA) in mGBA (from official pokemon.lua script)
@@ -249,8 +249,8 @@ export const buildPartyListFromSaveData = (saveData: SaveData): UIPokemonData[]
 
     const SPRITE_ANI_BASE_URL = '/sprites'
     const spriteAniUrl = useAltSprite
-      ? `${SPRITE_ANI_BASE_URL}/shiny/${parsedPokemon.nameId}.gif`
-      : `${SPRITE_ANI_BASE_URL}/${parsedPokemon.nameId}.gif`
+      ? `${SPRITE_ANI_BASE_URL}/shiny/${parsedPokemon.formNameId}.gif`
+      : `${SPRITE_ANI_BASE_URL}/${parsedPokemon.formNameId}.gif`
 
     let uiId: number | undefined = Array.isArray(pending) ? pending[index] : undefined
     if (uiId && usedIds.has(uiId)) uiId = undefined
@@ -90,6 +90,23 @@ export const useSaveFileStore = create<SaveFileStore>((set, get) => ({
         } catch {}
       }
       if (!transient) {
+        const warnings = saveData.warnings?.filter(w => w.severity !== 'info') ?? []
+        const skipped = saveData.skipped_slots ?? []
+        const problems = [
+          ...skipped.map(s => {
+            const where = s.box ? `Box ${s.box} slot ${s.slot}` : `Party slot ${s.slot}`
+            return `${where} was skipped: ${s.message}`
+          }),
+          ...warnings.map(w => w.message),
+        ]
+        if (problems.length > 0) {
+          toast.warning(
+            problems.length === 1
+              ? problems[0]!
+              : `${problems.length} problems found in this save file`,
+            { position: 'bottom-center', duration: 5000 }
+          )
+        }
         try {
           if (parser.fileHandle) {
             await addRecent(parser.fileHandle, parser.saveFileName ?? 'Save file')